@@ -0,0 +1,141 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"slices"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/internal/archive"
+)
+
+// ErrUnrecognizedFormat is returned by Dispatcher when no parser recognizes the binary format.
+var ErrUnrecognizedFormat = errors.New("unrecognized binary format")
+
+// Dispatcher routes binary analysis to format-specific analyzers.
+type Dispatcher struct {
+	analyzers []FormatAnalyzer
+	logger    *slog.Logger
+}
+
+// DispatcherOptions configures Dispatcher creation.
+type DispatcherOptions struct {
+	// Analyzers are tried in order for every binary: the first whose Recognizes accepts its magic and whose Analyze doesn't
+	// return binary.ErrUnsupportedFormat analyzes it. Formats without an analyzer are left unrecognized.
+	Analyzers []FormatAnalyzer
+	// Logger receives debug messages about the binaries dispatched. A nil Logger uses slog.Default().
+	Logger *slog.Logger
+}
+
+// NewDispatcher creates a dispatcher with the given analyzers.
+func NewDispatcher(opts DispatcherOptions) *Dispatcher {
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Dispatcher{
+		analyzers: opts.Analyzers,
+		logger:    logger.With(slog.String("component", "dispatcher")),
+	}
+}
+
+// arMagicPrefix is the start of the ar archive header of static libraries.
+var arMagicPrefix = []byte("!<ar")
+
+// Recognizes reports whether magic, the first MagicSize bytes of a file, starts an input Analyze may accept:
+// a static library or a binary of a format some analyzer recognizes.
+// It is a cheap prefilter: inputs it accepts may still fail to parse, but inputs it rejects are never recognized.
+func (d *Dispatcher) Recognizes(magic []byte) bool {
+	if bytes.HasPrefix(magic, arMagicPrefix) {
+		return true
+	}
+	return slices.ContainsFunc(d.analyzers, func(a FormatAnalyzer) bool { return a.Recognizes(magic) })
+}
+
+// Analyze parses the binary and returns analysis results.
+// Returns slice to handle fat/universal binaries (one result per arch slice) and static libraries (one result per member object).
+// file is the filesystem metadata of the file r was read from, or nil when unknown; rules for privileged binaries need it.
+// Returns ErrUnsupportedFormat if no parser matches, other errors for parse failures.
+func (d *Dispatcher) Analyze(ctx context.Context, r io.ReaderAt, file *binary.FileMetadata) ([]AnalysisResult, error) {
+	if archive.IsAr(r) {
+		return d.analyzeArchive(ctx, r)
+	}
+	return d.analyzeBinary(ctx, r, file)
+}
+
+// analyzeArchive analyzes every member of an ar archive, such as the objects of a static library.
+// Members in formats no parser recognizes, such as LTO bitcode, are skipped. They are never executed, so they get no file metadata.
+func (d *Dispatcher) analyzeArchive(ctx context.Context, r io.ReaderAt) ([]AnalysisResult, error) {
+	var results []AnalysisResult
+	err := archive.WalkAr(io.NewSectionReader(r, 0, math.MaxInt64), func(e archive.Entry) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		data, err := io.ReadAll(e.Content)
+		if err != nil {
+			return fmt.Errorf("failed to read member %s: %w", e.Name, err)
+		}
+		memberResults, err := d.analyzeBinary(ctx, bytes.NewReader(data), nil)
+		if errors.Is(err, ErrUnrecognizedFormat) {
+			d.logger.Debug("skipping unsupported archive member", slog.String("member", e.Name))
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("member %s: %w", e.Name, err)
+		}
+
+		sum := sha256.Sum256(data)
+		for _, res := range memberResults {
+			res.Member = e.Name
+			res.Identity.SHA256 = hex.EncodeToString(sum[:])
+			results = append(results, res)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, ErrUnrecognizedFormat
+	}
+	return results, nil
+}
+
+func (d *Dispatcher) analyzeBinary(ctx context.Context, r io.ReaderAt, file *binary.FileMetadata) ([]AnalysisResult, error) {
+	magic := make([]byte, MagicSize)
+	n, err := r.ReadAt(magic, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	magic = magic[:n]
+
+	for _, a := range d.analyzers {
+		if !a.Recognizes(magic) {
+			continue
+		}
+		results, err := a.Analyze(ctx, r, file)
+		if errors.Is(err, binary.ErrUnsupportedFormat) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for i := range results {
+			results[i].Format = a.Format()
+			d.logger.Debug("parsed binary",
+				slog.String("format", a.Format().String()),
+				slog.String("arch", results[i].Profile.Architecture.String()),
+				slog.String("slice", results[i].Slice))
+		}
+		return results, nil
+	}
+
+	return nil, ErrUnrecognizedFormat
+}
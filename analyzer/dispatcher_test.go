@@ -0,0 +1,125 @@
+package analyzer_test
+
+import (
+	"bytes"
+	"context"
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"go.kacmar.sk/crack/analyzer"
+	bin "go.kacmar.sk/crack/binary"
+)
+
+// fakeAnalyzer is an analyzer registered from outside the package. It recognizes files starting with magic and accepts those whose content contains accept.
+type fakeAnalyzer struct {
+	format bin.Format
+	magic  string
+	accept string
+}
+
+func (a fakeAnalyzer) Format() bin.Format { return a.format }
+
+func (a fakeAnalyzer) Recognizes(magic []byte) bool { return bytes.HasPrefix(magic, []byte(a.magic)) }
+
+func (a fakeAnalyzer) Analyze(_ context.Context, r io.ReaderAt, _ *bin.FileMetadata) ([]analyzer.AnalysisResult, error) {
+	data, err := io.ReadAll(io.NewSectionReader(r, 0, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Contains(data, []byte(a.accept)) {
+		return nil, bin.ErrUnsupportedFormat
+	}
+	return []analyzer.AnalysisResult{{Slice: "a"}, {Slice: "b"}}, nil
+}
+
+func TestDispatcherAnalyzers(t *testing.T) {
+	const firmware bin.Format = "firmware"
+	d := analyzer.NewDispatcher(analyzer.DispatcherOptions{
+		Analyzers: []analyzer.FormatAnalyzer{
+			fakeAnalyzer{format: bin.FormatELF, magic: "FW", accept: "elf"},
+			fakeAnalyzer{format: firmware, magic: "FW", accept: "fw"},
+		},
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+
+	tests := []struct {
+		name       string
+		data       string
+		wantFormat bin.Format
+		wantErr    error
+	}{
+		{name: "first analyzer", data: "FW00elf", wantFormat: bin.FormatELF},
+		{name: "falls through to next analyzer", data: "FW00fw", wantFormat: firmware},
+		{name: "rejected by all analyzers", data: "FW00", wantErr: analyzer.ErrUnrecognizedFormat},
+		{name: "unrecognized magic", data: "XXXXfw", wantErr: analyzer.ErrUnrecognizedFormat},
+		{name: "shorter than magic", data: "F", wantErr: analyzer.ErrUnrecognizedFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := d.Analyze(context.Background(), bytes.NewReader([]byte(tt.data)), nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Analyze() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if len(results) != 2 {
+				t.Fatalf("Analyze() returned %d results, want 2", len(results))
+			}
+			for _, res := range results {
+				if res.Format != tt.wantFormat {
+					t.Errorf("result %s format = %v, want %v", res.Slice, res.Format, tt.wantFormat)
+				}
+			}
+		})
+	}
+
+	if !d.Recognizes([]byte("FW00")) || !d.Recognizes([]byte("!<ar")) || d.Recognizes([]byte("\x7fELF")) {
+		t.Error("Recognizes() doesn't match the registered analyzers and static libraries")
+	}
+}
+
+func TestDispatcherBundledAnalyzers(t *testing.T) {
+	const firmware bin.Format = "firmware"
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	d := analyzer.NewDispatcher(analyzer.DispatcherOptions{
+		Analyzers: []analyzer.FormatAnalyzer{
+			fakeAnalyzer{format: firmware, magic: "FW", accept: "fw"},
+			analyzer.NewELFAnalyzer(analyzer.ELFAnalyzerOptions{Logger: logger}),
+			analyzer.NewPEAnalyzer(analyzer.PEAnalyzerOptions{Logger: logger}),
+			analyzer.NewMachOAnalyzer(analyzer.MachOAnalyzerOptions{Logger: logger}),
+		},
+		Logger: logger,
+	})
+
+	// An x86-64 executable with no sections or segments.
+	var buf bytes.Buffer
+	header := elf.Header64{
+		Ident:     [16]byte{0x7f, 'E', 'L', 'F', byte(elf.ELFCLASS64), byte(elf.ELFDATA2LSB), byte(elf.EV_CURRENT)},
+		Type:      uint16(elf.ET_EXEC),
+		Machine:   uint16(elf.EM_X86_64),
+		Version:   uint32(elf.EV_CURRENT),
+		Ehsize:    64,
+		Shentsize: 64,
+	}
+	if err := binary.Write(&buf, binary.LittleEndian, &header); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := d.Analyze(context.Background(), bytes.NewReader(buf.Bytes()), nil)
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Format != bin.FormatELF || results[0].Profile.Architecture != bin.ArchAMD64 {
+		t.Errorf("Analyze() = %+v, want one amd64 ELF result", results)
+	}
+
+	results, err = d.Analyze(context.Background(), bytes.NewReader([]byte("FW00fw")), nil)
+	if err != nil || len(results) != 2 || results[0].Format != firmware {
+		t.Errorf("Analyze() = %+v, %v, want the firmware analyzer's results", results, err)
+	}
+}
@@ -0,0 +1,131 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/internal/debuginfo"
+	"go.kacmar.sk/crack/rule"
+	"go.kacmar.sk/crack/toolchain"
+)
+
+// SectionSource supplies the sections stripped from ELF binaries, such as debug information served by debuginfod.
+type SectionSource interface {
+	// ResolverFor returns a Resolver fetching the sections of the binary with buildID, scoped to ctx.
+	ResolverFor(ctx context.Context, buildID string) elf.Resolver
+}
+
+// ELFAnalyzer runs ELF-specific analysis and returns findings.
+type ELFAnalyzer struct {
+	rules    []rule.ELFRule
+	sources  []SectionSource
+	detector elf.ToolchainDetector
+	memory   *binary.MemoryBudget
+	logger   *slog.Logger
+}
+
+// ELFAnalyzerOptions configures ELFAnalyzer creation.
+type ELFAnalyzerOptions struct {
+	Rules []rule.ELFRule
+	// Sources are asked in order for the sections missing from stripped binaries, which some rules need.
+	Sources []SectionSource
+	// Detector identifies the toolchain of binaries. A nil Detector uses elf.DefaultToolchainDetector.
+	Detector elf.ToolchainDetector
+	// Memory, when set, caps the section and segment data held by the binaries analyzed at once.
+	// A binary exceeding it fails analysis with binary.ErrLimitExceeded.
+	Memory *binary.MemoryBudget
+	// Logger receives debug messages about the resolvers attached. A nil Logger uses slog.Default().
+	Logger *slog.Logger
+}
+
+// NewELFAnalyzer creates an ELF analyzer with the given options.
+func NewELFAnalyzer(opts ELFAnalyzerOptions) *ELFAnalyzer {
+	detector := opts.Detector
+	if detector == nil {
+		detector = elf.DefaultToolchainDetector{}
+	}
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &ELFAnalyzer{
+		rules:    opts.Rules,
+		sources:  opts.Sources,
+		detector: detector,
+		memory:   opts.Memory,
+		logger:   logger.With(slog.String("component", "elf-analyzer")),
+	}
+}
+
+var elfMagic = []byte("\x7fELF")
+
+func (a *ELFAnalyzer) Format() binary.Format { return binary.FormatELF }
+
+func (a *ELFAnalyzer) Recognizes(magic []byte) bool { return bytes.HasPrefix(magic, elfMagic) }
+
+// Analyze opens r as an ELF binary, runs ELF-specific rules, and returns a single result with the composed Profile, findings and build ID.
+// file is recorded in the Profile for rules that depend on the privileges the binary runs with.
+// Returns binary.ErrUnsupportedFormat when r isn't an ELF file.
+func (a *ELFAnalyzer) Analyze(ctx context.Context, r io.ReaderAt, file *binary.FileMetadata) ([]AnalysisResult, error) {
+	account := a.memory.Account(ctx)
+	defer account.Close()
+
+	bin, err := elf.Open(r, elf.WithResolverFactory(a.resolverFactory(ctx)), elf.WithMemoryAccount(account))
+	if err != nil {
+		return nil, err
+	}
+
+	profile := binary.Profile{
+		Architecture: elf.DetectArchitecture(bin),
+		Kind:         elf.DetectKind(bin),
+		LibC:         elf.DetectLibC(bin),
+		Toolchain:    a.detector.Detect(bin),
+		File:         file,
+		Module:       elf.DetectModuleInfo(bin),
+		Go:           elf.DetectGoBuildInfo(bin),
+	}
+	// Scanning the symbols for Rust crates is only worth it for binaries rustc is known to have built.
+	if profile.Toolchain.Compiler == toolchain.Rustc {
+		profile.Rust = elf.DetectRustInfo(bin)
+	}
+
+	findings := rule.Check(a.rules, profile, func(r rule.ELFRule) rule.Result {
+		return r.Execute(bin)
+	})
+	// Rules skip or treat as absent the data they fail to read, so a binary that hit the budget would get an incomplete report.
+	if err := account.Err(); err != nil {
+		return nil, err
+	}
+	return []AnalysisResult{{
+		Identity: binary.Identity{BuildID: bin.BuildID()},
+		Profile:  profile,
+		Findings: findings,
+	}}, nil
+}
+
+// resolverFactory builds a Resolver for a given build ID, scoped to ctx.
+// Composes the configured sources in declared order, chaining them when more than one applies.
+// Returns nil for binaries without a build ID or when no source is configured.
+func (a *ELFAnalyzer) resolverFactory(ctx context.Context) func(buildID string) elf.Resolver {
+	return func(buildID string) elf.Resolver {
+		if buildID == "" || len(a.sources) == 0 {
+			return nil
+		}
+
+		resolvers := make([]elf.Resolver, 0, len(a.sources))
+		for _, src := range a.sources {
+			resolvers = append(resolvers, src.ResolverFor(ctx, buildID))
+		}
+
+		if len(resolvers) == 1 {
+			a.logger.Debug("resolver attached", slog.String("build_id", buildID))
+			return resolvers[0]
+		}
+		a.logger.Debug("resolver chain attached", slog.String("build_id", buildID), slog.Int("sources", len(resolvers)))
+		return debuginfo.Chain(resolvers)
+	}
+}
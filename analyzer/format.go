@@ -0,0 +1,29 @@
+// Package analyzer dispatches binaries to the analyzers of their executable formats. Formats are supported by
+// implementing FormatAnalyzer and registering the implementation with a Dispatcher, alongside the bundled ELFAnalyzer,
+// PEAnalyzer and MachOAnalyzer.
+package analyzer
+
+import (
+	"context"
+	"io"
+
+	"go.kacmar.sk/crack/binary"
+)
+
+// MagicSize is the number of leading bytes of a file passed to FormatAnalyzer.Recognizes.
+const MagicSize = 4
+
+// FormatAnalyzer analyzes the binaries of one executable format.
+// The Dispatcher tries the analyzers registered in DispatcherOptions.Analyzers in order, so a format is supported by
+// registering an implementation rather than by changing the dispatcher.
+type FormatAnalyzer interface {
+	// Format names the format of the binaries the analyzer accepts. The dispatcher records it in every result of the analyzer.
+	Format() binary.Format
+	// Recognizes reports whether magic, the first MagicSize bytes of a file or fewer for shorter files, may start a binary
+	// of the format. It is a cheap prefilter run for every file a scan walks: r may still fail to parse in Analyze.
+	Recognizes(magic []byte) bool
+	// Analyze parses r and runs the rules of the format against it, returning one result per binary r holds, such as the
+	// slices of a universal binary. file is the filesystem metadata of the file r was read from, or nil when unknown.
+	// Returns binary.ErrUnsupportedFormat when r isn't a binary of the format, letting the next analyzer try.
+	Analyze(ctx context.Context, r io.ReaderAt, file *binary.FileMetadata) ([]AnalysisResult, error)
+}
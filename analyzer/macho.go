@@ -0,0 +1,107 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/macho"
+	"go.kacmar.sk/crack/rule"
+)
+
+// MachOAnalyzer runs Mach-O-specific analysis and returns findings.
+type MachOAnalyzer struct {
+	rules  []rule.MachORule
+	memory *binary.MemoryBudget
+	logger *slog.Logger
+}
+
+// MachOAnalyzerOptions configures MachOAnalyzer creation.
+type MachOAnalyzerOptions struct {
+	Rules []rule.MachORule
+	// Memory, when set, caps the section data held by the binaries analyzed at once.
+	// A binary exceeding it fails analysis with binary.ErrLimitExceeded.
+	Memory *binary.MemoryBudget
+	// Logger is the parent of the analyzer's logger. A nil Logger uses slog.Default().
+	Logger *slog.Logger
+}
+
+// NewMachOAnalyzer creates a Mach-O analyzer with the given options.
+func NewMachOAnalyzer(opts MachOAnalyzerOptions) *MachOAnalyzer {
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &MachOAnalyzer{
+		rules:  opts.Rules,
+		memory: opts.Memory,
+		logger: logger.With(slog.String("component", "macho-analyzer")),
+	}
+}
+
+// machoMagics start thin Mach-O files of either word size and byte order, and universal binaries.
+var machoMagics = [][]byte{
+	[]byte("\xfe\xed\xfa\xce"), []byte("\xce\xfa\xed\xfe"),
+	[]byte("\xfe\xed\xfa\xcf"), []byte("\xcf\xfa\xed\xfe"),
+	[]byte("\xca\xfe\xba\xbe"),
+}
+
+func (a *MachOAnalyzer) Format() binary.Format { return binary.FormatMachO }
+
+// Recognizes accepts the universal binary magic, which Java class files share; Analyze tells them apart.
+func (a *MachOAnalyzer) Recognizes(magic []byte) bool {
+	return slices.ContainsFunc(machoMagics, func(m []byte) bool { return bytes.HasPrefix(magic, m) })
+}
+
+// Analyze opens r as a Mach-O file and runs Mach-O-specific rules against it.
+// A universal binary yields one result per slice, named in AnalysisResult.Slice; a thin file yields a single result.
+// Mach-O binaries record no compiler version, so their toolchain is left unknown. file is recorded in each Profile as for ELF binaries.
+// Returns binary.ErrUnsupportedFormat when r isn't a Mach-O file.
+func (a *MachOAnalyzer) Analyze(ctx context.Context, r io.ReaderAt, file *binary.FileMetadata) ([]AnalysisResult, error) {
+	account := a.memory.Account(ctx)
+	defer account.Close()
+
+	universal := macho.IsUniversal(r)
+	var bins []*macho.File
+	if universal {
+		var err error
+		if bins, err = macho.OpenUniversal(r, macho.WithMemoryAccount(account)); err != nil {
+			return nil, err
+		}
+	} else {
+		bin, err := macho.Open(r, macho.WithMemoryAccount(account))
+		if err != nil {
+			return nil, err
+		}
+		bins = []*macho.File{bin}
+	}
+
+	results := make([]AnalysisResult, 0, len(bins))
+	for _, bin := range bins {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		profile := binary.Profile{
+			Architecture: macho.DetectArchitecture(bin),
+			Kind:         macho.DetectKind(bin),
+			File:         file,
+		}
+		res := AnalysisResult{
+			Profile: profile,
+			Findings: rule.Check(a.rules, profile, func(r rule.MachORule) rule.Result {
+				return r.Execute(bin)
+			}),
+		}
+		if universal {
+			res.Slice = macho.ArchName(bin)
+		}
+		results = append(results, res)
+	}
+	if err := account.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,86 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/pe"
+	"go.kacmar.sk/crack/rule"
+)
+
+// PEAnalyzer runs PE-specific analysis and returns findings.
+type PEAnalyzer struct {
+	rules    []rule.PERule
+	detector pe.ToolchainDetector
+	memory   *binary.MemoryBudget
+	logger   *slog.Logger
+}
+
+// PEAnalyzerOptions configures PEAnalyzer creation.
+type PEAnalyzerOptions struct {
+	Rules []rule.PERule
+	// Detector identifies the toolchain of binaries. A nil Detector uses pe.DefaultToolchainDetector.
+	Detector pe.ToolchainDetector
+	// Memory, when set, caps the section data held by the binaries analyzed at once.
+	// A binary exceeding it fails analysis with binary.ErrLimitExceeded.
+	Memory *binary.MemoryBudget
+	// Logger is the parent of the analyzer's logger. A nil Logger uses slog.Default().
+	Logger *slog.Logger
+}
+
+// NewPEAnalyzer creates a PE analyzer with the given options.
+func NewPEAnalyzer(opts PEAnalyzerOptions) *PEAnalyzer {
+	detector := opts.Detector
+	if detector == nil {
+		detector = pe.DefaultToolchainDetector{}
+	}
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &PEAnalyzer{
+		rules:    opts.Rules,
+		detector: detector,
+		memory:   opts.Memory,
+		logger:   logger.With(slog.String("component", "pe-analyzer")),
+	}
+}
+
+// peMagic starts the MS-DOS stub of PE images.
+var peMagic = []byte("MZ")
+
+func (a *PEAnalyzer) Format() binary.Format { return binary.FormatPE }
+
+// Recognizes accepts any MS-DOS executable; Analyze tells PE images from plain MS-DOS programs.
+func (a *PEAnalyzer) Recognizes(magic []byte) bool { return bytes.HasPrefix(magic, peMagic) }
+
+// Analyze opens r as a PE image, runs PE-specific rules, and returns a single result with the composed Profile and findings.
+// file is recorded in the Profile as for ELF binaries.
+// Returns binary.ErrUnsupportedFormat when r isn't a PE image.
+func (a *PEAnalyzer) Analyze(ctx context.Context, r io.ReaderAt, file *binary.FileMetadata) ([]AnalysisResult, error) {
+	account := a.memory.Account(ctx)
+	defer account.Close()
+
+	bin, err := pe.Open(r, pe.WithMemoryAccount(account))
+	if err != nil {
+		return nil, err
+	}
+
+	profile := binary.Profile{
+		Architecture: pe.DetectArchitecture(bin),
+		Kind:         pe.DetectKind(bin),
+		Toolchain:    a.detector.Detect(bin),
+		File:         file,
+	}
+
+	findings := rule.Check(a.rules, profile, func(r rule.PERule) rule.Result {
+		return r.Execute(bin)
+	})
+	if err := account.Err(); err != nil {
+		return nil, err
+	}
+	return []AnalysisResult{{Profile: profile, Findings: findings}}, nil
+}
@@ -0,0 +1,22 @@
+package analyzer
+
+import (
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/rule"
+)
+
+// AnalysisResult contains findings and binary metadata from analysis.
+// Identity.SHA256 is left empty at this layer and filled by the scanner after hashing the file,
+// except for archive members, which only the dispatcher sees individually.
+type AnalysisResult struct {
+	// Member is the name of the archive member the result was read from, or "" when the input was a binary itself.
+	Member string
+	// Slice names the architecture of the universal binary slice the result describes, such as "arm64e",
+	// or is "" when the input wasn't a universal binary.
+	Slice string
+	// Format is the format of the analyzer that produced the result, set by the dispatcher.
+	Format   binary.Format
+	Identity binary.Identity
+	Profile  binary.Profile
+	Findings []rule.Finding
+}
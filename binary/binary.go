@@ -12,21 +12,22 @@ import (
 // ErrUnsupportedFormat is returned when the file is not a supported binary format.
 var ErrUnsupportedFormat = errors.New("unsupported binary format")
 
-// Format identifies the executable format.
-type Format int
+// Format identifies the executable format by its name, such as "ELF".
+// Analyzers for other formats define their own values.
+type Format string
 
 const (
-	FormatUnknown Format = iota
-	FormatELF
+	FormatUnknown Format = ""
+	FormatELF     Format = "ELF"
+	FormatPE      Format = "PE"
+	FormatMachO   Format = "Mach-O"
 )
 
 func (f Format) String() string {
-	switch f {
-	case FormatELF:
-		return "ELF"
-	default:
+	if f == FormatUnknown {
 		return "Unknown"
 	}
+	return string(f)
 }
 
 // LibC identifies the C library the binary is linked against.
@@ -69,11 +70,138 @@ func (l LibC) Matches(target LibC) bool {
 	return l&target != 0
 }
 
+// Kind identifies what a binary file is as a bitmask, allowing combinations.
+type Kind uint32
+
+const (
+	KindUnknown       Kind = 0
+	KindExecutable    Kind = 1 << 0
+	KindSharedLibrary Kind = 1 << 1
+	KindRelocatable   Kind = 1 << 2
+	// KindKernelModule is a relocatable object loaded into the kernel, such as a Linux .ko file.
+	// It isn't part of KindAll, so only rules written for kernel modules apply to it.
+	KindKernelModule Kind = 1 << 3
+	// KindKernelImage is an operating system kernel, such as a Linux vmlinux file. Like KindKernelModule, it isn't part of KindAll.
+	KindKernelImage Kind = 1 << 4
+
+	KindLinked = KindExecutable | KindSharedLibrary
+	KindAll    = KindExecutable | KindSharedLibrary | KindRelocatable
+)
+
+var kindNames = map[Kind]string{
+	KindExecutable:    "executable",
+	KindSharedLibrary: "shared library",
+	KindRelocatable:   "relocatable object",
+	KindKernelModule:  "kernel module",
+	KindKernelImage:   "kernel image",
+}
+
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	var names []string
+	for kind, name := range kindNames {
+		if k&kind != 0 {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		sort.Strings(names)
+		return strings.Join(names, ", ")
+	}
+	return "unknown"
+}
+
+// Matches reports whether k has any overlap with target.
+func (k Kind) Matches(target Kind) bool {
+	return k&target != 0
+}
+
 // Profile holds the detected attributes of a binary.
 type Profile struct {
 	Architecture Architecture
+	Kind         Kind
 	Toolchain    toolchain.Toolchain
 	LibC         LibC
+	// File holds the filesystem metadata of the file the binary was read from, or nil when it wasn't read from a file
+	// that records any, such as a stream.
+	File *FileMetadata
+	// Module holds the metadata of a kernel module, or nil when the binary isn't one.
+	Module *ModuleInfo
+	// Go holds the build information of a Go binary, or nil when the binary wasn't built by Go.
+	Go *GoBuildInfo
+	// Rust holds the metadata of a Rust binary, or nil when the binary wasn't built by rustc.
+	Rust *RustInfo
+}
+
+// ModuleInfo holds the fields of a kernel module's .modinfo section used to check for hardening.
+type ModuleInfo struct {
+	// Name is the module name, e.g. "ext4".
+	Name string
+	// VerMagic is the kernel release and build options the module was built for, e.g. "6.8.0 SMP preempt mod_unload".
+	VerMagic string
+	// License is the module license, e.g. "GPL".
+	License string
+	// Retpoline reports whether the module was built with retpolines, recorded as "retpoline=Y".
+	Retpoline bool
+}
+
+// GoBuildInfo holds the build information the Go linker embeds in a binary, as printed by go version -m.
+// Binaries built before Go 1.18 record neither their version nor their settings, leaving all fields empty.
+type GoBuildInfo struct {
+	// GoVersion is the version of the Go toolchain that built the binary, e.g. "go1.22.1".
+	GoVersion string
+	// Path is the package path of the main package, e.g. "go.kacmar.sk/crack/cmd/crack".
+	Path string
+	// Main is the module holding the main package.
+	Main GoModule
+	// Deps are the other modules the binary was built with.
+	Deps []GoModule
+	// BuildMode is the -buildmode the binary was built with, e.g. "exe" or "pie".
+	BuildMode string
+	// TrimPath reports whether the binary was built with -trimpath.
+	TrimPath bool
+	// CGOEnabled reports whether the binary was built with CGO_ENABLED=1.
+	CGOEnabled bool
+	// CGOCFlags are the CGO_CFLAGS the C code of cgo packages was compiled with.
+	// Empty when unset, and in -trimpath builds, which don't record them.
+	CGOCFlags string
+	// Race reports whether the binary was built with the race detector, -race.
+	Race bool
+}
+
+// GoModule identifies a Go module by path and version.
+type GoModule struct {
+	Path    string
+	Version string
+}
+
+// RustInfo holds the metadata rustc and the Rust standard library leave in a binary.
+type RustInfo struct {
+	// Commit is the abbreviated commit of the rustc that built the binary as recorded in .comment, e.g. "1159e78c4".
+	Commit string
+	// Std and Alloc describe the std and alloc crates linked into the binary.
+	Std   RustCrate
+	Alloc RustCrate
+}
+
+// RustCrate describes a crate of the Rust standard library linked into a binary.
+// Mangled symbol names identify the crate but carry no version. The version is taken from the source paths the standard
+// library embeds for panic locations, which name the commit of the rustc release it shipped with.
+type RustCrate struct {
+	// Linked reports whether symbols or source paths of the crate were found.
+	Linked bool
+	// Mangling is the symbol mangling scheme the crate's symbols use, "legacy" or "v0". Empty for stripped binaries.
+	Mangling string
+	// Disambiguator is the crate hash of v0 mangled symbols, e.g. "cKkwsb9kWaL".
+	Disambiguator string
+	// Commit is the commit of the rustc release the crate shipped with, e.g. "1159e78c4747b02ef996e55082b704c09b970588".
+	// Empty when no source path names it.
+	Commit string
+	// Version is the version of the rustc release the crate shipped with.
+	// Zero when its commit doesn't match the rustc recorded in .comment, such as for a standard library built from source.
+	Version toolchain.Version
 }
 
 // Identity contains the unique fingerprints of a binary artifact.
@@ -33,10 +33,46 @@ func DetectArchitecture(b Binary) binary.Architecture {
 	}
 }
 
+// DetectKind classifies the binary by its ELF type.
+// ET_DYN files are executables when they are flagged DF_1_PIE or request an interpreter, which covers static-pie and PIEs from linkers
+// that predate the flag, and shared libraries otherwise.
+// ET_REL files carrying a .modinfo section are kernel modules, and executables laid out like vmlinux are kernel images.
+func DetectKind(b Binary) binary.Kind {
+	if isKernelImage(b) {
+		return binary.KindKernelImage
+	}
+	switch b.Type() {
+	case elf.ET_EXEC:
+		return binary.KindExecutable
+	case elf.ET_DYN:
+		if pie, _ := HasDynFlag(b, elf.DT_FLAGS_1, uint64(elf.DF_1_PIE)); pie {
+			return binary.KindExecutable
+		}
+		for _, prog := range b.Progs() {
+			if prog.Type == elf.PT_INTERP {
+				return binary.KindExecutable
+			}
+		}
+		return binary.KindSharedLibrary
+	case elf.ET_REL:
+		if isKernelModule(b) {
+			return binary.KindKernelModule
+		}
+		return binary.KindRelocatable
+	default:
+		return binary.KindUnknown
+	}
+}
+
 // DetectLibC identifies the C library that the binary links against, using PT_INTERP and DT_NEEDED as evidence.
 // Returns LibCNone when the binary declares no libc dependency at all (static executables and self-contained shared objects).
-// Returns LibCUnknown when the binary references a libc but the specific implementation can't be classified.
+// Returns LibCUnknown when the binary references a libc but the specific implementation can't be classified,
+// and for relocatable objects, whose libc is only chosen when they are linked.
 func DetectLibC(b Binary) binary.LibC {
+	if b.Type() == elf.ET_REL {
+		return binary.LibCUnknown
+	}
+
 	hasInterp := false
 	for _, prog := range b.Progs() {
 		if prog.Type != elf.PT_INTERP {
@@ -9,15 +9,17 @@ import (
 	"go.kacmar.sk/crack/binary"
 )
 
-// fakeBinary is a minimal Binary used to drive DetectLibC.
+// fakeBinary is a minimal Binary used to drive DetectLibC and DetectKind.
+// A zero typ reports ET_EXEC.
 type fakeBinary struct {
+	typ      elf.Type
 	progs    []Prog
 	dynEntry []DynEntry
 	sections []Section
+	symbols  []elf.Symbol
 }
 
 func (f *fakeBinary) Class() elf.Class                  { return elf.ELFCLASS64 }
-func (f *fakeBinary) Type() elf.Type                    { return elf.ET_EXEC }
 func (f *fakeBinary) Machine() elf.Machine              { return elf.EM_X86_64 }
 func (f *fakeBinary) OSABI() elf.OSABI                  { return elf.ELFOSABI_NONE }
 func (f *fakeBinary) ByteOrder() stdbinary.ByteOrder    { return stdbinary.LittleEndian }
@@ -25,9 +27,17 @@ func (f *fakeBinary) Entry() uint64                     { return 0 }
 func (f *fakeBinary) BuildID() string                   { return "" }
 func (f *fakeBinary) Progs() []Prog                     { return f.progs }
 func (f *fakeBinary) Sections() []Section               { return f.sections }
-func (f *fakeBinary) Symbols() ([]elf.Symbol, error)    { return nil, nil }
+func (f *fakeBinary) Symbols() ([]elf.Symbol, error)    { return f.symbols, nil }
 func (f *fakeBinary) DynSymbols() ([]elf.Symbol, error) { return nil, nil }
 func (f *fakeBinary) DynEntries() ([]DynEntry, error)   { return f.dynEntry, nil }
+func (f *fakeBinary) Trailer() ([]byte, error)          { return nil, nil }
+
+func (f *fakeBinary) Type() elf.Type {
+	if f.typ == elf.ET_NONE {
+		return elf.ET_EXEC
+	}
+	return f.typ
+}
 
 // makeInterp builds a PT_INTERP segment carrying the given (NUL-terminated) interpreter path.
 func makeInterp(path string) Prog {
@@ -61,6 +71,7 @@ func makeDynamic(libs ...string) (Section, []DynEntry) {
 func TestDetectLibC(t *testing.T) {
 	tests := []struct {
 		name  string
+		typ   elf.Type
 		progs []Prog
 		libs  []string
 		want  binary.LibC
@@ -110,11 +121,16 @@ func TestDetectLibC(t *testing.T) {
 			libs:  []string{"libc.so.6"},
 			want:  binary.LibCGlibc,
 		},
+		{
+			name: "relocatable object: libc chosen at link time",
+			typ:  elf.ET_REL,
+			want: binary.LibCUnknown,
+		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			fb := &fakeBinary{progs: tc.progs}
+			fb := &fakeBinary{typ: tc.typ, progs: tc.progs}
 			if len(tc.libs) > 0 {
 				sec, entries := makeDynamic(tc.libs...)
 				fb.sections = []Section{sec}
@@ -127,3 +143,40 @@ func TestDetectLibC(t *testing.T) {
 		})
 	}
 }
+
+func TestDetectKind(t *testing.T) {
+	vmlinuxSections := []Section{
+		{SectionHeader: elf.SectionHeader{Name: ".init.text"}},
+		{SectionHeader: elf.SectionHeader{Name: "__ex_table"}},
+	}
+	tests := []struct {
+		name     string
+		typ      elf.Type
+		progs    []Prog
+		dynEntry []DynEntry
+		sections []Section
+		want     binary.Kind
+	}{
+		{name: "ET_EXEC", typ: elf.ET_EXEC, want: binary.KindExecutable},
+		{name: "PIE executable", typ: elf.ET_DYN, progs: []Prog{makeInterp("/lib64/ld-linux-x86-64.so.2")}, want: binary.KindExecutable},
+		{name: "static-pie executable", typ: elf.ET_DYN, dynEntry: []DynEntry{{Tag: elf.DT_FLAGS_1, Val: uint64(elf.DF_1_NOW | elf.DF_1_PIE)}}, want: binary.KindExecutable},
+		{name: "shared library", typ: elf.ET_DYN, want: binary.KindSharedLibrary},
+		{name: "shared library with DT_FLAGS_1", typ: elf.ET_DYN, dynEntry: []DynEntry{{Tag: elf.DT_FLAGS_1, Val: uint64(elf.DF_1_NOW)}}, want: binary.KindSharedLibrary},
+		{name: "relocatable object", typ: elf.ET_REL, want: binary.KindRelocatable},
+		{name: "kernel module", typ: elf.ET_REL, sections: []Section{makeModinfo("license=GPL")}, want: binary.KindKernelModule},
+		{name: "kernel image", typ: elf.ET_EXEC, sections: vmlinuxSections, want: binary.KindKernelImage},
+		{name: "relocatable kernel image", typ: elf.ET_DYN, sections: vmlinuxSections, want: binary.KindKernelImage},
+		{name: "PIE executable with kernel sections", typ: elf.ET_DYN, progs: []Prog{makeInterp("/lib/ld-musl-x86_64.so.1")}, sections: vmlinuxSections, want: binary.KindExecutable},
+		{name: "executable with .init.text", typ: elf.ET_EXEC, sections: vmlinuxSections[:1], want: binary.KindExecutable},
+		{name: "shared library with .modinfo", typ: elf.ET_DYN, sections: []Section{makeModinfo("license=GPL")}, want: binary.KindSharedLibrary},
+		{name: "core dump", typ: elf.ET_CORE, want: binary.KindUnknown},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := DetectKind(&fakeBinary{typ: tc.typ, progs: tc.progs, dynEntry: tc.dynEntry, sections: tc.sections}); got != tc.want {
+				t.Errorf("DetectKind() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -49,6 +49,10 @@ type Binary interface {
 	// DynEntries returns parsed entries from the .dynamic section.
 	// Returns (nil, nil) when .dynamic is absent.
 	DynEntries() ([]DynEntry, error)
+	// Trailer returns the bytes appended after the last header table, section and segment of the file,
+	// such as the signature of a kernel module.
+	// Returns (nil, nil) when nothing follows the ELF image.
+	Trailer() ([]byte, error)
 }
 
 // Resolver supplies the raw bytes of an ELF section that is not present in the local file.
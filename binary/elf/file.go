@@ -16,7 +16,9 @@ import (
 // It wraps stdlib's *elf.File and, when configured with a Resolver, transparently fetches sections that have been stripped from the local file.
 type File struct {
 	file     *elf.File
+	r        io.ReaderAt
 	resolver Resolver
+	memory   *bin.MemoryAccount
 	buildID  string
 
 	progs    []Prog
@@ -26,6 +28,11 @@ type File struct {
 	symbols    func() ([]elf.Symbol, error)
 	dynSymbols func() ([]elf.Symbol, error)
 	dynEntries func() ([]DynEntry, error)
+	trailer    func() ([]byte, error)
+	// goBuildInfo is decoded once, as every Go rule needs it.
+	goBuildInfo func() *bin.GoBuildInfo
+	// rustInfo is decoded once, as the analyzer and every Rust rule need it and it scans all symbols.
+	rustInfo func() *bin.RustInfo
 
 	// Cache of raw section bytes keyed by section name, populated lazily on first fetch.
 	// Guarded by sectionMu because individual sections may be requested concurrently and the resolver call is the slow path we want to deduplicate.
@@ -44,6 +51,7 @@ type Option func(*openConfig)
 
 type openConfig struct {
 	resolverFactory func(buildID string) Resolver
+	memory          *bin.MemoryAccount
 }
 
 // WithResolverFactory supplies a factory that produces a Resolver scoped to the binary's build ID.
@@ -53,6 +61,13 @@ func WithResolverFactory(factory func(buildID string) Resolver) Option {
 	return func(c *openConfig) { c.resolverFactory = factory }
 }
 
+// WithMemoryAccount accounts the section and segment data read from the binary, including sections fetched through the Resolver,
+// against the memory budget of account. Reads that would exceed it fail with bin.ErrLimitExceeded.
+// The caller closes the account once it is done with the returned binary.
+func WithMemoryAccount(account *bin.MemoryAccount) Option {
+	return func(c *openConfig) { c.memory = account }
+}
+
 // Open parses the ELF header and section/program header tables from r.
 // Section and segment contents are read lazily on demand.
 // The caller owns r and must keep it open while the returned binary is in use.
@@ -72,6 +87,8 @@ func Open(r io.ReaderAt, opts ...Option) (*File, error) {
 
 	b := &File{
 		file:         f,
+		r:            r,
+		memory:       cfg.memory,
 		sectionBytes: make(map[string]sectionResult),
 	}
 
@@ -81,10 +98,18 @@ func Open(r io.ReaderAt, opts ...Option) (*File, error) {
 		b.progs[i] = Prog{
 			ProgHeader: prog.ProgHeader,
 			data: func() ([]byte, error) {
-				buf := make([]byte, prog.Filesz)
-				if _, err := prog.ReadAt(buf, 0); err != nil {
+				if err := b.memory.Reserve(prog.Filesz); err != nil {
 					return nil, fmt.Errorf("failed to read segment: %w", err)
 				}
+				// Reading through a reader rather than into a buffer of Filesz bytes keeps a corrupt size from allocating
+				// more than the file holds.
+				buf, err := io.ReadAll(prog.Open())
+				if err != nil {
+					return nil, fmt.Errorf("failed to read segment: %w", err)
+				}
+				if uint64(len(buf)) != prog.Filesz {
+					return nil, fmt.Errorf("failed to read segment: %w", io.ErrUnexpectedEOF)
+				}
 				return buf, nil
 			},
 		}
@@ -108,6 +133,9 @@ func Open(r io.ReaderAt, opts ...Option) (*File, error) {
 	b.symbols = sync.OnceValues(b.loadLocalSymbols)
 	b.dynSymbols = sync.OnceValues(b.loadLocalDynSymbols)
 	b.dynEntries = sync.OnceValues(b.loadDynEntries)
+	b.trailer = sync.OnceValues(b.loadTrailer)
+	b.goBuildInfo = sync.OnceValue(func() *bin.GoBuildInfo { return decodeGoBuildInfo(b) })
+	b.rustInfo = sync.OnceValue(func() *bin.RustInfo { return decodeRustInfo(b) })
 
 	return b, nil
 }
@@ -125,6 +153,7 @@ func (b *File) Sections() []Section         { return b.sections }
 func (b *File) Symbols() ([]elf.Symbol, error)    { return b.symbols() }
 func (b *File) DynSymbols() ([]elf.Symbol, error) { return b.dynSymbols() }
 func (b *File) DynEntries() ([]DynEntry, error)   { return b.dynEntries() }
+func (b *File) Trailer() ([]byte, error)          { return b.trailer() }
 
 func (b *File) sectionDataByName(name string) ([]byte, error) {
 	b.sectionMu.Lock()
@@ -138,6 +167,9 @@ func (b *File) sectionDataByName(name string) ([]byte, error) {
 }
 
 func (b *File) loadLocalSymbols() ([]elf.Symbol, error) {
+	if err := b.memory.Reserve(b.symbolTableSize(".symtab")); err != nil {
+		return nil, fmt.Errorf("failed to read symbols: %w", err)
+	}
 	syms, err := b.file.Symbols()
 	if err != nil && !errors.Is(err, elf.ErrNoSymbols) {
 		return nil, fmt.Errorf("failed to read symbols: %w", err)
@@ -172,6 +204,9 @@ func (b *File) loadResolverSymbols() ([]elf.Symbol, error) {
 }
 
 func (b *File) loadLocalDynSymbols() ([]elf.Symbol, error) {
+	if err := b.memory.Reserve(b.symbolTableSize(".dynsym")); err != nil {
+		return nil, fmt.Errorf("failed to read dynamic symbols: %w", err)
+	}
 	syms, err := b.file.DynamicSymbols()
 	if err != nil && !errors.Is(err, elf.ErrNoSymbols) {
 		return nil, fmt.Errorf("failed to read dynamic symbols: %w", err)
@@ -185,6 +220,9 @@ func (b *File) loadDynEntries() ([]DynEntry, error) {
 		return nil, nil
 	}
 
+	if err := b.memory.Reserve(dynSec.Size); err != nil {
+		return nil, fmt.Errorf("failed to read .dynamic: %w", err)
+	}
 	data, err := dynSec.Data()
 	if err != nil {
 		return nil, fmt.Errorf("failed to read .dynamic: %w", err)
@@ -219,8 +257,73 @@ func (b *File) loadDynEntries() ([]DynEntry, error) {
 	return entries, nil
 }
 
+// maxTrailerSize bounds the data read after the ELF image. Kernel module signatures take a few kilobytes at most.
+const maxTrailerSize = 64 << 10
+
+// errTrailerTooLarge is returned when more than maxTrailerSize bytes follow the ELF image.
+var errTrailerTooLarge = fmt.Errorf("trailer exceeds %d bytes", maxTrailerSize)
+
+func (b *File) loadTrailer() ([]byte, error) {
+	end, err := b.imageEnd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trailer: %w", err)
+	}
+	// #nosec G115 -- imageEnd is bounded by the header fields, which elf.NewFile has validated against the file.
+	data, err := io.ReadAll(io.NewSectionReader(b.r, int64(end), maxTrailerSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trailer: %w", err)
+	}
+	if len(data) > maxTrailerSize {
+		return nil, fmt.Errorf("failed to read trailer: %w", errTrailerTooLarge)
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if err := b.memory.Reserve(uint64(len(data))); err != nil {
+		return nil, fmt.Errorf("failed to read trailer: %w", err)
+	}
+	return data, nil
+}
+
+// imageEnd returns the offset just past the ELF header, the program and section header tables, and the file content
+// of every section and segment.
+func (b *File) imageEnd() (uint64, error) {
+	var ehsize, phoff, phentsize, shoff, shentsize uint64
+	switch b.file.Class {
+	case elf.ELFCLASS64:
+		var hdr elf.Header64
+		if err := binary.Read(io.NewSectionReader(b.r, 0, int64(binary.Size(hdr))), b.file.ByteOrder, &hdr); err != nil {
+			return 0, err
+		}
+		ehsize, phoff, phentsize, shoff, shentsize = uint64(hdr.Ehsize), hdr.Phoff, uint64(hdr.Phentsize), hdr.Shoff, uint64(hdr.Shentsize)
+	default:
+		var hdr elf.Header32
+		if err := binary.Read(io.NewSectionReader(b.r, 0, int64(binary.Size(hdr))), b.file.ByteOrder, &hdr); err != nil {
+			return 0, err
+		}
+		ehsize, phoff, phentsize, shoff, shentsize = uint64(hdr.Ehsize), uint64(hdr.Phoff), uint64(hdr.Phentsize), uint64(hdr.Shoff), uint64(hdr.Shentsize)
+	}
+
+	// The section count comes from stdlib rather than e_shnum, which is zero in files with extended section numbering.
+	end := max(ehsize, phoff+phentsize*uint64(len(b.progs)), shoff+shentsize*uint64(len(b.sections)))
+	for _, sec := range b.sections {
+		if sec.Type != elf.SHT_NOBITS {
+			end = max(end, sec.Offset+sec.FileSize)
+		}
+	}
+	for _, prog := range b.progs {
+		end = max(end, prog.Off+prog.Filesz)
+	}
+	return end, nil
+}
+
 func (b *File) fetchSectionData(name string) ([]byte, error) {
 	if sec := b.file.Section(name); sec != nil {
+		if sec.Type != elf.SHT_NOBITS {
+			if err := b.memory.Reserve(sec.Size); err != nil {
+				return nil, fmt.Errorf("failed to read section %s: %w", name, err)
+			}
+		}
 		data, err := sec.Data()
 		if err != nil {
 			return nil, fmt.Errorf("failed to read section %s: %w", name, err)
@@ -234,13 +337,30 @@ func (b *File) fetchSectionData(name string) ([]byte, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch section %s: %w", name, err)
 	}
+	if err := b.memory.Reserve(uint64(len(data))); err != nil {
+		return nil, fmt.Errorf("failed to fetch section %s: %w", name, err)
+	}
 	return data, nil
 }
 
+// symbolTableSize returns the size of the named symbol table and of its string table, which stdlib reads in full to list symbols.
+func (b *File) symbolTableSize(name string) uint64 {
+	sec := b.file.Section(name)
+	if sec == nil {
+		return 0
+	}
+	size := sec.Size
+	if int(sec.Link) < len(b.file.Sections) {
+		size += b.file.Sections[sec.Link].Size
+	}
+	return size
+}
+
 // isFormatMismatch reports whether err from elf.NewFile signals that the input is not an ELF file (rather than a malformed one).
-// The stdlib returns these specific messages for a bad ELF magic or an invalid class byte.
+// The stdlib returns these specific messages for a bad ELF magic, an invalid class byte, or input too short to hold an ELF identifier.
 func isFormatMismatch(err error) bool {
 	msg := err.Error()
 	return strings.Contains(msg, "bad magic number") ||
-		strings.Contains(msg, "invalid argument")
+		strings.Contains(msg, "invalid argument") ||
+		strings.Contains(msg, "cannot read ELF identifier")
 }
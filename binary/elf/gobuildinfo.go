@@ -0,0 +1,102 @@
+package elf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"runtime/debug"
+
+	bin "go.kacmar.sk/crack/binary"
+)
+
+// The .go.buildinfo layout, see https://pkg.go.dev/debug/buildinfo.
+// Binaries built with Go 1.18+ store the version and module information inline after the header as varint-prefixed strings.
+// Older binaries reference them through pointers and are reported without either.
+var goBuildInfoMagic = []byte("\xff Go buildinf:")
+
+const (
+	goBuildInfoHeaderSize      = 32
+	goBuildInfoFlagsVersionInl = 0x2
+	// goModInfoSentinelSize is the size of the markers the linker wraps the module information in.
+	goModInfoSentinelSize = 16
+)
+
+// readGoBuildInfo returns the version and raw module information strings stored in .go.buildinfo.
+// ok is false when the binary has no .go.buildinfo section. Strings that aren't stored inline are returned empty.
+func readGoBuildInfo(b Binary) (version, modinfo string, ok bool) {
+	data, err := findSectionData(b, ".go.buildinfo")
+	if err != nil || len(data) < goBuildInfoHeaderSize || !bytes.HasPrefix(data, goBuildInfoMagic) {
+		return "", "", false
+	}
+	if data[15]&goBuildInfoFlagsVersionInl == 0 {
+		return "", "", true
+	}
+	data = data[goBuildInfoHeaderSize:]
+	version, data = readVarintString(data)
+	modinfo, _ = readVarintString(data)
+	return version, modinfo, true
+}
+
+// readVarintString decodes a string prefixed by its uvarint length and returns it with the rest of data.
+// Returns an empty string when the length is malformed or exceeds data.
+func readVarintString(data []byte) (string, []byte) {
+	length, n := binary.Uvarint(data)
+	if n <= 0 {
+		return "", nil
+	}
+	data = data[n:]
+	if length > uint64(len(data)) {
+		return "", nil
+	}
+	return string(data[:length]), data[length:]
+}
+
+// DetectGoBuildInfo decodes the build information the Go linker embeds in .go.buildinfo.
+// Returns nil when the binary wasn't built by Go. A File decodes it once and returns the same value to every caller,
+// which must not modify it.
+func DetectGoBuildInfo(b Binary) *bin.GoBuildInfo {
+	if f, ok := b.(*File); ok {
+		return f.goBuildInfo()
+	}
+	return decodeGoBuildInfo(b)
+}
+
+func decodeGoBuildInfo(b Binary) *bin.GoBuildInfo {
+	version, modinfo, ok := readGoBuildInfo(b)
+	if !ok {
+		return nil
+	}
+	info := &bin.GoBuildInfo{GoVersion: version}
+
+	// The module information ends with a newline followed by the closing sentinel.
+	if len(modinfo) <= 2*goModInfoSentinelSize || modinfo[len(modinfo)-goModInfoSentinelSize-1] != '\n' {
+		return info
+	}
+	bi, err := debug.ParseBuildInfo(modinfo[goModInfoSentinelSize : len(modinfo)-goModInfoSentinelSize])
+	if err != nil {
+		return info
+	}
+
+	info.Path = bi.Path
+	info.Main = bin.GoModule{Path: bi.Main.Path, Version: bi.Main.Version}
+	for _, dep := range bi.Deps {
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		info.Deps = append(info.Deps, bin.GoModule{Path: dep.Path, Version: dep.Version})
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "-buildmode":
+			info.BuildMode = s.Value
+		case "-trimpath":
+			info.TrimPath = s.Value == "true"
+		case "-race":
+			info.Race = s.Value == "true"
+		case "CGO_ENABLED":
+			info.CGOEnabled = s.Value == "1"
+		case "CGO_CFLAGS":
+			info.CGOCFlags = s.Value
+		}
+	}
+	return info
+}
@@ -0,0 +1,110 @@
+package elf
+
+import (
+	"encoding/binary"
+	"os"
+	"reflect"
+	"runtime"
+	"testing"
+
+	bin "go.kacmar.sk/crack/binary"
+)
+
+// makeGoBuildInfoWithModInfo builds a .go.buildinfo section in the inline format holding version and the module information
+// modinfo, wrapped in the linker's sentinels.
+func makeGoBuildInfoWithModInfo(version, modinfo string) Section {
+	data := append([]byte("\xff Go buildinf:\x08\x02"), make([]byte, 16)...)
+	for _, s := range []string{version, "0w\xaf\x0c\x92t\x08\x02A\xe1\xc1\x07\xe6\xd6\x18\xe6" + modinfo + "\xf92C1\x86\x18 r\x00\x82B\x10A\x16\xd8\xf2"} {
+		data = binary.AppendUvarint(data, uint64(len(s)))
+		data = append(data, s...)
+	}
+	return makeSection(".go.buildinfo", data)
+}
+
+func TestDetectGoBuildInfo(t *testing.T) {
+	const modinfo = "path\texample.com/tool/cmd/tool\n" +
+		"mod\texample.com/tool\tv1.2.0\th1:abc=\n" +
+		"dep\tgolang.org/x/sys\tv0.20.0\th1:def=\n" +
+		"dep\told.example/lib\tv1.0.0\n" +
+		"=>\tnew.example/lib\tv1.1.0\th1:ghi=\n" +
+		"build\t-buildmode=pie\n" +
+		"build\t-race=true\n" +
+		"build\t-trimpath=true\n" +
+		"build\tCGO_ENABLED=1\n" +
+		"build\tCGO_CFLAGS=\"-O2 -fstack-protector-strong\"\n"
+
+	tests := []struct {
+		name     string
+		sections []Section
+		want     *bin.GoBuildInfo
+	}{
+		{
+			name:     "full build information",
+			sections: []Section{makeGoBuildInfoWithModInfo("go1.22.1", modinfo)},
+			want: &bin.GoBuildInfo{
+				GoVersion:  "go1.22.1",
+				Path:       "example.com/tool/cmd/tool",
+				Main:       bin.GoModule{Path: "example.com/tool", Version: "v1.2.0"},
+				Deps:       []bin.GoModule{{Path: "golang.org/x/sys", Version: "v0.20.0"}, {Path: "new.example/lib", Version: "v1.1.0"}},
+				BuildMode:  "pie",
+				TrimPath:   true,
+				CGOEnabled: true,
+				CGOCFlags:  "-O2 -fstack-protector-strong",
+				Race:       true,
+			},
+		},
+		{
+			name:     "version without module information",
+			sections: []Section{makeGoBuildInfo("go1.21.3")},
+			want:     &bin.GoBuildInfo{GoVersion: "go1.21.3"},
+		},
+		{
+			name:     "malformed module information",
+			sections: []Section{makeGoBuildInfoWithModInfo("go1.21.3", "path\tonly one field without sentinel newline")},
+			want:     &bin.GoBuildInfo{GoVersion: "go1.21.3"},
+		},
+		{
+			name:     "pointer format",
+			sections: []Section{makeGoBuildInfoPointerFormat()},
+			want:     &bin.GoBuildInfo{},
+		},
+		{
+			name:     "not a Go binary",
+			sections: []Section{makeComment("GCC: (Debian 12.2.0-14) 12.2.0")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectGoBuildInfo(&fakeBinary{sections: tt.sections}); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectGoBuildInfo() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectGoBuildInfoDecodesOnce(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the test binary is only an ELF file on Linux")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	b, err := Open(f)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	info := DetectGoBuildInfo(b)
+	if info == nil || info.GoVersion != runtime.Version() {
+		t.Fatalf("DetectGoBuildInfo() = %+v, want the version of the test binary", info)
+	}
+	if again := DetectGoBuildInfo(b); again != info {
+		t.Error("DetectGoBuildInfo() decoded the build information again")
+	}
+}
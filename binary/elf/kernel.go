@@ -0,0 +1,80 @@
+package elf
+
+import (
+	"bytes"
+	"debug/elf"
+	"strings"
+
+	"go.kacmar.sk/crack/binary"
+)
+
+// ModuleSignatureMagic ends the signature that the kernel's sign-file appends to a module, see module_signature.h.
+const ModuleSignatureMagic = "~Module signature appended~\n"
+
+// isKernelModule reports whether the binary is a relocatable object carrying the .modinfo section of a Linux kernel module.
+func isKernelModule(b Binary) bool {
+	if b.Type() != elf.ET_REL {
+		return false
+	}
+	_, err := FindSection(b, ".modinfo")
+	return err == nil
+}
+
+// isKernelImage reports whether the binary is a Linux kernel image, vmlinux. It is an executable without an interpreter that,
+// unlike userspace programs, has the .init.text section of code freed after boot and the __ex_table section of exception fixups.
+// Relocatable arm64 and riscv kernels are ET_DYN files.
+func isKernelImage(b Binary) bool {
+	if b.Type() != elf.ET_EXEC && b.Type() != elf.ET_DYN {
+		return false
+	}
+	for _, prog := range b.Progs() {
+		if prog.Type == elf.PT_INTERP {
+			return false
+		}
+	}
+	for _, name := range []string{".init.text", "__ex_table"} {
+		if _, err := FindSection(b, name); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// DetectModuleInfo parses the NUL-separated key=value fields of a kernel module's .modinfo section.
+// Returns nil when the binary isn't a kernel module, and an empty ModuleInfo when .modinfo can't be read.
+func DetectModuleInfo(b Binary) *binary.ModuleInfo {
+	if !isKernelModule(b) {
+		return nil
+	}
+	info := &binary.ModuleInfo{}
+	data, err := findSectionData(b, ".modinfo")
+	if err != nil {
+		return info
+	}
+	for _, field := range bytes.Split(data, []byte{0}) {
+		key, value, ok := strings.Cut(string(field), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "name":
+			info.Name = value
+		case "vermagic":
+			info.VerMagic = value
+		case "license":
+			info.License = value
+		case "retpoline":
+			info.Retpoline = value == "Y"
+		}
+	}
+	return info
+}
+
+// HasModuleSignature reports whether a module signature is appended to the binary.
+func HasModuleSignature(b Binary) (bool, error) {
+	trailer, err := b.Trailer()
+	if err != nil {
+		return false, err
+	}
+	return bytes.HasSuffix(trailer, []byte(ModuleSignatureMagic)), nil
+}
@@ -0,0 +1,146 @@
+package elf
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	bin "go.kacmar.sk/crack/binary"
+)
+
+// makeModinfo builds a .modinfo section holding the given key=value fields.
+func makeModinfo(fields ...string) Section {
+	data := []byte(strings.Join(fields, "\x00") + "\x00")
+	return Section{
+		SectionHeader: elf.SectionHeader{Name: ".modinfo", Type: elf.SHT_PROGBITS},
+		data:          func() ([]byte, error) { return data, nil },
+	}
+}
+
+// buildModule returns a little-endian 64-bit relocatable object with a .modinfo section holding modinfo,
+// followed by trailer.
+func buildModule(t *testing.T, modinfo string, trailer []byte) []byte {
+	t.Helper()
+	return buildRelocatable(t, ".modinfo", modinfo, trailer)
+}
+
+// buildRelocatable returns a little-endian 64-bit relocatable object with a single section called name holding data,
+// followed by trailer.
+func buildRelocatable(t *testing.T, name, data string, trailer []byte) []byte {
+	t.Helper()
+
+	const (
+		headerSize        = 64
+		sectionHeaderSize = 64
+	)
+	names := []byte("\x00" + name + "\x00.shstrtab\x00")
+	dataOff := uint64(headerSize)
+	namesOff := dataOff + uint64(len(data))
+	sectionHeadersOff := namesOff + uint64(len(names))
+
+	header := elf.Header64{
+		Ident:     [16]byte{0x7f, 'E', 'L', 'F', byte(elf.ELFCLASS64), byte(elf.ELFDATA2LSB), byte(elf.EV_CURRENT)},
+		Type:      uint16(elf.ET_REL),
+		Machine:   uint16(elf.EM_X86_64),
+		Version:   uint32(elf.EV_CURRENT),
+		Shoff:     sectionHeadersOff,
+		Ehsize:    headerSize,
+		Shentsize: sectionHeaderSize,
+		Shnum:     3,
+		Shstrndx:  2,
+	}
+	sections := []elf.Section64{
+		{},
+		{Name: 1, Type: uint32(elf.SHT_PROGBITS), Off: dataOff, Size: uint64(len(data)), Addralign: 1},
+		{Name: uint32(len(name)) + 2, Type: uint32(elf.SHT_STRTAB), Off: namesOff, Size: uint64(len(names)), Addralign: 1},
+	}
+
+	var buf bytes.Buffer
+	for _, v := range []any{&header, []byte(data), names, sections, trailer} {
+		if err := binary.Write(&buf, binary.LittleEndian, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestDetectModuleInfo(t *testing.T) {
+	tests := []struct {
+		name string
+		b    *fakeBinary
+		want *bin.ModuleInfo
+	}{
+		{
+			name: "retpoline module",
+			b: &fakeBinary{typ: elf.ET_REL, sections: []Section{makeModinfo(
+				"license=GPL", "retpoline=Y", "name=hello", "vermagic=6.8.0-31-generic SMP preempt mod_unload modversions ",
+			)}},
+			want: &bin.ModuleInfo{Name: "hello", VerMagic: "6.8.0-31-generic SMP preempt mod_unload modversions ", License: "GPL", Retpoline: true},
+		},
+		{
+			name: "module without retpoline",
+			b:    &fakeBinary{typ: elf.ET_REL, sections: []Section{makeModinfo("license=Dual MIT/GPL", "description=no value=split", "depends=")}},
+			want: &bin.ModuleInfo{License: "Dual MIT/GPL"},
+		},
+		{
+			name: "relocatable object",
+			b:    &fakeBinary{typ: elf.ET_REL},
+		},
+		{
+			name: "executable with .modinfo",
+			b:    &fakeBinary{typ: elf.ET_EXEC, sections: []Section{makeModinfo("license=GPL")}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectModuleInfo(tt.b)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("DetectModuleInfo() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrailer(t *testing.T) {
+	signature := append(bytes.Repeat([]byte{0xaa}, 32), ModuleSignatureMagic...)
+	tests := []struct {
+		name          string
+		trailer       []byte
+		wantSignature bool
+		wantErr       bool
+	}{
+		{name: "signed", trailer: signature, wantSignature: true},
+		{name: "unsigned"},
+		{name: "garbage", trailer: []byte("~Module signature")},
+		{name: "oversized", trailer: make([]byte, maxTrailerSize+1), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Open(bytes.NewReader(buildModule(t, "license=GPL\x00", tt.trailer)))
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			if kind := DetectKind(f); kind != bin.KindKernelModule {
+				t.Errorf("DetectKind() = %v, want kernel module", kind)
+			}
+
+			trailer, err := f.Trailer()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Trailer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !bytes.Equal(trailer, tt.trailer) {
+				t.Errorf("Trailer() = %q, want %q", trailer, tt.trailer)
+			}
+
+			signed, err := HasModuleSignature(f)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("HasModuleSignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if signed != tt.wantSignature {
+				t.Errorf("HasModuleSignature() = %v, want %v", signed, tt.wantSignature)
+			}
+		})
+	}
+}
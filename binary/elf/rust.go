@@ -0,0 +1,123 @@
+package elf
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	bin "go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/toolchain"
+)
+
+// rustcComment matches the .comment entry of rustc, e.g. "rustc version 1.90.0 (1159e78c4 2025-09-14)".
+var rustcComment = regexp.MustCompile(`^rustc version (\S+) \(([0-9a-f]+) `)
+
+// rustSourcePath matches the source paths of the standard library that panic locations embed. Code compiled into the
+// prebuilt standard library has them relative, e.g. "library/std/src/io/mod.rs", while its generic code instantiated in
+// other crates names the commit of the rustc release, e.g. "/rustc/1159e78c4747b02ef996e55082b704c09b970588/library/alloc/src/vec/mod.rs".
+var rustSourcePath = regexp.MustCompile(`(?:/rustc/([0-9a-f]{40})/)?library/([a-z_]+)/src/`)
+
+// rustCrate recognizes the mangled symbols of a crate.
+type rustCrate struct {
+	name string
+	// legacy is the prefix of legacy mangled symbols of items in the crate, e.g. "_ZN3std".
+	legacy string
+	// v0 matches a reference to the crate in v0 mangled symbols, capturing its disambiguator.
+	v0 *regexp.Regexp
+	// v0Item matches v0 mangled symbols of items in the crate.
+	v0Item *regexp.Regexp
+}
+
+func newRustCrate(name string) rustCrate {
+	ident := strconv.Itoa(len(name)) + name
+	return rustCrate{
+		name:   name,
+		legacy: "_ZN" + ident,
+		v0:     regexp.MustCompile(`C(?:s([0-9A-Za-z]*)_)?` + ident),
+		v0Item: regexp.MustCompile(`^_R(?:N[A-Za-z])*C(?:s[0-9A-Za-z]*_)?` + ident),
+	}
+}
+
+var rustStd, rustAlloc = newRustCrate("std"), newRustCrate("alloc")
+
+// DetectRustInfo reads the rustc commit from .comment and identifies the std and alloc crates linked into the binary.
+// Returns nil when the binary carries neither. A File scans its symbols once and returns the same value to every caller,
+// which must not modify it.
+func DetectRustInfo(b Binary) *bin.RustInfo {
+	if f, ok := b.(*File); ok {
+		return f.rustInfo()
+	}
+	return decodeRustInfo(b)
+}
+
+func decodeRustInfo(b Binary) *bin.RustInfo {
+	info := &bin.RustInfo{}
+	var version toolchain.Version
+	for _, comment := range extractCompilerComments(b) {
+		if m := rustcComment.FindStringSubmatch(comment); m != nil {
+			info.Commit = m[2]
+			version, _ = toolchain.ParseVersion(m[1])
+			break
+		}
+	}
+
+	crates := map[string]*bin.RustCrate{rustStd.name: &info.Std, rustAlloc.name: &info.Alloc}
+	symbols, _ := b.Symbols()
+	dynSymbols, _ := b.DynSymbols()
+	for _, sym := range slices.Concat(symbols, dynSymbols) {
+		for _, rc := range []rustCrate{rustStd, rustAlloc} {
+			detectRustCrateSymbol(sym.Name, rc, crates[rc.name])
+		}
+	}
+
+	// The crates of the standard library ship together, so the commit of any of them is that of all of them.
+	var commit string
+	if data, err := findSectionData(b, ".rodata"); err == nil {
+		for _, m := range rustSourcePath.FindAllSubmatch(data, -1) {
+			if crate, ok := crates[string(m[2])]; ok {
+				crate.Linked = true
+			}
+			if commit == "" && m[1] != nil {
+				commit = string(m[1])
+			}
+		}
+	}
+	for _, crate := range crates {
+		if !crate.Linked || commit == "" {
+			continue
+		}
+		crate.Commit = commit
+		if info.Commit != "" && strings.HasPrefix(commit, info.Commit) {
+			crate.Version = version
+		}
+	}
+
+	if info.Commit == "" && !info.Std.Linked && !info.Alloc.Linked {
+		return nil
+	}
+	return info
+}
+
+// detectRustCrateSymbol records what the mangled symbol name reveals about the crate rc.
+func detectRustCrateSymbol(name string, rc rustCrate, crate *bin.RustCrate) {
+	switch {
+	case strings.HasPrefix(name, rc.legacy):
+		crate.Linked = true
+		if crate.Mangling == "" {
+			crate.Mangling = "legacy"
+		}
+	case strings.HasPrefix(name, "_R"):
+		m := rc.v0.FindStringSubmatch(name)
+		if m == nil {
+			return
+		}
+		crate.Linked = true
+		if crate.Disambiguator == "" {
+			crate.Disambiguator = m[1]
+		}
+		if crate.Mangling == "" && rc.v0Item.MatchString(name) {
+			crate.Mangling = "v0"
+		}
+	}
+}
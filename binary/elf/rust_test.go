@@ -0,0 +1,110 @@
+package elf
+
+import (
+	"bytes"
+	"debug/elf"
+	"testing"
+
+	bin "go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/toolchain"
+)
+
+func makeSymbols(names ...string) []elf.Symbol {
+	symbols := make([]elf.Symbol, len(names))
+	for i, name := range names {
+		symbols[i] = elf.Symbol{Name: name}
+	}
+	return symbols
+}
+
+func TestDetectRustInfo(t *testing.T) {
+	const (
+		comment = "rustc version 1.90.0 (1159e78c4 2025-09-14)"
+		commit  = "1159e78c4747b02ef996e55082b704c09b970588"
+	)
+	rodata := makeSection(".rodata", []byte("library/std/src/io/mod.rs\x00/rustc/"+commit+"/library/alloc/src/vec/mod.rs\x00"))
+	version := toolchain.Version{Major: 1, Minor: 90}
+
+	tests := []struct {
+		name     string
+		sections []Section
+		symbols  []elf.Symbol
+		want     *bin.RustInfo
+	}{
+		{
+			name:     "legacy mangling",
+			sections: []Section{makeComment(comment), rodata},
+			symbols:  makeSymbols("_ZN3std2io5stdio6_print17h1d0c4f5f2b3e4a5bE", "_ZN5alloc7raw_vec11finish_grow17h8a9b0c1d2e3f4a5bE"),
+			want: &bin.RustInfo{
+				Commit: "1159e78c4",
+				Std:    bin.RustCrate{Linked: true, Mangling: "legacy", Commit: commit, Version: version},
+				Alloc:  bin.RustCrate{Linked: true, Mangling: "legacy", Commit: commit, Version: version},
+			},
+		},
+		{
+			name:     "v0 mangling",
+			sections: []Section{makeComment(comment)},
+			symbols: makeSymbols(
+				"_RNvNtNtCscKkwsb9kWaL_3std2io5stdio6_print",
+				"_RINvNtCs5GmCzIpY9Qj_4core3ptr13drop_in_placeINtNtCscmSb185pVu_5alloc3vec3VecNtNtBL_6string6StringEECsiTHC7ExSeJm_4main",
+			),
+			want: &bin.RustInfo{
+				Commit: "1159e78c4",
+				Std:    bin.RustCrate{Linked: true, Mangling: "v0", Disambiguator: "cKkwsb9kWaL"},
+				Alloc:  bin.RustCrate{Linked: true, Disambiguator: "cmSb185pVu"},
+			},
+		},
+		{
+			name:     "stripped",
+			sections: []Section{makeComment(comment), rodata},
+			want: &bin.RustInfo{
+				Commit: "1159e78c4",
+				Std:    bin.RustCrate{Linked: true, Commit: commit, Version: version},
+				Alloc:  bin.RustCrate{Linked: true, Commit: commit, Version: version},
+			},
+		},
+		{
+			name:     "standard library of another release",
+			sections: []Section{makeComment("rustc version 1.91.0-nightly (0d1e2f3a4 2025-09-20)"), rodata},
+			want: &bin.RustInfo{
+				Commit: "0d1e2f3a4",
+				Std:    bin.RustCrate{Linked: true, Commit: commit},
+				Alloc:  bin.RustCrate{Linked: true, Commit: commit},
+			},
+		},
+		{
+			name:     "no_std",
+			sections: []Section{makeComment(comment)},
+			symbols:  makeSymbols("_ZN4core9panicking5panic17h0a1b2c3d4e5f6a7bE"),
+			want:     &bin.RustInfo{Commit: "1159e78c4"},
+		},
+		{
+			name:     "not Rust",
+			sections: []Section{makeComment("GCC: (Debian 12.2.0-14) 12.2.0")},
+			symbols:  makeSymbols("main", "_ZNSt6vectorIiSaIiEE9push_backERKi"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectRustInfo(&fakeBinary{sections: tt.sections, symbols: tt.symbols})
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("DetectRustInfo() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectRustInfoDecodesOnce(t *testing.T) {
+	f, err := Open(bytes.NewReader(buildRelocatable(t, ".rodata", "library/std/src/io/mod.rs\x00", nil)))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	info := DetectRustInfo(f)
+	if info == nil || !info.Std.Linked {
+		t.Fatalf("DetectRustInfo() = %+v, want std linked", info)
+	}
+	if again := DetectRustInfo(f); again != info {
+		t.Error("DetectRustInfo() scanned the binary again")
+	}
+}
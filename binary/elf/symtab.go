@@ -74,3 +74,36 @@ func parseSymbols(data, strtab []byte, class elf.Class, bo binary.ByteOrder) ([]
 
 	return syms, nil
 }
+
+// SymbolData returns the first n bytes of the code or data the named symbol points to within its section.
+// Returns ErrSectionMissing when the symbol is absent, undefined, or lies outside of its section.
+func SymbolData(b Binary, name string, n int) ([]byte, error) {
+	symbols, err := b.Symbols()
+	if err != nil {
+		return nil, err
+	}
+	sections := b.Sections()
+	for _, sym := range symbols {
+		if sym.Name != name {
+			continue
+		}
+		if sym.Section == elf.SHN_UNDEF || int(sym.Section) >= len(sections) {
+			return nil, ErrSectionMissing
+		}
+		sec := sections[sym.Section]
+		data, err := sec.Data()
+		if err != nil {
+			return nil, err
+		}
+		// Symbol values are section offsets in relocatable objects and addresses otherwise.
+		off := sym.Value
+		if b.Type() != elf.ET_REL {
+			off -= sec.Addr
+		}
+		if off > uint64(len(data)) || uint64(len(data))-off < uint64(n) {
+			return nil, ErrSectionMissing
+		}
+		return data[off : off+uint64(n)], nil
+	}
+	return nil, ErrSectionMissing
+}
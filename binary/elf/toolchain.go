@@ -3,8 +3,6 @@ package elf
 import (
 	"bytes"
 	"debug/dwarf"
-	"encoding/binary"
-	"strings"
 
 	"go.kacmar.sk/crack/toolchain"
 )
@@ -52,54 +50,19 @@ func (d DefaultToolchainDetector) Detect(b Binary) toolchain.Toolchain {
 }
 
 // detectGoBuildInfo reads the version embedded in .go.buildinfo by the Go linker.
-// The section layout is documented at https://pkg.go.dev/debug/buildinfo.
-// Binaries built with Go 1.18+ store the version inline.
-// Older binaries reference it through a pointer and are reported without a version.
+// Binaries built before Go 1.18 are reported without a version.
 func detectGoBuildInfo(b Binary) (toolchain.Toolchain, bool) {
-	data, err := findSectionData(b, ".go.buildinfo")
-	if err != nil || len(data) < 32 {
-		return toolchain.Toolchain{}, false
-	}
-	if !bytes.HasPrefix(data, []byte("\xff Go buildinf:")) {
+	version, _, ok := readGoBuildInfo(b)
+	if !ok {
 		return toolchain.Toolchain{}, false
 	}
 	tc := toolchain.Toolchain{Compiler: toolchain.Go}
-	const flagsVersionInl = 0x2
-	if data[15]&flagsVersionInl == 0 {
-		return tc, true
-	}
-	length, n := binary.Uvarint(data[32:])
-	if n <= 0 {
-		return tc, true
-	}
-	start := 32 + n
-	// #nosec G115 -- start <= len(data) since binary.Uvarint cannot consume more bytes than its input.
-	remaining := uint64(len(data) - start)
-	if length > remaining {
-		return tc, true
-	}
-	// #nosec G115 -- length <= remaining (checked above), which fits in int.
-	vers := string(data[start : start+int(length)])
-	if v, ok := parseGoVersion(vers); ok {
+	if v, ok := toolchain.ParseGoVersion(version); ok {
 		tc.Version = v
 	}
 	return tc, true
 }
 
-// parseGoVersion extracts a semantic version from a Go toolchain version string.
-// Accepts forms like "go1.21.3" or "go1.21" and ignores pre-release suffixes.
-func parseGoVersion(s string) (toolchain.Version, bool) {
-	s = strings.TrimPrefix(s, "go")
-	if i := strings.IndexAny(s, "-+ "); i >= 0 {
-		s = s[:i]
-	}
-	v, err := toolchain.ParseVersion(s)
-	if err != nil {
-		return toolchain.Version{}, false
-	}
-	return v, true
-}
-
 // detectFromComment scans the .comment section and returns the most specific recognized toolchain.
 func detectFromComment(b Binary, sd toolchain.StringDetector) toolchain.Toolchain {
 	detected := make(map[toolchain.Compiler]toolchain.Version)
@@ -149,6 +112,45 @@ func detectFromDWARF(b Binary, sd toolchain.StringDetector) toolchain.Toolchain
 	return toolchain.Toolchain{}
 }
 
+// cLanguages are the DW_AT_language values of C and C++ compile units.
+var cLanguages = map[int64]bool{
+	0x01: true, // DW_LANG_C89
+	0x02: true, // DW_LANG_C
+	0x04: true, // DW_LANG_C_plus_plus
+	0x0c: true, // DW_LANG_C99
+	0x19: true, // DW_LANG_C_plus_plus_03
+	0x1a: true, // DW_LANG_C_plus_plus_11
+	0x1d: true, // DW_LANG_C11
+	0x21: true, // DW_LANG_C_plus_plus_14
+	0x2a: true, // DW_LANG_C_plus_plus_17
+	0x2b: true, // DW_LANG_C_plus_plus_20
+	0x2c: true, // DW_LANG_C17
+}
+
+// HasCCompileUnit reports whether the debug information of the binary describes a C or C++ compile unit.
+// ok is false when the binary has no debug information to tell.
+func HasCCompileUnit(b Binary) (found, ok bool) {
+	d, err := loadDWARF(b)
+	if err != nil || d == nil {
+		return false, false
+	}
+
+	reader := d.Reader()
+	for {
+		entry, err := reader.Next()
+		if err != nil || entry == nil {
+			return false, true
+		}
+		if entry.Tag != dwarf.TagCompileUnit {
+			continue
+		}
+		if lang, ok := entry.Val(dwarf.AttrLanguage).(int64); ok && cLanguages[lang] {
+			return true, true
+		}
+		reader.SkipChildren()
+	}
+}
+
 func extractCompilerComments(b Binary) []string {
 	data, err := findSectionData(b, ".comment")
 	if err != nil || data == nil {
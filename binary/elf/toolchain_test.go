@@ -38,34 +38,6 @@ func makeGoBuildInfoPointerFormat() Section {
 	return makeSection(".go.buildinfo", data)
 }
 
-func TestParseGoVersion(t *testing.T) {
-	tests := []struct {
-		in     string
-		want   toolchain.Version
-		wantOK bool
-	}{
-		{"go1.21.3", toolchain.Version{Major: 1, Minor: 21, Patch: 3}, true},
-		{"go1.21", toolchain.Version{Major: 1, Minor: 21}, true},
-		{"go1.22-rc1", toolchain.Version{Major: 1, Minor: 22}, true},
-		{"go1.22+something", toolchain.Version{Major: 1, Minor: 22}, true},
-		{"go1.22 someinfo", toolchain.Version{Major: 1, Minor: 22}, true},
-		{"devel go1.22", toolchain.Version{}, false},
-		{"go1", toolchain.Version{}, false},
-		{"", toolchain.Version{}, false},
-	}
-	for _, tc := range tests {
-		t.Run(tc.in, func(t *testing.T) {
-			got, ok := parseGoVersion(tc.in)
-			if ok != tc.wantOK {
-				t.Fatalf("parseGoVersion(%q) ok = %v, want %v", tc.in, ok, tc.wantOK)
-			}
-			if got != tc.want {
-				t.Errorf("parseGoVersion(%q) = %v, want %v", tc.in, got, tc.want)
-			}
-		})
-	}
-}
-
 func TestDefaultToolchainDetector(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,239 @@
+package binary
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/bits"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// FileMetadata holds the filesystem attributes of the file a binary was read from, which decide the privileges it runs with.
+type FileMetadata struct {
+	// Mode holds the permission bits along with fs.ModeSetuid, fs.ModeSetgid, and fs.ModeSticky.
+	Mode fs.FileMode
+	UID  uint32
+	GID  uint32
+	// Capabilities are the file capabilities granted on execution, or nil when the file has none.
+	Capabilities *Capabilities
+}
+
+// Setuid reports whether the file runs with the privileges of its owner.
+func (m *FileMetadata) Setuid() bool {
+	return m != nil && m.Mode&fs.ModeSetuid != 0 && m.Mode&0o111 != 0
+}
+
+// Setgid reports whether the file runs with the privileges of its group.
+// Without group execute permission, the setgid bit marks mandatory locking instead.
+func (m *FileMetadata) Setgid() bool {
+	return m != nil && m.Mode&fs.ModeSetgid != 0 && m.Mode&0o010 != 0
+}
+
+// Privileged reports whether executing the file grants privileges the caller may not have:
+// it is setuid or setgid, or has file capabilities.
+func (m *FileMetadata) Privileged() bool {
+	return m.Setuid() || m.Setgid() || (m != nil && m.Capabilities != nil && m.Capabilities.Permitted != 0)
+}
+
+// String describes the privileges the file grants, e.g. "setuid uid 0, capabilities cap_net_raw=ep", or "" when it grants none.
+func (m *FileMetadata) String() string {
+	var parts []string
+	if m.Setuid() {
+		parts = append(parts, fmt.Sprintf("setuid uid %d", m.UID))
+	}
+	if m.Setgid() {
+		parts = append(parts, fmt.Sprintf("setgid gid %d", m.GID))
+	}
+	if m != nil && m.Capabilities != nil {
+		parts = append(parts, "capabilities "+m.Capabilities.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Capabilities are Linux file capabilities as stored in the security.capability extended attribute.
+// Bit n of a set stands for capability n, e.g. bit 13 for CAP_NET_RAW.
+type Capabilities struct {
+	Permitted   uint64
+	Inheritable uint64
+	// Effective raises the permitted capabilities into the effective set on execution.
+	Effective bool
+	// RootID is the user ID of root in the user namespace the capabilities apply to, or 0 for the initial namespace.
+	RootID uint32
+}
+
+// vfs_cap_data layout, see linux/capability.h.
+const (
+	capRevisionMask  = 0xff000000
+	capRevision1     = 0x01000000
+	capRevision2     = 0x02000000
+	capRevision3     = 0x03000000
+	capFlagEffective = 0x000001
+)
+
+// ErrMalformedCapabilities is returned by ParseCapabilities and ParseCapabilitiesText for values they can't decode.
+var ErrMalformedCapabilities = errors.New("malformed file capabilities")
+
+// ParseCapabilities decodes the value of the security.capability extended attribute.
+func ParseCapabilities(data []byte) (*Capabilities, error) {
+	if len(data) < 4 {
+		return nil, ErrMalformedCapabilities
+	}
+	magic := binary.LittleEndian.Uint32(data)
+	var want int
+	switch magic & capRevisionMask {
+	case capRevision1:
+		want = 12
+	case capRevision2:
+		want = 20
+	case capRevision3:
+		want = 24
+	default:
+		return nil, fmt.Errorf("%w: unknown revision %#x", ErrMalformedCapabilities, magic&capRevisionMask)
+	}
+	if len(data) < want {
+		return nil, ErrMalformedCapabilities
+	}
+
+	c := &Capabilities{
+		Permitted:   uint64(binary.LittleEndian.Uint32(data[4:])),
+		Inheritable: uint64(binary.LittleEndian.Uint32(data[8:])),
+		Effective:   magic&capFlagEffective != 0,
+	}
+	if want >= 20 {
+		c.Permitted |= uint64(binary.LittleEndian.Uint32(data[12:])) << 32
+		c.Inheritable |= uint64(binary.LittleEndian.Uint32(data[16:])) << 32
+	}
+	if want == 24 {
+		c.RootID = binary.LittleEndian.Uint32(data[20:])
+	}
+	return c, nil
+}
+
+// ParseCapabilitiesText decodes capabilities in the text form of cap_from_text(3) and getcap, e.g. "cap_net_raw=ep",
+// which is how RPM packages record them. The effective flag of any capability sets Effective.
+func ParseCapabilitiesText(text string) (*Capabilities, error) {
+	var permitted, inheritable, effective uint64
+	for _, clause := range strings.Fields(text) {
+		i := strings.IndexAny(clause, "=+-")
+		if i < 0 {
+			return nil, fmt.Errorf("%w: no operator in %q", ErrMalformedCapabilities, clause)
+		}
+		if i == 0 && clause[0] != '=' {
+			return nil, fmt.Errorf("%w: no capabilities in %q", ErrMalformedCapabilities, clause)
+		}
+		caps, err := parseCapabilityNames(clause[:i])
+		if err != nil {
+			return nil, err
+		}
+
+		for ops := clause[i:]; ops != ""; {
+			op, flags := ops[0], ops[1:]
+			if j := strings.IndexAny(flags, "=+-"); j >= 0 {
+				flags, ops = flags[:j], flags[j:]
+			} else {
+				ops = ""
+			}
+			if op == '=' {
+				permitted, inheritable, effective = permitted&^caps, inheritable&^caps, effective&^caps
+			} else if flags == "" {
+				return nil, fmt.Errorf("%w: no flags in %q", ErrMalformedCapabilities, clause)
+			}
+			for _, flag := range flags {
+				var set *uint64
+				switch flag {
+				case 'p':
+					set = &permitted
+				case 'i':
+					set = &inheritable
+				case 'e':
+					set = &effective
+				default:
+					return nil, fmt.Errorf("%w: unknown flag %q in %q", ErrMalformedCapabilities, flag, clause)
+				}
+				if op == '-' {
+					*set &^= caps
+				} else {
+					*set |= caps
+				}
+			}
+		}
+	}
+	return &Capabilities{Permitted: permitted, Inheritable: inheritable, Effective: effective != 0}, nil
+}
+
+// parseCapabilityNames returns the set of a comma-separated capability list, where "all" and an empty list stand for
+// every known capability and unknown ones may be given by number, e.g. "cap_41".
+func parseCapabilityNames(list string) (uint64, error) {
+	if list == "" || strings.EqualFold(list, "all") {
+		return 1<<len(capabilityNames) - 1, nil
+	}
+	var set uint64
+	for name := range strings.SplitSeq(strings.ToLower(list), ",") {
+		n := slices.Index(capabilityNames, name)
+		if n < 0 {
+			num, ok := strings.CutPrefix(name, "cap_")
+			v, err := strconv.ParseUint(num, 10, 6)
+			if !ok || err != nil {
+				return 0, fmt.Errorf("%w: unknown capability %q", ErrMalformedCapabilities, name)
+			}
+			n = int(v)
+		}
+		set |= 1 << n
+	}
+	return set, nil
+}
+
+// capabilityNames are the names of the capabilities by number, as printed by getcap.
+var capabilityNames = []string{
+	"cap_chown", "cap_dac_override", "cap_dac_read_search", "cap_fowner", "cap_fsetid", "cap_kill", "cap_setgid",
+	"cap_setuid", "cap_setpcap", "cap_linux_immutable", "cap_net_bind_service", "cap_net_broadcast", "cap_net_admin",
+	"cap_net_raw", "cap_ipc_lock", "cap_ipc_owner", "cap_sys_module", "cap_sys_rawio", "cap_sys_chroot", "cap_sys_ptrace",
+	"cap_sys_pacct", "cap_sys_admin", "cap_sys_boot", "cap_sys_nice", "cap_sys_resource", "cap_sys_time",
+	"cap_sys_tty_config", "cap_mknod", "cap_lease", "cap_audit_write", "cap_audit_control", "cap_setfcap",
+	"cap_mac_override", "cap_mac_admin", "cap_syslog", "cap_wake_alarm", "cap_block_suspend", "cap_audit_read",
+	"cap_perfmon", "cap_bpf", "cap_checkpoint_restore",
+}
+
+// capabilitySetNames lists the capabilities of set, naming unknown ones by number.
+func capabilitySetNames(set uint64) string {
+	var names []string
+	for set != 0 {
+		n := bits.TrailingZeros64(set)
+		set &^= 1 << n
+		if n < len(capabilityNames) {
+			names = append(names, capabilityNames[n])
+		} else {
+			names = append(names, fmt.Sprintf("cap_%d", n))
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// String formats the capabilities as getcap does, e.g. "cap_net_bind_service=ep".
+func (c *Capabilities) String() string {
+	flags := func(set string, effective bool) string {
+		if effective {
+			return "e" + set
+		}
+		return set
+	}
+	var clauses []string
+	switch {
+	case c.Permitted != 0 && c.Permitted == c.Inheritable:
+		clauses = append(clauses, capabilitySetNames(c.Permitted)+"="+flags("ip", c.Effective))
+	default:
+		if c.Permitted != 0 {
+			clauses = append(clauses, capabilitySetNames(c.Permitted)+"="+flags("p", c.Effective))
+		}
+		if c.Inheritable != 0 {
+			clauses = append(clauses, capabilitySetNames(c.Inheritable)+"=i")
+		}
+	}
+	if len(clauses) == 0 {
+		return "="
+	}
+	return strings.Join(clauses, " ")
+}
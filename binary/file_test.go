@@ -0,0 +1,129 @@
+package binary
+
+import (
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"testing"
+)
+
+func TestParseCapabilities(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		rootID  uint32
+		wantErr bool
+	}{
+		// setcap cap_net_raw=ep
+		{name: "revision 2 effective", data: "01000002" + "00200000" + "00000000" + "00000000" + "00000000", want: "cap_net_raw=ep"},
+		// setcap cap_net_bind_service,cap_net_admin=p
+		{name: "revision 2 permitted", data: "00000002" + "00140000" + "00000000" + "00000000" + "00000000", want: "cap_net_bind_service,cap_net_admin=p"},
+		// setcap cap_bpf,cap_chown=eip
+		{name: "high capability", data: "01000002" + "01000000" + "01000000" + "80000000" + "80000000", want: "cap_chown,cap_bpf=eip"},
+		{name: "revision 3 root id", data: "01000003" + "00200000" + "00000000" + "00000000" + "00000000" + "e8030000", want: "cap_net_raw=ep", rootID: 1000},
+		{name: "revision 1", data: "00000001" + "01000000" + "00000000", want: "cap_chown=p"},
+		{name: "truncated", data: "01000002" + "00200000", wantErr: true},
+		{name: "unknown revision", data: "01000009" + "00200000" + "00000000", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			caps, err := ParseCapabilities(data)
+			if tt.wantErr {
+				if !errors.Is(err, ErrMalformedCapabilities) {
+					t.Errorf("ParseCapabilities() error = %v, want ErrMalformedCapabilities", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCapabilities() error = %v", err)
+			}
+			if got := caps.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+			if caps.RootID != tt.rootID {
+				t.Errorf("RootID = %d, want %d", caps.RootID, tt.rootID)
+			}
+		})
+	}
+}
+
+func TestParseCapabilitiesText(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{text: "cap_net_raw=ep", want: "cap_net_raw=ep"},
+		{text: "cap_net_bind_service,cap_net_admin+p", want: "cap_net_bind_service,cap_net_admin=p"},
+		{text: "CAP_BPF,cap_chown=eip", want: "cap_chown,cap_bpf=eip"},
+		{text: "cap_setuid=p cap_setuid+i", want: "cap_setuid=ip"},
+		{text: "all=p all-p cap_chown+p", want: "cap_chown=p"},
+		{text: "cap_net_raw+ep-e", want: "cap_net_raw=p"},
+		{text: "cap_45=p", want: "cap_45=p"},
+		{text: "", want: "="},
+		{text: "=", want: "="},
+		{text: "cap_net_raw", wantErr: true},
+		{text: "+p", wantErr: true},
+		{text: "cap_net_raw+", wantErr: true},
+		{text: "cap_net_raw=x", wantErr: true},
+		{text: "cap_bogus=p", wantErr: true},
+		{text: "cap_64=p", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			caps, err := ParseCapabilitiesText(tt.text)
+			if tt.wantErr {
+				if !errors.Is(err, ErrMalformedCapabilities) {
+					t.Errorf("ParseCapabilitiesText() error = %v, want ErrMalformedCapabilities", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCapabilitiesText() error = %v", err)
+			}
+			if got := caps.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileMetadataPrivileged(t *testing.T) {
+	tests := []struct {
+		name       string
+		meta       *FileMetadata
+		privileged bool
+		want       string
+	}{
+		{name: "nil"},
+		{name: "regular", meta: &FileMetadata{Mode: 0o755}},
+		{name: "setuid root", meta: &FileMetadata{Mode: fs.ModeSetuid | 0o4755}, privileged: true, want: "setuid uid 0"},
+		{name: "setuid without execute", meta: &FileMetadata{Mode: fs.ModeSetuid | 0o644}},
+		{name: "setgid", meta: &FileMetadata{Mode: fs.ModeSetgid | 0o2755, GID: 42}, privileged: true, want: "setgid gid 42"},
+		{name: "setgid mandatory locking", meta: &FileMetadata{Mode: fs.ModeSetgid | 0o744}},
+		{
+			name:       "capabilities",
+			meta:       &FileMetadata{Mode: 0o755, Capabilities: &Capabilities{Permitted: 1 << 13, Effective: true}},
+			privileged: true,
+			want:       "capabilities cap_net_raw=ep",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.meta.Privileged(); got != tt.privileged {
+				t.Errorf("Privileged() = %v, want %v", got, tt.privileged)
+			}
+			if got := tt.meta.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
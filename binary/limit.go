@@ -0,0 +1,147 @@
+package binary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrLimitExceeded is returned when a binary isn't analyzed because it would exceed a resource limit,
+// such as its size, the time its analysis takes, or the memory budget.
+var ErrLimitExceeded = errors.New("resource limit exceeded")
+
+// MemoryBudget caps the section and segment data held at once by all binaries being analyzed.
+// Each binary reserves memory through its own MemoryAccount before reading data, and returns it once its analysis ends.
+// A nil MemoryBudget imposes no limit.
+type MemoryBudget struct {
+	limit uint64
+
+	mu   sync.Mutex
+	used uint64
+	// holders counts the accounts holding memory and waiting those of them blocked in Reserve.
+	holders int
+	waiting int
+	// released is closed and replaced whenever memory is returned to the budget.
+	released chan struct{}
+}
+
+// NewMemoryBudget returns a budget of limit bytes.
+func NewMemoryBudget(limit uint64) *MemoryBudget {
+	return &MemoryBudget{limit: limit, released: make(chan struct{})}
+}
+
+// Limit returns the size of the budget in bytes.
+func (b *MemoryBudget) Limit() uint64 {
+	if b == nil {
+		return 0
+	}
+	return b.limit
+}
+
+// Account opens an account for the data of one binary. Reservations wait for other binaries to return memory until ctx is done.
+// The returned account is nil when b is, which reserves nothing.
+func (b *MemoryBudget) Account(ctx context.Context) *MemoryAccount {
+	if b == nil {
+		return nil
+	}
+	return &MemoryAccount{budget: b, ctx: ctx}
+}
+
+// MemoryAccount tracks the memory reserved for one binary. Its methods are safe for concurrent use, and no-ops on a nil account.
+type MemoryAccount struct {
+	budget *MemoryBudget
+	ctx    context.Context
+	held   uint64
+	// err is the first limit error returned by Reserve.
+	err error
+}
+
+// Reserve reserves n bytes, waiting while other binaries hold the memory needed.
+// It fails with ErrLimitExceeded when the binary alone would exceed the budget, or when all binaries holding memory are
+// waiting for each other.
+func (a *MemoryAccount) Reserve(n uint64) error {
+	if a == nil {
+		return nil
+	}
+	b := a.budget
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for {
+		if n > b.limit-a.held {
+			return a.fail(fmt.Errorf("%w: reading %d more bytes would take the binary over the memory budget of %d bytes", ErrLimitExceeded, n, b.limit))
+		}
+		if n <= b.limit-b.used {
+			if a.held == 0 {
+				b.holders++
+			}
+			a.held += n
+			b.used += n
+			return nil
+		}
+
+		// Waiting is only worthwhile while some other binary holding memory keeps running and will eventually return it.
+		others := b.holders
+		if a.held > 0 {
+			others--
+		}
+		if others == b.waiting {
+			return a.fail(fmt.Errorf("%w: the memory budget of %d bytes is held by other binaries", ErrLimitExceeded, b.limit))
+		}
+		if a.held > 0 {
+			b.waiting++
+		}
+		released := b.released
+		b.mu.Unlock()
+		var err error
+		select {
+		case <-released:
+		case <-a.ctx.Done():
+			err = a.ctx.Err()
+		}
+		b.mu.Lock()
+		if a.held > 0 {
+			b.waiting--
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// fail records err as the limit the binary hit and returns it. b.mu must be held.
+func (a *MemoryAccount) fail(err error) error {
+	if a.err == nil {
+		a.err = err
+	}
+	return err
+}
+
+// Err returns the first limit error Reserve returned, so that analysis can report the binary as exceeding the budget
+// even when the reader that hit the limit degraded gracefully.
+func (a *MemoryAccount) Err() error {
+	if a == nil {
+		return nil
+	}
+	a.budget.mu.Lock()
+	defer a.budget.mu.Unlock()
+	return a.err
+}
+
+// Close returns the memory held by the account to the budget.
+func (a *MemoryAccount) Close() {
+	if a == nil {
+		return
+	}
+	b := a.budget
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if a.held == 0 {
+		return
+	}
+	b.used -= a.held
+	b.holders--
+	a.held = 0
+	close(b.released)
+	b.released = make(chan struct{})
+}
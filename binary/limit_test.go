@@ -0,0 +1,106 @@
+package binary
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryBudget(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("over budget alone", func(t *testing.T) {
+		b := NewMemoryBudget(100)
+		a := b.Account(ctx)
+		defer a.Close()
+		if err := a.Reserve(60); err != nil {
+			t.Fatalf("Reserve(60) error = %v", err)
+		}
+		if err := a.Reserve(50); !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("Reserve(50) error = %v, want ErrLimitExceeded", err)
+		}
+		if err := a.Err(); !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("Err() = %v, want ErrLimitExceeded", err)
+		}
+		if err := a.Reserve(40); err != nil {
+			t.Errorf("Reserve(40) error = %v", err)
+		}
+	})
+
+	t.Run("waits for release", func(t *testing.T) {
+		b := NewMemoryBudget(100)
+		first := b.Account(ctx)
+		if err := first.Reserve(80); err != nil {
+			t.Fatalf("Reserve(80) error = %v", err)
+		}
+		second := b.Account(ctx)
+		defer second.Close()
+		reserved := make(chan error)
+		go func() { reserved <- second.Reserve(50) }()
+
+		select {
+		case err := <-reserved:
+			t.Fatalf("Reserve(50) returned %v while the budget was held", err)
+		case <-time.After(50 * time.Millisecond):
+		}
+		first.Close()
+		if err := <-reserved; err != nil {
+			t.Errorf("Reserve(50) error = %v after release", err)
+		}
+	})
+
+	t.Run("all holders waiting", func(t *testing.T) {
+		b := NewMemoryBudget(100)
+		first, second := b.Account(ctx), b.Account(ctx)
+		if err := first.Reserve(60); err != nil {
+			t.Fatalf("Reserve(60) error = %v", err)
+		}
+		if err := second.Reserve(40); err != nil {
+			t.Fatalf("Reserve(40) error = %v", err)
+		}
+		reserved := make(chan error)
+		go func() { reserved <- first.Reserve(20) }()
+		// Wait for first to block, so that second finds every other holder waiting.
+		for {
+			b.mu.Lock()
+			waiting := b.waiting
+			b.mu.Unlock()
+			if waiting == 1 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		if err := second.Reserve(20); !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("Reserve(20) error = %v, want ErrLimitExceeded", err)
+		}
+		second.Close()
+		if err := <-reserved; err != nil {
+			t.Errorf("Reserve(20) error = %v after release", err)
+		}
+		first.Close()
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		b := NewMemoryBudget(100)
+		first := b.Account(ctx)
+		defer first.Close()
+		if err := first.Reserve(100); err != nil {
+			t.Fatalf("Reserve(100) error = %v", err)
+		}
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		if err := b.Account(canceled).Reserve(1); !errors.Is(err, context.Canceled) {
+			t.Errorf("Reserve(1) error = %v, want context.Canceled", err)
+		}
+	})
+
+	t.Run("nil budget", func(t *testing.T) {
+		var b *MemoryBudget
+		a := b.Account(ctx)
+		if err := a.Reserve(1 << 40); err != nil {
+			t.Errorf("Reserve() error = %v", err)
+		}
+		a.Close()
+	})
+}
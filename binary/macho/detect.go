@@ -0,0 +1,66 @@
+package macho
+
+import (
+	"debug/macho"
+	"strconv"
+
+	"go.kacmar.sk/crack/binary"
+)
+
+// DetectArchitecture returns the architecture of the binary.
+func DetectArchitecture(b Binary) binary.Architecture {
+	switch b.CPU() {
+	case macho.Cpu386:
+		return binary.ArchX86
+	case macho.CpuAmd64:
+		return binary.ArchAMD64
+	case macho.CpuArm:
+		return binary.ArchARM
+	case macho.CpuArm64:
+		return binary.ArchARM64
+	case macho.CpuPpc64:
+		return binary.ArchPPC64
+	default:
+		return binary.ArchUnknown
+	}
+}
+
+// DetectKind classifies the binary by its file type. Bundles, which are loaded like libraries, count as shared libraries.
+func DetectKind(b Binary) binary.Kind {
+	switch b.Type() {
+	case macho.TypeExec:
+		return binary.KindExecutable
+	case macho.TypeDylib, macho.TypeBundle:
+		return binary.KindSharedLibrary
+	case macho.TypeObj:
+		return binary.KindRelocatable
+	default:
+		return binary.KindUnknown
+	}
+}
+
+// ArchName returns the name lipo gives the slice of the binary, such as "x86_64" or "arm64e".
+func ArchName(b Binary) string {
+	switch b.CPU() {
+	case macho.Cpu386:
+		return "i386"
+	case macho.CpuAmd64:
+		if b.SubCPU() == CPUSubtypeX86_64H {
+			return "x86_64h"
+		}
+		return "x86_64"
+	case macho.CpuArm:
+		return "arm"
+	case macho.CpuArm64:
+		if b.SubCPU() == CPUSubtypeARM64E {
+			return "arm64e"
+		}
+		return "arm64"
+	case macho.CpuPpc:
+		return "ppc"
+	case macho.CpuPpc64:
+		return "ppc64"
+	default:
+		return "cpu" + strconv.FormatUint(uint64(b.CPU()), 10)
+	}
+}
@@ -0,0 +1,68 @@
+package macho
+
+import (
+	"debug/macho"
+	"testing"
+
+	"go.kacmar.sk/crack/binary"
+)
+
+// fakeBinary is a minimal Binary used to drive detection.
+type fakeBinary struct {
+	cpu    macho.Cpu
+	subCPU uint32
+	typ    macho.Type
+}
+
+func (f *fakeBinary) CPU() macho.Cpu                         { return f.cpu }
+func (f *fakeBinary) SubCPU() uint32                         { return f.subCPU }
+func (f *fakeBinary) Type() macho.Type                       { return f.typ }
+func (f *fakeBinary) Flags() uint32                          { return 0 }
+func (f *fakeBinary) Segments() []macho.SegmentHeader        { return nil }
+func (f *fakeBinary) Sections() []Section                    { return nil }
+func (f *fakeBinary) Symbols() []macho.Symbol                { return nil }
+func (f *fakeBinary) ImportedSymbols() ([]string, error)     { return nil, nil }
+func (f *fakeBinary) CodeSignature() (*CodeSignature, error) { return nil, nil }
+
+func TestDetectKind(t *testing.T) {
+	tests := []struct {
+		typ  macho.Type
+		want binary.Kind
+	}{
+		{macho.TypeExec, binary.KindExecutable},
+		{macho.TypeDylib, binary.KindSharedLibrary},
+		{macho.TypeBundle, binary.KindSharedLibrary},
+		{macho.TypeObj, binary.KindRelocatable},
+		{macho.Type(0x5), binary.KindUnknown}, // MH_CORE
+	}
+	for _, tt := range tests {
+		if got := DetectKind(&fakeBinary{typ: tt.typ}); got != tt.want {
+			t.Errorf("DetectKind(%v) = %v, want %v", tt.typ, got, tt.want)
+		}
+	}
+}
+
+func TestArchName(t *testing.T) {
+	tests := []struct {
+		cpu      macho.Cpu
+		subCPU   uint32
+		want     string
+		wantArch binary.Architecture
+	}{
+		{macho.CpuAmd64, 3, "x86_64", binary.ArchAMD64},
+		{macho.CpuAmd64, CPUSubtypeX86_64H, "x86_64h", binary.ArchAMD64},
+		{macho.CpuArm64, 0, "arm64", binary.ArchARM64},
+		{macho.CpuArm64, CPUSubtypeARM64E, "arm64e", binary.ArchARM64},
+		{macho.Cpu386, 3, "i386", binary.ArchX86},
+		{macho.Cpu(0x1000011), 0, "cpu16777233", binary.ArchUnknown},
+	}
+	for _, tt := range tests {
+		b := &fakeBinary{cpu: tt.cpu, subCPU: tt.subCPU}
+		if got := ArchName(b); got != tt.want {
+			t.Errorf("ArchName(%v, %d) = %q, want %q", tt.cpu, tt.subCPU, got, tt.want)
+		}
+		if got := DetectArchitecture(b); got != tt.wantArch {
+			t.Errorf("DetectArchitecture(%v) = %v, want %v", tt.cpu, got, tt.wantArch)
+		}
+	}
+}
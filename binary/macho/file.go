@@ -0,0 +1,244 @@
+package macho
+
+import (
+	"bytes"
+	"debug/macho"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	bin "go.kacmar.sk/crack/binary"
+)
+
+// File is the canonical Binary implementation. It wraps stdlib's *macho.File.
+type File struct {
+	file *macho.File
+	// r reads the Mach-O file itself, which is a slice of the input for universal binaries.
+	r      io.ReaderAt
+	memory *bin.MemoryAccount
+
+	segments []macho.SegmentHeader
+	sections []Section
+
+	// Memoized parsed views. Each closure runs at most once thanks to sync.OnceValues.
+	codeSignature func() (*CodeSignature, error)
+}
+
+// Option configures a File at construction time.
+type Option func(*openConfig)
+
+type openConfig struct {
+	memory *bin.MemoryAccount
+}
+
+// WithMemoryAccount accounts the section and code signature data read from the binary against the memory budget of account.
+// Reads that would exceed it fail with bin.ErrLimitExceeded. The caller closes the account once it is done with the returned binaries.
+func WithMemoryAccount(account *bin.MemoryAccount) Option {
+	return func(c *openConfig) { c.memory = account }
+}
+
+// maxFatArches bounds the slice count of a universal binary. Java class files share the universal magic, followed by
+// their version, which reads as a count of at least 45.
+const maxFatArches = 45
+
+// IsUniversal reports whether r starts with the header of a universal binary.
+func IsUniversal(r io.ReaderAt) bool {
+	var header [8]byte
+	if _, err := r.ReadAt(header[:], 0); err != nil {
+		return false
+	}
+	narch := binary.BigEndian.Uint32(header[4:])
+	return binary.BigEndian.Uint32(header[:]) == macho.MagicFat && narch > 0 && narch < maxFatArches
+}
+
+// isThin reports whether r starts with the magic of a 32-bit or 64-bit Mach-O file in either byte order.
+func isThin(r io.ReaderAt) bool {
+	var magic [4]byte
+	if _, err := r.ReadAt(magic[:], 0); err != nil {
+		return false
+	}
+	for _, m := range []uint32{binary.BigEndian.Uint32(magic[:]), binary.LittleEndian.Uint32(magic[:])} {
+		if m == macho.Magic32 || m == macho.Magic64 {
+			return true
+		}
+	}
+	return false
+}
+
+// Open parses the headers, load commands and symbol table of the Mach-O file read from r.
+// Section contents and the code signature are read lazily on demand. Universal binaries are opened with OpenUniversal.
+// The caller owns r and must keep it open while the returned binary is in use.
+func Open(r io.ReaderAt, opts ...Option) (*File, error) {
+	if !isThin(r) {
+		return nil, bin.ErrUnsupportedFormat
+	}
+	f, err := macho.NewFile(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Mach-O file: %w", err)
+	}
+	return newFile(f, r, opts), nil
+}
+
+// OpenUniversal parses every slice of the universal binary read from r, in the order of its header.
+// The caller owns r and must keep it open while the returned binaries are in use.
+func OpenUniversal(r io.ReaderAt, opts ...Option) ([]*File, error) {
+	if !IsUniversal(r) {
+		return nil, bin.ErrUnsupportedFormat
+	}
+	ff, err := macho.NewFatFile(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open universal binary: %w", err)
+	}
+	files := make([]*File, len(ff.Arches))
+	for i, arch := range ff.Arches {
+		files[i] = newFile(arch.File, io.NewSectionReader(r, int64(arch.Offset), int64(arch.Size)), opts)
+	}
+	return files, nil
+}
+
+func newFile(f *macho.File, r io.ReaderAt, opts []Option) *File {
+	var cfg openConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	b := &File{file: f, r: r, memory: cfg.memory}
+
+	for _, l := range f.Loads {
+		if seg, ok := l.(*macho.Segment); ok {
+			b.segments = append(b.segments, seg.SegmentHeader)
+		}
+	}
+
+	b.sections = make([]Section, len(f.Sections))
+	for i, s := range f.Sections {
+		sec := s
+		b.sections[i] = Section{
+			SectionHeader: sec.SectionHeader,
+			data: func() ([]byte, error) {
+				if err := b.memory.Reserve(sec.Size); err != nil {
+					return nil, fmt.Errorf("failed to read section %s,%s: %w", sec.Seg, sec.Name, err)
+				}
+				return sec.Data()
+			},
+		}
+	}
+
+	b.codeSignature = sync.OnceValues(b.loadCodeSignature)
+
+	return b
+}
+
+func (b *File) CPU() macho.Cpu                  { return b.file.Cpu }
+func (b *File) SubCPU() uint32                  { return b.file.SubCpu &^ cpuSubtypeMask }
+func (b *File) Type() macho.Type                { return b.file.Type }
+func (b *File) Flags() uint32                   { return b.file.Flags }
+func (b *File) Segments() []macho.SegmentHeader { return b.segments }
+func (b *File) Sections() []Section             { return b.sections }
+
+func (b *File) Symbols() []macho.Symbol {
+	if b.file.Symtab == nil {
+		return nil
+	}
+	return b.file.Symtab.Syms
+}
+
+func (b *File) ImportedSymbols() ([]string, error) {
+	if b.file.Symtab == nil {
+		return nil, nil
+	}
+	syms, err := b.file.ImportedSymbols()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read imports: %w", err)
+	}
+	return syms, nil
+}
+
+func (b *File) CodeSignature() (*CodeSignature, error) { return b.codeSignature() }
+
+// Code signature blob layout, see cs_blobs.h. All fields are big-endian regardless of the binary's byte order.
+const (
+	csMagicEmbeddedSignature = 0xfade0cc0
+	csMagicCodeDirectory     = 0xfade0c02
+	csSlotCodeDirectory      = 0
+	superBlobHeaderSize      = 12
+	blobIndexSize            = 8
+	codeDirectoryHeaderSize  = 24
+)
+
+// errMalformedSignature is returned for code signatures that don't follow the embedded signature layout.
+var errMalformedSignature = errors.New("malformed code signature")
+
+func (b *File) loadCodeSignature() (*CodeSignature, error) {
+	var dataOff, dataSize uint32
+	found := false
+	for _, l := range b.file.Loads {
+		raw := l.Raw()
+		if len(raw) < 16 || macho.LoadCmd(b.file.ByteOrder.Uint32(raw)) != LoadCmdCodeSignature {
+			continue
+		}
+		dataOff, dataSize = b.file.ByteOrder.Uint32(raw[8:]), b.file.ByteOrder.Uint32(raw[12:])
+		found = true
+		break
+	}
+	if !found {
+		return nil, nil
+	}
+
+	if err := b.memory.Reserve(uint64(dataSize)); err != nil {
+		return nil, fmt.Errorf("failed to read code signature: %w", err)
+	}
+	data, err := readFull(io.NewSectionReader(b.r, int64(dataOff), int64(dataSize)), dataSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read code signature: %w", err)
+	}
+	return parseCodeSignature(data)
+}
+
+// parseCodeSignature decodes the embedded signature superblob, taking the flags and identifier from its code directory.
+func parseCodeSignature(data []byte) (*CodeSignature, error) {
+	if len(data) < superBlobHeaderSize || binary.BigEndian.Uint32(data) != csMagicEmbeddedSignature {
+		return nil, errMalformedSignature
+	}
+	count := binary.BigEndian.Uint32(data[8:])
+	sig := &CodeSignature{}
+	for i := uint32(0); i < count; i++ {
+		entry := superBlobHeaderSize + int(i)*blobIndexSize
+		if entry+blobIndexSize > len(data) {
+			return nil, errMalformedSignature
+		}
+		if binary.BigEndian.Uint32(data[entry:]) != csSlotCodeDirectory {
+			continue
+		}
+		off := int(binary.BigEndian.Uint32(data[entry+4:]))
+		if off < 0 || off+codeDirectoryHeaderSize > len(data) || binary.BigEndian.Uint32(data[off:]) != csMagicCodeDirectory {
+			return nil, errMalformedSignature
+		}
+		cd := data[off:]
+		sig.Flags = binary.BigEndian.Uint32(cd[12:])
+		if ident := int(binary.BigEndian.Uint32(cd[20:])); ident > 0 && ident < len(cd) {
+			name := cd[ident:]
+			if end := bytes.IndexByte(name, 0); end >= 0 {
+				name = name[:end]
+			}
+			sig.Identifier = string(name)
+		}
+		break
+	}
+	return sig, nil
+}
+
+// readFull reads exactly size bytes from r. Reading through a reader rather than into a buffer of size bytes keeps a corrupt
+// size from allocating more than the file holds.
+func readFull(r io.Reader, size uint32) ([]byte, error) {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if uint32(len(buf)) != size { // #nosec G115 -- len(buf) is at most size
+		return nil, io.ErrUnexpectedEOF
+	}
+	return buf, nil
+}
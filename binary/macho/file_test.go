@@ -0,0 +1,298 @@
+package macho
+
+import (
+	"bytes"
+	"debug/macho"
+	"encoding/binary"
+	"errors"
+	"slices"
+	"testing"
+
+	bin "go.kacmar.sk/crack/binary"
+)
+
+// testImage describes a minimal 64-bit little-endian Mach-O file.
+type testImage struct {
+	cpu    macho.Cpu
+	subCPU uint32
+	typ    macho.Type
+	flags  uint32
+	// sections each get an empty section in a segment of their own, given as {segment, section}.
+	sections [][2]string
+	// imports become undefined external symbols of the symbol table.
+	imports []string
+	// signature is referenced by an LC_CODE_SIGNATURE load command when non-nil.
+	signature []byte
+}
+
+const (
+	testHeaderSize    = 32
+	testSegmentSize   = 72
+	testSectionSize   = 80
+	testSymtabCmdSize = 24
+	testNlistSize     = 16
+)
+
+func buildImage(t *testing.T, img testImage) []byte {
+	t.Helper()
+
+	ncmds := len(img.sections) + 1
+	cmdsize := len(img.sections)*(testSegmentSize+testSectionSize) + testSymtabCmdSize
+	if img.signature != nil {
+		ncmds++
+		cmdsize += 16
+	}
+	symoff := testHeaderSize + cmdsize
+	stroff := symoff + len(img.imports)*testNlistSize
+	strtab := []byte{0}
+	for _, name := range img.imports {
+		strtab = append(strtab, name...)
+		strtab = append(strtab, 0)
+	}
+	sigoff := stroff + len(strtab)
+
+	var buf bytes.Buffer
+	le := binary.LittleEndian
+	write := func(v any) {
+		if err := binary.Write(&buf, le, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	name16 := func(s string) (b [16]byte) {
+		copy(b[:], s)
+		return b
+	}
+
+	write(macho.FileHeader{Magic: macho.Magic64, Cpu: img.cpu, SubCpu: img.subCPU, Type: img.typ, Ncmd: uint32(ncmds), Cmdsz: uint32(cmdsize), Flags: img.flags})
+	write(uint32(0)) // reserved
+	for _, s := range img.sections {
+		write(macho.Segment64{Cmd: macho.LoadCmdSegment64, Len: testSegmentSize + testSectionSize, Name: name16(s[0]), Nsect: 1})
+		write(macho.Section64{Name: name16(s[1]), Seg: name16(s[0])})
+	}
+	write(macho.SymtabCmd{Cmd: macho.LoadCmdSymtab, Len: testSymtabCmdSize, Symoff: uint32(symoff), Nsyms: uint32(len(img.imports)), Stroff: uint32(stroff), Strsize: uint32(len(strtab))})
+	if img.signature != nil {
+		write([]uint32{uint32(LoadCmdCodeSignature), 16, uint32(sigoff), uint32(len(img.signature))})
+	}
+	strx := uint32(1)
+	for _, name := range img.imports {
+		write(macho.Nlist64{Name: strx, Type: 0x01})
+		strx += uint32(len(name)) + 1
+	}
+	buf.Write(strtab)
+	buf.Write(img.signature)
+	return buf.Bytes()
+}
+
+// buildSignature returns an embedded signature superblob holding a single code directory with the given flags and identifier.
+func buildSignature(flags uint32, identifier string) []byte {
+	be := binary.BigEndian
+	const cdOffset = superBlobHeaderSize + blobIndexSize
+	cd := make([]byte, codeDirectoryHeaderSize, codeDirectoryHeaderSize+len(identifier)+1)
+	cd = append(append(cd, identifier...), 0)
+	be.PutUint32(cd, csMagicCodeDirectory)
+	be.PutUint32(cd[4:], uint32(len(cd)))
+	be.PutUint32(cd[8:], 0x20400)
+	be.PutUint32(cd[12:], flags)
+	be.PutUint32(cd[20:], codeDirectoryHeaderSize)
+
+	sig := make([]byte, cdOffset, cdOffset+len(cd))
+	be.PutUint32(sig, csMagicEmbeddedSignature)
+	be.PutUint32(sig[4:], uint32(cdOffset+len(cd)))
+	be.PutUint32(sig[8:], 1)
+	be.PutUint32(sig[12:], csSlotCodeDirectory)
+	be.PutUint32(sig[16:], cdOffset)
+	return append(sig, cd...)
+}
+
+// buildUniversal returns a universal binary of the given slices, each aligned to a page.
+func buildUniversal(t *testing.T, images ...testImage) []byte {
+	t.Helper()
+
+	const align = 0x1000
+	var buf bytes.Buffer
+	be := binary.BigEndian
+	header := make([]byte, 8+20*len(images))
+	be.PutUint32(header, macho.MagicFat)
+	be.PutUint32(header[4:], uint32(len(images)))
+	buf.Write(header)
+
+	for i, img := range images {
+		data := buildImage(t, img)
+		buf.Write(make([]byte, align-buf.Len()%align))
+		arch := header[8+20*i:]
+		be.PutUint32(arch, uint32(img.cpu))
+		be.PutUint32(arch[4:], img.subCPU)
+		be.PutUint32(arch[8:], uint32(buf.Len()))
+		be.PutUint32(arch[12:], uint32(len(data)))
+		be.PutUint32(arch[16:], 12)
+		buf.Write(data)
+	}
+	out := buf.Bytes()
+	copy(out, header)
+	return out
+}
+
+func TestOpen(t *testing.T) {
+	tests := []struct {
+		name          string
+		img           testImage
+		wantSubCPU    uint32
+		wantSegments  []string
+		wantSignature *CodeSignature
+	}{
+		{
+			name: "signed arm64e executable",
+			img: testImage{
+				cpu:       macho.CpuArm64,
+				subCPU:    0x80000000 | CPUSubtypeARM64E,
+				typ:       macho.TypeExec,
+				flags:     macho.FlagPIE,
+				sections:  [][2]string{{"__TEXT", "__text"}, {"__RESTRICT", "__restrict"}},
+				imports:   []string{"___stack_chk_fail", "_printf"},
+				signature: buildSignature(CodeSignatureRuntime, "com.example.tool"),
+			},
+			wantSubCPU:    CPUSubtypeARM64E,
+			wantSegments:  []string{"__TEXT", "__RESTRICT"},
+			wantSignature: &CodeSignature{Flags: CodeSignatureRuntime, Identifier: "com.example.tool"},
+		},
+		{
+			name:       "unsigned x86_64 dylib",
+			img:        testImage{cpu: macho.CpuAmd64, subCPU: 3, typ: macho.TypeDylib, imports: []string{"_malloc"}},
+			wantSubCPU: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Open(bytes.NewReader(buildImage(t, tt.img)))
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			if f.SubCPU() != tt.wantSubCPU {
+				t.Errorf("SubCPU() = %#x, want %#x", f.SubCPU(), tt.wantSubCPU)
+			}
+			if f.Flags() != tt.img.flags {
+				t.Errorf("Flags() = %#x, want %#x", f.Flags(), tt.img.flags)
+			}
+
+			var segments []string
+			for _, seg := range f.Segments() {
+				segments = append(segments, seg.Name)
+			}
+			if !slices.Equal(segments, tt.wantSegments) {
+				t.Errorf("Segments() = %v, want %v", segments, tt.wantSegments)
+			}
+
+			imports, err := f.ImportedSymbols()
+			if err != nil {
+				t.Fatalf("ImportedSymbols() error = %v", err)
+			}
+			if !slices.Equal(imports, tt.img.imports) {
+				t.Errorf("ImportedSymbols() = %v, want %v", imports, tt.img.imports)
+			}
+
+			sig, err := f.CodeSignature()
+			if err != nil {
+				t.Fatalf("CodeSignature() error = %v", err)
+			}
+			if (sig == nil) != (tt.wantSignature == nil) || (sig != nil && *sig != *tt.wantSignature) {
+				t.Errorf("CodeSignature() = %+v, want %+v", sig, tt.wantSignature)
+			}
+		})
+	}
+}
+
+func TestOpenUniversal(t *testing.T) {
+	data := buildUniversal(t,
+		testImage{cpu: macho.CpuAmd64, subCPU: 3, typ: macho.TypeExec, flags: macho.FlagPIE},
+		testImage{cpu: macho.CpuArm64, subCPU: CPUSubtypeARM64E, typ: macho.TypeExec, flags: macho.FlagPIE, signature: buildSignature(CodeSignatureAdhoc, "tool")},
+	)
+
+	if !IsUniversal(bytes.NewReader(data)) {
+		t.Fatal("IsUniversal() = false, want true")
+	}
+	files, err := OpenUniversal(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("OpenUniversal() error = %v", err)
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, ArchName(f))
+	}
+	if want := []string{"x86_64", "arm64e"}; !slices.Equal(names, want) {
+		t.Fatalf("slices = %v, want %v", names, want)
+	}
+
+	// The code signature offset is relative to the slice, not the universal binary.
+	sig, err := files[1].CodeSignature()
+	if err != nil {
+		t.Fatalf("CodeSignature() error = %v", err)
+	}
+	if sig == nil || sig.Identifier != "tool" || sig.Flags != CodeSignatureAdhoc {
+		t.Errorf("CodeSignature() = %+v, want ad-hoc signature of tool", sig)
+	}
+	if sig, err := files[0].CodeSignature(); sig != nil || err != nil {
+		t.Errorf("CodeSignature() = %+v, %v, want none", sig, err)
+	}
+}
+
+func TestOpenUnsupported(t *testing.T) {
+	thin := buildImage(t, testImage{cpu: macho.CpuAmd64, typ: macho.TypeExec})
+	universal := buildUniversal(t, testImage{cpu: macho.CpuAmd64, typ: macho.TypeExec})
+	// A Java class file of major version 52 shares the universal magic.
+	class := []byte{0xca, 0xfe, 0xba, 0xbe, 0x00, 0x00, 0x00, 0x34}
+
+	for name, data := range map[string][]byte{
+		"ELF":        []byte("\x7fELF\x02\x01\x01"),
+		"Java class": class,
+		"universal":  universal,
+		"empty":      nil,
+	} {
+		if _, err := Open(bytes.NewReader(data)); !errors.Is(err, bin.ErrUnsupportedFormat) {
+			t.Errorf("Open(%s) error = %v, want ErrUnsupportedFormat", name, err)
+		}
+	}
+	for name, data := range map[string][]byte{
+		"Java class": class,
+		"thin":       thin,
+		"empty":      nil,
+	} {
+		if _, err := OpenUniversal(bytes.NewReader(data)); !errors.Is(err, bin.ErrUnsupportedFormat) {
+			t.Errorf("OpenUniversal(%s) error = %v, want ErrUnsupportedFormat", name, err)
+		}
+	}
+}
+
+func TestParseCodeSignature(t *testing.T) {
+	valid := buildSignature(CodeSignatureRuntime|CodeSignatureAdhoc, "com.example.tool")
+
+	noDirectory := bytes.Clone(valid[:superBlobHeaderSize])
+	binary.BigEndian.PutUint32(noDirectory[8:], 0)
+
+	badDirectory := bytes.Clone(valid)
+	binary.BigEndian.PutUint32(badDirectory[16:], uint32(len(valid)))
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    *CodeSignature
+		wantErr bool
+	}{
+		{"valid", valid, &CodeSignature{Flags: CodeSignatureRuntime | CodeSignatureAdhoc, Identifier: "com.example.tool"}, false},
+		{"without code directory", noDirectory, &CodeSignature{}, false},
+		{"code directory out of bounds", badDirectory, nil, true},
+		{"truncated index", valid[:superBlobHeaderSize+4], nil, true},
+		{"wrong magic", make([]byte, 16), nil, true},
+		{"empty", nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCodeSignature(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCodeSignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("parseCodeSignature() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Package macho provides access to the headers and hardening metadata of Mach-O binaries, including the slices of universal binaries.
+package macho
+
+import (
+	"debug/macho"
+	"errors"
+)
+
+// ErrSectionMissing is returned when a section or load command cannot be obtained.
+var ErrSectionMissing = errors.New("section missing")
+
+// Values of the Mach-O headers and code signature that debug/macho doesn't define.
+// See mach-o/loader.h, mach/machine.h and the cs_blobs.h header of the XNU kernel.
+const (
+	// LoadCmdCodeSignature is the LC_CODE_SIGNATURE load command, locating the code signature in the __LINKEDIT segment.
+	LoadCmdCodeSignature macho.LoadCmd = 0x1d
+	// CPUSubtypeARM64E is the arm64 subtype of binaries built with pointer authentication.
+	CPUSubtypeARM64E = 2
+	// CPUSubtypeX86_64H is the x86_64 subtype of binaries built for Haswell and newer CPUs.
+	CPUSubtypeX86_64H = 8
+	// CodeSignatureAdhoc marks a signature made without a signing identity, such as the one the linker applies on arm64.
+	CodeSignatureAdhoc = 0x00000002
+	// CodeSignatureRuntime marks a binary signed with the hardened runtime, codesign --options runtime.
+	CodeSignatureRuntime = 0x00010000
+	// CodeSignatureLinkerSigned marks an ad-hoc signature made by the linker rather than codesign.
+	CodeSignatureLinkerSigned = 0x00020000
+)
+
+// cpuSubtypeMask covers the capability bits of a CPU subtype, which carry feature flags rather than the subtype itself.
+const cpuSubtypeMask = 0xff000000
+
+// Binary exposes Mach-O metadata and section contents for analysis.
+// The Binary of a universal binary slice describes that slice alone.
+type Binary interface {
+	// CPU reports the CPU type, such as macho.CpuArm64.
+	CPU() macho.Cpu
+	// SubCPU reports the CPU subtype without its capability bits, such as CPUSubtypeARM64E.
+	SubCPU() uint32
+	// Type reports the file type, such as macho.TypeExec.
+	Type() macho.Type
+	// Flags reports the header flags, such as macho.FlagPIE.
+	Flags() uint32
+
+	// Segments returns the segment headers in load command order.
+	Segments() []macho.SegmentHeader
+	// Sections returns the section table.
+	Sections() []Section
+	// Symbols returns the symbol table.
+	// Returns nil when the binary has none.
+	Symbols() []macho.Symbol
+	// ImportedSymbols returns the undefined symbols the binary expects dynamic libraries to provide.
+	ImportedSymbols() ([]string, error)
+	// CodeSignature returns the code signature.
+	// Returns (nil, nil) when the binary isn't signed.
+	CodeSignature() (*CodeSignature, error)
+}
+
+// Section is a Mach-O section header bundled with a lazy accessor for its content.
+type Section struct {
+	macho.SectionHeader
+	data func() ([]byte, error)
+}
+
+// Data returns the section's raw bytes.
+func (s Section) Data() ([]byte, error) {
+	if s.data == nil {
+		return nil, ErrSectionMissing
+	}
+	return s.data()
+}
+
+// CodeSignature holds the parts of the embedded code signature used to check for hardening.
+type CodeSignature struct {
+	// Flags are the code directory flags, such as CodeSignatureRuntime. Zero when the signature has no code directory.
+	Flags uint32
+	// Identifier is the signing identifier recorded in the code directory, e.g. "com.example.tool".
+	Identifier string
+}
+
+// FindSection returns the named section of the named segment, or ErrSectionMissing if it isn't present.
+func FindSection(b Binary, segment, name string) (Section, error) {
+	for _, sec := range b.Sections() {
+		if sec.Seg == segment && sec.Name == name {
+			return sec, nil
+		}
+	}
+	return Section{}, ErrSectionMissing
+}
@@ -0,0 +1,37 @@
+package pe
+
+import (
+	"debug/pe"
+
+	"go.kacmar.sk/crack/binary"
+)
+
+// DetectArchitecture returns the architecture of the binary.
+func DetectArchitecture(b Binary) binary.Architecture {
+	switch b.Machine() {
+	case pe.IMAGE_FILE_MACHINE_I386:
+		return binary.ArchX86
+	case pe.IMAGE_FILE_MACHINE_AMD64:
+		return binary.ArchAMD64
+	case pe.IMAGE_FILE_MACHINE_ARM, pe.IMAGE_FILE_MACHINE_ARMNT:
+		return binary.ArchARM
+	case pe.IMAGE_FILE_MACHINE_ARM64:
+		return binary.ArchARM64
+	case pe.IMAGE_FILE_MACHINE_RISCV64:
+		return binary.ArchRISCV
+	default:
+		return binary.ArchUnknown
+	}
+}
+
+// DetectKind classifies the image as a DLL or an executable by its COFF characteristics.
+func DetectKind(b Binary) binary.Kind {
+	switch {
+	case b.Characteristics()&pe.IMAGE_FILE_DLL != 0:
+		return binary.KindSharedLibrary
+	case b.Characteristics()&pe.IMAGE_FILE_EXECUTABLE_IMAGE != 0:
+		return binary.KindExecutable
+	default:
+		return binary.KindUnknown
+	}
+}
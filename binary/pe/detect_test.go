@@ -0,0 +1,102 @@
+package pe
+
+import (
+	"debug/pe"
+	"testing"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/toolchain"
+)
+
+// fakeBinary is a minimal Binary used to drive detection.
+type fakeBinary struct {
+	machine         uint16
+	characteristics uint16
+	sections        []Section
+}
+
+func (f *fakeBinary) Machine() uint16                       { return f.machine }
+func (f *fakeBinary) Characteristics() uint16               { return f.characteristics }
+func (f *fakeBinary) DllCharacteristics() uint16            { return 0 }
+func (f *fakeBinary) Is64() bool                            { return true }
+func (f *fakeBinary) Sections() []Section                   { return f.sections }
+func (f *fakeBinary) Symbols() []*pe.Symbol                 { return nil }
+func (f *fakeBinary) ImportedSymbols() ([]string, error)    { return nil, nil }
+func (f *fakeBinary) LoadConfig() (*LoadConfig, error)      { return nil, nil }
+func (f *fakeBinary) ExDllCharacteristics() (uint32, error) { return 0, nil }
+
+func TestDetectKind(t *testing.T) {
+	tests := []struct {
+		name            string
+		characteristics uint16
+		want            binary.Kind
+	}{
+		{"executable", pe.IMAGE_FILE_EXECUTABLE_IMAGE, binary.KindExecutable},
+		{"DLL", pe.IMAGE_FILE_EXECUTABLE_IMAGE | pe.IMAGE_FILE_DLL, binary.KindSharedLibrary},
+		{"not executable", 0, binary.KindUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectKind(&fakeBinary{characteristics: tt.characteristics}); got != tt.want {
+				t.Errorf("DetectKind() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectArchitecture(t *testing.T) {
+	tests := []struct {
+		machine uint16
+		want    binary.Architecture
+	}{
+		{pe.IMAGE_FILE_MACHINE_I386, binary.ArchX86},
+		{pe.IMAGE_FILE_MACHINE_AMD64, binary.ArchAMD64},
+		{pe.IMAGE_FILE_MACHINE_ARMNT, binary.ArchARM},
+		{pe.IMAGE_FILE_MACHINE_ARM64, binary.ArchARM64},
+		{pe.IMAGE_FILE_MACHINE_IA64, binary.ArchUnknown},
+	}
+	for _, tt := range tests {
+		if got := DetectArchitecture(&fakeBinary{machine: tt.machine}); got != tt.want {
+			t.Errorf("DetectArchitecture(%#x) = %v, want %v", tt.machine, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultToolchainDetector(t *testing.T) {
+	rdata := func(data string) []Section {
+		return []Section{{
+			SectionHeader: pe.SectionHeader{Name: ".rdata"},
+			data:          func() ([]byte, error) { return []byte(data), nil },
+		}}
+	}
+	tests := []struct {
+		name     string
+		sections []Section
+		want     toolchain.Toolchain
+	}{
+		{
+			name:     "MinGW GCC",
+			sections: rdata("\x00\x01Hello\x00GCC: (x86_64-posix-seh-rev0, Built by MinGW-Builds project) 13.2.0\x00GCC: (GNU) 13.2.0\x00"),
+			want:     toolchain.Toolchain{Compiler: toolchain.GCC, Version: toolchain.Version{Major: 13, Minor: 2}},
+		},
+		{
+			name:     "unterminated identification",
+			sections: rdata("GCC: (GNU) 12.1.0"),
+			want:     toolchain.Toolchain{Compiler: toolchain.GCC, Version: toolchain.Version{Major: 12, Minor: 1}},
+		},
+		{
+			name:     "no identification",
+			sections: rdata("Hello, world\x00"),
+		},
+		{
+			name: "no .rdata",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (DefaultToolchainDetector{}).Detect(&fakeBinary{sections: tt.sections}); got != tt.want {
+				t.Errorf("Detect() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
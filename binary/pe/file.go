@@ -0,0 +1,290 @@
+package pe
+
+import (
+	"bytes"
+	"debug/pe"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+
+	bin "go.kacmar.sk/crack/binary"
+)
+
+// File is the canonical Binary implementation. It wraps stdlib's *pe.File.
+type File struct {
+	file   *pe.File
+	memory *bin.MemoryAccount
+
+	sections []Section
+
+	// Memoized parsed views. Each closure runs at most once thanks to sync.OnceValues.
+	imports              func() ([]string, error)
+	loadConfig           func() (*LoadConfig, error)
+	exDllCharacteristics func() (uint32, error)
+}
+
+// Option configures a File at construction time.
+type Option func(*openConfig)
+
+type openConfig struct {
+	memory *bin.MemoryAccount
+}
+
+// WithMemoryAccount accounts the section and directory data read from the binary against the memory budget of account.
+// Reads that would exceed it fail with bin.ErrLimitExceeded. The caller closes the account once it is done with the returned binary.
+func WithMemoryAccount(account *bin.MemoryAccount) Option {
+	return func(c *openConfig) { c.memory = account }
+}
+
+var (
+	dosMagic = []byte("MZ")
+	peMagic  = []byte("PE\x00\x00")
+)
+
+// dosHeaderSize is the size of the MS-DOS stub header, which ends with the offset of the PE signature.
+const dosHeaderSize = 0x40
+
+// Open parses the headers and section table of the PE image read from r.
+// Section contents are read lazily on demand. COFF object files, which lack the MS-DOS stub, aren't supported.
+// The caller owns r and must keep it open while the returned binary is in use.
+func Open(r io.ReaderAt, opts ...Option) (*File, error) {
+	var cfg openConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if !isImage(r) {
+		return nil, bin.ErrUnsupportedFormat
+	}
+	f, err := pe.NewFile(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PE file: %w", err)
+	}
+	if f.OptionalHeader == nil {
+		return nil, fmt.Errorf("failed to open PE file: no optional header")
+	}
+
+	b := &File{file: f, memory: cfg.memory}
+
+	b.sections = make([]Section, len(f.Sections))
+	for i, s := range f.Sections {
+		sec := s
+		b.sections[i] = Section{
+			SectionHeader: sec.SectionHeader,
+			data: func() ([]byte, error) {
+				if err := b.memory.Reserve(uint64(sec.Size)); err != nil {
+					return nil, fmt.Errorf("failed to read section %s: %w", sec.Name, err)
+				}
+				return readFull(io.NewSectionReader(sec, 0, int64(sec.Size)), sec.Size)
+			},
+		}
+	}
+
+	b.imports = sync.OnceValues(b.loadImports)
+	b.loadConfig = sync.OnceValues(b.loadLoadConfig)
+	b.exDllCharacteristics = sync.OnceValues(b.loadExDllCharacteristics)
+
+	return b, nil
+}
+
+// isImage reports whether r starts with an MS-DOS stub pointing at a PE signature.
+// Files failing this are other formats, such as plain MS-DOS executables, rather than malformed images.
+func isImage(r io.ReaderAt) bool {
+	var dos [dosHeaderSize]byte
+	if _, err := r.ReadAt(dos[:], 0); err != nil || !bytes.HasPrefix(dos[:], dosMagic) {
+		return false
+	}
+	var sig [4]byte
+	offset := int64(binary.LittleEndian.Uint32(dos[dosHeaderSize-4:]))
+	if _, err := r.ReadAt(sig[:], offset); err != nil {
+		return false
+	}
+	return bytes.Equal(sig[:], peMagic)
+}
+
+func (b *File) Machine() uint16         { return b.file.Machine }
+func (b *File) Characteristics() uint16 { return b.file.Characteristics }
+func (b *File) Sections() []Section     { return b.sections }
+func (b *File) Symbols() []*pe.Symbol   { return b.file.Symbols }
+
+func (b *File) Is64() bool {
+	_, ok := b.file.OptionalHeader.(*pe.OptionalHeader64)
+	return ok
+}
+
+func (b *File) DllCharacteristics() uint16 {
+	switch oh := b.file.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		return oh.DllCharacteristics
+	case *pe.OptionalHeader64:
+		return oh.DllCharacteristics
+	default:
+		return 0
+	}
+}
+
+func (b *File) ImportedSymbols() ([]string, error)    { return b.imports() }
+func (b *File) LoadConfig() (*LoadConfig, error)      { return b.loadConfig() }
+func (b *File) ExDllCharacteristics() (uint32, error) { return b.exDllCharacteristics() }
+
+// dataDirectory returns the data directory entry at index, or a zero entry when the optional header has fewer.
+func (b *File) dataDirectory(index int) pe.DataDirectory {
+	var dirs []pe.DataDirectory
+	switch oh := b.file.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		dirs = oh.DataDirectory[:min(int(oh.NumberOfRvaAndSizes), len(oh.DataDirectory))]
+	case *pe.OptionalHeader64:
+		dirs = oh.DataDirectory[:min(int(oh.NumberOfRvaAndSizes), len(oh.DataDirectory))]
+	}
+	if index >= len(dirs) {
+		return pe.DataDirectory{}
+	}
+	return dirs[index]
+}
+
+func (b *File) loadImports() ([]string, error) {
+	dir := b.dataDirectory(pe.IMAGE_DIRECTORY_ENTRY_IMPORT)
+	if dir.VirtualAddress == 0 {
+		return nil, nil
+	}
+	// stdlib reads the whole section holding the import directory.
+	if sec := b.sectionAt(dir.VirtualAddress); sec != nil {
+		if err := b.memory.Reserve(uint64(sec.Size)); err != nil {
+			return nil, fmt.Errorf("failed to read imports: %w", err)
+		}
+	}
+	syms, err := b.file.ImportedSymbols()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read imports: %w", err)
+	}
+	return syms, nil
+}
+
+// Offsets of the LoadConfig fields within IMAGE_LOAD_CONFIG_DIRECTORY32 and IMAGE_LOAD_CONFIG_DIRECTORY64.
+type loadConfigLayout struct {
+	securityCookie, seHandlerTable, seHandlerCount           int
+	guardCFCheck, guardCFFunctionTable, guardCFFunctionCount int
+	guardFlags                                               int
+	pointerSize                                              int
+}
+
+var (
+	loadConfigLayout32 = loadConfigLayout{0x3c, 0x40, 0x44, 0x48, 0x50, 0x54, 0x58, 4}
+	loadConfigLayout64 = loadConfigLayout{0x58, 0x60, 0x68, 0x70, 0x80, 0x88, 0x90, 8}
+)
+
+func (b *File) loadLoadConfig() (*LoadConfig, error) {
+	dir := b.dataDirectory(pe.IMAGE_DIRECTORY_ENTRY_LOAD_CONFIG)
+	if dir.VirtualAddress == 0 || dir.Size == 0 {
+		return nil, nil
+	}
+	head, err := b.readRVA(dir.VirtualAddress, 4)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read load configuration: %w", err)
+	}
+	layout := loadConfigLayout32
+	if b.Is64() {
+		layout = loadConfigLayout64
+	}
+	size := min(binary.LittleEndian.Uint32(head), uint32(layout.guardFlags+4)) // #nosec G115 -- small constant
+	data, err := b.readRVA(dir.VirtualAddress, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read load configuration: %w", err)
+	}
+	return parseLoadConfig(data, b.Is64()), nil
+}
+
+// parseLoadConfig decodes a load configuration directory, leaving the fields that data is too short to hold zero.
+func parseLoadConfig(data []byte, is64 bool) *LoadConfig {
+	layout := loadConfigLayout32
+	if is64 {
+		layout = loadConfigLayout64
+	}
+	pointer := func(off int) uint64 {
+		if off+layout.pointerSize > len(data) {
+			return 0
+		}
+		if layout.pointerSize == 8 {
+			return binary.LittleEndian.Uint64(data[off:])
+		}
+		return uint64(binary.LittleEndian.Uint32(data[off:]))
+	}
+
+	lc := &LoadConfig{
+		SecurityCookie:              pointer(layout.securityCookie),
+		SEHandlerTable:              pointer(layout.seHandlerTable),
+		SEHandlerCount:              pointer(layout.seHandlerCount),
+		GuardCFCheckFunctionPointer: pointer(layout.guardCFCheck),
+		GuardCFFunctionTable:        pointer(layout.guardCFFunctionTable),
+		GuardCFFunctionCount:        pointer(layout.guardCFFunctionCount),
+	}
+	if len(data) >= 4 {
+		lc.Size = binary.LittleEndian.Uint32(data)
+	}
+	if layout.guardFlags+4 <= len(data) {
+		lc.GuardFlags = binary.LittleEndian.Uint32(data[layout.guardFlags:])
+	}
+	return lc
+}
+
+// debugDirectoryEntrySize is the size of IMAGE_DEBUG_DIRECTORY.
+const debugDirectoryEntrySize = 28
+
+func (b *File) loadExDllCharacteristics() (uint32, error) {
+	dir := b.dataDirectory(pe.IMAGE_DIRECTORY_ENTRY_DEBUG)
+	if dir.VirtualAddress == 0 || dir.Size < debugDirectoryEntrySize {
+		return 0, nil
+	}
+	data, err := b.readRVA(dir.VirtualAddress, dir.Size)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read debug directory: %w", err)
+	}
+	for off := 0; off+debugDirectoryEntrySize <= len(data); off += debugDirectoryEntrySize {
+		entry := data[off:]
+		if binary.LittleEndian.Uint32(entry[12:]) != DebugTypeExDllCharacteristics || binary.LittleEndian.Uint32(entry[16:]) < 4 {
+			continue
+		}
+		value, err := b.readRVA(binary.LittleEndian.Uint32(entry[20:]), 4)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read extended DLL characteristics: %w", err)
+		}
+		return binary.LittleEndian.Uint32(value), nil
+	}
+	return 0, nil
+}
+
+// sectionAt returns the section whose image holds rva, or nil when none does.
+func (b *File) sectionAt(rva uint32) *pe.Section {
+	for _, sec := range b.file.Sections {
+		if rva >= sec.VirtualAddress && rva-sec.VirtualAddress < max(sec.VirtualSize, sec.Size) {
+			return sec
+		}
+	}
+	return nil
+}
+
+// readRVA reads size bytes of the image at the relative virtual address rva from the section file data holding it.
+func (b *File) readRVA(rva, size uint32) ([]byte, error) {
+	sec := b.sectionAt(rva)
+	if sec == nil {
+		return nil, fmt.Errorf("%w: no section holds RVA %#x", ErrSectionMissing, rva)
+	}
+	if err := b.memory.Reserve(uint64(size)); err != nil {
+		return nil, err
+	}
+	return readFull(io.NewSectionReader(sec, int64(rva-sec.VirtualAddress), int64(size)), size)
+}
+
+// readFull reads exactly size bytes from r. Reading through a reader rather than into a buffer of size bytes keeps a corrupt
+// size from allocating more than the file holds.
+func readFull(r io.Reader, size uint32) ([]byte, error) {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if uint32(len(buf)) != size { // #nosec G115 -- len(buf) is at most size
+		return nil, io.ErrUnexpectedEOF
+	}
+	return buf, nil
+}
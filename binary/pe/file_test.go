@@ -0,0 +1,193 @@
+package pe
+
+import (
+	"bytes"
+	"debug/pe"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	bin "go.kacmar.sk/crack/binary"
+)
+
+// testImage describes a minimal PE image with a single .rdata section.
+type testImage struct {
+	is64               bool
+	characteristics    uint16
+	dllCharacteristics uint16
+	// loadConfig is placed at the start of .rdata and referenced by the load configuration directory when non-nil.
+	loadConfig []byte
+	// exDllCharacteristics is referenced by a debug directory entry when non-zero.
+	exDllCharacteristics uint32
+}
+
+const (
+	testSectionRVA    = 0x1000
+	testSectionOffset = 0x200
+	testDebugOffset   = 0x100
+	testExDllOffset   = 0x140
+	testSectionSize   = 0x180
+)
+
+func buildImage(t *testing.T, img testImage) []byte {
+	t.Helper()
+
+	section := make([]byte, testSectionSize)
+	var dirs [16]pe.DataDirectory
+	if img.loadConfig != nil {
+		copy(section, img.loadConfig)
+		dirs[pe.IMAGE_DIRECTORY_ENTRY_LOAD_CONFIG] = pe.DataDirectory{VirtualAddress: testSectionRVA, Size: uint32(len(img.loadConfig))}
+	}
+	if img.exDllCharacteristics != 0 {
+		entry := section[testDebugOffset:]
+		binary.LittleEndian.PutUint32(entry[12:], DebugTypeExDllCharacteristics)
+		binary.LittleEndian.PutUint32(entry[16:], 4)
+		binary.LittleEndian.PutUint32(entry[20:], testSectionRVA+testExDllOffset)
+		binary.LittleEndian.PutUint32(section[testExDllOffset:], img.exDllCharacteristics)
+		dirs[pe.IMAGE_DIRECTORY_ENTRY_DEBUG] = pe.DataDirectory{VirtualAddress: testSectionRVA + testDebugOffset, Size: debugDirectoryEntrySize}
+	}
+
+	var optional any
+	machine := uint16(pe.IMAGE_FILE_MACHINE_I386)
+	if img.is64 {
+		machine = pe.IMAGE_FILE_MACHINE_AMD64
+		optional = &pe.OptionalHeader64{Magic: 0x20b, NumberOfRvaAndSizes: 16, DllCharacteristics: img.dllCharacteristics, DataDirectory: dirs}
+	} else {
+		optional = &pe.OptionalHeader32{Magic: 0x10b, NumberOfRvaAndSizes: 16, DllCharacteristics: img.dllCharacteristics, DataDirectory: dirs}
+	}
+
+	var buf bytes.Buffer
+	dos := make([]byte, dosHeaderSize)
+	copy(dos, dosMagic)
+	binary.LittleEndian.PutUint32(dos[dosHeaderSize-4:], dosHeaderSize)
+	buf.Write(dos)
+	buf.Write(peMagic)
+	header := pe.FileHeader{
+		Machine:              machine,
+		NumberOfSections:     1,
+		SizeOfOptionalHeader: uint16(binary.Size(optional)),
+		Characteristics:      img.characteristics,
+	}
+	sectionHeader := pe.SectionHeader32{
+		VirtualSize:      uint32(len(section)),
+		VirtualAddress:   testSectionRVA,
+		SizeOfRawData:    uint32(len(section)),
+		PointerToRawData: testSectionOffset,
+	}
+	copy(sectionHeader.Name[:], ".rdata")
+	for _, v := range []any{header, optional, sectionHeader} {
+		if err := binary.Write(&buf, binary.LittleEndian, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf.Write(make([]byte, testSectionOffset-buf.Len()))
+	buf.Write(section)
+	return buf.Bytes()
+}
+
+func TestOpen(t *testing.T) {
+	loadConfig32 := make([]byte, 0x5c)
+	binary.LittleEndian.PutUint32(loadConfig32, 0x5c)
+	binary.LittleEndian.PutUint32(loadConfig32[0x3c:], 0x403000)
+	binary.LittleEndian.PutUint32(loadConfig32[0x40:], 0x402000)
+	binary.LittleEndian.PutUint32(loadConfig32[0x44:], 3)
+	binary.LittleEndian.PutUint32(loadConfig32[0x58:], GuardCFInstrumented)
+
+	loadConfig64 := make([]byte, 0x94)
+	binary.LittleEndian.PutUint32(loadConfig64, 0x94)
+	binary.LittleEndian.PutUint64(loadConfig64[0x58:], 0x140003000)
+	binary.LittleEndian.PutUint64(loadConfig64[0x70:], 0x140002000)
+
+	tests := []struct {
+		name        string
+		img         testImage
+		wantConfig  *LoadConfig
+		wantExFlags uint32
+	}{
+		{
+			name: "PE32 with SafeSEH and CFG",
+			img:  testImage{loadConfig: loadConfig32},
+			wantConfig: &LoadConfig{
+				Size:           0x5c,
+				SecurityCookie: 0x403000,
+				SEHandlerTable: 0x402000,
+				SEHandlerCount: 3,
+				GuardFlags:     GuardCFInstrumented,
+			},
+		},
+		{
+			name: "PE32+ with CET",
+			img:  testImage{is64: true, loadConfig: loadConfig64, exDllCharacteristics: DllCharacteristicsExCETCompat},
+			wantConfig: &LoadConfig{
+				Size:                        0x94,
+				SecurityCookie:              0x140003000,
+				GuardCFCheckFunctionPointer: 0x140002000,
+			},
+			wantExFlags: DllCharacteristicsExCETCompat,
+		},
+		{
+			name: "no directories",
+			img:  testImage{is64: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Open(bytes.NewReader(buildImage(t, tt.img)))
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			if f.Is64() != tt.img.is64 {
+				t.Errorf("Is64() = %v, want %v", f.Is64(), tt.img.is64)
+			}
+
+			lc, err := f.LoadConfig()
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			if (lc == nil) != (tt.wantConfig == nil) || (lc != nil && *lc != *tt.wantConfig) {
+				t.Errorf("LoadConfig() = %+v, want %+v", lc, tt.wantConfig)
+			}
+
+			flags, err := f.ExDllCharacteristics()
+			if err != nil {
+				t.Fatalf("ExDllCharacteristics() error = %v", err)
+			}
+			if flags != tt.wantExFlags {
+				t.Errorf("ExDllCharacteristics() = %#x, want %#x", flags, tt.wantExFlags)
+			}
+		})
+	}
+}
+
+func TestOpenUnsupported(t *testing.T) {
+	dosOnly := make([]byte, 0x80)
+	copy(dosOnly, dosMagic)
+	binary.LittleEndian.PutUint32(dosOnly[dosHeaderSize-4:], dosHeaderSize)
+
+	for name, data := range map[string][]byte{
+		"ELF":    []byte("\x7fELF\x02\x01\x01"),
+		"MS-DOS": dosOnly,
+		"empty":  nil,
+	} {
+		if _, err := Open(bytes.NewReader(data)); !errors.Is(err, bin.ErrUnsupportedFormat) {
+			t.Errorf("Open(%s) error = %v, want ErrUnsupportedFormat", name, err)
+		}
+	}
+}
+
+func TestParseLoadConfig(t *testing.T) {
+	data := make([]byte, 0x94)
+	binary.LittleEndian.PutUint32(data, 0x94)
+	binary.LittleEndian.PutUint64(data[0x58:], 0x140003000)
+	binary.LittleEndian.PutUint32(data[0x90:], GuardCFInstrumented)
+
+	if lc := parseLoadConfig(data, true); lc.SecurityCookie != 0x140003000 || lc.GuardFlags != GuardCFInstrumented {
+		t.Errorf("parseLoadConfig() = %+v", lc)
+	}
+	if lc := parseLoadConfig(data[:0x60], true); lc.SecurityCookie != 0x140003000 || lc.GuardFlags != 0 {
+		t.Errorf("parseLoadConfig(truncated) = %+v, want cookie without guard flags", lc)
+	}
+	if lc := parseLoadConfig(nil, false); *lc != (LoadConfig{}) {
+		t.Errorf("parseLoadConfig(nil) = %+v, want zero", lc)
+	}
+}
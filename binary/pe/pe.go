@@ -0,0 +1,88 @@
+// Package pe provides access to the headers and hardening metadata of Windows PE images.
+package pe
+
+import (
+	"debug/pe"
+	"errors"
+)
+
+// ErrSectionMissing is returned when a section or directory cannot be obtained.
+var ErrSectionMissing = errors.New("section missing")
+
+// Flags of the load configuration and debug directories that debug/pe doesn't define.
+// See https://learn.microsoft.com/en-us/windows/win32/debug/pe-format.
+const (
+	// GuardCFInstrumented is set in LoadConfig.GuardFlags when the image was compiled with Control Flow Guard checks.
+	GuardCFInstrumented = 0x00000100
+	// DebugTypeExDllCharacteristics is the debug directory entry type holding the extended DLL characteristics.
+	DebugTypeExDllCharacteristics = 20
+	// DllCharacteristicsExCETCompat marks an image as compatible with CET shadow stacks.
+	DllCharacteristicsExCETCompat = 0x0001
+)
+
+// Binary exposes PE metadata and section contents for analysis.
+type Binary interface {
+	// Machine reports the target machine, one of the pe.IMAGE_FILE_MACHINE_* values.
+	Machine() uint16
+	// Characteristics reports the COFF header flags, such as pe.IMAGE_FILE_DLL.
+	Characteristics() uint16
+	// DllCharacteristics reports the optional header flags, such as pe.IMAGE_DLLCHARACTERISTICS_NX_COMPAT.
+	DllCharacteristics() uint16
+	// Is64 reports whether the image is in PE32+ format.
+	Is64() bool
+
+	// Sections returns the section table.
+	Sections() []Section
+	// Symbols returns the COFF symbol table, which MinGW toolchains leave in unstripped images.
+	// Returns nil when the image has none.
+	Symbols() []*pe.Symbol
+	// ImportedSymbols returns the functions imported from DLLs, each as "name:dll".
+	ImportedSymbols() ([]string, error)
+	// LoadConfig returns the load configuration directory.
+	// Returns (nil, nil) when the image has none.
+	LoadConfig() (*LoadConfig, error)
+	// ExDllCharacteristics returns the extended DLL characteristics from the debug directory.
+	// Returns (0, nil) when the image records none.
+	ExDllCharacteristics() (uint32, error)
+}
+
+// Section is a PE section header bundled with a lazy accessor for its content.
+type Section struct {
+	pe.SectionHeader
+	data func() ([]byte, error)
+}
+
+// Data returns the section's raw bytes.
+func (s Section) Data() ([]byte, error) {
+	if s.data == nil {
+		return nil, ErrSectionMissing
+	}
+	return s.data()
+}
+
+// LoadConfig holds the fields of the load configuration directory used to check for hardening.
+// Fields beyond the directory's Size, which older linkers write shorter, are zero.
+type LoadConfig struct {
+	Size uint32
+	// SecurityCookie is the address of the /GS stack cookie, __security_cookie.
+	SecurityCookie uint64
+	// SEHandlerTable and SEHandlerCount describe the table of safe exception handlers of an x86 image linked with /SAFESEH.
+	SEHandlerTable uint64
+	SEHandlerCount uint64
+	// GuardCFCheckFunctionPointer is the address of the Control Flow Guard check function pointer.
+	GuardCFCheckFunctionPointer uint64
+	GuardCFFunctionTable        uint64
+	GuardCFFunctionCount        uint64
+	// GuardFlags holds the Control Flow Guard flags, such as GuardCFInstrumented.
+	GuardFlags uint32
+}
+
+// FindSection returns the named section from the binary, or ErrSectionMissing if it isn't present.
+func FindSection(b Binary, name string) (Section, error) {
+	for _, sec := range b.Sections() {
+		if sec.Name == name {
+			return sec, nil
+		}
+	}
+	return Section{}, ErrSectionMissing
+}
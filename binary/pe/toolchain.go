@@ -0,0 +1,69 @@
+package pe
+
+import (
+	"bytes"
+
+	"go.kacmar.sk/crack/toolchain"
+)
+
+var defaultStringDetector toolchain.StringDetector = toolchain.DefaultStringDetector{}
+
+// ToolchainDetector identifies the compiler and version that produced a PE image.
+type ToolchainDetector interface {
+	Detect(b Binary) toolchain.Toolchain
+}
+
+// DefaultToolchainDetector recognizes images built by MinGW toolchains from the identification strings GCC embeds.
+// Images linked by link.exe or lld-link, including those built with clang-cl, record no compiler version and are reported
+// with an unknown toolchain.
+type DefaultToolchainDetector struct {
+	// StringDetector overrides the free-form string classifier.
+	// A nil value uses toolchain.DefaultStringDetector.
+	StringDetector toolchain.StringDetector
+}
+
+// identPrefixes start the compiler identification strings that MinGW toolchains place in .rdata in lieu of an ELF .comment section.
+var identPrefixes = [][]byte{[]byte("GCC: ("), []byte("clang version ")}
+
+func (d DefaultToolchainDetector) Detect(b Binary) toolchain.Toolchain {
+	sd := d.StringDetector
+	if sd == nil {
+		sd = defaultStringDetector
+	}
+	for _, ident := range extractIdents(b) {
+		if comp, ver := sd.Detect(ident); comp != toolchain.Unknown {
+			return toolchain.Toolchain{Compiler: comp, Version: ver}
+		}
+	}
+	return toolchain.Toolchain{}
+}
+
+// extractIdents returns the NUL-terminated compiler identification strings found in .rdata.
+func extractIdents(b Binary) []string {
+	sec, err := FindSection(b, ".rdata")
+	if err != nil {
+		return nil
+	}
+	data, err := sec.Data()
+	if err != nil {
+		return nil
+	}
+
+	var idents []string
+	for _, prefix := range identPrefixes {
+		for rest := data; ; {
+			idx := bytes.Index(rest, prefix)
+			if idx == -1 {
+				break
+			}
+			rest = rest[idx:]
+			end := bytes.IndexByte(rest, 0)
+			if end == -1 {
+				end = len(rest)
+			}
+			idents = append(idents, string(rest[:end]))
+			rest = rest[end:]
+		}
+	}
+	return idents
+}
@@ -2,6 +2,10 @@ module go.kacmar.sk/crack
 
 go 1.25.9
 
-require golang.org/x/sync v0.20.0
-
-require go.kacmar.sk/debuginfod v0.4.1
+require (
+	github.com/klauspost/compress v1.18.0
+	github.com/pierrec/lz4/v4 v4.1.22
+	github.com/ulikunitz/xz v0.5.12
+	go.kacmar.sk/debuginfod v0.4.1
+	golang.org/x/sync v0.20.0
+)
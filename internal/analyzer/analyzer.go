@@ -2,18 +2,37 @@ package analyzer
 
 import (
 	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/internal/archive"
+	"go.kacmar.sk/crack/internal/proc"
 	"go.kacmar.sk/crack/rule"
 )
 
 // FileResult contains analysis results for a single file (or arch slice for fat binaries).
 type FileResult struct {
-	Path     string
+	Path string
+	// Aliases lists other paths of the scan with the same content, which were analyzed once and reported under Path.
+	Aliases []string
+	// Package identifies the package the file was extracted from, or nil for files read directly from disk.
+	Package *archive.Package
+	// Layer is the digest of the container image layer that introduced the file, or "" when it wasn't read from an image.
+	Layer string
+	// ABI is the native ABI named by the container the file was extracted from, such as an APK's lib/<abi>/ directory, or "".
+	ABI string
+	// Processes lists the running processes the file was read from, or nil when it was read from disk.
+	Processes []proc.Process
+	// DiskState tells whether the file on disk still matches what the processes mapped. Only set alongside Processes.
+	DiskState proc.DiskState
+	// Slice names the architecture of the universal binary slice the result describes, or is "" for other files.
+	Slice    string
 	Format   binary.Format
 	Identity binary.Identity
 	Profile  binary.Profile
 	Findings []rule.Finding
 	Error    error
 	Skipped  bool
+	// LateAliases marks a follow-up record to the result already streamed for Path and Slice, which only lists in Aliases
+	// the paths with the same content found after it was.
+	LateAliases bool
 }
 
 func (r *FileResult) PassedRules() int {
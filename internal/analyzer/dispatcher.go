@@ -1,53 +1,47 @@
 package analyzer
 
 import (
-	"context"
-	"errors"
-	"io"
-	"log/slog"
+	"go.kacmar.sk/crack/analyzer"
+)
 
-	"go.kacmar.sk/crack/binary"
+// The dispatcher, the interface of format analyzers and the bundled analyzers are public, so that other programs can
+// register analyzers of their own alongside the bundled ones. They are aliased here for the scanner and the CLI.
+type (
+	FormatAnalyzer       = analyzer.FormatAnalyzer
+	AnalysisResult       = analyzer.AnalysisResult
+	Dispatcher           = analyzer.Dispatcher
+	DispatcherOptions    = analyzer.DispatcherOptions
+	SectionSource        = analyzer.SectionSource
+	ELFAnalyzer          = analyzer.ELFAnalyzer
+	ELFAnalyzerOptions   = analyzer.ELFAnalyzerOptions
+	PEAnalyzer           = analyzer.PEAnalyzer
+	PEAnalyzerOptions    = analyzer.PEAnalyzerOptions
+	MachOAnalyzer        = analyzer.MachOAnalyzer
+	MachOAnalyzerOptions = analyzer.MachOAnalyzerOptions
 )
 
-// Dispatcher routes binary analysis to format-specific analyzers.
-type Dispatcher struct {
-	elf    *ELFAnalyzer
-	logger *slog.Logger
-}
+// MagicSize is the number of leading bytes of a file passed to FormatAnalyzer.Recognizes.
+const MagicSize = analyzer.MagicSize
 
-// DispatcherOptions configures Dispatcher creation.
-type DispatcherOptions struct {
-	ELF    *ELFAnalyzer
-	Logger *slog.Logger
-}
+// ErrUnrecognizedFormat is returned by Dispatcher when no parser recognizes the binary format.
+var ErrUnrecognizedFormat = analyzer.ErrUnrecognizedFormat
 
 // NewDispatcher creates a dispatcher with the given analyzers.
 func NewDispatcher(opts DispatcherOptions) *Dispatcher {
-	return &Dispatcher{
-		elf:    opts.ELF,
-		logger: opts.Logger.With(slog.String("component", "dispatcher")),
-	}
+	return analyzer.NewDispatcher(opts)
+}
+
+// NewELFAnalyzer creates an ELF analyzer with the given options.
+func NewELFAnalyzer(opts ELFAnalyzerOptions) *ELFAnalyzer {
+	return analyzer.NewELFAnalyzer(opts)
+}
+
+// NewPEAnalyzer creates a PE analyzer with the given options.
+func NewPEAnalyzer(opts PEAnalyzerOptions) *PEAnalyzer {
+	return analyzer.NewPEAnalyzer(opts)
 }
 
-// Analyze parses the binary and returns analysis results.
-// Returns slice to handle fat/universal binaries (one result per arch slice).
-// Returns ErrUnsupportedFormat if no parser matches, other errors for parse failures.
-func (d *Dispatcher) Analyze(ctx context.Context, r io.ReaderAt) ([]AnalysisResult, error) {
-	profile, buildID, findings, err := d.elf.Analyze(ctx, r)
-	if err == nil {
-		d.logger.Debug("parsed ELF binary",
-			slog.String("format", binary.FormatELF.String()),
-			slog.String("arch", profile.Architecture.String()))
-
-		return []AnalysisResult{{
-			Format:   binary.FormatELF,
-			Identity: binary.Identity{BuildID: buildID},
-			Profile:  profile,
-			Findings: findings,
-		}}, nil
-	} else if !errors.Is(err, binary.ErrUnsupportedFormat) {
-		return nil, err
-	}
-
-	return nil, ErrUnrecognizedFormat
+// NewMachOAnalyzer creates a Mach-O analyzer with the given options.
+func NewMachOAnalyzer(opts MachOAnalyzerOptions) *MachOAnalyzer {
+	return analyzer.NewMachOAnalyzer(opts)
 }
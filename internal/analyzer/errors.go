@@ -1,6 +1,9 @@
 package analyzer
 
-import "errors"
+import (
+	"go.kacmar.sk/crack/binary"
+)
 
-// ErrUnrecognizedFormat is returned by Dispatcher when no parser recognizes the binary format.
-var ErrUnrecognizedFormat = errors.New("unrecognized binary format")
+// ErrLimitExceeded is wrapped by FileResult.Error for files left unanalyzed because they exceeded a resource limit:
+// the maximum file size, the per-file timeout, or the memory budget.
+var ErrLimitExceeded = binary.ErrLimitExceeded
@@ -0,0 +1,159 @@
+package archive
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// arMagic is the global header of a Unix ar archive.
+// See https://man.freebsd.org/cgi/man.cgi?query=ar&sektion=5 for the format, including the GNU and BSD long-name extensions.
+const arMagic = "!<arch>\n"
+
+// arHeaderSize is the size of the fixed-width header preceding each ar member.
+const arHeaderSize = 60
+
+// ErrMalformedAr is returned when an ar archive header can't be decoded.
+var ErrMalformedAr = errors.New("malformed ar archive")
+
+// arReader iterates the members of an ar archive sequentially, in the style of archive/tar.Reader.
+type arReader struct {
+	r         io.Reader
+	longNames []byte
+	// remaining is the number of unread content bytes of the current member, including the padding byte.
+	remaining int64
+	cur       io.Reader
+}
+
+// newArReader validates the global header and returns a reader positioned before the first member.
+func newArReader(r io.Reader) (*arReader, error) {
+	magic := make([]byte, len(arMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedAr, err)
+	}
+	if string(magic) != arMagic {
+		return nil, fmt.Errorf("%w: bad magic", ErrMalformedAr)
+	}
+	return &arReader{r: r}, nil
+}
+
+// Next advances to the next regular member and returns its name and size.
+// Symbol and long-name tables are consumed internally and never returned.
+// Returns io.EOF when the archive has no more members.
+func (a *arReader) Next() (string, int64, error) {
+	for {
+		if _, err := io.CopyN(io.Discard, a.r, a.remaining); err != nil {
+			return "", 0, fmt.Errorf("%w: %w", ErrMalformedAr, err)
+		}
+		a.remaining = 0
+
+		var hdr [arHeaderSize]byte
+		if _, err := io.ReadFull(a.r, hdr[:]); err != nil {
+			if err == io.EOF {
+				return "", 0, io.EOF
+			}
+			return "", 0, fmt.Errorf("%w: %w", ErrMalformedAr, err)
+		}
+		if string(hdr[58:60]) != "`\n" {
+			return "", 0, fmt.Errorf("%w: bad member header terminator", ErrMalformedAr)
+		}
+
+		size, err := strconv.ParseInt(strings.TrimSpace(string(hdr[48:58])), 10, 64)
+		if err != nil || size < 0 {
+			return "", 0, fmt.Errorf("%w: bad member size", ErrMalformedAr)
+		}
+		a.remaining = size + size%2
+		a.cur = io.LimitReader(a.r, size)
+
+		name := strings.TrimRight(string(hdr[0:16]), " ")
+		switch {
+		case name == "/" || name == "/SYM64/" || name == "__.SYMDEF" || name == "__.SYMDEF SORTED":
+			continue
+		case name == "//":
+			a.longNames, err = a.readAll(size)
+			if err != nil {
+				return "", 0, err
+			}
+			continue
+		case strings.HasPrefix(name, "#1/"):
+			// BSD stores long names immediately after the header and counts them in the member size.
+			n, err := strconv.ParseInt(name[3:], 10, 64)
+			if err != nil || n < 0 || n > size {
+				return "", 0, fmt.Errorf("%w: bad BSD name length", ErrMalformedAr)
+			}
+			raw, err := a.readAll(n)
+			if err != nil {
+				return "", 0, err
+			}
+			name = string(bytes.TrimRight(raw, "\x00"))
+			size -= n
+			a.cur = io.LimitReader(a.r, size)
+			if name == "__.SYMDEF" || name == "__.SYMDEF SORTED" {
+				continue
+			}
+		case strings.HasPrefix(name, "/"):
+			off, err := strconv.Atoi(name[1:])
+			if err != nil || off < 0 || off >= len(a.longNames) {
+				return "", 0, fmt.Errorf("%w: bad long name reference %q", ErrMalformedAr, name)
+			}
+			entry := a.longNames[off:]
+			if end := bytes.IndexByte(entry, '\n'); end >= 0 {
+				entry = entry[:end]
+			}
+			name = strings.TrimSuffix(string(entry), "/")
+		default:
+			name = strings.TrimSuffix(name, "/")
+		}
+		return name, size, nil
+	}
+}
+
+// Read reads from the current member.
+func (a *arReader) Read(p []byte) (int, error) {
+	if a.cur == nil {
+		return 0, io.EOF
+	}
+	n, err := a.cur.Read(p)
+	a.remaining -= int64(n)
+	return n, err
+}
+
+// readAll consumes n bytes of the current member.
+func (a *arReader) readAll(n int64) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(a, buf); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedAr, err)
+	}
+	return buf, nil
+}
+
+// IsAr reports whether r holds a Unix ar archive, such as a static library.
+func IsAr(r io.ReaderAt) bool {
+	magic := make([]byte, len(arMagic))
+	_, err := r.ReadAt(magic, 0)
+	return err == nil && string(magic) == arMagic
+}
+
+// WalkAr invokes fn for each member of the ar archive read from r.
+// Symbol and long-name tables are not yielded.
+func WalkAr(r io.Reader, fn WalkFunc) error {
+	ar, err := newArReader(r)
+	if err != nil {
+		return err
+	}
+	for {
+		name, size, err := ar.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(Entry{Name: name, Size: size, Content: ar}); err != nil {
+			return err
+		}
+	}
+}
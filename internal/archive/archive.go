@@ -0,0 +1,137 @@
+// Package archive reads package and archive formats and yields the regular files they contain.
+package archive
+
+import (
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"go.kacmar.sk/crack/binary"
+)
+
+// Separator joins a container path with the path of a member inside it, e.g. "pkg.deb!/usr/bin/foo".
+const Separator = "!/"
+
+// Entry is a regular file, or a link of a tar archive, read from an archive.
+type Entry struct {
+	// Name is the slash-separated member path relative to the archive root, without a leading "./" or "/".
+	Name string
+	// Size is the member's size in bytes.
+	Size int64
+	// Package identifies the package that shipped the entry, or nil when the archive isn't a package.
+	Package *Package
+	// Layer is the digest of the container image layer that introduced the entry, or "" when the archive isn't an image.
+	Layer string
+	// ABI is the native ABI the entry is built for as named by its container, e.g. "arm64-v8a" for lib/arm64-v8a/ in an APK, or "".
+	ABI string
+	// Metadata is the mode, ownership, and file capabilities the archive records for the entry, or nil when it records none.
+	Metadata *binary.FileMetadata
+	// Content streams the member's bytes. It is only valid for the duration of the WalkFunc call that receives the entry.
+	Content io.Reader
+	// Link is set for the links of tar archives, which have no content of their own. For a hard link it is the Name of the
+	// earlier member holding the content, for a symbolic link the target as recorded.
+	Link string
+	// Symlink reports whether a link is symbolic rather than hard.
+	Symlink bool
+}
+
+// Package identifies a software package by the metadata recorded in its header.
+type Package struct {
+	Name string
+	// Version is the full package version, including epoch and release where the format has them (e.g. "1:2.3-4.fc40").
+	Version string
+	Arch    string
+}
+
+// WalkFunc is invoked for each regular file in an archive, and for each link of tar archives.
+// Returning a non-nil error stops the walk, and the error is returned from Walk.
+type WalkFunc func(e Entry) error
+
+// Format is an archive format recognized by its leading bytes.
+type Format struct {
+	// Name is a short human-readable identifier such as "deb".
+	Name string
+
+	// match reports whether the archive starts with header. Formats that can't be told apart by their leading bytes may inspect r.
+	match func(r io.ReaderAt, header []byte) bool
+	// detect replaces match for formats whose recognition learns something the walk can reuse. It returns the format bound
+	// to what it learned about r, or nil when r isn't in the format.
+	detect func(r io.ReaderAt, header []byte) *Format
+	walk   func(r io.ReaderAt, size int64, fn WalkFunc) error
+}
+
+// Walk invokes fn for every regular file, and every link of tar archives, in the archive held by r.
+func (f *Format) Walk(r io.ReaderAt, size int64, fn WalkFunc) error {
+	return f.walk(r, size, fn)
+}
+
+// headerSize is the number of leading bytes read to recognize a format.
+const headerSize = 512
+
+// formats lists the recognized archive formats in match order.
+// More specific formats must precede the generic containers they are built on.
+var formats = []*Format{
+	&debFormat,
+	&rpmFormat,
+	&imageFormat,
+	&zipFormat,
+	&cpioFormat,
+	&squashfsFormat,
+	&tarFormat,
+}
+
+// Detect returns the archive format of the data in r, or nil when it isn't a recognized archive.
+// The returned format may carry what detection learned about r, so it must only walk the same data.
+func Detect(r io.ReaderAt) *Format {
+	header := make([]byte, headerSize)
+	n, err := r.ReadAt(header, 0)
+	if err != nil && err != io.EOF {
+		return nil
+	}
+	header = header[:n]
+	for _, f := range formats {
+		if f.detect != nil {
+			if bound := f.detect(r, header); bound != nil {
+				return bound
+			}
+			continue
+		}
+		if f.match(r, header) {
+			return f
+		}
+	}
+	return nil
+}
+
+// MemberPath returns the display path of member inside the container at path.
+func MemberPath(container, member string) string {
+	return container + Separator + member
+}
+
+// SplitPath splits a display path produced by MemberPath into the outermost container and the members nested inside it.
+// A plain filesystem path is returned as a single element.
+func SplitPath(p string) []string {
+	return strings.Split(p, Separator)
+}
+
+// cleanName normalizes a member path from an archive header to the form used in Entry.Name.
+// Rooting the name before cleaning keeps ".." components from escaping the archive.
+func cleanName(name string) string {
+	return strings.TrimLeft(path.Clean("/"+name), "/")
+}
+
+// unixMetadata converts the st_mode, owner, and group recorded by tar and cpio headers into file metadata.
+func unixMetadata(mode, uid, gid int64) *binary.FileMetadata {
+	m := fs.FileMode(mode & 0o777) // #nosec G115 -- masked to the permission bits
+	if mode&0o4000 != 0 {
+		m |= fs.ModeSetuid
+	}
+	if mode&0o2000 != 0 {
+		m |= fs.ModeSetgid
+	}
+	if mode&0o1000 != 0 {
+		m |= fs.ModeSticky
+	}
+	return &binary.FileMetadata{Mode: m, UID: uint32(uid), GID: uint32(gid)} // #nosec G115 -- IDs are 32-bit on Linux
+}
@@ -0,0 +1,240 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ErrMalformedCpio is returned when a cpio header can't be decoded.
+var ErrMalformedCpio = errors.New("malformed cpio archive")
+
+// cpio "new ASCII" (newc) format, with and without per-file checksums, and the POSIX "old character" (odc) format.
+// See https://man.freebsd.org/cgi/man.cgi?query=cpio&sektion=5.
+const (
+	cpioNewcMagic  = "070701"
+	cpioCRCMagic   = "070702"
+	cpioODCMagic   = "070707"
+	cpioMagicSize  = 6
+	cpioHeaderSize = 110
+	cpioODCSize    = 76
+	cpioTrailer    = "TRAILER!!!"
+
+	// cpioTypeMask and cpioTypeReg select the file type bits of the mode field, as in stat(2).
+	cpioTypeMask = 0o170000
+	cpioTypeReg  = 0o100000
+)
+
+// cpioFormat recognizes cpio archives and Linux initramfs images: a sequence of cpio archives, each optionally compressed,
+// as assembled by dracut and mkinitcpio with an uncompressed early microcode archive in front of the compressed main one.
+// See https://docs.kernel.org/driver-api/early-userspace/buffer-format.html.
+var cpioFormat = Format{
+	Name:  "cpio",
+	match: matchCpio,
+	walk:  walkInitramfs,
+}
+
+func isCpioMagic(header []byte) bool {
+	if len(header) < cpioMagicSize {
+		return false
+	}
+	magic := string(header[:cpioMagicSize])
+	return magic == cpioNewcMagic || magic == cpioCRCMagic || magic == cpioODCMagic
+}
+
+func matchCpio(r io.ReaderAt, header []byte) bool {
+	if isCpioMagic(header) {
+		return true
+	}
+	return isCompressed(header) && isCpioMagic(peekDecompressed(r, cpioMagicSize))
+}
+
+func walkInitramfs(r io.ReaderAt, size int64, fn WalkFunc) error {
+	return walkCpioSegments(io.NewSectionReader(r, 0, size), fn)
+}
+
+// walkCpioSegments walks the concatenated cpio archives in r, skipping the zero padding between them.
+// A compressed segment is decompressed and walked in turn. Its end can't be located in the compressed stream, so it must be the last one.
+func walkCpioSegments(r io.Reader, fn WalkFunc) error {
+	br := bufio.NewReader(r)
+	for {
+		if err := skipZeros(br); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("%w: %w", ErrMalformedCpio, err)
+		}
+
+		header, _ := br.Peek(cpioMagicSize)
+		switch {
+		case isCpioMagic(header):
+			if err := walkCpio(br, fn); err != nil {
+				return err
+			}
+		case isCompressed(header):
+			data, closeData, err := decompress(br)
+			if err != nil {
+				return err
+			}
+			defer closeData()
+			return walkCpioSegments(data, fn)
+		default:
+			return fmt.Errorf("%w: unrecognized segment", ErrMalformedCpio)
+		}
+	}
+}
+
+// skipZeros consumes zero bytes up to the next non-zero byte or the end of the stream.
+func skipZeros(br *bufio.Reader) error {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return err
+		}
+		if b != 0 {
+			return br.UnreadByte()
+		}
+	}
+}
+
+// cpioHeader holds the header fields walkCpio needs, decoded from either format.
+type cpioHeader struct {
+	dev, ino, mode, uid, gid, nlink, nameSize, size int64
+}
+
+// walkCpio invokes fn for each regular file in the uncompressed cpio archive read from r, stopping after the trailer entry.
+// Hard-linked files are yielded once: newc stores their content on the last entry only, and odc repeats it on every entry of the inode.
+func walkCpio(r io.Reader, fn WalkFunc) error {
+	cr := &countingReader{r: r}
+	seen := make(map[[2]int64]bool)
+	for {
+		var magic [cpioMagicSize]byte
+		if _, err := io.ReadFull(cr, magic[:]); err != nil {
+			return fmt.Errorf("%w: %w", ErrMalformedCpio, err)
+		}
+
+		var (
+			hdr   cpioHeader
+			align int64 = 1
+			err   error
+		)
+		switch string(magic[:]) {
+		case cpioNewcMagic, cpioCRCMagic:
+			hdr, err = readNewcHeader(cr)
+			align = 4
+		case cpioODCMagic:
+			hdr, err = readODCHeader(cr)
+		default:
+			return fmt.Errorf("%w: unsupported magic %q", ErrMalformedCpio, magic)
+		}
+		if err != nil {
+			return err
+		}
+
+		name := make([]byte, hdr.nameSize)
+		if _, err := io.ReadFull(cr, name); err != nil {
+			return fmt.Errorf("%w: %w", ErrMalformedCpio, err)
+		}
+		if err := cr.skipTo(align); err != nil {
+			return err
+		}
+		name = bytes.TrimRight(name, "\x00")
+		if string(name) == cpioTrailer {
+			return nil
+		}
+
+		content := io.LimitReader(cr, hdr.size)
+		if hdr.mode&cpioTypeMask == cpioTypeReg && hdr.size > 0 {
+			inode := [2]int64{hdr.dev, hdr.ino}
+			if hdr.nlink <= 1 || !seen[inode] {
+				seen[inode] = hdr.nlink > 1
+				if err := fn(Entry{Name: cleanName(string(name)), Size: hdr.size, Metadata: unixMetadata(hdr.mode, hdr.uid, hdr.gid), Content: content}); err != nil {
+					return err
+				}
+			}
+		}
+		if _, err := io.Copy(io.Discard, content); err != nil {
+			return fmt.Errorf("%w: %w", ErrMalformedCpio, err)
+		}
+		if err := cr.skipTo(align); err != nil {
+			return err
+		}
+	}
+}
+
+// readNewcHeader decodes the 8-digit hexadecimal fields following the magic of a newc header.
+func readNewcHeader(r io.Reader) (cpioHeader, error) {
+	var buf [cpioHeaderSize - cpioMagicSize]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return cpioHeader{}, fmt.Errorf("%w: %w", ErrMalformedCpio, err)
+	}
+	field := func(i int) (int64, error) { return cpioField(buf[i*8:i*8+8], 16) }
+
+	var hdr cpioHeader
+	var devMajor, devMinor int64
+	var err error
+	for _, f := range []struct {
+		dst *int64
+		i   int
+	}{{&hdr.ino, 0}, {&hdr.mode, 1}, {&hdr.uid, 2}, {&hdr.gid, 3}, {&hdr.nlink, 4}, {&hdr.size, 6}, {&devMajor, 7}, {&devMinor, 8}, {&hdr.nameSize, 11}} {
+		if *f.dst, err = field(f.i); err != nil {
+			return cpioHeader{}, err
+		}
+	}
+	hdr.dev = devMajor<<32 | devMinor
+	return hdr, nil
+}
+
+// readODCHeader decodes the octal fields following the magic of an odc header.
+func readODCHeader(r io.Reader) (cpioHeader, error) {
+	var buf [cpioODCSize - cpioMagicSize]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return cpioHeader{}, fmt.Errorf("%w: %w", ErrMalformedCpio, err)
+	}
+
+	var hdr cpioHeader
+	var err error
+	// Field offsets and widths after the magic: dev, ino, mode, uid, gid, nlink, rdev, mtime, namesize, filesize.
+	for _, f := range []struct {
+		dst        *int64
+		off, width int
+	}{{&hdr.dev, 0, 6}, {&hdr.ino, 6, 6}, {&hdr.mode, 12, 6}, {&hdr.uid, 18, 6}, {&hdr.gid, 24, 6}, {&hdr.nlink, 30, 6}, {&hdr.nameSize, 53, 6}, {&hdr.size, 59, 11}} {
+		if *f.dst, err = cpioField(buf[f.off:f.off+f.width], 8); err != nil {
+			return cpioHeader{}, err
+		}
+	}
+	return hdr, nil
+}
+
+// cpioField decodes a fixed-width numeric header field in the given base.
+func cpioField(field []byte, base int) (int64, error) {
+	v, err := strconv.ParseUint(string(field), base, 63)
+	if err != nil {
+		return 0, fmt.Errorf("%w: bad header field: %w", ErrMalformedCpio, err)
+	}
+	return int64(v), nil
+}
+
+// countingReader tracks the stream offset so that alignment padding can be skipped.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// skipTo discards bytes until the offset is a multiple of align.
+func (c *countingReader) skipTo(align int64) error {
+	pad := (align - c.n%align) % align
+	if _, err := io.CopyN(io.Discard, c, pad); err != nil {
+		return fmt.Errorf("%w: %w", ErrMalformedCpio, err)
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package archive
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/pierrec/lz4/v4"
+
+	"go.kacmar.sk/crack/binary"
+)
+
+type cpioMember struct {
+	name  string
+	mode  uint32
+	uid   uint32
+	gid   uint32
+	nlink uint32
+	data  string
+}
+
+func buildCpio(t *testing.T, members ...cpioMember) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	pad := func() {
+		for buf.Len()%4 != 0 {
+			buf.WriteByte(0)
+		}
+	}
+	for i, m := range append(members, cpioMember{name: cpioTrailer, nlink: 1}) {
+		nlink := m.nlink
+		if nlink == 0 {
+			nlink = 1
+		}
+		fmt.Fprintf(&buf, "%s%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x",
+			cpioNewcMagic, i+1, m.mode, m.uid, m.gid, nlink, 0, len(m.data), 0, 0, 0, 0, len(m.name)+1, 0)
+		buf.WriteString(m.name)
+		buf.WriteByte(0)
+		pad()
+		buf.WriteString(m.data)
+		pad()
+	}
+	return buf.Bytes()
+}
+
+func TestWalkCpio(t *testing.T) {
+	data := buildCpio(t,
+		cpioMember{name: ".", mode: 0o040755},
+		cpioMember{name: "./usr/bin/foo", mode: 0o100755, data: "foo"},
+		cpioMember{name: "./usr/bin/foo-link", mode: 0o120777, data: "foo"},
+		cpioMember{name: "./usr/bin/hard", mode: 0o100755, nlink: 2},
+		cpioMember{name: "./usr/bin/hard2", mode: 0o100755, nlink: 2, data: "hardlinked"},
+		cpioMember{name: "./etc/empty", mode: 0o100644},
+	)
+
+	got := make(map[string]string)
+	err := walkCpio(bytes.NewReader(data), func(e Entry) error {
+		content, err := io.ReadAll(e.Content)
+		if err != nil {
+			return err
+		}
+		got[e.Name] = string(content)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walkCpio() error = %v", err)
+	}
+
+	want := map[string]string{"usr/bin/foo": "foo", "usr/bin/hard2": "hardlinked"}
+	if len(got) != len(want) {
+		t.Fatalf("entries = %v, want %v", got, want)
+	}
+	for name, content := range want {
+		if got[name] != content {
+			t.Errorf("entry %q = %q, want %q", name, got[name], content)
+		}
+	}
+}
+
+func TestWalkCpioMetadata(t *testing.T) {
+	data := buildCpio(t,
+		cpioMember{name: "./usr/bin/su", mode: 0o104755, data: "su"},
+		cpioMember{name: "./usr/bin/wall", mode: 0o102755, gid: 5, data: "wall"},
+		cpioMember{name: "./usr/bin/ls", mode: 0o100755, uid: 1000, gid: 1000, data: "ls"},
+	)
+
+	got := make(map[string]binary.FileMetadata)
+	err := walkCpio(bytes.NewReader(data), func(e Entry) error {
+		got[e.Name] = *e.Metadata
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walkCpio() error = %v", err)
+	}
+
+	want := map[string]binary.FileMetadata{
+		"usr/bin/su":   {Mode: fs.ModeSetuid | 0o755},
+		"usr/bin/wall": {Mode: fs.ModeSetgid | 0o755, GID: 5},
+		"usr/bin/ls":   {Mode: 0o755, UID: 1000, GID: 1000},
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("metadata = %v, want %v", got, want)
+	}
+}
+
+func TestWalkCpioTruncated(t *testing.T) {
+	data := buildCpio(t, cpioMember{name: "foo", mode: 0o100755, data: "foo"})
+	err := walkCpio(bytes.NewReader(data[:len(data)-cpioHeaderSize]), func(Entry) error { return nil })
+	if !errors.Is(err, ErrMalformedCpio) {
+		t.Fatalf("walkCpio() error = %v, want ErrMalformedCpio", err)
+	}
+}
+
+func buildODCCpio(t *testing.T, members ...cpioMember) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	for i, m := range append(members, cpioMember{name: cpioTrailer, nlink: 1}) {
+		nlink := m.nlink
+		if nlink == 0 {
+			nlink = 1
+		}
+		// Hard-linked members share the inode of the first member with the same content.
+		ino := i + 1
+		if nlink > 1 {
+			ino = len(m.data)
+		}
+		fmt.Fprintf(&buf, "%s%06o%06o%06o%06o%06o%06o%06o%011o%06o%011o",
+			cpioODCMagic, 1, ino, m.mode, 0, 0, nlink, 0, 0, len(m.name)+1, len(m.data))
+		buf.WriteString(m.name)
+		buf.WriteByte(0)
+		buf.WriteString(m.data)
+	}
+	return buf.Bytes()
+}
+
+func TestWalkCpioODC(t *testing.T) {
+	data := buildODCCpio(t,
+		cpioMember{name: "bin", mode: 0o040755},
+		cpioMember{name: "bin/sh", mode: 0o100755, data: "sh"},
+		cpioMember{name: "bin/busybox", mode: 0o100755, nlink: 2, data: "busybox"},
+		cpioMember{name: "bin/ls", mode: 0o100755, nlink: 2, data: "busybox"},
+	)
+
+	got := walkAll(t, &cpioFormat, data)
+	want := map[string]string{"bin/sh": "sh", "bin/busybox": "busybox"}
+	if len(got) != len(want) {
+		t.Fatalf("entries = %v, want %v", got, want)
+	}
+	for name, content := range want {
+		if got[name] != content {
+			t.Errorf("entry %q = %q, want %q", name, got[name], content)
+		}
+	}
+}
+
+func lz4Bytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := lz4.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestInitramfsWalk(t *testing.T) {
+	early := buildCpio(t, cpioMember{name: "kernel/x86/microcode/GenuineIntel.bin", mode: 0o100644, data: "ucode"})
+	main := buildCpio(t,
+		cpioMember{name: "usr/bin/init", mode: 0o100755, data: "init"},
+		cpioMember{name: "usr/lib/libc.so.6", mode: 0o100755, data: "libc"},
+	)
+	extra := buildODCCpio(t, cpioMember{name: "etc/hostname", mode: 0o100644, data: "host"})
+	want := map[string]string{
+		"kernel/x86/microcode/GenuineIntel.bin": "ucode",
+		"usr/bin/init":                          "init",
+		"usr/lib/libc.so.6":                     "libc",
+		"etc/hostname":                          "host",
+	}
+
+	// The early archive is padded to a 512-byte boundary, as dracut does, and the main archives are compressed together.
+	padded := append(early, make([]byte, 512-len(early)%512)...)
+	mainArchives := append(append(main, make([]byte, 4)...), extra...)
+	compressors := []struct {
+		name     string
+		compress func(t *testing.T, data []byte) []byte
+	}{
+		{"uncompressed", func(_ *testing.T, data []byte) []byte { return data }},
+		{"gzip", gzipBytes},
+		{"xz", xzBytes},
+		{"zstd", zstdBytes},
+		{"lz4", lz4Bytes},
+	}
+	for _, c := range compressors {
+		t.Run(c.name, func(t *testing.T) {
+			data := append(slices.Clone(padded), c.compress(t, mainArchives)...)
+
+			format := Detect(bytes.NewReader(data))
+			if format == nil || format.Name != "cpio" {
+				t.Fatalf("Detect() = %v, want cpio", format)
+			}
+			got := walkAll(t, format, data)
+			if len(got) != len(want) {
+				t.Fatalf("entries = %v, want %v", got, want)
+			}
+			for name, content := range want {
+				if got[name] != content {
+					t.Errorf("entry %q = %q, want %q", name, got[name], content)
+				}
+			}
+		})
+	}
+}
+
+func TestDetectCompressedCpio(t *testing.T) {
+	data := gzipBytes(t, buildCpio(t, cpioMember{name: "init", mode: 0o100755, data: "init"}))
+	if f := Detect(bytes.NewReader(data)); f == nil || f.Name != "cpio" {
+		t.Errorf("Detect() = %v, want cpio", f)
+	}
+	if f := Detect(bytes.NewReader(gzipBytes(t, []byte("not an archive")))); f != nil {
+		t.Errorf("Detect(gzip text) = %s, want nil", f.Name)
+	}
+}
+
+func TestInitramfsWalkGarbage(t *testing.T) {
+	data := append(buildCpio(t, cpioMember{name: "init", mode: 0o100755, data: "init"}), "garbage"...)
+	err := cpioFormat.Walk(bytes.NewReader(data), int64(len(data)), func(Entry) error { return nil })
+	if !errors.Is(err, ErrMalformedCpio) {
+		t.Fatalf("Walk() error = %v, want ErrMalformedCpio", err)
+	}
+}
@@ -0,0 +1,105 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrMalformedDeb is returned when a Debian package lacks the members required by deb(5).
+var ErrMalformedDeb = errors.New("malformed deb package")
+
+// debFormat recognizes Debian binary packages: an ar archive whose first member is "debian-binary".
+// Package metadata lives in the control.tar member and file contents in the data.tar member.
+// Either may be uncompressed or compressed with gzip, xz, zstd or bzip2.
+// See https://man7.org/linux/man-pages/man5/deb.5.html.
+var debFormat = Format{
+	Name:  "deb",
+	match: matchDeb,
+	walk:  walkDeb,
+}
+
+func matchDeb(_ io.ReaderAt, header []byte) bool {
+	return bytes.HasPrefix(header, []byte(arMagic+"debian-binary"))
+}
+
+func walkDeb(r io.ReaderAt, size int64, fn WalkFunc) error {
+	ar, err := newArReader(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return err
+	}
+
+	var pkg *Package
+	for {
+		name, _, err := ar.Next()
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: no data.tar member", ErrMalformedDeb)
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case strings.HasPrefix(name, "control.tar"):
+			pkg, err = readDebControl(ar)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		case strings.HasPrefix(name, "data.tar"):
+			data, closeData, err := decompress(ar)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			defer closeData()
+			return walkTar(data, func(e Entry) error {
+				e.Package = pkg
+				return fn(e)
+			})
+		}
+	}
+}
+
+// readDebControl extracts the package identity from the control file in a control.tar member.
+// Returns nil when the member has no control file.
+func readDebControl(r io.Reader) (*Package, error) {
+	data, closeData, err := decompress(r)
+	if err != nil {
+		return nil, err
+	}
+	defer closeData()
+
+	var pkg *Package
+	err = walkTar(data, func(e Entry) error {
+		if e.Name != "control" || e.Link != "" {
+			return nil
+		}
+		pkg = parseDebControl(e.Content)
+		return nil
+	})
+	return pkg, err
+}
+
+// parseDebControl reads the Package, Version and Architecture fields of a deb-control(5) paragraph.
+func parseDebControl(r io.Reader) *Package {
+	pkg := &Package{}
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		key, value, ok := strings.Cut(sc.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "Package":
+			pkg.Name = value
+		case "Version":
+			pkg.Version = value
+		case "Architecture":
+			pkg.Arch = value
+		}
+	}
+	return pkg
+}
@@ -0,0 +1,276 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+const debControl = `Package: foo
+Version: 1:2.0-1
+Architecture: amd64
+Maintainer: Jane Doe <jane@example.com>
+Description: test package
+ with a continuation line: that is not a field
+`
+
+func TestDebWalk(t *testing.T) {
+	files := map[string]string{
+		"./usr/bin/foo":          "foo",
+		"./usr/lib/libbar.so.1":  "bar",
+		"./usr/share/doc/README": "readme",
+	}
+
+	compressors := []struct {
+		name     string
+		compress func(t *testing.T, data []byte) []byte
+	}{
+		{"data.tar", func(_ *testing.T, data []byte) []byte { return data }},
+		{"data.tar.gz", gzipBytes},
+		{"data.tar.xz", xzBytes},
+		{"data.tar.zst", zstdBytes},
+	}
+
+	for _, c := range compressors {
+		t.Run(c.name, func(t *testing.T) {
+			deb := buildAr(t,
+				arMember{"debian-binary", []byte("2.0\n")},
+				arMember{"control.tar.gz", gzipBytes(t, buildTar(t, map[string]string{"./control": debControl}))},
+				arMember{c.name, c.compress(t, buildTar(t, files))},
+			)
+
+			format := Detect(bytes.NewReader(deb))
+			if format == nil || format.Name != "deb" {
+				t.Fatalf("Detect() = %v, want deb", format)
+			}
+
+			wantPkg := Package{Name: "foo", Version: "1:2.0-1", Arch: "amd64"}
+			err := format.Walk(bytes.NewReader(deb), int64(len(deb)), func(e Entry) error {
+				if e.Package == nil || *e.Package != wantPkg {
+					t.Errorf("entry %q package = %+v, want %+v", e.Name, e.Package, wantPkg)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Walk() error = %v", err)
+			}
+
+			got := walkAll(t, format, deb)
+			want := map[string]string{
+				"usr/bin/foo":          "foo",
+				"usr/bin/foo-link":     "-> foo",
+				"usr/lib/libbar.so.1":  "bar",
+				"usr/share/doc/README": "readme",
+			}
+			if len(got) != len(want) {
+				t.Fatalf("got %d entries %v, want %d", len(got), got, len(want))
+			}
+			for name, content := range want {
+				if got[name] != content {
+					t.Errorf("entry %q = %q, want %q", name, got[name], content)
+				}
+			}
+		})
+	}
+}
+
+func TestDebWalkMissingData(t *testing.T) {
+	deb := buildAr(t, arMember{"debian-binary", []byte("2.0\n")})
+	err := debFormat.Walk(bytes.NewReader(deb), int64(len(deb)), func(Entry) error { return nil })
+	if !errors.Is(err, ErrMalformedDeb) {
+		t.Fatalf("Walk() error = %v, want ErrMalformedDeb", err)
+	}
+}
+
+func TestDetectNotArchive(t *testing.T) {
+	for _, data := range [][]byte{nil, []byte("\x7fELF\x02\x01\x01"), []byte(arMagic + "foo.o/")} {
+		if f := Detect(bytes.NewReader(data)); f != nil {
+			t.Errorf("Detect(%q) = %s, want nil", data, f.Name)
+		}
+	}
+}
+
+func TestArReaderLongNames(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString(arMagic)
+	writeArHeader(&buf, "/", 4)
+	buf.WriteString("\x00\x00\x00\x00")
+	longNames := "a_very_long_object_name.o/\n"
+	writeArHeader(&buf, "//", len(longNames))
+	buf.WriteString(longNames)
+	buf.WriteString("\n")
+	writeArHeader(&buf, "/0", 3)
+	buf.WriteString("abc\n")
+	writeArHeader(&buf, "#1/8", 10)
+	buf.WriteString("bsd.o\x00\x00\x00xy")
+	writeArHeader(&buf, "short.o/", 1)
+	buf.WriteString("z\n")
+
+	ar, err := newArReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for {
+		name, _, err := ar.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		content, err := io.ReadAll(ar)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, name+"="+string(content))
+	}
+
+	want := []string{"a_very_long_object_name.o=abc", "bsd.o=xy", "short.o=z"}
+	if !slices.Equal(got, want) {
+		t.Errorf("members = %v, want %v", got, want)
+	}
+}
+
+func TestSplitPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/usr/bin/foo", []string{"/usr/bin/foo"}},
+		{MemberPath("pkg.deb", "usr/bin/foo"), []string{"pkg.deb", "usr/bin/foo"}},
+		{MemberPath(MemberPath("sdk.tar", "pkg.deb"), "usr/bin/foo"), []string{"sdk.tar", "pkg.deb", "usr/bin/foo"}},
+	}
+	for _, tt := range tests {
+		if got := SplitPath(tt.path); !slices.Equal(got, tt.want) {
+			t.Errorf("SplitPath(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+type arMember struct {
+	name string
+	data []byte
+}
+
+func buildAr(t *testing.T, members ...arMember) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	buf.WriteString(arMagic)
+	for _, m := range members {
+		writeArHeader(&buf, m.name, len(m.data))
+		buf.Write(m.data)
+		if len(m.data)%2 != 0 {
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes()
+}
+
+func writeArHeader(buf *bytes.Buffer, name string, size int) {
+	fmt.Fprintf(buf, "%-16s%-12d%-6d%-6d%-8s%-10d`\n", name, 0, 0, 0, "100644", size)
+}
+
+func buildTar(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{Name: "./usr/", Typeflag: tar.TypeDir, Mode: 0o755}); err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		content := files[name]
+		if err := tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o755, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: "./usr/bin/foo-link", Typeflag: tar.TypeSymlink, Linkname: "foo"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// walkAll returns the content of each entry by name, with links recorded as "-> target" for symbolic and "=> target"
+// for hard ones.
+func walkAll(t *testing.T, format *Format, data []byte) map[string]string {
+	t.Helper()
+	got := make(map[string]string)
+	err := format.Walk(bytes.NewReader(data), int64(len(data)), func(e Entry) error {
+		if e.Link != "" {
+			arrow := "=> "
+			if e.Symlink {
+				arrow = "-> "
+			}
+			got[e.Name] = arrow + e.Link
+			return nil
+		}
+		content, err := io.ReadAll(e.Content)
+		if err != nil {
+			return err
+		}
+		got[e.Name] = string(content)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+	return got
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func xzBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := xz.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zstdBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	w, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	return w.EncodeAll(data, nil)
+}
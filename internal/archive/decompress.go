@@ -0,0 +1,86 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"github.com/ulikunitz/xz"
+)
+
+// Compression stream magic numbers.
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	xzMagic    = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	bzip2Magic = []byte("BZh") // followed by the block size digit '1'-'9'
+	lz4Magic   = []byte{0x04, 0x22, 0x4d, 0x18}
+	// lz4LegacyMagic starts the legacy LZ4 format still used for Linux initramfs images (lz4 -l).
+	lz4LegacyMagic = []byte{0x02, 0x21, 0x4c, 0x18}
+)
+
+// isCompressed reports whether header starts with the magic number of a compression method decompress understands.
+func isCompressed(header []byte) bool {
+	return bytes.HasPrefix(header, gzipMagic) ||
+		bytes.HasPrefix(header, xzMagic) ||
+		bytes.HasPrefix(header, zstdMagic) ||
+		(len(header) > len(bzip2Magic) && bytes.HasPrefix(header, bzip2Magic) && header[3] >= '1' && header[3] <= '9') ||
+		bytes.HasPrefix(header, lz4Magic) ||
+		bytes.HasPrefix(header, lz4LegacyMagic)
+}
+
+// decompress returns a reader yielding the decompressed content of r.
+// The compression method is sniffed from the stream's magic number rather than trusted from a file extension.
+// Data without a recognized compression header is returned unchanged.
+// The returned close function releases decoder resources and must be called once reading is done.
+func decompress(r io.Reader) (io.Reader, func(), error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(6)
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, nil, fmt.Errorf("gzip: %w", err)
+		}
+		return zr, func() { _ = zr.Close() }, nil
+	case bytes.HasPrefix(magic, xzMagic):
+		zr, err := xz.NewReader(br)
+		if err != nil {
+			return nil, nil, fmt.Errorf("xz: %w", err)
+		}
+		return zr, func() {}, nil
+	case bytes.HasPrefix(magic, zstdMagic):
+		zr, err := zstd.NewReader(br, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, nil, fmt.Errorf("zstd: %w", err)
+		}
+		return zr, zr.Close, nil
+	case len(magic) > len(bzip2Magic) && bytes.HasPrefix(magic, bzip2Magic) && magic[3] >= '1' && magic[3] <= '9':
+		return bzip2.NewReader(br), func() {}, nil
+	case bytes.HasPrefix(magic, lz4Magic) || bytes.HasPrefix(magic, lz4LegacyMagic):
+		return lz4.NewReader(br), func() {}, nil
+	default:
+		return br, func() {}, nil
+	}
+}
+
+// peekDecompressed returns the first n bytes of the decompressed stream held by r, or nil when they can't be read.
+func peekDecompressed(r io.ReaderAt, n int) []byte {
+	data, closeData, err := decompress(io.NewSectionReader(r, 0, math.MaxInt64))
+	if err != nil {
+		return nil
+	}
+	defer closeData()
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(data, buf); err != nil {
+		return nil
+	}
+	return buf
+}
@@ -0,0 +1,400 @@
+package archive
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strings"
+)
+
+// ErrMalformedImage is returned when an image tarball's manifests can't be decoded or reference missing blobs.
+var ErrMalformedImage = errors.New("malformed container image")
+
+// Container image tarballs as written by "docker save" and by tools exporting an OCI image layout.
+// See https://github.com/moby/docker-image-spec/blob/main/spec.md and https://github.com/opencontainers/image-spec/blob/main/image-layout.md.
+const (
+	dockerManifestFile = "manifest.json"
+	ociLayoutFile      = "oci-layout"
+	ociIndexFile       = "index.json"
+
+	whiteoutPrefix = ".wh."
+	whiteoutOpaque = ".wh..wh..opq"
+
+	// imageMaxManifestSize bounds the JSON documents read into memory.
+	imageMaxManifestSize = 16 << 20
+)
+
+const imageFormatName = "image"
+
+// imageFormat recognizes container image tarballs and walks the regular files of each image's merged root filesystem.
+// Layers are applied in order, honouring whiteout files, so only files visible in the final filesystem are yielded.
+var imageFormat = Format{
+	Name:   imageFormatName,
+	detect: detectImage,
+	walk:   walkImage,
+}
+
+// detectImage indexes the tar archive in r and reports it as an image when it holds an image manifest.
+// The returned format walks the image through that index, so the archive is only indexed once.
+func detectImage(r io.ReaderAt, header []byte) *Format {
+	if !isTarHeader(header) {
+		return nil
+	}
+	members, err := indexTar(r, math.MaxInt64, isImageLayoutMember)
+	if errors.Is(err, errNotImageLayout) || err != nil && len(members) == 0 {
+		return nil
+	}
+	_, docker := members[dockerManifestFile]
+	_, oci := members[ociLayoutFile]
+	if !docker && !oci {
+		return nil
+	}
+	return &Format{
+		Name: imageFormatName,
+		walk: func(r io.ReaderAt, _ int64, fn WalkFunc) error {
+			return walkImageMembers(r, members, fn)
+		},
+	}
+}
+
+// tarMember locates the content of a regular file inside an uncompressed tar archive.
+type tarMember struct {
+	offset, size int64
+}
+
+// errNotImageLayout is returned by indexTar when a member rules out an image layout.
+var errNotImageLayout = errors.New("not an image layout")
+
+// isImageLayoutMember reports whether a regular file named name can be part of an image tarball: a top-level manifest or
+// config, a layer directory of a "docker save" tarball, or a blob of an OCI image layout.
+func isImageLayoutMember(name string) bool {
+	parts := strings.Split(name, "/")
+	switch len(parts) {
+	case 1:
+		return true
+	case 2:
+		return parts[1] == "layer.tar" || parts[1] == "json" || parts[1] == "VERSION"
+	case 3:
+		return parts[0] == "blobs"
+	default:
+		return false
+	}
+}
+
+// indexTar records the content location of every regular file in the tar archive held by r.
+// The archive is seeked through rather than read, so indexing costs one header read per member.
+// When accept is set, indexing stops with errNotImageLayout at the first regular file it rejects.
+func indexTar(r io.ReaderAt, size int64, accept func(name string) bool) (map[string]tarMember, error) {
+	sr := io.NewSectionReader(r, 0, size)
+	tr := tar.NewReader(sr)
+	members := make(map[string]tarMember)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return members, nil
+		}
+		if err != nil {
+			return members, fmt.Errorf("tar: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := cleanName(hdr.Name)
+		if accept != nil && !accept(name) {
+			return nil, errNotImageLayout
+		}
+		offset, err := sr.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return members, err
+		}
+		members[name] = tarMember{offset: offset, size: hdr.Size}
+	}
+}
+
+// image is a single image in a tarball: its layers from the base upwards.
+type image struct {
+	ref    string
+	layers []imageLayer
+}
+
+type imageLayer struct {
+	// digest identifies the layer, e.g. "sha256:…". It may be empty for legacy tarballs without diff IDs.
+	digest string
+	member tarMember
+}
+
+func walkImage(r io.ReaderAt, size int64, fn WalkFunc) error {
+	members, err := indexTar(r, size, nil)
+	if err != nil {
+		return err
+	}
+	return walkImageMembers(r, members, fn)
+}
+
+// walkImageMembers walks the images of the tarball in r whose regular files members indexes.
+func walkImageMembers(r io.ReaderAt, members map[string]tarMember, fn WalkFunc) error {
+	var images []image
+	var err error
+	if _, ok := members[dockerManifestFile]; ok {
+		images, err = readDockerManifest(r, members)
+	} else {
+		images, err = readOCIIndex(r, members)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, img := range images {
+		err := walkImageLayers(r, img, func(e Entry) error {
+			// Several images in one tarball are told apart by nesting their files under the image reference.
+			if len(images) > 1 {
+				e.Name = MemberPath(img.ref, e.Name)
+				if e.Link != "" && !e.Symlink {
+					e.Link = MemberPath(img.ref, e.Link)
+				}
+			}
+			return fn(e)
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", img.ref, err)
+		}
+	}
+	return nil
+}
+
+// readDockerManifest reads the images listed in the manifest.json of a "docker save" tarball.
+func readDockerManifest(r io.ReaderAt, members map[string]tarMember) ([]image, error) {
+	var manifest []struct {
+		Config   string
+		RepoTags []string
+		Layers   []string
+	}
+	if err := readImageJSON(r, members, dockerManifestFile, &manifest); err != nil {
+		return nil, err
+	}
+
+	images := make([]image, 0, len(manifest))
+	for _, m := range manifest {
+		var config struct {
+			RootFS struct {
+				DiffIDs []string `json:"diff_ids"`
+			} `json:"rootfs"`
+		}
+		if err := readImageJSON(r, members, m.Config, &config); err != nil {
+			return nil, err
+		}
+
+		img := image{ref: path.Base(m.Config)}
+		if len(m.RepoTags) > 0 {
+			img.ref = m.RepoTags[0]
+		}
+		for i, name := range m.Layers {
+			member, ok := members[cleanName(name)]
+			if !ok {
+				return nil, fmt.Errorf("%w: missing layer %s", ErrMalformedImage, name)
+			}
+			layer := imageLayer{digest: blobDigest(name), member: member}
+			if layer.digest == "" && i < len(config.RootFS.DiffIDs) {
+				layer.digest = config.RootFS.DiffIDs[i]
+			}
+			img.layers = append(img.layers, layer)
+		}
+		images = append(images, img)
+	}
+	return images, nil
+}
+
+// ociDescriptor references a blob in an OCI image layout.
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+const ociRefNameAnnotation = "org.opencontainers.image.ref.name"
+
+// readOCIIndex reads the images reachable from the index.json of an OCI image layout.
+// Manifests whose blobs are absent, such as the other platforms of a multi-platform image, are skipped.
+func readOCIIndex(r io.ReaderAt, members map[string]tarMember) ([]image, error) {
+	var images []image
+	var visit func(name, ref string) error
+	visit = func(name, ref string) error {
+		var doc struct {
+			Manifests []ociDescriptor `json:"manifests"`
+			Layers    []ociDescriptor `json:"layers"`
+		}
+		if err := readImageJSON(r, members, name, &doc); err != nil {
+			return err
+		}
+
+		if doc.Manifests == nil {
+			img := image{ref: ref}
+			for _, l := range doc.Layers {
+				// Attestation manifests and artifacts carry layers that aren't filesystem changesets.
+				if !strings.Contains(l.MediaType, "tar") {
+					continue
+				}
+				member, ok := members[blobName(l.Digest)]
+				if !ok {
+					return fmt.Errorf("%w: missing layer %s", ErrMalformedImage, l.Digest)
+				}
+				img.layers = append(img.layers, imageLayer{digest: l.Digest, member: member})
+			}
+			if len(img.layers) > 0 {
+				images = append(images, img)
+			}
+			return nil
+		}
+
+		for _, m := range doc.Manifests {
+			if _, ok := members[blobName(m.Digest)]; !ok {
+				continue
+			}
+			childRef := ref
+			if name := m.Annotations[ociRefNameAnnotation]; name != "" {
+				childRef = name
+			}
+			if childRef == "" || childRef == ref {
+				childRef = m.Digest
+			}
+			if err := visit(blobName(m.Digest), childRef); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := visit(ociIndexFile, ""); err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+// blobName returns the member path of the blob with the given digest in an OCI image layout.
+func blobName(digest string) string {
+	alg, hex, _ := strings.Cut(digest, ":")
+	return path.Join("blobs", alg, hex)
+}
+
+// blobDigest is the inverse of blobName. It returns "" for paths outside the blobs directory.
+func blobDigest(name string) string {
+	parts := strings.Split(cleanName(name), "/")
+	if len(parts) != 3 || parts[0] != "blobs" {
+		return ""
+	}
+	return parts[1] + ":" + parts[2]
+}
+
+func readImageJSON(r io.ReaderAt, members map[string]tarMember, name string, v any) error {
+	m, ok := members[cleanName(name)]
+	if !ok {
+		return fmt.Errorf("%w: missing %s", ErrMalformedImage, name)
+	}
+	if m.size > imageMaxManifestSize {
+		return fmt.Errorf("%w: %s too large", ErrMalformedImage, name)
+	}
+	if err := json.NewDecoder(io.NewSectionReader(r, m.offset, m.size)).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrMalformedImage, name, err)
+	}
+	return nil
+}
+
+// walkImageLayers yields the regular files and links of the image's merged root filesystem.
+// Layers are visited from the top down: a path is visible unless a higher layer already provided it,
+// whited it out, made an ancestor opaque, or replaced an ancestor with a non-directory.
+func walkImageLayers(r io.ReaderAt, img image, fn WalkFunc) error {
+	var (
+		// upper maps paths provided by higher layers to whether they are directories.
+		upper    = make(map[string]bool)
+		deleted  = make(map[string]bool)
+		opaque   = make(map[string]bool)
+		isHidden = func(name string) bool {
+			if _, ok := upper[name]; ok || deleted[name] {
+				return true
+			}
+			for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+				if isDir, ok := upper[dir]; (ok && !isDir) || deleted[dir] || opaque[dir] {
+					return true
+				}
+			}
+			return false
+		}
+	)
+
+	for i := len(img.layers) - 1; i >= 0; i-- {
+		layer := img.layers[i]
+		data, closeData, err := decompress(io.NewSectionReader(r, layer.member.offset, layer.member.size))
+		if err != nil {
+			return fmt.Errorf("layer %s: %w", layer.digest, err)
+		}
+
+		// Whiteouts and new paths only affect the layers below, so they are merged once the layer is done.
+		layerPaths := make(map[string]bool)
+		layerDeleted := make(map[string]bool)
+		layerOpaque := make(map[string]bool)
+		layerYielded := make(map[string]bool)
+
+		tr := tar.NewReader(data)
+		for {
+			hdr, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				closeData()
+				return fmt.Errorf("layer %s: tar: %w", layer.digest, err)
+			}
+
+			name := cleanName(hdr.Name)
+			if name == "" {
+				continue
+			}
+			dir, base := path.Split(name)
+			switch {
+			case base == whiteoutOpaque:
+				layerOpaque[path.Clean(dir)] = true
+				continue
+			case strings.HasPrefix(base, whiteoutPrefix):
+				layerDeleted[dir+strings.TrimPrefix(base, whiteoutPrefix)] = true
+				continue
+			}
+
+			if isHidden(name) {
+				continue
+			}
+			layerPaths[name] = hdr.Typeflag == tar.TypeDir
+			e, ok := newTarEntry(hdr, tr)
+			if !ok {
+				continue
+			}
+			// A hard link refers to an earlier member of its layer, which a higher layer may have hidden.
+			if e.Link != "" && !e.Symlink && !layerYielded[e.Link] {
+				continue
+			}
+			if e.Link == "" {
+				layerYielded[name] = true
+			}
+			e.Layer = layer.digest
+			if err := fn(e); err != nil {
+				closeData()
+				return err
+			}
+		}
+		closeData()
+
+		for name, isDir := range layerPaths {
+			upper[name] = isDir
+		}
+		for name := range layerDeleted {
+			deleted[name] = true
+		}
+		for name := range layerOpaque {
+			opaque[name] = true
+		}
+	}
+	return nil
+}
@@ -0,0 +1,315 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+)
+
+// tarEntry is a member of a tar built by buildTarEntries. Entries with a linkname are symlinks, those with a hardlink are hard links
+// and names ending in "/" are directories. Regular files have mode 0o755 unless mode is set.
+type tarEntry struct {
+	name     string
+	data     string
+	linkname string
+	hardlink string
+	mode     int64
+	uid, gid int
+}
+
+func (e tarEntry) fileMode() int64 {
+	if e.mode == 0 {
+		return 0o755
+	}
+	return e.mode
+}
+
+func buildTarEntries(t *testing.T, entries ...tarEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Typeflag: tar.TypeReg, Mode: e.fileMode(), Uid: e.uid, Gid: e.gid, Size: int64(len(e.data))}
+		switch {
+		case e.linkname != "":
+			hdr.Typeflag, hdr.Linkname, hdr.Size = tar.TypeSymlink, e.linkname, 0
+		case e.hardlink != "":
+			hdr.Typeflag, hdr.Linkname, hdr.Size = tar.TypeLink, e.hardlink, 0
+		case e.name[len(e.name)-1] == '/':
+			hdr.Typeflag, hdr.Size = tar.TypeDir, 0
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func digestOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// imageLayers returns three layers exercising replacement, whiteouts, opaque directories and files under replaced directories.
+func imageLayers(t *testing.T) [][]byte {
+	return [][]byte{
+		buildTarEntries(t,
+			tarEntry{name: "bin/"},
+			tarEntry{name: "bin/sh", data: "base-sh"},
+			tarEntry{name: "bin/ls", data: "base-ls"},
+			tarEntry{name: "opt/tool/"},
+			tarEntry{name: "opt/tool/old", data: "old"},
+			tarEntry{name: "lib/"},
+			tarEntry{name: "lib/libc.so", data: "libc"},
+			tarEntry{name: "etc/"},
+			tarEntry{name: "etc/app/"},
+			tarEntry{name: "etc/app/conf", data: "conf"},
+		),
+		buildTarEntries(t,
+			tarEntry{name: "bin/.wh.ls"},
+			tarEntry{name: "opt/tool/"},
+			tarEntry{name: "opt/tool/.wh..wh..opq"},
+			tarEntry{name: "opt/tool/new", data: "new"},
+			tarEntry{name: "etc/app", linkname: "/srv/app"},
+		),
+		buildTarEntries(t,
+			tarEntry{name: "./bin/sh", data: "top-sh"},
+			tarEntry{name: "./usr/bin/app", data: "app"},
+		),
+	}
+}
+
+func TestImageWalk(t *testing.T) {
+	layers := imageLayers(t)
+	wantLayer := map[string]int{"bin/sh": 2, "usr/bin/app": 2, "opt/tool/new": 1, "etc/app": 1, "lib/libc.so": 0}
+	wantData := map[string]string{"bin/sh": "top-sh", "usr/bin/app": "app", "opt/tool/new": "new", "etc/app": "-> /srv/app", "lib/libc.so": "libc"}
+
+	tests := []struct {
+		name  string
+		build func(t *testing.T, layers [][]byte) (tarball []byte, digests []string)
+	}{
+		{"docker legacy", buildDockerImage},
+		{"oci layout", buildOCIImage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tarball, digests := tt.build(t, layers)
+
+			format := Detect(bytes.NewReader(tarball))
+			if format == nil || format.Name != "image" {
+				t.Fatalf("Detect() = %v, want image", format)
+			}
+
+			got := make(map[string]string)
+			err := format.Walk(bytes.NewReader(tarball), int64(len(tarball)), func(e Entry) error {
+				data, err := io.ReadAll(e.Content)
+				if err != nil {
+					return err
+				}
+				got[e.Name] = string(data)
+				if e.Symlink {
+					got[e.Name] = "-> " + e.Link
+				}
+				if want := digests[wantLayer[e.Name]]; e.Layer != want {
+					t.Errorf("entry %q layer = %s, want %s", e.Name, e.Layer, want)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Walk() error = %v", err)
+			}
+			if len(got) != len(wantData) {
+				t.Fatalf("entries = %v, want %v", got, wantData)
+			}
+			for name, data := range wantData {
+				if got[name] != data {
+					t.Errorf("entry %q = %q, want %q", name, got[name], data)
+				}
+			}
+		})
+	}
+}
+
+// buildDockerImage builds a legacy "docker save" tarball with uncompressed layers identified by the config's diff IDs.
+func buildDockerImage(t *testing.T, layers [][]byte) ([]byte, []string) {
+	var entries []tarEntry
+	var names, digests []string
+	for i, layer := range layers {
+		name := string(rune('a'+i)) + "/layer.tar"
+		entries = append(entries, tarEntry{name: name, data: string(layer)})
+		names = append(names, name)
+		digests = append(digests, digestOf(layer))
+	}
+	config := mustJSON(t, map[string]any{"rootfs": map[string]any{"type": "layers", "diff_ids": digests}})
+	manifest := mustJSON(t, []map[string]any{{"Config": "config.json", "RepoTags": []string{"app:latest"}, "Layers": names}})
+	entries = append(entries,
+		tarEntry{name: "config.json", data: config},
+		tarEntry{name: "manifest.json", data: manifest},
+	)
+	return buildTarEntries(t, entries...), digests
+}
+
+// buildOCIImage builds an OCI image layout with gzip-compressed layers behind a multi-platform index whose other platform is absent.
+func buildOCIImage(t *testing.T, layers [][]byte) ([]byte, []string) {
+	var entries []tarEntry
+	var descriptors []map[string]string
+	var digests []string
+	blob := func(data string) string {
+		digest := digestOf([]byte(data))
+		entries = append(entries, tarEntry{name: blobName(digest), data: data})
+		return digest
+	}
+	for _, layer := range layers {
+		digest := blob(string(gzipBytes(t, layer)))
+		descriptors = append(descriptors, map[string]string{"mediaType": "application/vnd.oci.image.layer.v1.tar+gzip", "digest": digest})
+		digests = append(digests, digest)
+	}
+	descriptors = append(descriptors, map[string]string{"mediaType": "application/vnd.in-toto+json", "digest": blob("{}")})
+
+	manifest := blob(mustJSON(t, map[string]any{"layers": descriptors}))
+	nested := blob(mustJSON(t, map[string]any{"manifests": []map[string]any{
+		{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": manifest},
+		{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": digestOf([]byte("other platform"))},
+	}}))
+	index := mustJSON(t, map[string]any{"manifests": []map[string]any{{
+		"mediaType":   "application/vnd.oci.image.index.v1+json",
+		"digest":      nested,
+		"annotations": map[string]string{ociRefNameAnnotation: "app:latest"},
+	}}})
+	entries = append(entries,
+		tarEntry{name: "oci-layout", data: `{"imageLayoutVersion":"1.0.0"}`},
+		tarEntry{name: "index.json", data: index},
+	)
+	return buildTarEntries(t, entries...), digests
+}
+
+func TestImageWalkMultipleImages(t *testing.T) {
+	layer := buildTarEntries(t, tarEntry{name: "bin/sh", data: "sh"})
+	manifest := mustJSON(t, []map[string]any{
+		{"Config": "config.json", "RepoTags": []string{"one:1"}, "Layers": []string{"layer.tar"}},
+		{"Config": "config.json", "RepoTags": []string{"two:2"}, "Layers": []string{"layer.tar"}},
+	})
+	tarball := buildTarEntries(t,
+		tarEntry{name: "layer.tar", data: string(layer)},
+		tarEntry{name: "config.json", data: "{}"},
+		tarEntry{name: "manifest.json", data: manifest},
+	)
+
+	got := walkAll(t, &imageFormat, tarball)
+	for _, name := range []string{MemberPath("one:1", "bin/sh"), MemberPath("two:2", "bin/sh")} {
+		if got[name] != "sh" {
+			t.Errorf("entry %q = %q, want %q", name, got[name], "sh")
+		}
+	}
+}
+
+func TestImageWalkMultipleImagesHardLinks(t *testing.T) {
+	one := buildTarEntries(t, tarEntry{name: "bin/sh", data: "one"}, tarEntry{name: "bin/bash", hardlink: "bin/sh"})
+	two := buildTarEntries(t, tarEntry{name: "bin/sh", data: "two"}, tarEntry{name: "bin/bash", hardlink: "bin/sh"})
+	manifest := mustJSON(t, []map[string]any{
+		{"Config": "config.json", "RepoTags": []string{"one:1"}, "Layers": []string{"one.tar"}},
+		{"Config": "config.json", "RepoTags": []string{"two:2"}, "Layers": []string{"two.tar"}},
+	})
+	tarball := buildTarEntries(t,
+		tarEntry{name: "one.tar", data: string(one)},
+		tarEntry{name: "two.tar", data: string(two)},
+		tarEntry{name: "config.json", data: "{}"},
+		tarEntry{name: "manifest.json", data: manifest},
+	)
+
+	got := walkAll(t, &imageFormat, tarball)
+	for _, ref := range []string{"one:1", "two:2"} {
+		name, want := MemberPath(ref, "bin/bash"), "=> "+MemberPath(ref, "bin/sh")
+		if got[name] != want {
+			t.Errorf("entry %q = %q, want %q", name, got[name], want)
+		}
+	}
+}
+
+func TestImageWalkMissingLayer(t *testing.T) {
+	manifest := mustJSON(t, []map[string]any{{"Config": "config.json", "Layers": []string{"missing/layer.tar"}}})
+	tarball := buildTarEntries(t,
+		tarEntry{name: "config.json", data: "{}"},
+		tarEntry{name: "manifest.json", data: manifest},
+	)
+	err := imageFormat.Walk(bytes.NewReader(tarball), int64(len(tarball)), func(Entry) error { return nil })
+	if !errors.Is(err, ErrMalformedImage) {
+		t.Fatalf("Walk() error = %v, want ErrMalformedImage", err)
+	}
+}
+
+func TestDetectPlainTar(t *testing.T) {
+	data := buildTarEntries(t, tarEntry{name: "bin/sh", data: "sh"})
+	if f := Detect(bytes.NewReader(data)); f == nil || f.Name != "tar" {
+		t.Errorf("Detect() = %v, want tar", f)
+	}
+}
+
+func TestDetectPlainTarStopsEarly(t *testing.T) {
+	entries := []tarEntry{{name: "usr/bin/app", data: "app"}}
+	for range 100 {
+		entries = append(entries, tarEntry{name: "usr/share/doc/app/README", data: "doc"})
+	}
+	entries = append(entries, tarEntry{name: "manifest.json", data: "[]"})
+	data := buildTarEntries(t, entries...)
+
+	r := &countingReaderAt{r: bytes.NewReader(data)}
+	if f := Detect(r); f == nil || f.Name != "tar" {
+		t.Fatalf("Detect() = %v, want tar", f)
+	}
+	// The first member rules out an image layout, so the remaining headers aren't read.
+	if r.reads > 10 {
+		t.Errorf("Detect() made %d reads, want the first headers only", r.reads)
+	}
+}
+
+// countingReaderAt counts the reads made through it.
+type countingReaderAt struct {
+	r     io.ReaderAt
+	reads int
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	c.reads++
+	return c.r.ReadAt(p, off)
+}
+
+func TestDetectImageReusesIndex(t *testing.T) {
+	tarball, _ := buildDockerImage(t, [][]byte{buildTarEntries(t, tarEntry{name: "bin/sh", data: "sh"})})
+	walk := func(f *Format) int {
+		r := &countingReaderAt{r: bytes.NewReader(tarball)}
+		if err := f.Walk(r, int64(len(tarball)), func(Entry) error { return nil }); err != nil {
+			t.Fatalf("Walk() error = %v", err)
+		}
+		return r.reads
+	}
+
+	detected := Detect(bytes.NewReader(tarball))
+	if detected == nil || detected.Name != "image" {
+		t.Fatalf("Detect() = %v, want image", detected)
+	}
+	// Walking the detected format skips indexing the tarball again, which reads every member header.
+	if reused, indexed := walk(detected), walk(&imageFormat); reused >= indexed {
+		t.Errorf("walk of detected format made %d reads, want fewer than the %d of a fresh walk", reused, indexed)
+	}
+}
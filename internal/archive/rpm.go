@@ -0,0 +1,276 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ulikunitz/xz/lzma"
+
+	bin "go.kacmar.sk/crack/binary"
+)
+
+// ErrMalformedRPM is returned when an RPM lead or header can't be decoded.
+var ErrMalformedRPM = errors.New("malformed rpm package")
+
+// RPM file layout: a fixed 96-byte lead, a signature header padded to 8 bytes, the main header, then the compressed cpio payload.
+// See https://rpm-software-management.github.io/rpm/manual/format_v4.html.
+const (
+	rpmLeadSize        = 96
+	rpmHeaderIntroSize = 16
+	rpmIndexEntrySize  = 16
+
+	// rpmMaxHeaderSize bounds the index and data store sizes read from a header, mirroring rpm's own sanity limit.
+	rpmMaxHeaderSize = 256 << 20
+)
+
+var (
+	rpmLeadMagic   = []byte{0xed, 0xab, 0xee, 0xdb}
+	rpmHeaderMagic = []byte{0x8e, 0xad, 0xe8, 0x01}
+)
+
+// Header tags and data types used to identify the package and the capabilities of its files.
+const (
+	rpmTagName              = 1000
+	rpmTagVersion           = 1001
+	rpmTagRelease           = 1002
+	rpmTagEpoch             = 1003
+	rpmTagArch              = 1022
+	rpmTagDirIndexes        = 1116
+	rpmTagBaseNames         = 1117
+	rpmTagDirNames          = 1118
+	rpmTagPayloadCompressor = 1125
+	rpmTagFileCaps          = 5010
+
+	rpmTypeInt32       = 4
+	rpmTypeString      = 6
+	rpmTypeStringArray = 8
+)
+
+// rpmFormat recognizes RPM packages and walks the regular files of their cpio payload.
+var rpmFormat = Format{
+	Name:  "rpm",
+	match: matchRPM,
+	walk:  walkRPM,
+}
+
+func matchRPM(_ io.ReaderAt, header []byte) bool {
+	return bytes.HasPrefix(header, rpmLeadMagic)
+}
+
+func walkRPM(r io.ReaderAt, size int64, fn WalkFunc) error {
+	sigSize, err := rpmHeaderSize(r, rpmLeadSize, size)
+	if err != nil {
+		return fmt.Errorf("signature header: %w", err)
+	}
+	// The signature header is padded so the main header starts on an 8-byte boundary.
+	mainOff := rpmLeadSize + sigSize + (8-sigSize%8)%8
+
+	hdr, err := readRPMHeader(r, mainOff, size)
+	if err != nil {
+		return fmt.Errorf("main header: %w", err)
+	}
+
+	pkg := &Package{
+		Name:    hdr.str(rpmTagName),
+		Version: hdr.str(rpmTagVersion),
+		Arch:    hdr.str(rpmTagArch),
+	}
+	if release := hdr.str(rpmTagRelease); release != "" {
+		pkg.Version += "-" + release
+	}
+	if epoch, ok := hdr.number(rpmTagEpoch); ok {
+		pkg.Version = fmt.Sprintf("%d:%s", epoch, pkg.Version)
+	}
+
+	payloadOff := mainOff + hdr.size
+	if payloadOff > size {
+		return fmt.Errorf("%w: headers extend past end of file", ErrMalformedRPM)
+	}
+	payload := io.NewSectionReader(r, payloadOff, size-payloadOff)
+
+	var data io.Reader
+	if hdr.str(rpmTagPayloadCompressor) == "lzma" {
+		// Legacy lzma-alone streams have no magic number to sniff.
+		data, err = lzma.NewReader(payload)
+		if err != nil {
+			return fmt.Errorf("payload: lzma: %w", err)
+		}
+	} else {
+		var closeData func()
+		data, closeData, err = decompress(payload)
+		if err != nil {
+			return fmt.Errorf("payload: %w", err)
+		}
+		defer closeData()
+	}
+
+	caps := hdr.fileCaps()
+	return walkCpio(data, func(e Entry) error {
+		e.Package = pkg
+		if c, ok := caps[e.Name]; ok && e.Metadata != nil {
+			e.Metadata.Capabilities = c
+		}
+		return fn(e)
+	})
+}
+
+// fileCaps returns the file capabilities RPMTAG_FILECAPS records, keyed by the path of the file as named in Entry.Name.
+// The payload's cpio headers carry no extended attributes, so rpm sets the capabilities from the header on install.
+// Files without capabilities have an empty string, and malformed ones are left out as for tar.
+func (h *rpmHeader) fileCaps() map[string]*bin.Capabilities {
+	capsText := h.strs(rpmTagFileCaps)
+	if len(capsText) == 0 {
+		return nil
+	}
+	baseNames, dirNames, dirIndexes := h.strs(rpmTagBaseNames), h.strs(rpmTagDirNames), h.numbers(rpmTagDirIndexes)
+	if len(baseNames) != len(capsText) || len(dirIndexes) != len(capsText) {
+		return nil
+	}
+
+	caps := make(map[string]*bin.Capabilities)
+	for i, text := range capsText {
+		if text == "" || int64(dirIndexes[i]) >= int64(len(dirNames)) {
+			continue
+		}
+		if c, err := bin.ParseCapabilitiesText(text); err == nil {
+			caps[cleanName(dirNames[dirIndexes[i]]+baseNames[i])] = c
+		}
+	}
+	return caps
+}
+
+// rpmHeader is a decoded header structure: an index of tagged entries pointing into a data store.
+type rpmHeader struct {
+	index []rpmIndexEntry
+	store []byte
+	// size is the total on-disk size of the header structure, including its intro.
+	size int64
+}
+
+type rpmIndexEntry struct {
+	tag, typ, offset, count uint32
+}
+
+// rpmHeaderSize returns the on-disk size of the header structure at off without reading its data store.
+func rpmHeaderSize(r io.ReaderAt, off, size int64) (int64, error) {
+	nindex, hsize, err := readRPMHeaderIntro(r, off, size)
+	if err != nil {
+		return 0, err
+	}
+	return rpmHeaderIntroSize + int64(nindex)*rpmIndexEntrySize + int64(hsize), nil
+}
+
+// readRPMHeaderIntro decodes the intro of the header structure at off in a file of size bytes.
+// Headers claiming more index entries or data than the file holds are rejected before anything is allocated for them.
+func readRPMHeaderIntro(r io.ReaderAt, off, size int64) (nindex, hsize uint32, err error) {
+	var intro [rpmHeaderIntroSize]byte
+	if _, err := r.ReadAt(intro[:], off); err != nil {
+		return 0, 0, fmt.Errorf("%w: %w", ErrMalformedRPM, err)
+	}
+	if !bytes.HasPrefix(intro[:], rpmHeaderMagic) {
+		return 0, 0, fmt.Errorf("%w: bad header magic", ErrMalformedRPM)
+	}
+	nindex = binary.BigEndian.Uint32(intro[8:12])
+	hsize = binary.BigEndian.Uint32(intro[12:16])
+	if uint64(nindex)*rpmIndexEntrySize > rpmMaxHeaderSize || hsize > rpmMaxHeaderSize {
+		return 0, 0, fmt.Errorf("%w: header too large", ErrMalformedRPM)
+	}
+	if int64(nindex)*rpmIndexEntrySize+int64(hsize) > size-off-rpmHeaderIntroSize {
+		return 0, 0, fmt.Errorf("%w: header extends past end of file", ErrMalformedRPM)
+	}
+	return nindex, hsize, nil
+}
+
+func readRPMHeader(r io.ReaderAt, off, size int64) (*rpmHeader, error) {
+	nindex, hsize, err := readRPMHeaderIntro(r, off, size)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, int64(nindex)*rpmIndexEntrySize+int64(hsize))
+	if _, err := r.ReadAt(buf, off+rpmHeaderIntroSize); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedRPM, err)
+	}
+
+	h := &rpmHeader{
+		index: make([]rpmIndexEntry, nindex),
+		store: buf[nindex*rpmIndexEntrySize:],
+		size:  rpmHeaderIntroSize + int64(len(buf)),
+	}
+	for i := range h.index {
+		e := buf[i*rpmIndexEntrySize:]
+		h.index[i] = rpmIndexEntry{
+			tag:    binary.BigEndian.Uint32(e[0:4]),
+			typ:    binary.BigEndian.Uint32(e[4:8]),
+			offset: binary.BigEndian.Uint32(e[8:12]),
+			count:  binary.BigEndian.Uint32(e[12:16]),
+		}
+	}
+	return h, nil
+}
+
+func (h *rpmHeader) find(tag uint32) (rpmIndexEntry, bool) {
+	for _, e := range h.index {
+		if e.tag == tag {
+			return e, true
+		}
+	}
+	return rpmIndexEntry{}, false
+}
+
+// str returns the value of a STRING tag, or "" when the tag is absent or malformed.
+func (h *rpmHeader) str(tag uint32) string {
+	e, ok := h.find(tag)
+	if !ok || e.typ != rpmTypeString || int64(e.offset) >= int64(len(h.store)) {
+		return ""
+	}
+	s := h.store[e.offset:]
+	if end := bytes.IndexByte(s, 0); end >= 0 {
+		s = s[:end]
+	}
+	return string(s)
+}
+
+// strs returns the values of a STRING_ARRAY tag, or nil when the tag is absent or malformed.
+func (h *rpmHeader) strs(tag uint32) []string {
+	e, ok := h.find(tag)
+	if !ok || e.typ != rpmTypeStringArray || int64(e.offset) >= int64(len(h.store)) || int64(e.count) > int64(len(h.store)) {
+		return nil
+	}
+	values := make([]string, 0, e.count)
+	s := h.store[e.offset:]
+	for range e.count {
+		end := bytes.IndexByte(s, 0)
+		if end < 0 {
+			return nil
+		}
+		values = append(values, string(s[:end]))
+		s = s[end+1:]
+	}
+	return values
+}
+
+// numbers returns the values of an INT32 tag, or nil when the tag is absent or malformed.
+func (h *rpmHeader) numbers(tag uint32) []uint32 {
+	e, ok := h.find(tag)
+	if !ok || e.typ != rpmTypeInt32 || int64(e.offset)+4*int64(e.count) > int64(len(h.store)) {
+		return nil
+	}
+	values := make([]uint32, e.count)
+	for i := range values {
+		values[i] = binary.BigEndian.Uint32(h.store[int(e.offset)+4*i:])
+	}
+	return values
+}
+
+// number returns the first value of an INT32 tag.
+func (h *rpmHeader) number(tag uint32) (uint32, bool) {
+	e, ok := h.find(tag)
+	if !ok || e.typ != rpmTypeInt32 || e.count == 0 || int64(e.offset)+4 > int64(len(h.store)) {
+		return 0, false
+	}
+	return binary.BigEndian.Uint32(h.store[e.offset:]), true
+}
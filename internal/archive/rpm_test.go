@@ -0,0 +1,215 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"maps"
+	"runtime"
+	"testing"
+)
+
+type rpmTag struct {
+	tag   uint32
+	typ   uint32
+	value any
+}
+
+// buildRPMHeader encodes a header structure holding the given STRING, STRING_ARRAY and INT32 tags.
+func buildRPMHeader(tags ...rpmTag) []byte {
+	var index, store bytes.Buffer
+	for _, tag := range tags {
+		var entry [rpmIndexEntrySize]byte
+		binary.BigEndian.PutUint32(entry[0:4], tag.tag)
+		binary.BigEndian.PutUint32(entry[4:8], tag.typ)
+		count := 1
+		switch v := tag.value.(type) {
+		case string:
+			binary.BigEndian.PutUint32(entry[8:12], uint32(store.Len()))
+			store.WriteString(v + "\x00")
+		case []string:
+			binary.BigEndian.PutUint32(entry[8:12], uint32(store.Len()))
+			for _, s := range v {
+				store.WriteString(s + "\x00")
+			}
+			count = len(v)
+		case uint32, []uint32:
+			for store.Len()%4 != 0 {
+				store.WriteByte(0)
+			}
+			binary.BigEndian.PutUint32(entry[8:12], uint32(store.Len()))
+			_ = binary.Write(&store, binary.BigEndian, v)
+			if values, ok := v.([]uint32); ok {
+				count = len(values)
+			}
+		}
+		binary.BigEndian.PutUint32(entry[12:16], uint32(count))
+		index.Write(entry[:])
+	}
+
+	var buf bytes.Buffer
+	buf.Write(rpmHeaderMagic)
+	buf.Write(make([]byte, 4))
+	_ = binary.Write(&buf, binary.BigEndian, uint32(len(tags)))
+	_ = binary.Write(&buf, binary.BigEndian, uint32(store.Len()))
+	buf.Write(index.Bytes())
+	buf.Write(store.Bytes())
+	return buf.Bytes()
+}
+
+func buildRPM(t *testing.T, payload []byte, tags ...rpmTag) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	lead := make([]byte, rpmLeadSize)
+	copy(lead, rpmLeadMagic)
+	buf.Write(lead)
+
+	sig := buildRPMHeader(rpmTag{tag: 1000, typ: rpmTypeString, value: "abc"})
+	buf.Write(sig)
+	for buf.Len()%8 != 0 {
+		buf.WriteByte(0)
+	}
+	buf.Write(buildRPMHeader(tags...))
+	buf.Write(payload)
+	return buf.Bytes()
+}
+
+func TestRPMWalk(t *testing.T) {
+	payload := buildCpio(t,
+		cpioMember{name: "./usr/bin/foo", mode: 0o100755, data: "foo"},
+		cpioMember{name: "./usr/lib64/libfoo.so.1", mode: 0o100755, data: "libfoo"},
+	)
+
+	tests := []struct {
+		name        string
+		payload     []byte
+		tags        []rpmTag
+		wantVersion string
+	}{
+		{
+			name:        "gzip payload",
+			payload:     gzipBytes(t, payload),
+			wantVersion: "1.2-3.fc40",
+		},
+		{
+			name:        "zstd payload with epoch",
+			payload:     zstdBytes(t, payload),
+			tags:        []rpmTag{{tag: rpmTagEpoch, typ: rpmTypeInt32, value: uint32(2)}},
+			wantVersion: "2:1.2-3.fc40",
+		},
+		{
+			name:        "xz payload",
+			payload:     xzBytes(t, payload),
+			tags:        []rpmTag{{tag: rpmTagPayloadCompressor, typ: rpmTypeString, value: "xz"}},
+			wantVersion: "1.2-3.fc40",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags := append([]rpmTag{
+				{tag: rpmTagName, typ: rpmTypeString, value: "foo"},
+				{tag: rpmTagVersion, typ: rpmTypeString, value: "1.2"},
+				{tag: rpmTagRelease, typ: rpmTypeString, value: "3.fc40"},
+				{tag: rpmTagArch, typ: rpmTypeString, value: "x86_64"},
+			}, tt.tags...)
+			rpm := buildRPM(t, tt.payload, tags...)
+
+			format := Detect(bytes.NewReader(rpm))
+			if format == nil || format.Name != "rpm" {
+				t.Fatalf("Detect() = %v, want rpm", format)
+			}
+
+			var names []string
+			err := format.Walk(bytes.NewReader(rpm), int64(len(rpm)), func(e Entry) error {
+				names = append(names, e.Name)
+				want := Package{Name: "foo", Version: tt.wantVersion, Arch: "x86_64"}
+				if e.Package == nil || *e.Package != want {
+					t.Errorf("entry %q package = %+v, want %+v", e.Name, e.Package, want)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Walk() error = %v", err)
+			}
+			if len(names) != 2 || names[0] != "usr/bin/foo" || names[1] != "usr/lib64/libfoo.so.1" {
+				t.Errorf("entries = %v", names)
+			}
+		})
+	}
+}
+
+func TestRPMWalkFileCaps(t *testing.T) {
+	payload := buildCpio(t,
+		cpioMember{name: "./usr/bin/ping", mode: 0o100755, data: "ping"},
+		cpioMember{name: "./usr/bin/su", mode: 0o104755, data: "su"},
+		cpioMember{name: "./usr/sbin/arping", mode: 0o100755, data: "arping"},
+	)
+	rpm := buildRPM(t, gzipBytes(t, payload),
+		rpmTag{tag: rpmTagName, typ: rpmTypeString, value: "iputils"},
+		rpmTag{tag: rpmTagDirIndexes, typ: rpmTypeInt32, value: []uint32{0, 0, 1}},
+		rpmTag{tag: rpmTagBaseNames, typ: rpmTypeStringArray, value: []string{"ping", "su", "arping"}},
+		rpmTag{tag: rpmTagDirNames, typ: rpmTypeStringArray, value: []string{"/usr/bin/", "/usr/sbin/"}},
+		rpmTag{tag: rpmTagFileCaps, typ: rpmTypeStringArray, value: []string{"cap_net_raw=p", "", "cap_net_raw,cap_net_admin=ep"}},
+	)
+
+	want := map[string]string{
+		"usr/bin/ping":    "cap_net_raw=p",
+		"usr/bin/su":      "",
+		"usr/sbin/arping": "cap_net_admin,cap_net_raw=ep",
+	}
+	got := make(map[string]string)
+	err := rpmFormat.Walk(bytes.NewReader(rpm), int64(len(rpm)), func(e Entry) error {
+		if e.Metadata == nil {
+			t.Fatalf("entry %q has no metadata", e.Name)
+		}
+		got[e.Name] = ""
+		if e.Metadata.Capabilities != nil {
+			got[e.Name] = e.Metadata.Capabilities.String()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("capabilities = %v, want %v", got, want)
+	}
+}
+
+func TestRPMWalkBadHeader(t *testing.T) {
+	rpm := make([]byte, rpmLeadSize+rpmHeaderIntroSize)
+	copy(rpm, rpmLeadMagic)
+	err := rpmFormat.Walk(bytes.NewReader(rpm), int64(len(rpm)), func(Entry) error { return nil })
+	if !errors.Is(err, ErrMalformedRPM) {
+		t.Fatalf("Walk() error = %v, want ErrMalformedRPM", err)
+	}
+}
+
+func TestRPMWalkHugeHeader(t *testing.T) {
+	// A truncated package whose main header claims the largest index and data store rpm accepts.
+	var buf bytes.Buffer
+	lead := make([]byte, rpmLeadSize)
+	copy(lead, rpmLeadMagic)
+	buf.Write(lead)
+	buf.Write(buildRPMHeader(rpmTag{tag: 1000, typ: rpmTypeString, value: "abc"}))
+	for buf.Len()%8 != 0 {
+		buf.WriteByte(0)
+	}
+	buf.Write(rpmHeaderMagic)
+	buf.Write(make([]byte, 4))
+	_ = binary.Write(&buf, binary.BigEndian, uint32(rpmMaxHeaderSize/rpmIndexEntrySize))
+	_ = binary.Write(&buf, binary.BigEndian, uint32(rpmMaxHeaderSize))
+	rpm := buf.Bytes()
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	err := rpmFormat.Walk(bytes.NewReader(rpm), int64(len(rpm)), func(Entry) error { return nil })
+	runtime.ReadMemStats(&after)
+	if !errors.Is(err, ErrMalformedRPM) {
+		t.Fatalf("Walk() error = %v, want ErrMalformedRPM", err)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+		t.Errorf("Walk() allocated %d bytes for a %d byte file", allocated, len(rpm))
+	}
+}
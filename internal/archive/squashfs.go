@@ -0,0 +1,630 @@
+package archive
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"github.com/ulikunitz/xz"
+	"github.com/ulikunitz/xz/lzma"
+)
+
+// ErrMalformedSquashfs is returned when a SquashFS superblock, table or inode can't be decoded.
+var ErrMalformedSquashfs = errors.New("malformed squashfs image")
+
+// SquashFS 4.0 on-disk layout: a superblock followed by data blocks, fragments and the metadata tables.
+// Tables are stored as metadata blocks of up to 8 KiB, each prefixed by a 16-bit length whose top bit marks it uncompressed.
+// See https://dr-emann.github.io/squashfs/squashfs.html.
+const (
+	squashfsSuperblockSize = 96
+	squashfsMetadataSize   = 8192
+	squashfsMaxBlockSize   = 1 << 20
+
+	squashfsMetadataUncompressed = 1 << 15
+	squashfsBlockUncompressed    = 1 << 24
+	squashfsNoFragment           = 0xffffffff
+
+	// squashfsFragmentEntrySize is the size of a fragment table entry: a 64-bit start, a 32-bit size and an unused word.
+	squashfsFragmentEntrySize = 16
+	// squashfsIDEntrySize is the size of an ID table entry, a 32-bit user or group ID.
+	squashfsIDEntrySize = 4
+	// squashfsDirHeaderSize and squashfsDirEntrySize are the fixed parts of directory listing headers and entries.
+	squashfsDirHeaderSize = 12
+	squashfsDirEntrySize  = 8
+)
+
+var squashfsMagic = []byte("hsqs")
+
+// Inode types.
+const (
+	squashfsBasicDir  = 1
+	squashfsBasicFile = 2
+	squashfsExtDir    = 8
+	squashfsExtFile   = 9
+)
+
+// Compressor IDs from the superblock.
+const (
+	squashfsGzip = 1
+	squashfsLZMA = 2
+	squashfsLZO  = 3
+	squashfsXZ   = 4
+	squashfsLZ4  = 5
+	squashfsZstd = 6
+)
+
+// squashfsFormat recognizes SquashFS 4.0 images, such as firmware root filesystems and snaps, and walks their regular files
+// with the mode and ownership of their inodes.
+// Images are read in place through their tables, so no mount or extraction is needed.
+var squashfsFormat = Format{
+	Name:  "squashfs",
+	match: matchSquashfs,
+	walk:  walkSquashfs,
+}
+
+func matchSquashfs(_ io.ReaderAt, header []byte) bool {
+	return len(header) >= squashfsSuperblockSize && bytes.HasPrefix(header, squashfsMagic) &&
+		binary.LittleEndian.Uint16(header[28:]) == 4
+}
+
+// squashfsSuperblock holds the superblock fields needed to locate the tables.
+type squashfsSuperblock struct {
+	Magic              uint32
+	InodeCount         uint32
+	ModTime            uint32
+	BlockSize          uint32
+	FragmentCount      uint32
+	Compressor         uint16
+	BlockLog           uint16
+	Flags              uint16
+	IDCount            uint16
+	VersionMajor       uint16
+	VersionMinor       uint16
+	RootInode          uint64
+	BytesUsed          uint64
+	IDTableStart       uint64
+	XattrTableStart    uint64
+	InodeTableStart    uint64
+	DirTableStart      uint64
+	FragmentTableStart uint64
+	ExportTableStart   uint64
+}
+
+// squashfs reads an image through its superblock.
+type squashfs struct {
+	r          io.ReaderAt
+	size       int64
+	sb         squashfsSuperblock
+	decompress func(src []byte, maxSize int) ([]byte, error)
+	close      func()
+	fragments  []squashfsFragment
+	// ids holds the user and group IDs that inodes refer to by index.
+	ids []uint32
+
+	// metadata caches decompressed metadata blocks by image position, as every directory entry rereads the block holding its inode.
+	metadata map[uint64]squashfsMetadataBlock
+
+	// The most recently used fragment block is kept, as consecutive small files usually share one.
+	fragIndex uint32
+	fragData  []byte
+}
+
+type squashfsMetadataBlock struct {
+	data []byte
+	next uint64
+}
+
+// squashfsMetadataCacheSize bounds the number of cached metadata blocks, at most 8 KiB each.
+const squashfsMetadataCacheSize = 256
+
+type squashfsFragment struct {
+	start uint64
+	size  uint32
+}
+
+func walkSquashfs(r io.ReaderAt, size int64, fn WalkFunc) error {
+	fs, err := openSquashfs(io.NewSectionReader(r, 0, size), size)
+	if err != nil {
+		return err
+	}
+	defer fs.close()
+
+	root, err := fs.readInode(fs.sb.RootInode)
+	if err != nil {
+		return fmt.Errorf("root inode: %w", err)
+	}
+	seen := make(map[uint32]bool)
+	return fs.walkDir(root, "", seen, fn)
+}
+
+func openSquashfs(r io.ReaderAt, size int64) (*squashfs, error) {
+	fs := &squashfs{r: r, size: size, metadata: make(map[uint64]squashfsMetadataBlock)}
+	if err := binary.Read(io.NewSectionReader(r, 0, squashfsSuperblockSize), binary.LittleEndian, &fs.sb); err != nil {
+		return nil, fmt.Errorf("%w: superblock: %w", ErrMalformedSquashfs, err)
+	}
+	if fs.sb.VersionMajor != 4 {
+		return nil, fmt.Errorf("%w: unsupported version %d.%d", ErrMalformedSquashfs, fs.sb.VersionMajor, fs.sb.VersionMinor)
+	}
+	if fs.sb.BlockSize == 0 || fs.sb.BlockSize > squashfsMaxBlockSize {
+		return nil, fmt.Errorf("%w: bad block size %d", ErrMalformedSquashfs, fs.sb.BlockSize)
+	}
+
+	var err error
+	if fs.decompress, fs.close, err = squashfsDecompressor(fs.sb.Compressor); err != nil {
+		return nil, err
+	}
+	if err := fs.readFragmentTable(); err != nil {
+		fs.close()
+		return nil, err
+	}
+	if err := fs.readIDTable(); err != nil {
+		fs.close()
+		return nil, err
+	}
+	return fs, nil
+}
+
+// squashfsDecompressor returns the block decompressor for a superblock compressor ID.
+// Decompressed blocks larger than maxSize are rejected.
+func squashfsDecompressor(id uint16) (func(src []byte, maxSize int) ([]byte, error), func(), error) {
+	readAll := func(r io.Reader, maxSize int) ([]byte, error) {
+		data, err := io.ReadAll(io.LimitReader(r, int64(maxSize)+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > maxSize {
+			return nil, fmt.Errorf("%w: block exceeds %d bytes", ErrMalformedSquashfs, maxSize)
+		}
+		return data, nil
+	}
+
+	switch id {
+	case squashfsGzip:
+		return func(src []byte, maxSize int) ([]byte, error) {
+			zr, err := zlib.NewReader(bytes.NewReader(src))
+			if err != nil {
+				return nil, fmt.Errorf("gzip: %w", err)
+			}
+			defer zr.Close()
+			return readAll(zr, maxSize)
+		}, func() {}, nil
+	case squashfsLZMA:
+		return func(src []byte, maxSize int) ([]byte, error) {
+			zr, err := lzma.NewReader(bytes.NewReader(src))
+			if err != nil {
+				return nil, fmt.Errorf("lzma: %w", err)
+			}
+			return readAll(zr, maxSize)
+		}, func() {}, nil
+	case squashfsXZ:
+		return func(src []byte, maxSize int) ([]byte, error) {
+			zr, err := xz.NewReader(bytes.NewReader(src))
+			if err != nil {
+				return nil, fmt.Errorf("xz: %w", err)
+			}
+			return readAll(zr, maxSize)
+		}, func() {}, nil
+	case squashfsLZ4:
+		return func(src []byte, maxSize int) ([]byte, error) {
+			dst := make([]byte, maxSize)
+			n, err := lz4.UncompressBlock(src, dst)
+			if err != nil {
+				return nil, fmt.Errorf("lz4: %w", err)
+			}
+			return dst[:n], nil
+		}, func() {}, nil
+	case squashfsZstd:
+		dec, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(squashfsMaxBlockSize))
+		if err != nil {
+			return nil, nil, fmt.Errorf("zstd: %w", err)
+		}
+		return func(src []byte, maxSize int) ([]byte, error) {
+			data, err := dec.DecodeAll(src, nil)
+			if err != nil {
+				return nil, fmt.Errorf("zstd: %w", err)
+			}
+			if len(data) > maxSize {
+				return nil, fmt.Errorf("%w: block exceeds %d bytes", ErrMalformedSquashfs, maxSize)
+			}
+			return data, nil
+		}, dec.Close, nil
+	case squashfsLZO:
+		return nil, nil, fmt.Errorf("%w: lzo compression is not supported", ErrMalformedSquashfs)
+	default:
+		return nil, nil, fmt.Errorf("%w: unknown compressor %d", ErrMalformedSquashfs, id)
+	}
+}
+
+// readFragmentTable reads the location of every fragment block.
+// The table is stored in metadata blocks whose positions are listed at FragmentTableStart.
+func (fs *squashfs) readFragmentTable() error {
+	if fs.sb.FragmentCount == 0 {
+		return nil
+	}
+	entriesPerBlock := uint32(squashfsMetadataSize / squashfsFragmentEntrySize)
+	blocks := (fs.sb.FragmentCount + entriesPerBlock - 1) / entriesPerBlock
+	if int64(fs.sb.FragmentTableStart) < 0 || int64(fs.sb.FragmentTableStart)+8*int64(blocks) > fs.size { // #nosec G115 -- checked for overflow
+		return fmt.Errorf("%w: fragment table out of range", ErrMalformedSquashfs)
+	}
+	index := make([]byte, 8*int64(blocks))
+	if _, err := fs.r.ReadAt(index, int64(fs.sb.FragmentTableStart)); err != nil { // #nosec G115 -- offsets past the image fail to read
+		return fmt.Errorf("%w: fragment table: %w", ErrMalformedSquashfs, err)
+	}
+
+	for i := range blocks {
+		meta := fs.metadataReader(binary.LittleEndian.Uint64(index[8*i:]), 0)
+		n := min(fs.sb.FragmentCount-i*entriesPerBlock, entriesPerBlock)
+		for range n {
+			var entry [squashfsFragmentEntrySize]byte
+			if _, err := io.ReadFull(meta, entry[:]); err != nil {
+				return fmt.Errorf("%w: fragment table: %w", ErrMalformedSquashfs, err)
+			}
+			fs.fragments = append(fs.fragments, squashfsFragment{
+				start: binary.LittleEndian.Uint64(entry[0:]),
+				size:  binary.LittleEndian.Uint32(entry[8:]),
+			})
+		}
+	}
+	return nil
+}
+
+// readIDTable reads the user and group IDs, stored like the fragment table in metadata blocks listed at IDTableStart.
+func (fs *squashfs) readIDTable() error {
+	if fs.sb.IDCount == 0 {
+		return nil
+	}
+	entriesPerBlock := uint32(squashfsMetadataSize / squashfsIDEntrySize)
+	blocks := (uint32(fs.sb.IDCount) + entriesPerBlock - 1) / entriesPerBlock
+	if int64(fs.sb.IDTableStart) < 0 || int64(fs.sb.IDTableStart)+8*int64(blocks) > fs.size { // #nosec G115 -- checked for overflow
+		return fmt.Errorf("%w: id table out of range", ErrMalformedSquashfs)
+	}
+	index := make([]byte, 8*int64(blocks))
+	if _, err := fs.r.ReadAt(index, int64(fs.sb.IDTableStart)); err != nil { // #nosec G115 -- offsets past the image fail to read
+		return fmt.Errorf("%w: id table: %w", ErrMalformedSquashfs, err)
+	}
+
+	fs.ids = make([]uint32, 0, fs.sb.IDCount)
+	for i := range blocks {
+		meta := fs.metadataReader(binary.LittleEndian.Uint64(index[8*i:]), 0)
+		n := min(uint32(fs.sb.IDCount)-i*entriesPerBlock, entriesPerBlock)
+		for range n {
+			var entry [squashfsIDEntrySize]byte
+			if _, err := io.ReadFull(meta, entry[:]); err != nil {
+				return fmt.Errorf("%w: id table: %w", ErrMalformedSquashfs, err)
+			}
+			fs.ids = append(fs.ids, binary.LittleEndian.Uint32(entry[:]))
+		}
+	}
+	return nil
+}
+
+// metadataReader reads a metadata table sequentially from the block at the absolute position start, skipping offset decompressed bytes.
+func (fs *squashfs) metadataReader(start uint64, offset uint16) *squashfsMetadataReader {
+	return &squashfsMetadataReader{fs: fs, next: start, skip: int(offset)}
+}
+
+type squashfsMetadataReader struct {
+	fs   *squashfs
+	next uint64
+	skip int
+	buf  []byte
+}
+
+func (m *squashfsMetadataReader) Read(p []byte) (int, error) {
+	for len(m.buf) == 0 {
+		if err := m.readBlock(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, m.buf)
+	m.buf = m.buf[n:]
+	return n, nil
+}
+
+func (m *squashfsMetadataReader) readBlock() error {
+	block, err := m.fs.metadataBlock(m.next)
+	if err != nil {
+		return err
+	}
+	if m.skip > len(block.data) {
+		return fmt.Errorf("%w: metadata offset %d out of range", ErrMalformedSquashfs, m.skip)
+	}
+	m.buf = block.data[m.skip:]
+	m.skip = 0
+	m.next = block.next
+	return nil
+}
+
+// metadataBlock returns the decompressed metadata block at pos and the position of the block following it.
+func (fs *squashfs) metadataBlock(pos uint64) (squashfsMetadataBlock, error) {
+	if block, ok := fs.metadata[pos]; ok {
+		return block, nil
+	}
+
+	var hdr [2]byte
+	if _, err := fs.r.ReadAt(hdr[:], int64(pos)); err != nil { // #nosec G115 -- offsets past the image fail to read
+		return squashfsMetadataBlock{}, fmt.Errorf("%w: metadata block: %w", ErrMalformedSquashfs, err)
+	}
+	size := binary.LittleEndian.Uint16(hdr[:])
+	stored := int(size &^ squashfsMetadataUncompressed)
+	if stored == 0 || stored > squashfsMetadataSize {
+		return squashfsMetadataBlock{}, fmt.Errorf("%w: bad metadata block size %d", ErrMalformedSquashfs, stored)
+	}
+	data := make([]byte, stored)
+	if _, err := fs.r.ReadAt(data, int64(pos)+2); err != nil { // #nosec G115 -- offsets past the image fail to read
+		return squashfsMetadataBlock{}, fmt.Errorf("%w: metadata block: %w", ErrMalformedSquashfs, err)
+	}
+	if size&squashfsMetadataUncompressed == 0 {
+		var err error
+		if data, err = fs.decompress(data, squashfsMetadataSize); err != nil {
+			return squashfsMetadataBlock{}, fmt.Errorf("metadata block: %w", err)
+		}
+	}
+
+	block := squashfsMetadataBlock{data: data, next: pos + 2 + uint64(stored)}
+	if len(fs.metadata) >= squashfsMetadataCacheSize {
+		clear(fs.metadata)
+	}
+	fs.metadata[pos] = block
+	return block, nil
+}
+
+// squashfsInode holds the fields of directory and regular file inodes; other types only set the common fields.
+type squashfsInode struct {
+	typ    uint16
+	number uint32
+	// mode holds the permission bits, including setuid, setgid and sticky; uid and gid are resolved through the ID table.
+	mode     uint16
+	uid, gid uint32
+
+	// Directories: the listing's block relative to the directory table, the offset into it and the listing size.
+	dirBlock  uint32
+	dirOffset uint16
+	dirSize   uint32
+
+	// Regular files: the first data block, the file size, the on-disk size of each full block and the tail fragment.
+	blocksStart uint64
+	size        uint64
+	blockSizes  []uint32
+	fragment    uint32
+	fragOffset  uint32
+}
+
+func (i *squashfsInode) isDir() bool  { return i.typ == squashfsBasicDir || i.typ == squashfsExtDir }
+func (i *squashfsInode) isFile() bool { return i.typ == squashfsBasicFile || i.typ == squashfsExtFile }
+
+// readInode reads the inode at ref: the position of its metadata block relative to the inode table in the upper bits
+// and the offset into the decompressed block in the low 16 bits.
+func (fs *squashfs) readInode(ref uint64) (*squashfsInode, error) {
+	meta := fs.metadataReader(fs.sb.InodeTableStart+ref>>16, uint16(ref)) // #nosec G115 -- the low 16 bits are the offset
+	le := binary.LittleEndian
+
+	var common [16]byte
+	if _, err := io.ReadFull(meta, common[:]); err != nil {
+		return nil, fmt.Errorf("%w: inode: %w", ErrMalformedSquashfs, err)
+	}
+	inode := &squashfsInode{typ: le.Uint16(common[0:]), number: le.Uint32(common[12:]), mode: le.Uint16(common[2:])}
+	uidIndex, gidIndex := int(le.Uint16(common[4:])), int(le.Uint16(common[6:]))
+	if uidIndex >= len(fs.ids) || gidIndex >= len(fs.ids) {
+		return nil, fmt.Errorf("%w: inode %d: id index out of range", ErrMalformedSquashfs, inode.number)
+	}
+	inode.uid, inode.gid = fs.ids[uidIndex], fs.ids[gidIndex]
+
+	switch inode.typ {
+	case squashfsBasicDir:
+		var b [16]byte
+		if _, err := io.ReadFull(meta, b[:]); err != nil {
+			return nil, fmt.Errorf("%w: inode: %w", ErrMalformedSquashfs, err)
+		}
+		inode.dirBlock, inode.dirSize, inode.dirOffset = le.Uint32(b[0:]), uint32(le.Uint16(b[8:])), le.Uint16(b[10:])
+	case squashfsExtDir:
+		var b [24]byte
+		if _, err := io.ReadFull(meta, b[:]); err != nil {
+			return nil, fmt.Errorf("%w: inode: %w", ErrMalformedSquashfs, err)
+		}
+		inode.dirSize, inode.dirBlock, inode.dirOffset = le.Uint32(b[4:]), le.Uint32(b[8:]), le.Uint16(b[18:])
+	case squashfsBasicFile:
+		var b [16]byte
+		if _, err := io.ReadFull(meta, b[:]); err != nil {
+			return nil, fmt.Errorf("%w: inode: %w", ErrMalformedSquashfs, err)
+		}
+		inode.blocksStart, inode.fragment, inode.fragOffset, inode.size = uint64(le.Uint32(b[0:])), le.Uint32(b[4:]), le.Uint32(b[8:]), uint64(le.Uint32(b[12:]))
+	case squashfsExtFile:
+		var b [40]byte
+		if _, err := io.ReadFull(meta, b[:]); err != nil {
+			return nil, fmt.Errorf("%w: inode: %w", ErrMalformedSquashfs, err)
+		}
+		inode.blocksStart, inode.size, inode.fragment, inode.fragOffset = le.Uint64(b[0:]), le.Uint64(b[8:]), le.Uint32(b[28:]), le.Uint32(b[32:])
+	default:
+		return inode, nil
+	}
+
+	if inode.isFile() {
+		blocks := inode.size / uint64(fs.sb.BlockSize)
+		if inode.fragment == squashfsNoFragment && inode.size%uint64(fs.sb.BlockSize) != 0 {
+			blocks++
+		}
+		// The block list is read incrementally rather than preallocated from the untrusted size.
+		var b [4]byte
+		for range blocks {
+			if _, err := io.ReadFull(meta, b[:]); err != nil {
+				return nil, fmt.Errorf("%w: block list: %w", ErrMalformedSquashfs, err)
+			}
+			inode.blockSizes = append(inode.blockSizes, le.Uint32(b[:]))
+		}
+	}
+	return inode, nil
+}
+
+// walkDir invokes fn for each regular file below the directory inode dir, whose path is prefix.
+// Inodes are visited once, so hard links are yielded under the first name found and directory loops are not followed.
+func (fs *squashfs) walkDir(dir *squashfsInode, prefix string, seen map[uint32]bool, fn WalkFunc) error {
+	seen[dir.number] = true
+	// The listing size counts the "." and ".." entries, which aren't stored.
+	if dir.dirSize <= 3 {
+		return nil
+	}
+	meta := fs.metadataReader(fs.sb.DirTableStart+uint64(dir.dirBlock), dir.dirOffset)
+	le := binary.LittleEndian
+
+	for remaining := int64(dir.dirSize) - 3; remaining > 0; {
+		var hdr [squashfsDirHeaderSize]byte
+		if _, err := io.ReadFull(meta, hdr[:]); err != nil {
+			return fmt.Errorf("%w: directory %q: %w", ErrMalformedSquashfs, prefix, err)
+		}
+		remaining -= squashfsDirHeaderSize
+		count, inodeBlock := le.Uint32(hdr[0:])+1, le.Uint32(hdr[4:])
+
+		for range count {
+			var e [squashfsDirEntrySize]byte
+			if _, err := io.ReadFull(meta, e[:]); err != nil {
+				return fmt.Errorf("%w: directory %q: %w", ErrMalformedSquashfs, prefix, err)
+			}
+			name := make([]byte, int(le.Uint16(e[6:]))+1)
+			if _, err := io.ReadFull(meta, name); err != nil {
+				return fmt.Errorf("%w: directory %q: %w", ErrMalformedSquashfs, prefix, err)
+			}
+			remaining -= squashfsDirEntrySize + int64(len(name))
+
+			typ := le.Uint16(e[4:])
+			if typ != squashfsBasicDir && typ != squashfsBasicFile {
+				continue
+			}
+			inode, err := fs.readInode(uint64(inodeBlock)<<16 | uint64(le.Uint16(e[0:])))
+			if err != nil {
+				return err
+			}
+			if seen[inode.number] {
+				continue
+			}
+			childPath := cleanName(path.Join(prefix, string(name)))
+
+			switch {
+			case inode.isDir():
+				if err := fs.walkDir(inode, childPath, seen, fn); err != nil {
+					return err
+				}
+			case inode.isFile():
+				seen[inode.number] = true
+				if inode.size == 0 {
+					continue
+				}
+				err := fn(Entry{
+					Name:     childPath,
+					Size:     int64(inode.size), // #nosec G115 -- sizes beyond int64 fail to read anyway
+					Metadata: unixMetadata(int64(inode.mode), int64(inode.uid), int64(inode.gid)),
+					Content:  &squashfsFileReader{fs: fs, inode: inode, pos: inode.blocksStart},
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// squashfsFileReader reads the content of a regular file block by block.
+type squashfsFileReader struct {
+	fs    *squashfs
+	inode *squashfsInode
+	// pos is the image position of the next stored block; read counts the file bytes produced so far.
+	pos   uint64
+	block int
+	read  uint64
+	buf   []byte
+}
+
+func (f *squashfsFileReader) Read(p []byte) (int, error) {
+	for len(f.buf) == 0 {
+		if f.read >= f.inode.size {
+			return 0, io.EOF
+		}
+		if err := f.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, f.buf)
+	f.buf = f.buf[n:]
+	return n, nil
+}
+
+func (f *squashfsFileReader) next() error {
+	blockSize := uint64(f.fs.sb.BlockSize)
+	want := min(blockSize, f.inode.size-f.read)
+
+	var data []byte
+	if f.block < len(f.inode.blockSizes) {
+		stored := f.inode.blockSizes[f.block]
+		f.block++
+		if stored == 0 {
+			// Sparse blocks aren't stored at all.
+			data = make([]byte, want)
+		} else {
+			var err error
+			if data, err = f.fs.readBlock(f.pos, stored, int(blockSize)); err != nil {
+				return err
+			}
+			f.pos += uint64(stored &^ squashfsBlockUncompressed)
+		}
+	} else {
+		frag, err := f.fs.fragmentBlock(f.inode.fragment)
+		if err != nil {
+			return err
+		}
+		end := uint64(f.inode.fragOffset) + want
+		if end > uint64(len(frag)) {
+			return fmt.Errorf("%w: fragment %d too short", ErrMalformedSquashfs, f.inode.fragment)
+		}
+		data = frag[f.inode.fragOffset:end]
+	}
+
+	if uint64(len(data)) < want {
+		return fmt.Errorf("%w: short data block", ErrMalformedSquashfs)
+	}
+	f.buf = data[:want]
+	f.read += want
+	return nil
+}
+
+// readBlock reads the data or fragment block stored at pos, whose size field may carry the uncompressed flag.
+func (fs *squashfs) readBlock(pos uint64, sizeField uint32, maxSize int) ([]byte, error) {
+	stored := sizeField &^ squashfsBlockUncompressed
+	if stored > squashfsMaxBlockSize {
+		return nil, fmt.Errorf("%w: bad block size %d", ErrMalformedSquashfs, stored)
+	}
+	data := make([]byte, stored)
+	if _, err := fs.r.ReadAt(data, int64(pos)); err != nil { // #nosec G115 -- offsets past the image fail to read
+		return nil, fmt.Errorf("%w: data block: %w", ErrMalformedSquashfs, err)
+	}
+	if sizeField&squashfsBlockUncompressed != 0 {
+		return data, nil
+	}
+	data, err := fs.decompress(data, maxSize)
+	if err != nil {
+		return nil, fmt.Errorf("data block: %w", err)
+	}
+	return data, nil
+}
+
+func (fs *squashfs) fragmentBlock(index uint32) ([]byte, error) {
+	if fs.fragData != nil && fs.fragIndex == index {
+		return fs.fragData, nil
+	}
+	if int64(index) >= int64(len(fs.fragments)) {
+		return nil, fmt.Errorf("%w: fragment %d out of range", ErrMalformedSquashfs, index)
+	}
+	frag := fs.fragments[index]
+	data, err := fs.readBlock(frag.start, frag.size, int(fs.sb.BlockSize))
+	if err != nil {
+		return nil, err
+	}
+	fs.fragIndex, fs.fragData = index, data
+	return data, nil
+}
@@ -0,0 +1,405 @@
+package archive
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pierrec/lz4/v4"
+
+	bin "go.kacmar.sk/crack/binary"
+)
+
+const squashfsTestBlockSize = 4096
+
+// squashfsMetaWriter writes a metadata table, splitting it into blocks of squashfsMetadataSize bytes as mksquashfs does.
+type squashfsMetaWriter struct {
+	compress func([]byte) []byte
+	out      []byte
+	pending  []byte
+	starts   []int
+}
+
+// ref returns the reference to the next byte written: the table offset of its block and the offset within it.
+func (w *squashfsMetaWriter) ref() uint64 {
+	return uint64(len(w.out))<<16 | uint64(len(w.pending))
+}
+
+func (w *squashfsMetaWriter) write(b []byte) {
+	w.pending = append(w.pending, b...)
+	for len(w.pending) >= squashfsMetadataSize {
+		w.flush(w.pending[:squashfsMetadataSize])
+		w.pending = w.pending[squashfsMetadataSize:]
+	}
+}
+
+func (w *squashfsMetaWriter) finish() []byte {
+	if len(w.pending) > 0 {
+		w.flush(w.pending)
+		w.pending = nil
+	}
+	return w.out
+}
+
+func (w *squashfsMetaWriter) flush(block []byte) {
+	w.starts = append(w.starts, len(w.out))
+	stored, size := block, uint16(len(block))|squashfsMetadataUncompressed
+	if c := w.compress(block); c != nil && len(c) < len(block) {
+		stored, size = c, uint16(len(c))
+	}
+	w.out = binary.LittleEndian.AppendUint16(w.out, size)
+	w.out = append(w.out, stored...)
+}
+
+// buildSquashfs builds a SquashFS 4.0 image holding entries, compressing blocks with compress where that saves space.
+// File tails are packed into fragments, and all-zero blocks are stored as sparse blocks.
+func buildSquashfs(t *testing.T, compressor uint16, compress func([]byte) []byte, entries ...tarEntry) []byte {
+	t.Helper()
+	le := binary.LittleEndian
+	img := make([]byte, squashfsSuperblockSize)
+
+	writeBlock := func(block []byte) uint32 {
+		if !bytes.ContainsFunc(block, func(r rune) bool { return r != 0 }) && len(block) == squashfsTestBlockSize {
+			return 0
+		}
+		if c := compress(block); c != nil && len(c) < len(block) {
+			img = append(img, c...)
+			return uint32(len(c))
+		}
+		img = append(img, block...)
+		return uint32(len(block)) | squashfsBlockUncompressed
+	}
+
+	type file struct {
+		entry      tarEntry
+		start      uint64
+		size       int
+		blockSizes []uint32
+		fragment   uint32
+		fragOffset uint32
+	}
+	var (
+		files       = make(map[string]*file)
+		children    = make(map[string][]string)
+		fragments   []squashfsFragment
+		fragPending []byte
+	)
+	flushFragment := func() {
+		if len(fragPending) == 0 {
+			return
+		}
+		start := uint64(len(img))
+		fragments = append(fragments, squashfsFragment{start: start, size: writeBlock(fragPending)})
+		fragPending = nil
+	}
+	byName := make(map[string]tarEntry)
+	for _, e := range entries {
+		name := strings.TrimSuffix(e.name, "/")
+		byName[name] = e
+		for p := name; p != "."; p = path.Dir(p) {
+			dir := path.Dir(p)
+			if !slices.Contains(children[dir], p) {
+				children[dir] = append(children[dir], p)
+			}
+		}
+		if e.linkname != "" || strings.HasSuffix(e.name, "/") {
+			continue
+		}
+
+		f := &file{entry: e, start: uint64(len(img)), size: len(e.data), fragment: squashfsNoFragment}
+		data := []byte(e.data)
+		for len(data) >= squashfsTestBlockSize {
+			f.blockSizes = append(f.blockSizes, writeBlock(data[:squashfsTestBlockSize]))
+			data = data[squashfsTestBlockSize:]
+		}
+		if len(data) > 0 {
+			if len(fragPending)+len(data) > squashfsTestBlockSize {
+				flushFragment()
+			}
+			f.fragment, f.fragOffset = uint32(len(fragments)), uint32(len(fragPending))
+			fragPending = append(fragPending, data...)
+		}
+		files[name] = f
+	}
+	flushFragment()
+
+	inodes := &squashfsMetaWriter{compress: compress}
+	dirs := &squashfsMetaWriter{compress: compress}
+	var inodeCount uint32
+	ids := []uint32{0}
+	idIndex := func(id int) uint16 {
+		i := slices.Index(ids, uint32(id))
+		if i < 0 {
+			i = len(ids)
+			ids = append(ids, uint32(id))
+		}
+		return uint16(i)
+	}
+	writeInode := func(typ uint16, body []byte, e tarEntry) (uint64, uint32) {
+		inodeCount++
+		ref := inodes.ref()
+		var common [16]byte
+		le.PutUint16(common[0:], typ)
+		le.PutUint16(common[2:], uint16(e.fileMode()))
+		le.PutUint16(common[4:], idIndex(e.uid))
+		le.PutUint16(common[6:], idIndex(e.gid))
+		le.PutUint32(common[12:], inodeCount)
+		inodes.write(common[:])
+		inodes.write(body)
+		return ref, inodeCount
+	}
+
+	var writeDir func(dir string) uint64
+	writeDir = func(dir string) uint64 {
+		type dirEntry struct {
+			name string
+			ref  uint64
+			typ  uint16
+			num  uint32
+		}
+		var list []dirEntry
+		names := children[dir]
+		slices.Sort(names)
+		for _, child := range names {
+			e, ok := byName[child]
+			f := files[child]
+			var ref uint64
+			var num uint32
+			var typ uint16
+			switch {
+			case f != nil:
+				typ = squashfsBasicFile
+				body := le.AppendUint32(nil, uint32(f.start))
+				body = le.AppendUint32(body, f.fragment)
+				body = le.AppendUint32(body, f.fragOffset)
+				body = le.AppendUint32(body, uint32(f.size))
+				for _, s := range f.blockSizes {
+					body = le.AppendUint32(body, s)
+				}
+				ref, num = writeInode(typ, body, f.entry)
+			case ok && e.linkname != "":
+				typ = 3
+				body := le.AppendUint32(nil, 1)
+				body = le.AppendUint32(body, uint32(len(e.linkname)))
+				ref, num = writeInode(typ, append(body, e.linkname...), tarEntry{})
+			default:
+				typ = squashfsBasicDir
+				ref = writeDir(child)
+				num = inodeCount
+			}
+			list = append(list, dirEntry{name: path.Base(child), ref: ref, typ: typ, num: num})
+		}
+
+		listing := dirs.ref()
+		var size int
+		for len(list) > 0 {
+			n := 1
+			for n < len(list) && n < 256 && list[n].ref>>16 == list[0].ref>>16 {
+				n++
+			}
+			hdr := le.AppendUint32(nil, uint32(n-1))
+			hdr = le.AppendUint32(hdr, uint32(list[0].ref>>16))
+			hdr = le.AppendUint32(hdr, list[0].num)
+			dirs.write(hdr)
+			size += len(hdr)
+			for _, e := range list[:n] {
+				entry := le.AppendUint16(nil, uint16(e.ref))
+				entry = le.AppendUint16(entry, uint16(int16(e.num-list[0].num)))
+				entry = le.AppendUint16(entry, e.typ)
+				entry = le.AppendUint16(entry, uint16(len(e.name)-1))
+				dirs.write(append(entry, e.name...))
+				size += len(entry) + len(e.name)
+			}
+			list = list[n:]
+		}
+
+		body := le.AppendUint32(nil, uint32(listing>>16))
+		body = le.AppendUint32(body, 2)
+		body = le.AppendUint16(body, uint16(size+3))
+		body = le.AppendUint16(body, uint16(listing))
+		body = le.AppendUint32(body, 0)
+		ref, _ := writeInode(squashfsBasicDir, body, tarEntry{})
+		return ref
+	}
+	root := writeDir(".")
+
+	inodeTable := uint64(len(img))
+	img = append(img, inodes.finish()...)
+	dirTable := uint64(len(img))
+	img = append(img, dirs.finish()...)
+
+	fragTable := &squashfsMetaWriter{compress: compress}
+	for _, f := range fragments {
+		entry := le.AppendUint64(nil, f.start)
+		entry = le.AppendUint32(entry, f.size)
+		fragTable.write(le.AppendUint32(entry, 0))
+	}
+	fragStart := uint64(len(img))
+	img = append(img, fragTable.finish()...)
+	fragIndex := uint64(len(img))
+	for _, start := range fragTable.starts {
+		img = le.AppendUint64(img, fragStart+uint64(start))
+	}
+
+	idTable := &squashfsMetaWriter{compress: compress}
+	for _, id := range ids {
+		idTable.write(le.AppendUint32(nil, id))
+	}
+	idStart := uint64(len(img))
+	img = append(img, idTable.finish()...)
+	idIndexStart := uint64(len(img))
+	for _, start := range idTable.starts {
+		img = le.AppendUint64(img, idStart+uint64(start))
+	}
+
+	sb := squashfsSuperblock{
+		Magic:              le.Uint32(squashfsMagic),
+		InodeCount:         inodeCount,
+		BlockSize:          squashfsTestBlockSize,
+		FragmentCount:      uint32(len(fragments)),
+		Compressor:         compressor,
+		BlockLog:           12,
+		IDCount:            uint16(len(ids)),
+		VersionMajor:       4,
+		RootInode:          root,
+		BytesUsed:          uint64(len(img)),
+		IDTableStart:       idIndexStart,
+		XattrTableStart:    ^uint64(0),
+		InodeTableStart:    inodeTable,
+		DirTableStart:      dirTable,
+		FragmentTableStart: fragIndex,
+		ExportTableStart:   ^uint64(0),
+	}
+	var hdr bytes.Buffer
+	if err := binary.Write(&hdr, le, sb); err != nil {
+		t.Fatal(err)
+	}
+	copy(img, hdr.Bytes())
+	return img
+}
+
+func zlibBytes(data []byte) []byte {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	_, _ = w.Write(data)
+	_ = w.Close()
+	return buf.Bytes()
+}
+
+func lz4BlockBytes(data []byte) []byte {
+	dst := make([]byte, lz4.CompressBlockBound(len(data)))
+	n, err := lz4.CompressBlock(data, dst, nil)
+	if err != nil || n == 0 {
+		return nil
+	}
+	return dst[:n]
+}
+
+func TestSquashfsWalk(t *testing.T) {
+	// The executable spans full blocks, one of them sparse, and a tail packed into a fragment.
+	app := strings.Repeat("app", squashfsTestBlockSize) + string(make([]byte, squashfsTestBlockSize)) + "tail"
+	entries := []tarEntry{
+		{name: "bin/sh", data: "sh"},
+		{name: "usr/bin/app", data: app},
+		{name: "etc/link", linkname: "/bin/sh"},
+		{name: "var/empty/"},
+	}
+	want := map[string]string{"bin/sh": "sh", "usr/bin/app": app}
+	// Enough files to spread inodes over several metadata blocks and the listing over several headers.
+	for i := range 300 {
+		name := fmt.Sprintf("usr/lib/many/f%03d", i)
+		entries = append(entries, tarEntry{name: name, data: name})
+		want[name] = name
+	}
+
+	compressors := []struct {
+		name     string
+		id       uint16
+		compress func([]byte) []byte
+	}{
+		{"uncompressed", squashfsGzip, func([]byte) []byte { return nil }},
+		{"gzip", squashfsGzip, zlibBytes},
+		{"xz", squashfsXZ, func(data []byte) []byte { return xzBytes(t, data) }},
+		{"zstd", squashfsZstd, func(data []byte) []byte { return zstdBytes(t, data) }},
+		{"lz4", squashfsLZ4, lz4BlockBytes},
+	}
+	for _, c := range compressors {
+		t.Run(c.name, func(t *testing.T) {
+			img := buildSquashfs(t, c.id, c.compress, entries...)
+
+			format := Detect(bytes.NewReader(img))
+			if format == nil || format.Name != "squashfs" {
+				t.Fatalf("Detect() = %v, want squashfs", format)
+			}
+			got := walkAll(t, format, img)
+			if len(got) != len(want) {
+				t.Fatalf("got %d entries, want %d", len(got), len(want))
+			}
+			for name, content := range want {
+				if got[name] != content {
+					t.Errorf("entry %q = %.20q, want %.20q", name, got[name], content)
+				}
+			}
+		})
+	}
+}
+
+func TestSquashfsWalkMetadata(t *testing.T) {
+	img := buildSquashfs(t, squashfsGzip, zlibBytes,
+		tarEntry{name: "usr/bin/su", data: "su", mode: 0o4755},
+		tarEntry{name: "usr/bin/wall", data: "wall", mode: 0o2755, gid: 5},
+		tarEntry{name: "usr/bin/ls", data: "ls", uid: 1000, gid: 1000},
+	)
+
+	got := make(map[string]bin.FileMetadata)
+	err := squashfsFormat.Walk(bytes.NewReader(img), int64(len(img)), func(e Entry) error {
+		got[e.Name] = *e.Metadata
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+
+	want := map[string]bin.FileMetadata{
+		"usr/bin/su":   {Mode: fs.ModeSetuid | 0o755},
+		"usr/bin/wall": {Mode: fs.ModeSetgid | 0o755, GID: 5},
+		"usr/bin/ls":   {Mode: 0o755, UID: 1000, GID: 1000},
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("metadata = %v, want %v", got, want)
+	}
+}
+
+func TestSquashfsWalkMalformed(t *testing.T) {
+	img := buildSquashfs(t, squashfsGzip, zlibBytes, tarEntry{name: "bin/sh", data: "sh", uid: 1000})
+	lzo := slices.Clone(img)
+	binary.LittleEndian.PutUint16(lzo[20:], squashfsLZO)
+	// The inode refers to the second ID, which the table no longer holds.
+	ids := slices.Clone(img)
+	binary.LittleEndian.PutUint16(ids[26:], 1)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"truncated", img[:len(img)-16]},
+		{"lzo", lzo},
+		{"id index", ids},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := squashfsFormat.Walk(bytes.NewReader(tt.data), int64(len(tt.data)), func(Entry) error { return nil })
+			if !errors.Is(err, ErrMalformedSquashfs) {
+				t.Fatalf("Walk() error = %v, want ErrMalformedSquashfs", err)
+			}
+		})
+	}
+}
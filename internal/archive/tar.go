@@ -0,0 +1,100 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"go.kacmar.sk/crack/binary"
+)
+
+// tarFormat recognizes tar archives, uncompressed or compressed with gzip, xz, zstd, bzip2 or lz4, such as release tarballs.
+// It is the most generic format and must be matched after the formats built on tar.
+var tarFormat = Format{
+	Name:  "tar",
+	match: matchTar,
+	walk:  walkCompressedTar,
+}
+
+// tarMagicOffset is the offset of the "ustar" magic in a POSIX or GNU tar header block.
+const tarMagicOffset = 257
+
+// isTarHeader reports whether block starts with a POSIX or GNU tar header.
+// Pre-POSIX archives without the magic are not recognized.
+func isTarHeader(block []byte) bool {
+	return len(block) >= tarMagicOffset+5 && string(block[tarMagicOffset:tarMagicOffset+5]) == "ustar"
+}
+
+func matchTar(r io.ReaderAt, header []byte) bool {
+	if isTarHeader(header) {
+		return true
+	}
+	// Compressed streams are told apart from other compressed files by decompressing the first header block.
+	return isCompressed(header) && isTarHeader(peekDecompressed(r, 512))
+}
+
+func walkCompressedTar(r io.ReaderAt, size int64, fn WalkFunc) error {
+	data, closeData, err := decompress(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return err
+	}
+	defer closeData()
+	return walkTar(data, fn)
+}
+
+// walkTar invokes fn for each regular file and link in the uncompressed tar stream r.
+// Links are yielded without content, as the content they refer to is yielded once under its own name. Devices and
+// directories are not yielded.
+func walkTar(r io.Reader, fn WalkFunc) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("tar: %w", err)
+		}
+		e, ok := newTarEntry(hdr, tr)
+		if !ok {
+			continue
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+}
+
+// newTarEntry returns the entry for the member described by hdr, whose content tr is positioned at. ok is false for members
+// that aren't yielded.
+func newTarEntry(hdr *tar.Header, tr *tar.Reader) (e Entry, ok bool) {
+	e = Entry{Name: cleanName(hdr.Name), Metadata: tarMetadata(hdr)}
+	switch hdr.Typeflag {
+	case tar.TypeReg:
+		e.Size, e.Content = hdr.Size, tr
+	case tar.TypeLink:
+		e.Link, e.Content = cleanName(hdr.Linkname), bytes.NewReader(nil)
+	case tar.TypeSymlink:
+		e.Link, e.Symlink, e.Content = hdr.Linkname, true, bytes.NewReader(nil)
+	default:
+		return Entry{}, false
+	}
+	return e, true
+}
+
+// tarCapabilityRecord is the PAX record GNU tar and container tools store the security.capability extended attribute in.
+const tarCapabilityRecord = "SCHILY.xattr.security.capability"
+
+// tarMetadata returns the metadata recorded by a tar header, including file capabilities when the archive preserved them.
+// Malformed capabilities are left out, as the kernel would refuse to grant them.
+func tarMetadata(hdr *tar.Header) *binary.FileMetadata {
+	meta := unixMetadata(hdr.Mode, int64(hdr.Uid), int64(hdr.Gid))
+	if record, ok := hdr.PAXRecords[tarCapabilityRecord]; ok {
+		if caps, err := binary.ParseCapabilities([]byte(record)); err == nil {
+			meta.Capabilities = caps
+		}
+	}
+	return meta
+}
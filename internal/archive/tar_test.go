@@ -0,0 +1,127 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/fs"
+	"testing"
+
+	"go.kacmar.sk/crack/binary"
+)
+
+func TestTarWalk(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	headers := []struct {
+		hdr  tar.Header
+		data string
+	}{
+		{tar.Header{Name: "sdk/", Typeflag: tar.TypeDir}, ""},
+		{tar.Header{Name: "sdk/bin/tool", Typeflag: tar.TypeReg, Size: 4}, "tool"},
+		{tar.Header{Name: "sdk/bin/tool-alias", Typeflag: tar.TypeSymlink, Linkname: "tool"}, ""},
+		{tar.Header{Name: "sdk/bin/tool-hard", Typeflag: tar.TypeLink, Linkname: "sdk/bin/tool"}, ""},
+		{tar.Header{Name: "sdk/lib/libsdk.so.1", Typeflag: tar.TypeReg, Size: 6}, "libsdk"},
+		{tar.Header{Name: "sdk/dev/null", Typeflag: tar.TypeChar}, ""},
+	}
+	for _, h := range headers {
+		if err := tw.WriteHeader(&h.hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(h.data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	plain := buf.Bytes()
+
+	compressors := []struct {
+		name     string
+		compress func(t *testing.T, data []byte) []byte
+	}{
+		{"tar", func(_ *testing.T, data []byte) []byte { return data }},
+		{"tar.gz", gzipBytes},
+		{"tar.xz", xzBytes},
+		{"tar.zst", zstdBytes},
+	}
+	for _, c := range compressors {
+		t.Run(c.name, func(t *testing.T) {
+			data := c.compress(t, plain)
+			format := Detect(bytes.NewReader(data))
+			if format == nil || format.Name != "tar" {
+				t.Fatalf("Detect() = %v, want tar", format)
+			}
+
+			got := walkAll(t, format, data)
+			want := map[string]string{
+				"sdk/bin/tool":        "tool",
+				"sdk/bin/tool-alias":  "-> tool",
+				"sdk/bin/tool-hard":   "=> sdk/bin/tool",
+				"sdk/lib/libsdk.so.1": "libsdk",
+			}
+			if len(got) != len(want) {
+				t.Fatalf("entries = %v, want %v", got, want)
+			}
+			for name, content := range want {
+				if got[name] != content {
+					t.Errorf("entry %q = %q, want %q", name, got[name], content)
+				}
+			}
+		})
+	}
+}
+
+func TestTarMetadata(t *testing.T) {
+	// cap_net_raw=ep as written by setcap.
+	netRaw := "\x01\x00\x00\x02\x00\x20\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"
+	tests := []struct {
+		name string
+		hdr  tar.Header
+		want binary.FileMetadata
+	}{
+		{
+			name: "setuid root",
+			hdr:  tar.Header{Mode: 0o4755},
+			want: binary.FileMetadata{Mode: fs.ModeSetuid | 0o755},
+		},
+		{
+			name: "owner and group",
+			hdr:  tar.Header{Mode: 0o2711, Uid: 1000, Gid: 42},
+			want: binary.FileMetadata{Mode: fs.ModeSetgid | 0o711, UID: 1000, GID: 42},
+		},
+		{
+			name: "file capabilities",
+			hdr:  tar.Header{Mode: 0o755, PAXRecords: map[string]string{tarCapabilityRecord: netRaw}},
+			want: binary.FileMetadata{Mode: 0o755, Capabilities: &binary.Capabilities{Permitted: 1 << 13, Effective: true}},
+		},
+		{
+			name: "malformed capabilities",
+			hdr:  tar.Header{Mode: 0o755, PAXRecords: map[string]string{tarCapabilityRecord: "\x01"}},
+			want: binary.FileMetadata{Mode: 0o755},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tarMetadata(&tt.hdr)
+			if got.Mode != tt.want.Mode || got.UID != tt.want.UID || got.GID != tt.want.GID {
+				t.Errorf("tarMetadata() = %+v, want %+v", got, tt.want)
+			}
+			if (got.Capabilities == nil) != (tt.want.Capabilities == nil) ||
+				(got.Capabilities != nil && *got.Capabilities != *tt.want.Capabilities) {
+				t.Errorf("tarMetadata().Capabilities = %v, want %v", got.Capabilities, tt.want.Capabilities)
+			}
+		})
+	}
+}
+
+func TestDetectCompressedNonTar(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"gzip": gzipBytes(t, []byte("\x7fELF not a tarball")),
+		"zstd": zstdBytes(t, []byte("plain text")),
+	} {
+		if f := Detect(bytes.NewReader(data)); f != nil {
+			t.Errorf("Detect(%s) = %s, want nil", name, f.Name)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// zipFormat recognizes ZIP-based containers such as Android APKs and AARs, Python wheels and Java JARs.
+// Members are read through the central directory, so nothing is extracted to disk.
+var zipFormat = Format{
+	Name:  "zip",
+	match: matchZip,
+	walk:  walkZip,
+}
+
+// zipLocalMagic starts the first local file header of a non-empty archive.
+var zipLocalMagic = []byte("PK\x03\x04")
+
+// androidABIs lists the directory names Android uses for native libraries of each ABI, as in lib/<abi>/ of an APK or jni/<abi>/ of an AAR.
+// See https://developer.android.com/ndk/guides/abis.
+var androidABIs = map[string]bool{
+	"armeabi":     true,
+	"armeabi-v7a": true,
+	"arm64-v8a":   true,
+	"x86":         true,
+	"x86_64":      true,
+	"mips":        true,
+	"mips64":      true,
+	"riscv64":     true,
+}
+
+func matchZip(_ io.ReaderAt, header []byte) bool {
+	return bytes.HasPrefix(header, zipLocalMagic)
+}
+
+func walkZip(r io.ReaderAt, size int64, fn WalkFunc) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("zip: %w", err)
+	}
+	for _, f := range zr.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		if err := walkZipFile(f, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkZipFile(f *zip.File, fn WalkFunc) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("zip: %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+
+	name := cleanName(f.Name)
+	return fn(Entry{
+		Name:    name,
+		Size:    int64(f.UncompressedSize64), // #nosec G115 -- sizes beyond int64 fail to read anyway
+		ABI:     zipABI(name),
+		Content: rc,
+	})
+}
+
+// zipABI returns the ABI a member is built for: the Android ABI directory it sits in,
+// or the PEP 3149 tag of a Python extension module name such as "_foo.cpython-312-x86_64-linux-gnu.so".
+// Returns "" when the name carries no ABI.
+func zipABI(name string) string {
+	if abi := path.Base(path.Dir(name)); androidABIs[abi] {
+		return abi
+	}
+	base, ok := strings.CutSuffix(path.Base(name), ".so")
+	if !ok {
+		return ""
+	}
+	if tag := strings.TrimPrefix(path.Ext(base), "."); strings.HasPrefix(tag, "cpython-") || strings.HasPrefix(tag, "pypy") || tag == "abi3" {
+		return tag
+	}
+	return ""
+}
@@ -0,0 +1,89 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/fs"
+	"testing"
+)
+
+func buildZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	if _, err := zw.Create("lib/"); err != nil {
+		t.Fatal(err)
+	}
+	link := &zip.FileHeader{Name: "lib/arm64-v8a/libalias.so"}
+	link.SetMode(fs.ModeSymlink | 0o777)
+	w, err := zw.CreateHeader(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("libfoo.so")); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestZipWalk(t *testing.T) {
+	data := buildZip(t, map[string]string{
+		"AndroidManifest.xml":                                    "manifest",
+		"lib/arm64-v8a/libfoo.so":                                "arm64",
+		"lib/x86_64/libfoo.so":                                   "x86_64",
+		"jni/armeabi-v7a/libbar.so":                              "armv7",
+		"numpy/core/_multiarray.cpython-312-x86_64-linux-gnu.so": "cpython",
+		"pkg/_speedups.abi3.so":                                  "abi3",
+		"../escape.so":                                           "escape",
+	})
+
+	format := Detect(bytes.NewReader(data))
+	if format == nil || format.Name != "zip" {
+		t.Fatalf("Detect() = %v, want zip", format)
+	}
+
+	type entry struct{ data, abi string }
+	got := make(map[string]entry)
+	err := format.Walk(bytes.NewReader(data), int64(len(data)), func(e Entry) error {
+		content, err := io.ReadAll(e.Content)
+		if err != nil {
+			return err
+		}
+		got[e.Name] = entry{string(content), e.ABI}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+
+	want := map[string]entry{
+		"AndroidManifest.xml":                                    {"manifest", ""},
+		"lib/arm64-v8a/libfoo.so":                                {"arm64", "arm64-v8a"},
+		"lib/x86_64/libfoo.so":                                   {"x86_64", "x86_64"},
+		"jni/armeabi-v7a/libbar.so":                              {"armv7", "armeabi-v7a"},
+		"numpy/core/_multiarray.cpython-312-x86_64-linux-gnu.so": {"cpython", "cpython-312-x86_64-linux-gnu"},
+		"pkg/_speedups.abi3.so":                                  {"abi3", "abi3"},
+		"escape.so":                                              {"escape", ""},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("entries = %v, want %v", got, want)
+	}
+	for name, w := range want {
+		if got[name] != w {
+			t.Errorf("entry %q = %+v, want %+v", name, got[name], w)
+		}
+	}
+}
@@ -8,14 +8,18 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"time"
 
+	"go.kacmar.sk/crack/binary"
 	"go.kacmar.sk/crack/internal/analyzer"
 	"go.kacmar.sk/crack/internal/debuginfo"
 	"go.kacmar.sk/crack/internal/output"
 	"go.kacmar.sk/crack/internal/preset"
+	"go.kacmar.sk/crack/internal/resultcache"
 	"go.kacmar.sk/crack/internal/scanner"
 	"go.kacmar.sk/crack/rule"
 	"go.kacmar.sk/crack/rule/registry"
@@ -27,6 +31,12 @@ var errNoPathsSpecified = fmt.Errorf("no paths specified")
 // defaultDebuginfodServer is the public elfutils debuginfod server.
 const defaultDebuginfodServer = "https://debuginfod.elfutils.org"
 
+// defaultArchiveDepth allows for an SDK tarball shipping packages that in turn hold archives.
+const defaultArchiveDepth = 3
+
+// defaultStdinName is the name a binary read from stdin is reported under without --name.
+const defaultStdinName = "stdin"
+
 type outputOptions struct {
 	includePassed  bool
 	includeSkipped bool
@@ -36,10 +46,20 @@ type outputOptions struct {
 
 type analyzeConfig struct {
 	rulesFlag         string
+	presetFlag        string
 	targetPlatform    string
 	targetCompiler    string
 	inputFile         string
+	stdinBinary       bool
+	stdinName         string
+	include           globsFlag
+	exclude           globsFlag
+	pids              string
+	allProcesses      bool
 	recursive         bool
+	watch             bool
+	noArchives        bool
+	archiveDepth      int
 	logFile           string
 	logLevel          string
 	parallel          int
@@ -50,27 +70,46 @@ type analyzeConfig struct {
 	debuginfodRetries int
 	useLocalDebuginfo bool
 	localDebuginfoDir string
+	sysroot           string
+	useCache          bool
+	cacheDir          string
+	maxFileSize       sizeFlag
+	fileTimeout       time.Duration
+	memoryLimit       sizeFlag
 	profile           profileConfig
 }
 
 func (a *App) printAnalyzeUsage(prog string) {
 	fmt.Fprintf(os.Stderr, `Usage: %s analyze [options] [<path>...]
+       %s analyze [options] --pid <pid>[,<pid>...] | --all-processes
+       %s analyze [options] [--name <name>] - | --stdin-binary
 
 Analyze binaries for security hardening features.
 
 Options:
   -i, --input string          Read file paths from file, one path per line (use "-" for stdin, mutually exclusive with positional args)
+      --stdin-binary          Analyze a single binary read from stdin, also selected by "-" as the only path
+      --name string           Name to report the binary read from stdin under (default "stdin")
   -p, --parallel int          Number of files to analyze in parallel (default %d)
   -r, --recursive             Recursively scan directories
+      --watch                 Keep analyzing files as they are written, printing only changed findings (Linux only)
+      --archive-depth int     Maximum depth of nested archives and packages to descend into (default %d)
+      --no-archives           Analyze archives and packages as plain files instead of descending into them
+      --include glob          Only scan files matching this pattern in directories, repeatable
+      --exclude glob          Skip files and directories matching this pattern in directories, repeatable
+      --pid string            Analyze the executables and libraries mapped by these comma-separated process IDs (Linux only)
+      --all-processes         Analyze the executables and libraries mapped by all running processes (Linux only)
+      --sysroot string        Root filesystem of the analyzed target, where files the binaries refer to are looked up (requires --local-debuginfo)
 
-`, prog, runtime.NumCPU())
+`, prog, prog, prog, runtime.NumCPU(), defaultArchiveDepth)
 
 	fmt.Fprintf(os.Stderr, `Rule selection:
       --rules string              Comma-separated list of rule IDs to run
+      --preset string             Run the rules of a preset instead of the default set: %s
       --target-compiler string    Only run rules available for these compilers: %s
       --target-platform string    Only run rules available for these platforms: %s
 
-`, strings.Join(validCompilerNames(), ", "), strings.Join(validArchitectureNames(), ", "))
+`, strings.Join(preset.Names(), ", "), strings.Join(validCompilerNames(), ", "), strings.Join(validArchitectureNames(), ", "))
 
 	fmt.Fprint(os.Stderr, `Output options:
       --exit-zero             Exit with 0 even when findings are detected
@@ -99,27 +138,56 @@ Logging options:
 	fmt.Fprintf(os.Stderr, `
 Local debuginfo:
       --local-debuginfo                Resolve missing sections from a local build-id-indexed debug directory
-      --local-debuginfo-dir string     Root directory of the local debuginfo store (default %q)
+      --local-debuginfo-dir string     Root directory of the local debuginfo store (default %q, inside --sysroot when set)
 `, debuginfo.DefaultBuildIDDir)
 
+	fmt.Fprintf(os.Stderr, `
+Result cache:
+      --cache                 Reuse the results of files analyzed by earlier scans with the same rules and settings
+      --cache-dir string      Result cache directory (default "%s")
+`, defaultResultCacheDir())
+
+	fmt.Fprint(os.Stderr, `
+Resource limits:
+      --max-file-size size        Report binaries larger than this as exceeding a limit instead of analyzing them, e.g. 512M
+      --file-timeout duration     Give up on binaries whose analysis takes longer than this, e.g. 30s
+      --memory-limit size         Cap the section and segment data held by all binaries being analyzed, e.g. 2G
+`)
+
 	if usage := profileUsage(); usage != "" {
 		fmt.Fprint(os.Stderr, usage)
 	}
 }
 
-func parseRules(rulesFlag, targetPlatform, targetCompiler string) ([]rule.ELFRule, error) {
-	var selectedRules []rule.ELFRule
-	if rulesFlag != "" {
+// memoryBudget returns the budget for --memory-limit, or nil when it is unset.
+func memoryBudget(limit sizeFlag) *binary.MemoryBudget {
+	if limit == 0 {
+		return nil
+	}
+	return binary.NewMemoryBudget(uint64(limit))
+}
+
+func parseRules(rulesFlag, presetFlag, targetPlatform, targetCompiler string) ([]rule.Rule, error) {
+	var selectedRules []rule.Rule
+	switch {
+	case rulesFlag != "" && presetFlag != "":
+		return nil, fmt.Errorf("--rules and --preset are mutually exclusive")
+	case presetFlag != "":
+		var ok bool
+		if selectedRules, ok = preset.Find(presetFlag); !ok {
+			return nil, fmt.Errorf("unknown preset %q", presetFlag)
+		}
+	case rulesFlag != "":
 		ids := strings.Split(rulesFlag, ",")
 		for _, id := range ids {
 			id = strings.TrimSpace(id)
-			r, ok := registry.Find[rule.ELFRule](registry.ByID(id))
+			r, ok := registry.Find[rule.Rule](registry.ByID(id))
 			if !ok {
 				return nil, fmt.Errorf("unknown rule %q", id)
 			}
 			selectedRules = append(selectedRules, r)
 		}
-	} else {
+	default:
 		selectedRules = preset.Default()
 	}
 
@@ -137,6 +205,17 @@ func parseRules(rulesFlag, targetPlatform, targetCompiler string) ([]rule.ELFRul
 	return selectedRules, nil
 }
 
+// rulesOf returns the rules of the family T, such as the rules for ELF binaries, keeping their order.
+func rulesOf[T rule.Rule](rules []rule.Rule) []T {
+	var result []T
+	for _, r := range rules {
+		if typed, ok := r.(T); ok {
+			result = append(result, typed)
+		}
+	}
+	return result
+}
+
 func parsePaths(fs *flag.FlagSet, inputFile string) ([]string, error) {
 	if fs.NArg() == 0 && inputFile == "" {
 		return nil, errNoPathsSpecified
@@ -178,19 +257,69 @@ func (a *App) runAnalyze(prog string, args []string) int {
 		defer stopProfile()
 	}
 
-	selectedRules, err := parseRules(cfg.rulesFlag, cfg.targetPlatform, cfg.targetCompiler)
+	selectedRules, err := parseRules(cfg.rulesFlag, cfg.presetFlag, cfg.targetPlatform, cfg.targetCompiler)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return ExitError
 	}
 
-	paths, err := parsePaths(fs, cfg.inputFile)
-	if err != nil {
-		if errors.Is(err, errNoPathsSpecified) {
-			fs.Usage()
-		} else {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	var paths []string
+	var pids []int
+	stdin := cfg.stdinBinary || slices.Contains(fs.Args(), "-")
+	switch {
+	case stdin:
+		if fs.NArg() > 1 || (cfg.stdinBinary && fs.NArg() > 0) || cfg.inputFile != "" || cfg.pids != "" || cfg.allProcesses {
+			fmt.Fprintf(os.Stderr, "Error: reading a binary from stdin can't be combined with paths, --input, --pid or --all-processes\n")
+			return ExitError
 		}
+	case cfg.stdinName != "":
+		fmt.Fprintf(os.Stderr, "Error: --name requires --stdin-binary or \"-\"\n")
+		return ExitError
+	case cfg.pids != "" || cfg.allProcesses:
+		if cfg.pids != "" && cfg.allProcesses {
+			fmt.Fprintf(os.Stderr, "Error: --pid and --all-processes are mutually exclusive\n")
+			return ExitError
+		}
+		if fs.NArg() > 0 || cfg.inputFile != "" {
+			fmt.Fprintf(os.Stderr, "Error: --pid and --all-processes can't be combined with paths or --input\n")
+			return ExitError
+		}
+		if cfg.pids != "" {
+			if pids, err = parsePIDs(cfg.pids); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: --pid: %v\n", err)
+				return ExitError
+			}
+		}
+	default:
+		if paths, err = parsePaths(fs, cfg.inputFile); err != nil {
+			if errors.Is(err, errNoPathsSpecified) {
+				fs.Usage()
+			} else {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+			return ExitError
+		}
+	}
+
+	if cfg.watch && (paths == nil || opts.sarifOutput != "") {
+		fmt.Fprintf(os.Stderr, "Error: --watch requires paths and can't be combined with --sarif\n")
+		return ExitError
+	}
+
+	if cfg.sysroot != "" {
+		// Separate debug files are the only files read from the target's root, so the sysroot would go unused.
+		if !cfg.useLocalDebuginfo {
+			fmt.Fprintf(os.Stderr, "Error: --sysroot requires --local-debuginfo\n")
+			return ExitError
+		}
+		if info, err := os.Stat(cfg.sysroot); err != nil || !info.IsDir() {
+			fmt.Fprintf(os.Stderr, "Error: --sysroot must be a directory: %s\n", cfg.sysroot)
+			return ExitError
+		}
+	}
+
+	if cfg.fileTimeout < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --file-timeout can't be negative\n")
 		return ExitError
 	}
 
@@ -199,6 +328,15 @@ func (a *App) runAnalyze(prog string, args []string) int {
 		return ExitError
 	}
 
+	if cfg.archiveDepth < 1 {
+		fmt.Fprintf(os.Stderr, "Error: --archive-depth must be at least 1\n")
+		return ExitError
+	}
+	archiveDepth := cfg.archiveDepth
+	if cfg.noArchives {
+		archiveDepth = 0
+	}
+
 	closeLog, err := a.setupLogging(cfg.logFile, cfg.logLevel)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -212,26 +350,72 @@ func (a *App) runAnalyze(prog string, args []string) int {
 		return ExitError
 	}
 
+	memory := memoryBudget(cfg.memoryLimit)
 	elfAnalyzer := analyzer.NewELFAnalyzer(analyzer.ELFAnalyzerOptions{
-		Rules:   selectedRules,
+		Rules:   rulesOf[rule.ELFRule](selectedRules),
 		Sources: a.buildDebuginfoSources(cfg, debuginfodCache),
+		Memory:  memory,
 		Logger:  a.logger,
 	})
+	peAnalyzer := analyzer.NewPEAnalyzer(analyzer.PEAnalyzerOptions{
+		Rules:  rulesOf[rule.PERule](selectedRules),
+		Memory: memory,
+		Logger: a.logger,
+	})
+	machoAnalyzer := analyzer.NewMachOAnalyzer(analyzer.MachOAnalyzerOptions{
+		Rules:  rulesOf[rule.MachORule](selectedRules),
+		Memory: memory,
+		Logger: a.logger,
+	})
 
 	dispatcher := analyzer.NewDispatcher(analyzer.DispatcherOptions{
-		ELF:    elfAnalyzer,
-		Logger: a.logger,
+		Analyzers: []analyzer.FormatAnalyzer{elfAnalyzer, peAnalyzer, machoAnalyzer},
+		Logger:    a.logger,
 	})
 
+	resultCache, err := a.setupResultCache(cfg, selectedRules, archiveDepth)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return ExitError
+	}
+
 	scan := scanner.NewScanner(dispatcher, scanner.Options{
-		Logger:  a.logger,
-		Workers: cfg.parallel,
+		Logger:       a.logger,
+		Workers:      cfg.parallel,
+		ArchiveDepth: archiveDepth,
+		Include:      cfg.include,
+		Exclude:      cfg.exclude,
+		Cache:        resultCache,
+		MaxFileSize:  int64(cfg.maxFileSize),
+		FileTimeout:  cfg.fileTimeout,
 	})
 
 	ctx := cancelOnSignal(context.Background())
-	a.logger.Info("starting scan", slog.Int("paths", len(paths)), slog.Bool("recursive", cfg.recursive))
+	var written <-chan analyzer.FileResult
+	if cfg.watch {
+		// Watching starts before the initial scan, so that no file written in between is missed.
+		if written, err = scan.Watch(ctx, paths, cfg.recursive); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return ExitError
+		}
+	}
 
-	resultsChan := scan.ScanPaths(ctx, paths, cfg.recursive)
+	var resultsChan <-chan analyzer.FileResult
+	switch {
+	case stdin:
+		name := cfg.stdinName
+		if name == "" {
+			name = defaultStdinName
+		}
+		a.logger.Info("starting stdin scan", slog.String("name", name))
+		resultsChan = scan.ScanReader(ctx, os.Stdin, name)
+	case pids != nil || cfg.allProcesses:
+		a.logger.Info("starting process scan", slog.Int("pids", len(pids)), slog.Bool("all", cfg.allProcesses))
+		resultsChan = scan.ScanProcesses(ctx, pids, cfg.allProcesses)
+	default:
+		a.logger.Info("starting scan", slog.Int("paths", len(paths)), slog.Bool("recursive", cfg.recursive))
+		resultsChan = scan.ScanPaths(ctx, paths, cfg.recursive)
+	}
 
 	invocation := &output.InvocationInfo{
 		CommandLine: strings.Join(append([]string{prog}, args...), " "),
@@ -240,10 +424,14 @@ func (a *App) runAnalyze(prog string, args []string) int {
 		WorkingDir:  workingDir,
 	}
 
+	progress := startProgress(scan.Progress)
+	if written != nil {
+		return a.processWatch(resultsChan, written, opts, progress, scan.FilterStats)
+	}
 	if opts.sarifOutput != "" {
-		return a.processFullReport(resultsChan, opts, invocation)
+		return a.processFullReport(resultsChan, opts, invocation, progress, scan.FilterStats)
 	}
-	return a.processStreaming(resultsChan, opts)
+	return a.processStreaming(resultsChan, opts, progress, scan.FilterStats)
 }
 
 func (a *App) setupAnalyzeFlags(prog string) (*flag.FlagSet, *outputOptions, *analyzeConfig) {
@@ -252,11 +440,21 @@ func (a *App) setupAnalyzeFlags(prog string) (*flag.FlagSet, *outputOptions, *an
 	cfg := &analyzeConfig{}
 
 	fs.StringVar(&cfg.rulesFlag, "rules", "", "")
+	fs.StringVar(&cfg.presetFlag, "preset", "", "")
 	fs.StringVar(&cfg.targetPlatform, "target-platform", "", "")
 	fs.StringVar(&cfg.targetCompiler, "target-compiler", "", "")
 	fs.StringVar(&cfg.inputFile, "input", "", "")
+	fs.BoolVar(&cfg.stdinBinary, "stdin-binary", false, "")
+	fs.StringVar(&cfg.stdinName, "name", "", "")
 	fs.StringVar(&opts.sarifOutput, "sarif", "", "")
+	fs.Var(&cfg.include, "include", "")
+	fs.Var(&cfg.exclude, "exclude", "")
+	fs.StringVar(&cfg.pids, "pid", "", "")
+	fs.BoolVar(&cfg.allProcesses, "all-processes", false, "")
 	fs.BoolVar(&cfg.recursive, "recursive", false, "")
+	fs.BoolVar(&cfg.watch, "watch", false, "")
+	fs.BoolVar(&cfg.noArchives, "no-archives", false, "")
+	fs.IntVar(&cfg.archiveDepth, "archive-depth", defaultArchiveDepth, "")
 	fs.StringVar(&cfg.logFile, "log", "", "")
 	fs.StringVar(&cfg.logLevel, "log-level", "error", "")
 	fs.BoolVar(&opts.includePassed, "include-passed", false, "")
@@ -270,6 +468,12 @@ func (a *App) setupAnalyzeFlags(prog string) (*flag.FlagSet, *outputOptions, *an
 	fs.IntVar(&cfg.debuginfodRetries, "debuginfod-retries", debuginfo.DefaultMaxRetries, "")
 	fs.BoolVar(&cfg.useLocalDebuginfo, "local-debuginfo", false, "")
 	fs.StringVar(&cfg.localDebuginfoDir, "local-debuginfo-dir", "", "")
+	fs.StringVar(&cfg.sysroot, "sysroot", "", "")
+	fs.BoolVar(&cfg.useCache, "cache", false, "")
+	fs.StringVar(&cfg.cacheDir, "cache-dir", "", "")
+	fs.Var(&cfg.maxFileSize, "max-file-size", "")
+	fs.DurationVar(&cfg.fileTimeout, "file-timeout", 0, "")
+	fs.Var(&cfg.memoryLimit, "memory-limit", "")
 	registerProfileFlags(fs, &cfg.profile)
 
 	fs.Usage = func() { a.printAnalyzeUsage(prog) }
@@ -313,15 +517,48 @@ func (a *App) setupDebuginfod(cfg *analyzeConfig) (*cache.DiskCache, error) {
 	})
 }
 
+// setupResultCache opens the result cache for the selected rules and the settings that change what analysis finds,
+// or returns nil when caching isn't enabled.
+func (a *App) setupResultCache(cfg *analyzeConfig, selectedRules []rule.Rule, archiveDepth int) (*resultcache.Cache, error) {
+	if !cfg.useCache {
+		return nil, nil
+	}
+	ruleIDs := make([]string, len(selectedRules))
+	for i, r := range selectedRules {
+		ruleIDs[i] = r.ID()
+	}
+	settings := []string{fmt.Sprintf("archive-depth=%d", archiveDepth)}
+	if cfg.useLocalDebuginfo {
+		settings = append(settings, "local-debuginfo="+cfg.buildIDDir())
+	}
+	if cfg.useDebuginfod {
+		settings = append(settings, "debuginfod="+cfg.debuginfodServers)
+	}
+	return resultcache.Open(resultcache.Options{
+		Dir:      cfg.cacheDir,
+		RuleIDs:  ruleIDs,
+		Settings: settings,
+		Logger:   a.logger,
+	})
+}
+
+// buildIDDir returns the local debuginfo directory: the one given, or the default re-rooted under the sysroot of the target.
+func (cfg *analyzeConfig) buildIDDir() string {
+	switch {
+	case cfg.localDebuginfoDir != "":
+		return cfg.localDebuginfoDir
+	case cfg.sysroot != "":
+		return filepath.Join(cfg.sysroot, debuginfo.DefaultBuildIDDir)
+	default:
+		return debuginfo.DefaultBuildIDDir
+	}
+}
+
 // buildDebuginfoSources assembles the configured debug-information sources in priority order.
-func (a *App) buildDebuginfoSources(cfg *analyzeConfig, debuginfodCache *cache.DiskCache) []debuginfo.Source {
-	var sources []debuginfo.Source
+func (a *App) buildDebuginfoSources(cfg *analyzeConfig, debuginfodCache *cache.DiskCache) []analyzer.SectionSource {
+	var sources []analyzer.SectionSource
 	if cfg.useLocalDebuginfo {
-		root := cfg.localDebuginfoDir
-		if root == "" {
-			root = debuginfo.DefaultBuildIDDir
-		}
-		sources = append(sources, debuginfo.NewBuildIDDirSource(root, a.logger))
+		sources = append(sources, debuginfo.NewBuildIDDirSource(cfg.buildIDDir(), a.logger))
 	}
 	if debuginfodCache != nil {
 		sources = append(sources, debuginfo.NewDebuginfodSource(debuginfodCache, a.logger))
@@ -0,0 +1,23 @@
+package cli
+
+import "testing"
+
+func TestBuildIDDir(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  analyzeConfig
+		want string
+	}{
+		{name: "default", want: "/usr/lib/debug"},
+		{name: "sysroot", cfg: analyzeConfig{sysroot: "/srv/rootfs-arm64"}, want: "/srv/rootfs-arm64/usr/lib/debug"},
+		{name: "explicit", cfg: analyzeConfig{localDebuginfoDir: "/opt/debug"}, want: "/opt/debug"},
+		{name: "explicit with sysroot", cfg: analyzeConfig{localDebuginfoDir: "/opt/debug", sysroot: "/srv/rootfs-arm64"}, want: "/opt/debug"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.buildIDDir(); got != tt.want {
+				t.Errorf("buildIDDir() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"go.kacmar.sk/crack/internal/resultcache"
+)
+
+// defaultPruneAge is how long a result cache entry may go unused before prune removes it.
+const defaultPruneAge = 30 * 24 * time.Hour
+
+func defaultResultCacheDir() string {
+	dir, err := resultcache.DefaultDir()
+	if err != nil {
+		return "(unavailable)"
+	}
+	return dir
+}
+
+func (a *App) printCacheUsage(prog string) {
+	fmt.Fprintf(os.Stderr, `Usage: %s cache prune [options]
+
+Remove result cache entries not used by recent scans. Entries of other crack versions, rule selections, or settings
+are never used again, so they are removed once they reach the maximum age.
+
+Options:
+      --all                   Remove all entries regardless of age
+      --cache-dir string      Result cache directory (default "%s")
+      --max-age duration      Remove entries not used for this long (default %v)
+`, prog, defaultResultCacheDir(), defaultPruneAge)
+}
+
+func (a *App) runCache(prog string, args []string) int {
+	if len(args) == 0 {
+		a.printCacheUsage(prog)
+		return ExitError
+	}
+	switch args[0] {
+	case "prune":
+	case "help", "-h", "--help":
+		a.printCacheUsage(prog)
+		return ExitSuccess
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown cache command: %s\n\n", args[0])
+		a.printCacheUsage(prog)
+		return ExitError
+	}
+
+	fs := flag.NewFlagSet("cache prune", flag.ExitOnError)
+	all := fs.Bool("all", false, "")
+	dir := fs.String("cache-dir", "", "")
+	maxAge := fs.Duration("max-age", defaultPruneAge, "")
+	fs.Usage = func() { a.printCacheUsage(prog) }
+	if err := fs.Parse(args[1:]); err != nil {
+		return ExitError
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: unexpected arguments: %v\n", fs.Args())
+		return ExitError
+	}
+
+	root := *dir
+	if root == "" {
+		var err error
+		if root, err = resultcache.DefaultDir(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return ExitError
+		}
+	}
+	var before time.Time
+	if !*all {
+		before = time.Now().Add(-*maxAge)
+	}
+
+	stats, err := resultcache.Prune(root, before)
+	fmt.Printf("Removed %d entries (%.1f MiB) from %s\n", stats.Entries, float64(stats.Bytes)/(1<<20), root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return ExitError
+	}
+	return ExitSuccess
+}
@@ -32,6 +32,8 @@ func (a *App) Run(args []string) int {
 	switch cmd {
 	case "analyze":
 		return a.runAnalyze(args[0], args[2:])
+	case "cache":
+		return a.runCache(args[0], args[2:])
 	case "version", "-v", "--version":
 		a.printVersion()
 		return ExitSuccess
@@ -62,6 +64,7 @@ Usage: %s <command> [options]
 
 Commands:
   analyze      Analyze binaries for security hardening features
+  cache        Manage the result cache
   version      Show version information
   help         Show this help message
 
@@ -3,7 +3,10 @@ package cli
 import (
 	"bufio"
 	"fmt"
+	"math"
 	"os"
+	"path"
+	"strconv"
 	"strings"
 )
 
@@ -32,3 +35,73 @@ func readPathsFromInput(input string) ([]string, error) {
 	}
 	return paths, nil
 }
+
+// parsePIDs parses the comma-separated process IDs of --pid.
+func parsePIDs(s string) ([]int, error) {
+	var pids []int
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		pid, err := strconv.Atoi(field)
+		if err != nil || pid < 1 {
+			return nil, fmt.Errorf("invalid PID %q", field)
+		}
+		pids = append(pids, pid)
+	}
+	if len(pids) == 0 {
+		return nil, fmt.Errorf("no PIDs specified")
+	}
+	return pids, nil
+}
+
+// globsFlag collects the patterns of a repeatable glob flag such as --include.
+type globsFlag []string
+
+func (g *globsFlag) String() string {
+	return strings.Join(*g, ",")
+}
+
+func (g *globsFlag) Set(pattern string) error {
+	if _, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	*g = append(*g, pattern)
+	return nil
+}
+
+// sizeUnits are the suffixes accepted by sizeFlag, in binary multiples.
+var sizeUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"G", 1 << 30},
+	{"M", 1 << 20},
+	{"K", 1 << 10},
+}
+
+// sizeFlag is a size in bytes given as an integer with an optional K, M, or G suffix, e.g. "512M". Zero means no limit.
+type sizeFlag int64
+
+func (s *sizeFlag) String() string {
+	return strconv.FormatInt(int64(*s), 10)
+}
+
+func (s *sizeFlag) Set(value string) error {
+	// Accept "512M", "512MB", and "512MiB" alike.
+	number := strings.TrimSuffix(strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(value)), "B"), "I")
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if rest, ok := strings.CutSuffix(number, unit.suffix); ok {
+			number, multiplier = rest, unit.multiplier
+			break
+		}
+	}
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n < 0 || n > math.MaxInt64/multiplier {
+		return fmt.Errorf("invalid size %q, want bytes with an optional K, M, or G suffix", value)
+	}
+	*s = sizeFlag(n * multiplier)
+	return nil
+}
@@ -3,6 +3,7 @@ package cli
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 )
 
@@ -82,3 +83,73 @@ func TestReadPathsFromInput(t *testing.T) {
 		}
 	})
 }
+
+func TestParsePIDs(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  []int
+		wantError bool
+	}{
+		{input: "1234", expected: []int{1234}},
+		{input: "1, 42,", expected: []int{1, 42}},
+		{input: "", wantError: true},
+		{input: "0", wantError: true},
+		{input: "nginx", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			pids, err := parsePIDs(tt.input)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("parsePIDs(%q) = %v, want error", tt.input, pids)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parsePIDs(%q) error = %v", tt.input, err)
+			}
+			if !slices.Equal(pids, tt.expected) {
+				t.Errorf("parsePIDs(%q) = %v, want %v", tt.input, pids, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSizeFlag(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  int64
+		wantError bool
+	}{
+		{input: "0", expected: 0},
+		{input: "1048576", expected: 1 << 20},
+		{input: "512M", expected: 512 << 20},
+		{input: "2G", expected: 2 << 30},
+		{input: "64KiB", expected: 64 << 10},
+		{input: "1gb", expected: 1 << 30},
+		{input: "", wantError: true},
+		{input: "-1", wantError: true},
+		{input: "1T", wantError: true},
+		{input: "9999999999G", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var size sizeFlag
+			err := size.Set(tt.input)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("Set(%q) = %d, want error", tt.input, size)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Set(%q) error = %v", tt.input, err)
+			}
+			if int64(size) != tt.expected {
+				t.Errorf("Set(%q) = %d, want %d", tt.input, size, tt.expected)
+			}
+		})
+	}
+}
@@ -1,23 +1,35 @@
 package cli
 
 import (
+	"fmt"
 	"log/slog"
 	"os"
 	"time"
 
 	"go.kacmar.sk/crack/internal/analyzer"
 	"go.kacmar.sk/crack/internal/output"
+	"go.kacmar.sk/crack/internal/scanner"
 	"go.kacmar.sk/crack/internal/suggestions"
 )
 
-func (a *App) processFullReport(resultsChan <-chan analyzer.FileResult, opts *outputOptions, invocation *output.InvocationInfo) int {
+func (a *App) processFullReport(resultsChan <-chan analyzer.FileResult, opts *outputOptions, invocation *output.InvocationInfo, progress *progressLine, filterStats func() scanner.FilterStats) int {
 	var results []analyzer.FileResult
 	var hasFindings, hasErrors bool
+	// reported indexes results by path and slice, to merge the aliases of follow-up records into them.
+	reported := make(map[[2]string]int)
 
 	for res := range resultsChan {
 		if res.Skipped {
 			continue
 		}
+		key := [2]string{res.Path, res.Slice}
+		if res.LateAliases {
+			if i, ok := reported[key]; ok {
+				results[i].Aliases = append(results[i].Aliases, res.Aliases...)
+			}
+			continue
+		}
+		reported[key] = len(results)
 		results = append(results, res)
 		if res.FailedRules() > 0 {
 			hasFindings = true
@@ -26,6 +38,10 @@ func (a *App) processFullReport(resultsChan <-chan analyzer.FileResult, opts *ou
 			hasErrors = true
 		}
 	}
+	progress.Stop()
+
+	stats := filterStats()
+	a.reportFiltered(stats)
 
 	report := decorateReport(results)
 
@@ -38,6 +54,13 @@ func (a *App) processFullReport(resultsChan <-chan analyzer.FileResult, opts *ou
 	if opts.sarifOutput != "" {
 		invocation.EndTime = time.Now()
 		invocation.Successful = !hasErrors
+		if stats.Total() > 0 {
+			invocation.FilteredFiles = map[string]int64{
+				"excluded":  stats.Excluded,
+				"ignored":   stats.Ignored,
+				"notBinary": stats.NotBinary,
+			}
+		}
 
 		sarifFormatter := &output.SARIFFormatter{
 			IncludePassed:  opts.includePassed,
@@ -60,7 +83,7 @@ func (a *App) processFullReport(resultsChan <-chan analyzer.FileResult, opts *ou
 	return exitCode(hasFindings, hasErrors, opts.exitZero)
 }
 
-func (a *App) processStreaming(resultsChan <-chan analyzer.FileResult, opts *outputOptions) int {
+func (a *App) processStreaming(resultsChan <-chan analyzer.FileResult, opts *outputOptions, progress *progressLine, filterStats func() scanner.FilterStats) int {
 	var hasFindings, hasErrors bool
 	textFormatter := &output.TextFormatter{IncludePassed: opts.includePassed, IncludeSkipped: opts.includeSkipped}
 
@@ -75,14 +98,29 @@ func (a *App) processStreaming(resultsChan <-chan analyzer.FileResult, opts *out
 			hasErrors = true
 		}
 		report := decorateReport([]analyzer.FileResult{res})
-		if err := textFormatter.Format(report, os.Stdout); err != nil {
-			a.logger.Error("failed to format output", slog.Any("error", err))
-		}
+		progress.suspend(func() {
+			if err := textFormatter.Format(report, os.Stdout); err != nil {
+				a.logger.Error("failed to format output", slog.Any("error", err))
+			}
+		})
 	}
+	progress.Stop()
+
+	a.reportFiltered(filterStats())
 
 	return exitCode(hasFindings, hasErrors, opts.exitZero)
 }
 
+// reportFiltered logs how many paths the directory walk filters left out and summarizes them on stderr.
+func (a *App) reportFiltered(stats scanner.FilterStats) {
+	a.logger.Info("filtered paths",
+		slog.Int64("excluded", stats.Excluded), slog.Int64("ignored", stats.Ignored), slog.Int64("not_binary", stats.NotBinary))
+	if stats.Total() > 0 {
+		fmt.Fprintf(os.Stderr, "Filtered %d paths: %d by --include/--exclude, %d by %s, %d not binaries\n",
+			stats.Total(), stats.Excluded, stats.Ignored, scanner.IgnoreFileName, stats.NotBinary)
+	}
+}
+
 // exitCode maps run outcomes to a process exit code, with file errors taking precedence over findings.
 func exitCode(hasFindings, hasErrors, exitZero bool) int {
 	switch {
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"go.kacmar.sk/crack/internal/scanner"
+)
+
+// progressInterval is how often the progress line is redrawn.
+const progressInterval = 200 * time.Millisecond
+
+// progressLine keeps a line counting found, analyzed, and failed files at the bottom of a terminal.
+// A nil progressLine draws nothing, so callers need not check whether progress is shown.
+type progressLine struct {
+	mu       sync.Mutex
+	w        io.Writer
+	progress func() scanner.Progress
+	shown    bool
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// startProgress starts redrawing the progress line on stderr, or returns nil when stderr isn't a terminal.
+func startProgress(progress func() scanner.Progress) *progressLine {
+	if !isTerminal(os.Stderr) {
+		return nil
+	}
+	p := &progressLine{
+		w:        os.Stderr,
+		progress: progress,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+func (p *progressLine) run() {
+	defer close(p.done)
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.mu.Lock()
+			progress := p.progress()
+			cached := ""
+			if progress.Cached > 0 {
+				cached = fmt.Sprintf(" (%d cached)", progress.Cached)
+			}
+			fmt.Fprintf(p.w, "\r\033[KFound %d files, analyzed %d%s, failed %d", progress.Found, progress.Analyzed, cached, progress.Failed)
+			p.shown = true
+			p.mu.Unlock()
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// suspend erases the progress line while write runs, so that output to the same terminal starts on a clean line.
+// The line is drawn again on the next tick.
+func (p *progressLine) suspend(write func()) {
+	if p == nil {
+		write()
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+	write()
+}
+
+// Stop stops redrawing the progress line and erases it.
+func (p *progressLine) Stop() {
+	if p == nil {
+		return
+	}
+	close(p.stop)
+	<-p.done
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+}
+
+func (p *progressLine) clear() {
+	if p.shown {
+		fmt.Fprint(p.w, "\r\033[K")
+		p.shown = false
+	}
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -22,13 +22,15 @@ func parseCompiler(s string) (toolchain.Compiler, bool) {
 		return toolchain.Clang, true
 	case toolchain.Rustc.String():
 		return toolchain.Rustc, true
+	case toolchain.Go.String():
+		return toolchain.Go, true
 	default:
 		return toolchain.Unknown, false
 	}
 }
 
 func validCompilerNames() []string {
-	return []string{toolchain.GCC.String(), toolchain.Clang.String(), toolchain.Rustc.String()}
+	return []string{toolchain.GCC.String(), toolchain.Clang.String(), toolchain.Rustc.String(), toolchain.Go.String()}
 }
 
 func validArchitectureNames() []string {
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"go.kacmar.sk/crack/internal/analyzer"
+	"go.kacmar.sk/crack/internal/output"
+	"go.kacmar.sk/crack/internal/scanner"
+	"go.kacmar.sk/crack/rule"
+)
+
+// fileStatus is the outcome of the last analysis of a path in watch mode.
+type fileStatus struct {
+	err      string
+	statuses map[string]rule.Status
+}
+
+func statusOf(res analyzer.FileResult) *fileStatus {
+	if res.Error != nil {
+		return &fileStatus{err: res.Error.Error()}
+	}
+	statuses := make(map[string]rule.Status, len(res.Findings))
+	for _, f := range res.Findings {
+		statuses[f.RuleID] = f.Status
+	}
+	return &fileStatus{statuses: statuses}
+}
+
+// changedFindings returns the findings of res whose status differs from prev, the status of the previous analysis of the path.
+// Without a previous analysis, or when it failed, the findings are selected as in a regular run.
+func changedFindings(prev *fileStatus, res analyzer.FileResult, opts *outputOptions) []rule.Finding {
+	var changed []rule.Finding
+	for _, f := range res.Findings {
+		if prev == nil || prev.err != "" {
+			if f.Status == rule.StatusFailed || (f.Status == rule.StatusPassed && opts.includePassed) || (f.Status == rule.StatusSkipped && opts.includeSkipped) {
+				changed = append(changed, f)
+			}
+			continue
+		}
+		if status, ok := prev.statuses[f.RuleID]; !ok || status != f.Status {
+			changed = append(changed, f)
+		}
+	}
+	return changed
+}
+
+// processWatch prints the results of the initial scan as processStreaming does, then follows the files written afterwards,
+// printing only what changed since the previous analysis of each path until the watch ends.
+// The exit code reflects the last analysis of every path.
+func (a *App) processWatch(initial, written <-chan analyzer.FileResult, opts *outputOptions, progress *progressLine, filterStats func() scanner.FilterStats) int {
+	last := make(map[string]*fileStatus)
+	show := func(formatter *output.TextFormatter, res analyzer.FileResult) {
+		report := decorateReport([]analyzer.FileResult{res})
+		progress.suspend(func() {
+			if err := formatter.Format(report, os.Stdout); err != nil {
+				a.logger.Error("failed to format output", slog.Any("error", err))
+			}
+		})
+	}
+
+	textFormatter := &output.TextFormatter{IncludePassed: opts.includePassed, IncludeSkipped: opts.includeSkipped}
+	for res := range initial {
+		if res.Skipped {
+			continue
+		}
+		if res.LateAliases {
+			for _, alias := range res.Aliases {
+				last[alias] = last[res.Path]
+			}
+			show(textFormatter, res)
+			continue
+		}
+		status := statusOf(res)
+		last[res.Path] = status
+		for _, alias := range res.Aliases {
+			last[alias] = status
+		}
+		show(textFormatter, res)
+	}
+	progress.Stop()
+	a.reportFiltered(filterStats())
+	fmt.Fprintln(os.Stderr, "Watching for changes, press Ctrl+C to stop.")
+
+	// Findings are selected by changedFindings, so the formatter prints all it is given.
+	changeFormatter := &output.TextFormatter{IncludePassed: true, IncludeSkipped: true}
+	for res := range written {
+		if res.Skipped {
+			continue
+		}
+		prev := last[res.Path]
+		last[res.Path] = statusOf(res)
+		if res.Error != nil {
+			if prev == nil || prev.err != res.Error.Error() {
+				show(changeFormatter, res)
+			}
+			continue
+		}
+		if res.Findings = changedFindings(prev, res, opts); len(res.Findings) > 0 {
+			show(changeFormatter, res)
+		}
+	}
+
+	var hasFindings, hasErrors bool
+	for _, status := range last {
+		if status.err != "" {
+			hasErrors = true
+		}
+		for _, s := range status.statuses {
+			if s == rule.StatusFailed {
+				hasFindings = true
+			}
+		}
+	}
+	return exitCode(hasFindings, hasErrors, opts.exitZero)
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"go.kacmar.sk/crack/internal/analyzer"
+	"go.kacmar.sk/crack/rule"
+)
+
+func TestChangedFindings(t *testing.T) {
+	finding := func(id string, status rule.Status) rule.Finding {
+		return rule.Finding{Result: rule.Result{Status: status}, RuleID: id}
+	}
+	res := analyzer.FileResult{Findings: []rule.Finding{
+		finding("pie", rule.StatusPassed),
+		finding("relro", rule.StatusFailed),
+		finding("cfi", rule.StatusSkipped),
+	}}
+	previous := analyzer.FileResult{Findings: []rule.Finding{
+		finding("pie", rule.StatusFailed),
+		finding("relro", rule.StatusFailed),
+		finding("cfi", rule.StatusSkipped),
+	}}
+
+	tests := []struct {
+		name string
+		prev *fileStatus
+		opts outputOptions
+		want []string
+	}{
+		{name: "first analysis", want: []string{"relro"}},
+		{name: "first analysis with passed", opts: outputOptions{includePassed: true}, want: []string{"pie", "relro"}},
+		{name: "after failure", prev: statusOf(analyzer.FileResult{Error: errors.New("truncated")}), want: []string{"relro"}},
+		{name: "status changed", prev: statusOf(previous), want: []string{"pie"}},
+		{name: "unchanged", prev: statusOf(res)},
+		{name: "new rule", prev: statusOf(analyzer.FileResult{Findings: previous.Findings[:2]}), want: []string{"pie", "cfi"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range changedFindings(tt.prev, res, &tt.opts) {
+				got = append(got, f.RuleID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("changedFindings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,16 +2,23 @@ package output
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"maps"
 	"regexp"
 	"slices"
 	"strings"
 	"time"
 
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/internal/analyzer"
+	"go.kacmar.sk/crack/internal/archive"
 	"go.kacmar.sk/crack/internal/suggestions"
 	"go.kacmar.sk/crack/internal/version"
 	"go.kacmar.sk/crack/rule"
+	"go.kacmar.sk/crack/toolchain"
 )
 
 type SARIFReport struct {
@@ -35,14 +42,24 @@ type SARIFInvocation struct {
 	EndTimeUtc                 string                 `json:"endTimeUtc,omitempty"`
 	WorkingDirectory           *SARIFArtifactLocation `json:"workingDirectory,omitempty"`
 	ToolExecutionNotifications []SARIFNotification    `json:"toolExecutionNotifications,omitempty"`
+	Properties                 map[string]any         `json:"properties,omitempty"`
 }
 
 type SARIFNotification struct {
-	Level     string          `json:"level"`
-	Message   SARIFMessage    `json:"message"`
-	Locations []SARIFLocation `json:"locations,omitempty"`
+	Level      string                    `json:"level"`
+	Message    SARIFMessage              `json:"message"`
+	Locations  []SARIFLocation           `json:"locations,omitempty"`
+	Descriptor *SARIFDescriptorReference `json:"descriptor,omitempty"`
+}
+
+// SARIFDescriptorReference identifies the kind of a notification.
+type SARIFDescriptorReference struct {
+	ID string `json:"id"`
 }
 
+// limitExceededNotification is the descriptor ID of notifications for files that exceeded a resource limit.
+const limitExceededNotification = "resource-limit-exceeded"
+
 type SARIFTool struct {
 	Driver SARIFDriver `json:"driver"`
 }
@@ -76,6 +93,8 @@ type SARIFResult struct {
 	Level     string          `json:"level,omitempty"`
 	Message   SARIFMessage    `json:"message"`
 	Locations []SARIFLocation `json:"locations,omitempty"`
+	// Properties names the universal binary slice the result was found in, which shares its artifact with the other slices.
+	Properties map[string]any `json:"properties,omitempty"`
 }
 
 type SARIFLocation struct {
@@ -91,8 +110,10 @@ type SARIFArtifactLocation struct {
 }
 
 type SARIFArtifact struct {
-	Location SARIFArtifactLocation `json:"location"`
-	Hashes   map[string]string     `json:"hashes,omitempty"`
+	Location    SARIFArtifactLocation `json:"location"`
+	ParentIndex *int                  `json:"parentIndex,omitempty"`
+	Hashes      map[string]string     `json:"hashes,omitempty"`
+	Properties  map[string]any        `json:"properties,omitempty"`
 }
 
 type InvocationInfo struct {
@@ -102,6 +123,8 @@ type InvocationInfo struct {
 	EndTime     time.Time
 	WorkingDir  string
 	Successful  bool
+	// FilteredFiles counts the paths left out of directory walks by each filter, or is nil when none were.
+	FilteredFiles map[string]int64
 }
 
 type SARIFFormatter struct {
@@ -191,20 +214,18 @@ func (f *SARIFFormatter) buildResults(report *DecoratedReport, ruleIndex, artifa
 	notifications := make([]SARIFNotification, 0)
 
 	for _, res := range report.Results {
-		fileURI := toFileURI(res.Path)
-
 		if res.Error != nil {
-			notifications = append(notifications, SARIFNotification{
+			notification := SARIFNotification{
 				Level: "error",
 				Message: SARIFMessage{
 					Text: fmt.Sprintf("Scan error: %v", res.Error),
 				},
-				Locations: []SARIFLocation{
-					{PhysicalLocation: SARIFPhysicalLocation{
-						ArtifactIndex: artifactIndex[fileURI],
-					}},
-				},
-			})
+				Locations: resultLocations(res, artifactIndex),
+			}
+			if errors.Is(res.Error, analyzer.ErrLimitExceeded) {
+				notification.Descriptor = &SARIFDescriptorReference{ID: limitExceededNotification}
+			}
+			notifications = append(notifications, notification)
 			continue
 		}
 
@@ -237,11 +258,10 @@ func (f *SARIFFormatter) buildResults(report *DecoratedReport, ruleIndex, artifa
 				Kind:      kind,
 				Level:     level,
 				Message:   SARIFMessage{Text: message},
-				Locations: []SARIFLocation{
-					{PhysicalLocation: SARIFPhysicalLocation{
-						ArtifactIndex: artifactIndex[fileURI],
-					}},
-				},
+				Locations: resultLocations(res, artifactIndex),
+			}
+			if res.Slice != "" {
+				sarifResult.Properties = map[string]any{"slice": res.Slice}
 			}
 
 			sarifResults = append(sarifResults, sarifResult)
@@ -251,34 +271,173 @@ func (f *SARIFFormatter) buildResults(report *DecoratedReport, ruleIndex, artifa
 	return sarifResults, notifications
 }
 
+// resultLocations returns the locations of a result: the artifact of its path followed by those of its aliases.
+func resultLocations(res DecoratedFileResult, artifactIndex map[string]int) []SARIFLocation {
+	locations := make([]SARIFLocation, 0, 1+len(res.Aliases))
+	for _, p := range append([]string{res.Path}, res.Aliases...) {
+		locations = append(locations, SARIFLocation{PhysicalLocation: SARIFPhysicalLocation{ArtifactIndex: artifactIndex[p]}})
+	}
+	return locations
+}
+
+// buildArtifacts registers one artifact per reported path and alias, keyed by that path.
+// Files extracted from archives become nested artifacts whose URI is the member path and whose parentIndex points at the enclosing archive.
+// Package metadata is attached to the artifact of the package that shipped the file, and the format, image layer and ABI to the file's own artifact.
 func (f *SARIFFormatter) buildArtifacts(report *DecoratedReport) ([]SARIFArtifact, map[string]int) {
 	artifactHashes := make(map[string]string)
+	packages := make(map[string]*archive.Package)
+	properties := make(map[string]map[string]any)
 	for _, res := range report.Results {
-		fileURI := toFileURI(res.Path)
-		artifactHashes[fileURI] = res.Identity.SHA256
+		for _, p := range append([]string{res.Path}, res.Aliases...) {
+			parts := archive.SplitPath(p)
+			for i := 1; i < len(parts); i++ {
+				container := strings.Join(parts[:i], archive.Separator)
+				if _, ok := artifactHashes[container]; !ok {
+					artifactHashes[container] = ""
+				}
+			}
+			artifactHashes[p] = res.Identity.SHA256
+			if props := fileProperties(res); props != nil {
+				properties[p] = props
+			}
+			if res.Package != nil && len(parts) > 1 {
+				packages[strings.Join(parts[:len(parts)-1], archive.Separator)] = res.Package
+			}
+		}
 	}
 
-	uris := make([]string, 0, len(artifactHashes))
-	for uri := range artifactHashes {
-		uris = append(uris, uri)
+	paths := make([]string, 0, len(artifactHashes))
+	for p := range artifactHashes {
+		paths = append(paths, p)
 	}
-	slices.Sort(uris)
+	slices.Sort(paths)
 
-	artifacts := make([]SARIFArtifact, 0, len(artifactHashes))
-	artifactIndex := make(map[string]int)
-	for i, uri := range uris {
-		artifactIndex[uri] = i
+	artifactIndex := make(map[string]int, len(paths))
+	for i, p := range paths {
+		artifactIndex[p] = i
+	}
+
+	artifacts := make([]SARIFArtifact, 0, len(paths))
+	for _, p := range paths {
 		artifact := SARIFArtifact{
-			Location: SARIFArtifactLocation{URI: uri},
+			Location: SARIFArtifactLocation{URI: toFileURI(p)},
+		}
+		if i := strings.LastIndex(p, archive.Separator); i >= 0 {
+			parent := artifactIndex[p[:i]]
+			artifact.Location.URI = "/" + p[i+len(archive.Separator):]
+			artifact.ParentIndex = &parent
 		}
-		if hash := artifactHashes[uri]; hash != "" {
+		if hash := artifactHashes[p]; hash != "" {
 			artifact.Hashes = map[string]string{"sha-256": hash}
 		}
+		if pkg := packages[p]; pkg != nil {
+			artifact.Properties = packageProperties(pkg)
+		}
+		if props := properties[p]; props != nil {
+			if artifact.Properties == nil {
+				artifact.Properties = props
+			} else {
+				maps.Copy(artifact.Properties, props)
+			}
+		}
 		artifacts = append(artifacts, artifact)
 	}
 	return artifacts, artifactIndex
 }
 
+// packageProperties renders package metadata as a SARIF property bag, omitting fields the package header didn't provide.
+func packageProperties(pkg *archive.Package) map[string]any {
+	props := make(map[string]any, 3)
+	for key, value := range map[string]string{
+		"packageName":    pkg.Name,
+		"packageVersion": pkg.Version,
+		"packageArch":    pkg.Arch,
+	} {
+		if value != "" {
+			props[key] = value
+		}
+	}
+	return props
+}
+
+// SARIFProcess identifies a running process that mapped a file, in the "processes" artifact property.
+type SARIFProcess struct {
+	PID         int    `json:"pid"`
+	CommandLine string `json:"commandLine"`
+}
+
+// fileProperties renders the format, provenance, filesystem and kernel module and Rust standard library metadata of a file as a SARIF property bag,
+// or nil when there is none.
+func fileProperties(res DecoratedFileResult) map[string]any {
+	props := make(map[string]any, 2)
+	if res.Format != binary.FormatUnknown {
+		props["format"] = res.Format.String()
+	}
+	if meta := res.Profile.File; meta != nil {
+		props["mode"] = fmt.Sprintf("%04o", unixMode(meta.Mode))
+		props["uid"] = meta.UID
+		props["gid"] = meta.GID
+		if meta.Capabilities != nil {
+			props["capabilities"] = meta.Capabilities.String()
+		}
+	}
+	if mod := res.Profile.Module; mod != nil {
+		for key, value := range map[string]string{
+			"moduleName":     mod.Name,
+			"moduleVermagic": mod.VerMagic,
+			"moduleLicense":  mod.License,
+		} {
+			if value != "" {
+				props[key] = value
+			}
+		}
+		props["moduleRetpoline"] = mod.Retpoline
+	}
+	if rust := res.Profile.Rust; rust != nil {
+		for prefix, crate := range map[string]binary.RustCrate{"rustStd": rust.Std, "rustAlloc": rust.Alloc} {
+			if crate.Commit != "" {
+				props[prefix+"Commit"] = crate.Commit
+			}
+			if crate.Version != (toolchain.Version{}) {
+				props[prefix+"Version"] = crate.Version.String()
+			}
+		}
+	}
+	if res.Layer != "" {
+		props["layer"] = res.Layer
+	}
+	if res.ABI != "" {
+		props["abi"] = res.ABI
+	}
+	if len(res.Processes) > 0 {
+		processes := make([]SARIFProcess, len(res.Processes))
+		for i, p := range res.Processes {
+			processes[i] = SARIFProcess{PID: p.PID, CommandLine: p.Cmdline}
+		}
+		props["processes"] = processes
+		props["diskState"] = res.DiskState.String()
+	}
+	if len(props) == 0 {
+		return nil
+	}
+	return props
+}
+
+// unixMode returns the permission and special bits of m as in st_mode, e.g. 04755 for a setuid executable.
+func unixMode(m fs.FileMode) uint32 {
+	mode := uint32(m.Perm())
+	if m&fs.ModeSetuid != 0 {
+		mode |= 0o4000
+	}
+	if m&fs.ModeSetgid != 0 {
+		mode |= 0o2000
+	}
+	if m&fs.ModeSticky != 0 {
+		mode |= 0o1000
+	}
+	return mode
+}
+
 func (f *SARIFFormatter) buildInvocation(notifications []SARIFNotification) SARIFInvocation {
 	var inv SARIFInvocation
 
@@ -295,6 +454,9 @@ func (f *SARIFFormatter) buildInvocation(notifications []SARIFNotification) SARI
 		if f.Invocation.WorkingDir != "" {
 			inv.WorkingDirectory = &SARIFArtifactLocation{URI: toFileURI(f.Invocation.WorkingDir)}
 		}
+		if f.Invocation.FilteredFiles != nil {
+			inv.Properties = map[string]any{"filteredFiles": f.Invocation.FilteredFiles}
+		}
 	}
 
 	if len(notifications) > 0 {
@@ -3,12 +3,21 @@ package output
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"slices"
 	"testing"
 	"time"
 
+	"go.kacmar.sk/crack/binary"
 	"go.kacmar.sk/crack/internal/analyzer"
+	"go.kacmar.sk/crack/internal/archive"
+	"go.kacmar.sk/crack/internal/proc"
 	"go.kacmar.sk/crack/internal/suggestions"
 	"go.kacmar.sk/crack/rule"
+	"go.kacmar.sk/crack/toolchain"
 )
 
 func TestSARIFResultKind(t *testing.T) {
@@ -159,3 +168,326 @@ func TestSARIFInvocation(t *testing.T) {
 		}
 	})
 }
+
+func TestSARIFNestedArtifacts(t *testing.T) {
+	passed := []suggestions.DecoratedFinding{{
+		Finding: rule.Finding{
+			Result: rule.Result{Status: rule.StatusPassed, Message: "test passed"},
+			RuleID: "test-rule",
+			Name:   "Test Rule",
+		},
+	}}
+	pkg := &archive.Package{Name: "foo", Version: "1.0-1", Arch: "amd64"}
+	report := &DecoratedReport{
+		Results: []DecoratedFileResult{
+			{FileResult: analyzer.FileResult{Path: "/pkgs/foo.deb!/usr/bin/foo", Identity: binary.Identity{SHA256: "aaaa"}, Package: pkg}, Findings: passed},
+			{FileResult: analyzer.FileResult{Path: "/pkgs/foo.deb!/usr/lib/libfoo.so.1", Identity: binary.Identity{SHA256: "bbbb"}, Package: pkg}, Findings: passed},
+			{FileResult: analyzer.FileResult{Path: "/usr/bin/bar", Identity: binary.Identity{SHA256: "cccc"}}, Findings: passed},
+		},
+	}
+
+	formatter := &SARIFFormatter{IncludePassed: true}
+	var buf bytes.Buffer
+	if err := formatter.Format(report, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	var sarifReport SARIFReport
+	if err := json.Unmarshal(buf.Bytes(), &sarifReport); err != nil {
+		t.Fatalf("failed to parse SARIF output: %v", err)
+	}
+	run := sarifReport.Runs[0]
+
+	type artifact struct {
+		uri    string
+		parent int
+		hash   string
+	}
+	var got []artifact
+	for _, a := range run.Artifacts {
+		parent := -1
+		if a.ParentIndex != nil {
+			parent = *a.ParentIndex
+		}
+		got = append(got, artifact{uri: a.Location.URI, parent: parent, hash: a.Hashes["sha-256"]})
+	}
+	want := []artifact{
+		{uri: "file:///pkgs/foo.deb", parent: -1},
+		{uri: "/usr/bin/foo", parent: 0, hash: "aaaa"},
+		{uri: "/usr/lib/libfoo.so.1", parent: 0, hash: "bbbb"},
+		{uri: "file:///usr/bin/bar", parent: -1, hash: "cccc"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("artifacts = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("artifact[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	wantProps := map[string]any{"packageName": "foo", "packageVersion": "1.0-1", "packageArch": "amd64"}
+	if props := run.Artifacts[0].Properties; !maps.Equal(props, wantProps) {
+		t.Errorf("package properties = %v, want %v", props, wantProps)
+	}
+	if props := run.Artifacts[3].Properties; props != nil {
+		t.Errorf("file properties = %v, want none", props)
+	}
+
+	wantIndex := []int{1, 2, 3}
+	for i, res := range run.Results {
+		if idx := res.Locations[0].PhysicalLocation.ArtifactIndex; idx != wantIndex[i] {
+			t.Errorf("result[%d] artifactIndex = %d, want %d", i, idx, wantIndex[i])
+		}
+	}
+}
+
+func TestSARIFFileProperties(t *testing.T) {
+	report := &DecoratedReport{
+		Results: []DecoratedFileResult{
+			{FileResult: analyzer.FileResult{Path: "/app.apk!/lib/arm64-v8a/libfoo.so", ABI: "arm64-v8a"}},
+			{FileResult: analyzer.FileResult{Path: "/image.tar!/usr/bin/foo", Layer: "sha256:abcd"}},
+			{FileResult: analyzer.FileResult{Path: "/usr/bin/bar"}},
+			{FileResult: analyzer.FileResult{Path: "/usr/bin/app.exe", Format: binary.FormatPE}},
+			{FileResult: analyzer.FileResult{Path: "/lib/modules/hello.ko", Profile: binary.Profile{
+				Module: &binary.ModuleInfo{Name: "hello", VerMagic: "6.8.0 SMP mod_unload", Retpoline: true},
+			}}},
+			{FileResult: analyzer.FileResult{Path: "/usr/bin/rg", Profile: binary.Profile{
+				Rust: &binary.RustInfo{
+					Commit: "1159e78c4",
+					Std:    binary.RustCrate{Linked: true, Commit: "1159e78c4747b02ef996e55082b704c09b970588", Version: toolchain.Version{Major: 1, Minor: 90}},
+					Alloc:  binary.RustCrate{Linked: true},
+				},
+			}}},
+			{FileResult: analyzer.FileResult{Path: "/usr/bin/su", Profile: binary.Profile{
+				File: &binary.FileMetadata{Mode: fs.ModeSetuid | 0o755},
+			}}},
+			{FileResult: analyzer.FileResult{Path: "/usr/bin/ping", Profile: binary.Profile{
+				File: &binary.FileMetadata{Mode: 0o755, GID: 42, Capabilities: &binary.Capabilities{Permitted: 1 << 13, Effective: true}},
+			}}},
+		},
+	}
+
+	formatter := &SARIFFormatter{}
+	var buf bytes.Buffer
+	if err := formatter.Format(report, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	var sarifReport SARIFReport
+	if err := json.Unmarshal(buf.Bytes(), &sarifReport); err != nil {
+		t.Fatalf("failed to parse SARIF output: %v", err)
+	}
+
+	got := make(map[string]map[string]any)
+	for _, a := range sarifReport.Runs[0].Artifacts {
+		got[a.Location.URI] = a.Properties
+	}
+	want := map[string]map[string]any{
+		"file:///app.apk":              nil,
+		"/lib/arm64-v8a/libfoo.so":     {"abi": "arm64-v8a"},
+		"file:///image.tar":            nil,
+		"/usr/bin/foo":                 {"layer": "sha256:abcd"},
+		"file:///usr/bin/bar":          nil,
+		"file:///usr/bin/app.exe":      {"format": "PE"},
+		"file:///lib/modules/hello.ko": {"moduleName": "hello", "moduleVermagic": "6.8.0 SMP mod_unload", "moduleRetpoline": true},
+		"file:///usr/bin/rg":           {"rustStdCommit": "1159e78c4747b02ef996e55082b704c09b970588", "rustStdVersion": "1.90"},
+		"file:///usr/bin/su":           {"mode": "4755", "uid": float64(0), "gid": float64(0)},
+		"file:///usr/bin/ping":         {"mode": "0755", "uid": float64(0), "gid": float64(42), "capabilities": "cap_net_raw=ep"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("artifacts = %v, want %v", got, want)
+	}
+	for uri, props := range want {
+		if !maps.Equal(got[uri], props) {
+			t.Errorf("artifact %q properties = %v, want %v", uri, got[uri], props)
+		}
+	}
+}
+
+func TestSARIFPackageResultProperties(t *testing.T) {
+	pkg := &archive.Package{Name: "foo", Version: "1.0-1", Arch: "amd64"}
+	report := &DecoratedReport{
+		Results: []DecoratedFileResult{
+			{FileResult: analyzer.FileResult{Path: "/image.tar!/var/cache/foo.deb", Layer: "sha256:abcd"}},
+			{FileResult: analyzer.FileResult{Path: "/image.tar!/var/cache/foo.deb!/usr/bin/foo", Package: pkg}},
+		},
+	}
+
+	formatter := &SARIFFormatter{}
+	var buf bytes.Buffer
+	if err := formatter.Format(report, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	var sarifReport SARIFReport
+	if err := json.Unmarshal(buf.Bytes(), &sarifReport); err != nil {
+		t.Fatalf("failed to parse SARIF output: %v", err)
+	}
+
+	got := make(map[string]map[string]any)
+	for _, a := range sarifReport.Runs[0].Artifacts {
+		got[a.Location.URI] = a.Properties
+	}
+	want := map[string]any{"layer": "sha256:abcd", "packageName": "foo", "packageVersion": "1.0-1", "packageArch": "amd64"}
+	if props := got["/var/cache/foo.deb"]; !maps.Equal(props, want) {
+		t.Errorf("package artifact properties = %v, want %v", props, want)
+	}
+}
+
+func TestSARIFProcessProperties(t *testing.T) {
+	report := &DecoratedReport{
+		Results: []DecoratedFileResult{{FileResult: analyzer.FileResult{
+			Path:      "/usr/lib/libfoo.so.1",
+			Processes: []proc.Process{{PID: 1, Cmdline: "/sbin/init"}, {PID: 42, Cmdline: "/usr/bin/app --serve"}},
+			DiskState: proc.DiskReplaced,
+		}}},
+	}
+
+	formatter := &SARIFFormatter{}
+	var buf bytes.Buffer
+	if err := formatter.Format(report, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	var sarifReport struct {
+		Runs []struct {
+			Artifacts []struct {
+				Properties struct {
+					Processes []SARIFProcess `json:"processes"`
+					DiskState string         `json:"diskState"`
+				} `json:"properties"`
+			} `json:"artifacts"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &sarifReport); err != nil {
+		t.Fatalf("failed to parse SARIF output: %v", err)
+	}
+
+	props := sarifReport.Runs[0].Artifacts[0].Properties
+	wantProcesses := []SARIFProcess{{PID: 1, CommandLine: "/sbin/init"}, {PID: 42, CommandLine: "/usr/bin/app --serve"}}
+	if !slices.Equal(props.Processes, wantProcesses) {
+		t.Errorf("processes = %+v, want %+v", props.Processes, wantProcesses)
+	}
+	if props.DiskState != "replaced" {
+		t.Errorf("diskState = %q, want replaced", props.DiskState)
+	}
+}
+
+func TestSARIFAliasLocations(t *testing.T) {
+	report := &DecoratedReport{
+		Results: []DecoratedFileResult{
+			{
+				FileResult: analyzer.FileResult{
+					Path:     "/usr/lib/libfoo.so.1",
+					Aliases:  []string{"/opt/app/lib/libfoo.so.1", "/srv/app.tar!/lib/libfoo.so.1"},
+					Identity: binary.Identity{SHA256: "aaaa"},
+				},
+				Findings: []suggestions.DecoratedFinding{{
+					Finding: rule.Finding{
+						Result: rule.Result{Status: rule.StatusFailed, Message: "test failed"},
+						RuleID: "test-rule",
+						Name:   "Test Rule",
+					},
+				}},
+			},
+		},
+	}
+
+	formatter := &SARIFFormatter{}
+	var buf bytes.Buffer
+	if err := formatter.Format(report, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	var sarifReport SARIFReport
+	if err := json.Unmarshal(buf.Bytes(), &sarifReport); err != nil {
+		t.Fatalf("failed to parse SARIF output: %v", err)
+	}
+	run := sarifReport.Runs[0]
+
+	if len(run.Results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(run.Results))
+	}
+	var got []string
+	for _, loc := range run.Results[0].Locations {
+		a := run.Artifacts[loc.PhysicalLocation.ArtifactIndex]
+		if a.Hashes["sha-256"] != "aaaa" {
+			t.Errorf("artifact %q hash = %q, want aaaa", a.Location.URI, a.Hashes["sha-256"])
+		}
+		got = append(got, a.Location.URI)
+	}
+	want := []string{"file:///usr/lib/libfoo.so.1", "file:///opt/app/lib/libfoo.so.1", "/lib/libfoo.so.1"}
+	if !slices.Equal(got, want) {
+		t.Errorf("locations = %v, want %v", got, want)
+	}
+}
+
+func TestSARIFSliceProperties(t *testing.T) {
+	finding := []suggestions.DecoratedFinding{{
+		Finding: rule.Finding{
+			Result: rule.Result{Status: rule.StatusFailed, Message: "test failed"},
+			RuleID: "test-rule",
+			Name:   "Test Rule",
+		},
+	}}
+	report := &DecoratedReport{
+		Results: []DecoratedFileResult{
+			{FileResult: analyzer.FileResult{Path: "/usr/local/bin/tool", Slice: "x86_64", Identity: binary.Identity{SHA256: "aaaa"}}, Findings: finding},
+			{FileResult: analyzer.FileResult{Path: "/usr/local/bin/tool", Slice: "arm64e", Identity: binary.Identity{SHA256: "aaaa"}}, Findings: finding},
+		},
+	}
+
+	formatter := &SARIFFormatter{}
+	var buf bytes.Buffer
+	if err := formatter.Format(report, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	var sarifReport SARIFReport
+	if err := json.Unmarshal(buf.Bytes(), &sarifReport); err != nil {
+		t.Fatalf("failed to parse SARIF output: %v", err)
+	}
+	run := sarifReport.Runs[0]
+
+	if len(run.Artifacts) != 1 {
+		t.Fatalf("expected slices to share 1 artifact, got %d", len(run.Artifacts))
+	}
+	var got []any
+	for _, res := range run.Results {
+		got = append(got, res.Properties["slice"])
+	}
+	if want := []any{"x86_64", "arm64e"}; !slices.Equal(got, want) {
+		t.Errorf("slice properties = %v, want %v", got, want)
+	}
+}
+
+func TestSARIFLimitExceededNotification(t *testing.T) {
+	report := &DecoratedReport{
+		Results: []DecoratedFileResult{
+			{FileResult: analyzer.FileResult{Path: "/usr/bin/huge", Error: fmt.Errorf("%w: file is 10 bytes, over the maximum of 5", analyzer.ErrLimitExceeded)}},
+			{FileResult: analyzer.FileResult{Path: "/usr/bin/broken", Error: errors.New("failed to open ELF file")}},
+		},
+	}
+
+	formatter := &SARIFFormatter{Invocation: &InvocationInfo{CommandLine: "crack analyze /usr/bin"}}
+	var buf bytes.Buffer
+	if err := formatter.Format(report, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	var sarifReport SARIFReport
+	if err := json.Unmarshal(buf.Bytes(), &sarifReport); err != nil {
+		t.Fatalf("failed to parse SARIF output: %v", err)
+	}
+	notifications := sarifReport.Runs[0].Invocations[0].ToolExecutionNotifications
+	if len(notifications) != 2 {
+		t.Fatalf("expected 2 notifications, got %d", len(notifications))
+	}
+	if d := notifications[0].Descriptor; d == nil || d.ID != limitExceededNotification {
+		t.Errorf("limit notification descriptor = %+v, want %s", d, limitExceededNotification)
+	}
+	if d := notifications[1].Descriptor; d != nil {
+		t.Errorf("error notification descriptor = %+v, want none", d)
+	}
+}
@@ -3,7 +3,10 @@ package output
 import (
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 
+	"go.kacmar.sk/crack/internal/proc"
 	"go.kacmar.sk/crack/rule"
 )
 
@@ -14,8 +17,16 @@ type TextFormatter struct {
 
 func (f *TextFormatter) Format(report *DecoratedReport, w io.Writer) error {
 	for _, result := range report.Results {
+		if result.LateAliases {
+			aliases := result.Aliases
+			result.Aliases = nil
+			fmt.Fprintf(w, "ALIAS = %s: also %s\n", textLocation(result), strings.Join(aliases, ", "))
+			continue
+		}
+
+		location := textLocation(result)
 		if result.Error != nil {
-			fmt.Fprintf(w, "ERROR = %s: %v\n", result.Path, result.Error)
+			fmt.Fprintf(w, "ERROR = %s: %v\n", location, result.Error)
 			continue
 		}
 
@@ -23,17 +34,17 @@ func (f *TextFormatter) Format(report *DecoratedReport, w io.Writer) error {
 			switch finding.Status {
 			case rule.StatusPassed:
 				if f.IncludePassed {
-					fmt.Fprintf(w, "PASS = %s @ %s: %s\n", finding.RuleID, result.Path, finding.Message)
+					fmt.Fprintf(w, "PASS = %s @ %s: %s\n", finding.RuleID, location, finding.Message)
 				}
 			case rule.StatusFailed:
 				if finding.Suggestion != "" {
-					fmt.Fprintf(w, "FAIL = %s @ %s: %s %s\n", finding.RuleID, result.Path, finding.Message, finding.Suggestion)
+					fmt.Fprintf(w, "FAIL = %s @ %s: %s %s\n", finding.RuleID, location, finding.Message, finding.Suggestion)
 				} else {
-					fmt.Fprintf(w, "FAIL = %s @ %s: %s\n", finding.RuleID, result.Path, finding.Message)
+					fmt.Fprintf(w, "FAIL = %s @ %s: %s\n", finding.RuleID, location, finding.Message)
 				}
 			case rule.StatusSkipped:
 				if f.IncludeSkipped {
-					fmt.Fprintf(w, "SKIP = %s @ %s: %s\n", finding.RuleID, result.Path, finding.Message)
+					fmt.Fprintf(w, "SKIP = %s @ %s: %s\n", finding.RuleID, location, finding.Message)
 				}
 			}
 		}
@@ -41,3 +52,36 @@ func (f *TextFormatter) Format(report *DecoratedReport, w io.Writer) error {
 
 	return nil
 }
+
+// textLocation returns the path of a result, followed by the universal binary slice it describes, the other paths with the same content and, for files mapped
+// by running processes, by their PIDs and, when the file on disk no longer matches the mapping, its state.
+func textLocation(result DecoratedFileResult) string {
+	var notes []string
+	if result.Slice != "" {
+		notes = append(notes, "slice "+result.Slice)
+	}
+	if privileges := result.Profile.File.String(); privileges != "" {
+		notes = append(notes, privileges)
+	}
+	if len(result.Aliases) > 0 {
+		notes = append(notes, "also "+strings.Join(result.Aliases, ", "))
+	}
+	if len(result.Processes) > 0 {
+		pids := make([]string, len(result.Processes))
+		for i, p := range result.Processes {
+			pids[i] = strconv.Itoa(p.PID)
+		}
+		label := "pid"
+		if len(pids) > 1 {
+			label = "pids"
+		}
+		notes = append(notes, label+" "+strings.Join(pids, ", "))
+		if result.DiskState != proc.DiskUnchanged {
+			notes = append(notes, result.DiskState.String()+" on disk")
+		}
+	}
+	if len(notes) == 0 {
+		return result.Path
+	}
+	return result.Path + " (" + strings.Join(notes, "; ") + ")"
+}
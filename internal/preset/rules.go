@@ -1,12 +1,20 @@
 package preset
 
 import (
+	"maps"
+	"slices"
+
 	"go.kacmar.sk/crack/rule"
 	"go.kacmar.sk/crack/rule/elf"
+	"go.kacmar.sk/crack/rule/golang"
+	"go.kacmar.sk/crack/rule/kernel"
+	"go.kacmar.sk/crack/rule/macho"
+	"go.kacmar.sk/crack/rule/pe"
+	"go.kacmar.sk/crack/rule/rust"
 )
 
-func Default() []rule.ELFRule {
-	return []rule.ELFRule{
+func Default() []rule.Rule {
+	return []rule.Rule{
 		elf.ASLRRule{},
 		elf.FortifySourceRule{},
 		elf.FullRELRORule{},
@@ -14,8 +22,81 @@ func Default() []rule.ELFRule {
 		elf.NoInsecureRUNPATHRule{},
 		elf.NXBitRule{},
 		elf.PIERule{},
+		elf.PrivilegedFullRELRORule{},
+		elf.PrivilegedNoDLOpenRule{},
+		elf.PrivilegedNoRUNPATHRule{},
 		elf.RELRORule{},
 		elf.SeparateCodeRule{},
 		elf.StackCanaryRule{},
+		golang.CgoHardeningRule{},
+		golang.NoRaceRule{},
+		golang.PIERule{},
+		golang.VersionRule{},
+		kernel.ModuleNoWXRule{},
+		kernel.ModuleRetpolineRule{},
+		kernel.ModuleSignatureRule{},
+		kernel.ModuleStackProtectorRule{},
+		macho.CodeSignatureRule{},
+		macho.NoStackExecRule{},
+		macho.PIERule{},
+		macho.StackCanaryRule{},
+		pe.DynamicBaseRule{},
+		pe.GSRule{},
+		pe.HighEntropyVARule{},
+		pe.NXCompatRule{},
+		pe.SafeSEHRule{},
+		rust.CHardeningRule{},
+		rust.OverflowChecksRule{},
+		rust.PanicAbortRule{},
 	}
 }
+
+// Kernel returns the rules for Linux kernel images and modules, for scanning a kernel build or /boot and /lib/modules.
+func Kernel() []rule.Rule {
+	return []rule.Rule{
+		kernel.IBTRule{},
+		kernel.KCFIRule{},
+		kernel.ModuleCFIRule{},
+		kernel.ModuleNoWXRule{},
+		kernel.ModuleRetpolineRule{},
+		kernel.ModuleSignatureRule{},
+		kernel.ModuleStackProtectorRule{},
+		kernel.RandstructRule{},
+		kernel.RetpolineRule{},
+		kernel.ROAfterInitRule{},
+		kernel.ShadowCallStackRule{},
+		kernel.StackProtectorRule{},
+	}
+}
+
+// Rust returns the rules for Rust binaries: the ELF rules rustc controls and the rules for Rust's own build options.
+func Rust() []rule.Rule {
+	return []rule.Rule{
+		elf.FullRELRORule{},
+		elf.NXBitRule{},
+		elf.PIERule{},
+		rust.CHardeningRule{},
+		rust.OverflowChecksRule{},
+		rust.PanicAbortRule{},
+	}
+}
+
+var presets = map[string]func() []rule.Rule{
+	"default": Default,
+	"kernel":  Kernel,
+	"rust":    Rust,
+}
+
+// Find returns the rules of the named preset.
+func Find(name string) ([]rule.Rule, bool) {
+	preset, ok := presets[name]
+	if !ok {
+		return nil, false
+	}
+	return preset(), true
+}
+
+// Names returns the names of all presets, sorted.
+func Names() []string {
+	return slices.Sorted(maps.Keys(presets))
+}
@@ -0,0 +1,57 @@
+package preset
+
+import (
+	"slices"
+	"testing"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/rule"
+)
+
+func ruleIDs(rules []rule.Rule) []string {
+	ids := make([]string, 0, len(rules))
+	for _, r := range rules {
+		ids = append(ids, r.ID())
+	}
+	return ids
+}
+
+func TestKernel(t *testing.T) {
+	rules := Kernel()
+	ids := ruleIDs(rules)
+	want := []string{
+		"kernel-ibt",
+		"kernel-kcfi",
+		"kmod-cfi",
+		"kmod-no-wx",
+		"kmod-retpoline",
+		"kmod-signature",
+		"kmod-stack-protector",
+		"kernel-randstruct",
+		"kernel-retpoline",
+		"kernel-ro-after-init",
+		"kernel-shadow-call-stack",
+		"kernel-stack-protector",
+	}
+	if !slices.Equal(ids, want) {
+		t.Errorf("Kernel() = %v, want %v", ids, want)
+	}
+
+	for _, r := range rules {
+		if _, ok := r.(rule.ELFRule); !ok {
+			t.Errorf("%s isn't an ELF rule", r.ID())
+		}
+		kinds := r.Applicability().Kinds
+		if kinds == 0 || kinds&^(binary.KindKernelImage|binary.KindKernelModule) != 0 {
+			t.Errorf("%s applies to %v, want kernel images or modules only", r.ID(), kinds)
+		}
+	}
+
+	found, ok := Find("kernel")
+	if !ok {
+		t.Fatal(`Find("kernel") not found`)
+	}
+	if got := ruleIDs(found); !slices.Equal(got, ids) {
+		t.Errorf(`Find("kernel") = %v, want %v`, got, ids)
+	}
+}
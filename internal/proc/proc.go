@@ -0,0 +1,91 @@
+// Package proc discovers the executables and shared objects mapped by running processes.
+package proc
+
+import (
+	"errors"
+	"os"
+	"slices"
+)
+
+// ErrNoExecutable is returned for processes without an executable image, such as kernel threads.
+var ErrNoExecutable = errors.New("process has no executable")
+
+// Process identifies a running process.
+type Process struct {
+	PID int
+	// Cmdline is the process's command line with arguments separated by spaces.
+	Cmdline string
+}
+
+// DiskState tells how the file a process mapped relates to the file now at its path.
+type DiskState int
+
+const (
+	// DiskUnchanged means the path still holds the mapped file, unmodified since the process started.
+	DiskUnchanged DiskState = iota
+	// DiskDeleted means the mapped file was removed and nothing replaced it.
+	DiskDeleted
+	// DiskReplaced means the path now holds a different file, or the mapped file was modified after the process started.
+	DiskReplaced
+)
+
+func (s DiskState) String() string {
+	switch s {
+	case DiskDeleted:
+		return "deleted"
+	case DiskReplaced:
+		return "replaced"
+	default:
+		return "unchanged"
+	}
+}
+
+// Image is a file mapped by one or more processes.
+type Image struct {
+	// Path is the file's path as seen by the processes.
+	Path string
+	// Processes lists the processes mapping the file, in the order they were added.
+	Processes []Process
+	State     DiskState
+	// source is the path the mapped content is read from, which reaches the mapped inode even when Path no longer does.
+	source string
+}
+
+// Open opens the mapped content of the image.
+func (i *Image) Open() (*os.File, error) {
+	return os.Open(i.source)
+}
+
+// Set collects the images mapped by processes, deduplicated by the mapped inode.
+type Set struct {
+	images []*Image
+	byKey  map[string]*Image
+}
+
+func NewSet() *Set {
+	return &Set{byKey: make(map[string]*Image)}
+}
+
+// Images returns the collected images: each process's executable first, followed by the files it maps.
+func (s *Set) Images() []*Image {
+	return s.images
+}
+
+// addProcess records that process maps the image identified by key. It reports false when the image hasn't been seen yet.
+func (s *Set) addProcess(key string, process Process) bool {
+	image, ok := s.byKey[key]
+	if !ok {
+		return false
+	}
+	if !slices.ContainsFunc(image.Processes, func(p Process) bool { return p.PID == process.PID }) {
+		image.Processes = append(image.Processes, process)
+	}
+	return true
+}
+
+// add records a newly seen image identified by key, mapped by process.
+func (s *Set) add(key string, process Process, image Image) {
+	image.Processes = []Process{process}
+	s.images = append(s.images, &image)
+	s.byKey[key] = &image
+}
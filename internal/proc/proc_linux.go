@@ -0,0 +1,229 @@
+//go:build linux
+
+package proc
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// procRoot is where procfs is mounted.
+const procRoot = "/proc"
+
+// deletedSuffix is appended by the kernel to the path of mapped files that were unlinked.
+const deletedSuffix = " (deleted)"
+
+// clockTicks is USER_HZ, the unit of process start times in /proc/<pid>/stat. It is 100 on every Linux architecture.
+const clockTicks = 100
+
+// List returns the PIDs of all running processes.
+func List() ([]int, error) {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil, err
+	}
+	var pids []int
+	for _, e := range entries {
+		if pid, err := strconv.Atoi(e.Name()); err == nil && e.IsDir() {
+			pids = append(pids, pid)
+		}
+	}
+	return pids, nil
+}
+
+// Add inspects the process pid and adds its executable and mapped files to the set.
+// The executable is read through /proc/<pid>/exe and unchanged files through the process's root, so files in other mount namespaces are found.
+// Files deleted or replaced since they were mapped are read through /proc/<pid>/map_files, which requires CAP_SYS_ADMIN;
+// without it, opening such an image fails.
+func (s *Set) Add(pid int) error {
+	dir := filepath.Join(procRoot, strconv.Itoa(pid))
+
+	exe, err := os.Readlink(filepath.Join(dir, "exe"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			if _, statErr := os.Stat(dir); statErr == nil {
+				return ErrNoExecutable
+			}
+		}
+		return err
+	}
+
+	cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")) // #nosec G304 -- procfs path built from a PID
+	if err != nil {
+		return err
+	}
+	process := Process{PID: pid, Cmdline: parseCmdline(cmdline)}
+
+	started, err := startTime(dir)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(filepath.Join(dir, "maps")) // #nosec G304 -- procfs path built from a PID
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	mappings, err := parseMaps(f)
+	if err != nil {
+		return fmt.Errorf("maps: %w", err)
+	}
+
+	exePath, exeDeleted := strings.CutSuffix(exe, deletedSuffix)
+	exeKey := "exe:" + dir
+	for _, m := range mappings {
+		if m.path == exePath {
+			exeKey = m.key
+			break
+		}
+	}
+	if !s.addProcess(exeKey, process) {
+		s.add(exeKey, process, Image{
+			Path:   exePath,
+			State:  diskState(dir, exePath, exeDeleted, started),
+			source: filepath.Join(dir, "exe"),
+		})
+	}
+
+	for _, m := range mappings {
+		if m.key == exeKey {
+			continue
+		}
+		if s.addProcess(m.key, process) {
+			continue
+		}
+		image := Image{Path: m.path, State: diskState(dir, m.path, m.deleted, started)}
+		if image.State == DiskUnchanged {
+			image.source = filepath.Join(dir, "root", m.path)
+		} else {
+			image.source = filepath.Join(dir, "map_files", m.addresses)
+		}
+		s.add(m.key, process, image)
+	}
+	return nil
+}
+
+// diskState compares the file at path, as seen from the process's root, with the file the process mapped.
+// A file modified after the process started no longer matches what was mapped.
+func diskState(dir, path string, deleted bool, started time.Time) DiskState {
+	info, err := os.Stat(filepath.Join(dir, "root", path))
+	switch {
+	case err != nil && deleted:
+		return DiskDeleted
+	case err != nil:
+		// The mapping is intact, so an unreadable path is a permission problem rather than a change.
+		return DiskUnchanged
+	case deleted:
+		return DiskReplaced
+	case info.ModTime().After(started):
+		return DiskReplaced
+	default:
+		return DiskUnchanged
+	}
+}
+
+// mapping is a file-backed memory mapping from /proc/<pid>/maps.
+type mapping struct {
+	// addresses is the mapped range as named in /proc/<pid>/map_files, e.g. "7f1c2a000000-7f1c2a028000".
+	addresses string
+	// key identifies the mapped inode by device and inode number.
+	key     string
+	path    string
+	deleted bool
+}
+
+// parseMaps returns the first mapping of every file in a /proc/<pid>/maps listing.
+// Anonymous and special mappings are left out, as are device files and System V shared memory, which aren't binaries.
+func parseMaps(r io.Reader) ([]mapping, error) {
+	var mappings []mapping
+	seen := make(map[string]bool)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		// Fields: address perms offset dev inode path. The path may contain spaces.
+		var fields [5]string
+		rest := sc.Text()
+		for i := range fields {
+			fields[i], rest, _ = strings.Cut(strings.TrimLeft(rest, " "), " ")
+		}
+		path := strings.TrimLeft(rest, " ")
+		if fields[4] == "0" || !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "/dev/") || strings.HasPrefix(path, "/SYSV") {
+			continue
+		}
+		key := fields[3] + ":" + fields[4]
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		path, deleted := strings.CutSuffix(path, deletedSuffix)
+		mappings = append(mappings, mapping{addresses: fields[0], key: key, path: path, deleted: deleted})
+	}
+	return mappings, sc.Err()
+}
+
+// parseCmdline joins the NUL-separated arguments of /proc/<pid>/cmdline.
+func parseCmdline(data []byte) string {
+	return string(bytes.ReplaceAll(bytes.TrimRight(data, "\x00"), []byte{0}, []byte{' '}))
+}
+
+// startTime returns when the process in dir started, from its start time in clock ticks since boot.
+func startTime(dir string) (time.Time, error) {
+	stat, err := os.ReadFile(filepath.Join(dir, "stat")) // #nosec G304 -- procfs path built from a PID
+	if err != nil {
+		return time.Time{}, err
+	}
+	ticks, err := parseStartTicks(stat)
+	if err != nil {
+		return time.Time{}, err
+	}
+	boot, err := bootTime()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return boot.Add(time.Duration(ticks) * time.Second / clockTicks), nil
+}
+
+// parseStartTicks extracts the starttime field from /proc/<pid>/stat.
+// Fields are counted from after the parenthesized command name, which may itself contain spaces and parentheses.
+func parseStartTicks(stat []byte) (uint64, error) {
+	i := bytes.LastIndexByte(stat, ')')
+	if i < 0 {
+		return 0, errors.New("stat: malformed")
+	}
+	// starttime is field 22; the fields after the command name start at field 3.
+	fields := strings.Fields(string(stat[i+1:]))
+	if len(fields) < 20 {
+		return 0, errors.New("stat: malformed")
+	}
+	ticks, err := strconv.ParseUint(fields[19], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("stat: %w", err)
+	}
+	return ticks, nil
+}
+
+func bootTime() (time.Time, error) {
+	data, err := os.ReadFile(filepath.Join(procRoot, "stat"))
+	if err != nil {
+		return time.Time{}, err
+	}
+	for line := range strings.Lines(string(data)) {
+		if v, ok := strings.CutPrefix(line, "btime "); ok {
+			sec, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("btime: %w", err)
+			}
+			// btime is truncated to the second, so a second is added to avoid flagging files modified just before the process started.
+			return time.Unix(sec+1, 0), nil
+		}
+	}
+	return time.Time{}, errors.New("btime not found")
+}
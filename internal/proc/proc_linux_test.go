@@ -0,0 +1,82 @@
+//go:build linux
+
+package proc
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseMaps(t *testing.T) {
+	maps := `55d0c8a00000-55d0c8a28000 r--p 00000000 fd:01 1310742                    /usr/bin/app
+55d0c8a28000-55d0c8b00000 r-xp 00028000 fd:01 1310742                    /usr/bin/app
+55d0c9e5b000-55d0c9e7c000 rw-p 00000000 00:00 0                          [heap]
+7f1c2a000000-7f1c2a028000 r--p 00000000 fd:01 1311001                    /usr/lib/x86_64-linux-gnu/libc.so.6
+7f1c2a100000-7f1c2a101000 r--p 00000000 fd:01 1311999                    /opt/my app/libplugin.so (deleted)
+7f1c2a200000-7f1c2a300000 rw-s 00000000 00:05 4                          /dev/dri/card0
+7f1c2a300000-7f1c2a301000 rw-s 00000000 00:01 32770                      /SYSV00000000 (deleted)
+7f1c2a400000-7f1c2a401000 r-xp 00000000 00:01 2048                       /memfd:jit (deleted)
+7ffd4a1f0000-7ffd4a1f2000 r-xp 00000000 00:00 0                          [vdso]
+`
+	got, err := parseMaps(strings.NewReader(maps))
+	if err != nil {
+		t.Fatalf("parseMaps() error = %v", err)
+	}
+	want := []mapping{
+		{addresses: "55d0c8a00000-55d0c8a28000", key: "fd:01:1310742", path: "/usr/bin/app"},
+		{addresses: "7f1c2a000000-7f1c2a028000", key: "fd:01:1311001", path: "/usr/lib/x86_64-linux-gnu/libc.so.6"},
+		{addresses: "7f1c2a100000-7f1c2a101000", key: "fd:01:1311999", path: "/opt/my app/libplugin.so", deleted: true},
+		{addresses: "7f1c2a400000-7f1c2a401000", key: "00:01:2048", path: "/memfd:jit", deleted: true},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("parseMaps() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseStartTicks(t *testing.T) {
+	stat := "1234 (my (odd) cmd) S 1 1234 1234 0 -1 4194560 100 0 0 0 1 2 0 0 20 0 1 0 98765 1000000 200 18446744073709551615"
+	got, err := parseStartTicks([]byte(stat))
+	if err != nil {
+		t.Fatalf("parseStartTicks() error = %v", err)
+	}
+	if got != 98765 {
+		t.Errorf("parseStartTicks() = %d, want 98765", got)
+	}
+	if _, err := parseStartTicks([]byte("1234 (cmd) S 1")); err == nil {
+		t.Error("parseStartTicks() on truncated stat succeeded, want error")
+	}
+}
+
+func TestParseCmdline(t *testing.T) {
+	if got := parseCmdline([]byte("/usr/sbin/nginx\x00-g\x00daemon off;\x00")); got != "/usr/sbin/nginx -g daemon off;" {
+		t.Errorf("parseCmdline() = %q", got)
+	}
+}
+
+func TestSetAddSelf(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := NewSet()
+	for range 2 {
+		if err := set.Add(os.Getpid()); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	images := set.Images()
+	if len(images) == 0 || images[0].Path != exe {
+		t.Fatalf("first image = %+v, want executable %s", images, exe)
+	}
+	if len(images[0].Processes) != 1 || images[0].Processes[0].PID != os.Getpid() {
+		t.Errorf("processes = %+v, want only this process", images[0].Processes)
+	}
+	f, err := images[0].Open()
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	_ = f.Close()
+}
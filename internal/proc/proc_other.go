@@ -0,0 +1,17 @@
+//go:build !linux
+
+package proc
+
+import "errors"
+
+var errUnsupported = errors.New("process scanning is only supported on Linux")
+
+// List returns the PIDs of all running processes.
+func List() ([]int, error) {
+	return nil, errUnsupported
+}
+
+// Add inspects the process pid and adds its executable and mapped files to the set.
+func (s *Set) Add(int) error {
+	return errUnsupported
+}
@@ -0,0 +1,270 @@
+// Package resultcache stores analysis results on disk, keyed by the SHA256 of the analyzed file, so that unchanged files
+// aren't analyzed again by later scans.
+//
+// Results depend on more than the file: the crack build, the selected rules, and the settings affecting analysis.
+// These are hashed into a fingerprint, and every fingerprint has a directory of its own under the cache root, so a change
+// to any of them makes earlier entries unreachable rather than stale. Entries that are no longer read are removed by Prune.
+package resultcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/internal/analyzer"
+	"go.kacmar.sk/crack/internal/archive"
+	"go.kacmar.sk/crack/internal/version"
+	"go.kacmar.sk/crack/rule"
+)
+
+// entryFormat is the version of the on-disk entry layout, part of every fingerprint.
+const entryFormat = 5
+
+// entrySuffix is the file name extension of cache entries.
+const entrySuffix = ".json"
+
+// DefaultDir returns the default cache root, next to the debuginfod cache in the user cache directory.
+func DefaultDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("result cache: failed to determine user cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "crack", "results"), nil
+}
+
+type Options struct {
+	// Dir is the cache root. Empty means DefaultDir.
+	Dir string
+	// RuleIDs are the IDs of the rules run on every file, in any order.
+	RuleIDs []string
+	// Settings lists any other settings the results depend on, such as enabled debug info sources, in a fixed order.
+	Settings []string
+	Logger   *slog.Logger
+}
+
+// Cache maps file hashes to the results of analyzing the file under one fingerprint.
+type Cache struct {
+	dir    string
+	logger *slog.Logger
+}
+
+// Open returns the cache for the fingerprint of opts, creating its directory if needed.
+func Open(opts Options) (*Cache, error) {
+	root := opts.Dir
+	if root == "" {
+		var err error
+		if root, err = DefaultDir(); err != nil {
+			return nil, err
+		}
+	}
+	dir := filepath.Join(root, Fingerprint(opts.RuleIDs, opts.Settings))
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("result cache: %w", err)
+	}
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Cache{dir: dir, logger: logger.With(slog.String("component", "resultcache"))}, nil
+}
+
+// Fingerprint identifies the crack build, rule set and settings results are computed with.
+// Development builds all share the version "dev", so the commit is included as well.
+func Fingerprint(ruleIDs, settings []string) string {
+	ids := slices.Clone(ruleIDs)
+	slices.Sort(ids)
+	h := sha256.New()
+	fmt.Fprintf(h, "format %d\nversion %s\ncommit %s\nrules %s\n", entryFormat, version.Version, version.GitCommit, strings.Join(ids, ","))
+	for _, s := range settings {
+		fmt.Fprintf(h, "setting %s\n", s)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// result is the stored form of an analyzer.FileResult. Errors aren't stored, as they may not recur.
+type result struct {
+	// Member is the path of the result relative to the analyzed file, such as "!/usr/bin/foo" for an archive member,
+	// or "" for the file itself.
+	Member   string           `json:"member,omitempty"`
+	Package  *archive.Package `json:"package,omitempty"`
+	Layer    string           `json:"layer,omitempty"`
+	ABI      string           `json:"abi,omitempty"`
+	Slice    string           `json:"slice,omitempty"`
+	Format   binary.Format    `json:"format"`
+	Identity binary.Identity  `json:"identity"`
+	Profile  binary.Profile   `json:"profile"`
+	Findings []rule.Finding   `json:"findings"`
+	Skipped  bool             `json:"skipped,omitempty"`
+}
+
+// Get returns the results stored for the file with the given hash, reported under path.
+// A hit refreshes the entry's modification time, which Prune uses to tell entries still in use.
+func (c *Cache) Get(sum, path string) ([]analyzer.FileResult, bool) {
+	p := c.entryPath(sum)
+	data, err := os.ReadFile(p) // #nosec G304 -- path built from a hex digest under the cache directory
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			c.logger.Warn("failed to read cache entry", slog.String("entry", p), slog.Any("error", err))
+		}
+		return nil, false
+	}
+	var stored []result
+	if err := json.Unmarshal(data, &stored); err != nil {
+		c.logger.Warn("ignoring malformed cache entry", slog.String("entry", p), slog.Any("error", err))
+		return nil, false
+	}
+
+	now := time.Now()
+	if err := os.Chtimes(p, now, now); err != nil {
+		c.logger.Debug("failed to touch cache entry", slog.String("entry", p), slog.Any("error", err))
+	}
+
+	results := make([]analyzer.FileResult, len(stored))
+	for i, r := range stored {
+		results[i] = analyzer.FileResult{
+			Path:     path + r.Member,
+			Package:  r.Package,
+			Layer:    r.Layer,
+			ABI:      r.ABI,
+			Slice:    r.Slice,
+			Format:   r.Format,
+			Identity: r.Identity,
+			Profile:  r.Profile,
+			Findings: r.Findings,
+			Skipped:  r.Skipped,
+		}
+	}
+	return results, true
+}
+
+// Put stores the results of analyzing the file at path with the given hash.
+// Results including an error aren't stored, so that the file is analyzed again by the next scan.
+func (c *Cache) Put(sum, path string, results []analyzer.FileResult) error {
+	stored := make([]result, len(results))
+	for i, r := range results {
+		if r.Error != nil {
+			return nil
+		}
+		stored[i] = result{
+			Member:   strings.TrimPrefix(r.Path, path),
+			Package:  r.Package,
+			Layer:    r.Layer,
+			ABI:      r.ABI,
+			Slice:    r.Slice,
+			Format:   r.Format,
+			Identity: r.Identity,
+			Profile:  r.Profile,
+			Findings: r.Findings,
+			Skipped:  r.Skipped,
+		}
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+
+	p := c.entryPath(sum)
+	if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+		return err
+	}
+	// Entries are renamed into place, so that concurrent scans never read a partial entry.
+	tmp, err := os.CreateTemp(filepath.Dir(p), "."+sum+"-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// entryPath spreads entries over subdirectories named by the first byte of the hash.
+func (c *Cache) entryPath(sum string) string {
+	return filepath.Join(c.dir, sum[:2], sum+entrySuffix)
+}
+
+// cacheFile and cacheDir match the names of the files and directories the cache creates, so that pointing Prune at the wrong
+// directory doesn't remove anything else. Files are entries and temporary files left by interrupted writes.
+var (
+	cacheFile = regexp.MustCompile(`^(?:[0-9a-f]{64}\.json|\.[0-9a-f]{64}-[0-9]+)$`)
+	cacheDir  = regexp.MustCompile(`^(?:[0-9a-f]{2}|[0-9a-f]{16})$`)
+)
+
+// PruneStats summarizes what Prune removed.
+type PruneStats struct {
+	Entries int
+	Bytes   int64
+}
+
+// Prune removes the entries under root not read or written since before. A zero before removes every entry.
+// Directories left empty, such as those of fingerprints no longer in use, are removed as well.
+func Prune(root string, before time.Time) (PruneStats, error) {
+	var stats PruneStats
+	var dirs []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && p == root {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if d.IsDir() {
+			if p != root {
+				dirs = append(dirs, p)
+			}
+			return nil
+		}
+		if !cacheFile.MatchString(d.Name()) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !before.IsZero() && !info.ModTime().Before(before) {
+			return nil
+		}
+		if err := os.Remove(p); err != nil {
+			return err
+		}
+		stats.Entries++
+		stats.Bytes += info.Size()
+		return nil
+	})
+	if err != nil {
+		return stats, fmt.Errorf("result cache: %w", err)
+	}
+
+	// Deepest directories first, so that parents emptied by removing their children are removed too.
+	slices.Reverse(dirs)
+	for _, dir := range dirs {
+		if !cacheDir.MatchString(filepath.Base(dir)) {
+			continue
+		}
+		if entries, err := os.ReadDir(dir); err == nil && len(entries) == 0 {
+			_ = os.Remove(dir)
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,145 @@
+package resultcache
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/internal/analyzer"
+	"go.kacmar.sk/crack/internal/archive"
+	"go.kacmar.sk/crack/rule"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestCacheRoundTrip(t *testing.T) {
+	c, err := Open(Options{Dir: t.TempDir(), RuleIDs: []string{"pie", "nx"}, Logger: testLogger})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := strings.Repeat("ab", 32)
+	findings := []rule.Finding{{Result: rule.Result{Status: rule.StatusFailed, Message: "not PIE"}, RuleID: "pie", Name: "PIE"}}
+	results := []analyzer.FileResult{
+		{
+			Path:     "/old/foo.deb!/usr/bin/foo",
+			Package:  &archive.Package{Name: "foo", Version: "1.0"},
+			Format:   binary.FormatELF,
+			Identity: binary.Identity{BuildID: "1234", SHA256: "cdcd"},
+			Profile:  binary.Profile{Architecture: binary.ArchAMD64, Kind: binary.KindExecutable},
+			Findings: findings,
+		},
+		{Path: "/old/foo.deb!/usr/share/doc", Skipped: true},
+	}
+
+	if _, ok := c.Get(sum, "/old/foo.deb"); ok {
+		t.Fatal("Get() hit on an empty cache")
+	}
+	if err := c.Put(sum, "/old/foo.deb", results); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	got, ok := c.Get(sum, "/new/foo.deb")
+	if !ok {
+		t.Fatal("Get() missed a stored entry")
+	}
+	if len(got) != 2 {
+		t.Fatalf("Get() returned %d results, want 2", len(got))
+	}
+	if got[0].Path != "/new/foo.deb!/usr/bin/foo" || got[1].Path != "/new/foo.deb!/usr/share/doc" {
+		t.Errorf("paths = %q, %q, want them under /new/foo.deb", got[0].Path, got[1].Path)
+	}
+	if *got[0].Package != *results[0].Package || got[0].Identity != results[0].Identity || got[0].Profile != results[0].Profile {
+		t.Errorf("result = %+v, want %+v", got[0], results[0])
+	}
+	if !slices.Equal(got[0].Findings, findings) || !got[1].Skipped {
+		t.Errorf("findings = %+v, skipped = %v", got[0].Findings, got[1].Skipped)
+	}
+
+	// A failed analysis isn't stored.
+	failed := strings.Repeat("ef", 32)
+	if err := c.Put(failed, "/bad", []analyzer.FileResult{{Path: "/bad", Error: errors.New("truncated")}}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, ok := c.Get(failed, "/bad"); ok {
+		t.Error("Get() hit for a failed analysis")
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	base := Fingerprint([]string{"pie", "nx"}, []string{"archive-depth=3"})
+	if got := Fingerprint([]string{"nx", "pie"}, []string{"archive-depth=3"}); got != base {
+		t.Errorf("fingerprint depends on rule order: %s != %s", got, base)
+	}
+	for _, other := range []string{
+		Fingerprint([]string{"pie"}, []string{"archive-depth=3"}),
+		Fingerprint([]string{"pie", "nx"}, []string{"archive-depth=1"}),
+		Fingerprint([]string{"pie", "nx"}, nil),
+	} {
+		if other == base {
+			t.Errorf("fingerprint %s doesn't change with the rules or settings", other)
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	root := t.TempDir()
+	oldCache, err := Open(Options{Dir: root, RuleIDs: []string{"old"}, Logger: testLogger})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := Open(Options{Dir: root, RuleIDs: []string{"new"}, Logger: testLogger})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale, fresh := strings.Repeat("11", 32), strings.Repeat("22", 32)
+	for _, put := range []struct {
+		cache *Cache
+		sum   string
+	}{{oldCache, stale}, {c, stale}, {c, fresh}} {
+		if err := put.cache.Put(put.sum, "/f", []analyzer.FileResult{{Path: "/f"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lastWeek := time.Now().Add(-7 * 24 * time.Hour)
+	for _, p := range []string{oldCache.entryPath(stale), c.entryPath(stale)} {
+		if err := os.Chtimes(p, lastWeek, lastWeek); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A hit refreshes the entry, so it survives.
+	if _, ok := c.Get(stale, "/f"); !ok {
+		t.Fatal("Get() missed a stored entry")
+	}
+	foreign := filepath.Join(root, "notes.txt")
+	if err := os.WriteFile(foreign, []byte("keep"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := Prune(root, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if stats.Entries != 1 {
+		t.Errorf("Prune() removed %d entries, want 1", stats.Entries)
+	}
+	if _, err := os.Stat(oldCache.dir); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("directory of the unused fingerprint wasn't removed: %v", err)
+	}
+
+	if stats, err = Prune(root, time.Time{}); err != nil || stats.Entries != 2 {
+		t.Errorf("Prune(all) = %+v, %v, want 2 entries removed", stats, err)
+	}
+	if _, err := os.Stat(foreign); err != nil {
+		t.Errorf("Prune() removed a file it doesn't own: %v", err)
+	}
+	if stats, err := Prune(filepath.Join(root, "missing"), time.Time{}); err != nil || stats.Entries != 0 {
+		t.Errorf("Prune(missing) = %+v, %v, want nothing removed", stats, err)
+	}
+}
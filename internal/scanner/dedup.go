@@ -0,0 +1,157 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/internal/analyzer"
+)
+
+// fileGroup is a set of paths with identical content, analyzed once under the first path.
+type fileGroup struct {
+	paths []string
+	// sha256 is the hex SHA256 of the content, or "" when it couldn't be computed and the paths were grouped by inode alone.
+	sha256 string
+	// meta is the file metadata shared by the paths, as it decides which rules apply.
+	meta *binary.FileMetadata
+	// reported holds the Path and Slice of the results analyzed from the first path once they were streamed, so that paths
+	// joining the group afterwards are reported for them in follow-up records. It is nil until then.
+	reported []analyzer.FileResult
+}
+
+// deduper groups the files of a scan by content as they are discovered.
+// Hard links and symlinks resolving to the same inode are grouped without reading them; the first path of every inode
+// is then hashed and inodes with the same SHA256 are merged. A file that can't be hashed keeps its inode group,
+// and a file that can't be stat'ed is a group of its own, so that the error is reported when it is scanned.
+//
+// Results are streamed as soon as they are analyzed, with the paths of their group so far as aliases. Paths joining the
+// group later are reported by records with LateAliases set.
+type deduper struct {
+	mu      sync.Mutex
+	byInode map[fileID]*fileGroup
+	byHash  map[string]*fileGroup
+}
+
+func newDeduper() *deduper {
+	return &deduper{
+		byInode: make(map[fileID]*fileGroup),
+		byHash:  make(map[string]*fileGroup),
+	}
+}
+
+// add registers the path p and returns the group to analyze it as, or nil when p has the same content as a file already
+// registered and was added to its group. Files are only grouped when their metadata matches too. When the results of the
+// group p joined were already streamed, late holds the records reporting p as their alias.
+func (d *deduper) add(p string) (group *fileGroup, late []analyzer.FileResult) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return &fileGroup{paths: []string{p}}, nil
+	}
+	id, hasID := inode(info)
+	// Failing to read capabilities is reported when the file is scanned.
+	meta, _ := fileMetadata(p, info)
+
+	group = &fileGroup{paths: []string{p}, meta: meta}
+	if hasID {
+		d.mu.Lock()
+		if existing := d.byInode[id]; existing != nil {
+			late = existing.join(p)
+			d.mu.Unlock()
+			return nil, late
+		}
+		d.byInode[id] = group
+		d.mu.Unlock()
+	}
+
+	sum, err := hashPath(p)
+	if err != nil {
+		return group, nil
+	}
+
+	key := groupKey(sum, meta)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if existing := d.byHash[key]; existing != nil {
+		// Links to this inode registered meanwhile move along with it.
+		if hasID {
+			d.byInode[id] = existing
+		}
+		return nil, existing.join(group.paths...)
+	}
+	group.sha256 = sum
+	d.byHash[key] = group
+	return group, nil
+}
+
+// join adds paths to the group and returns the records reporting them as aliases of the results already streamed.
+// The caller holds the deduper's lock.
+func (g *fileGroup) join(paths ...string) []analyzer.FileResult {
+	g.paths = append(g.paths, paths...)
+	if g.reported == nil {
+		return nil
+	}
+	late := make([]analyzer.FileResult, len(g.reported))
+	for i, res := range g.reported {
+		late[i] = analyzer.FileResult{Path: res.Path, Slice: res.Slice, Skipped: res.Skipped, LateAliases: true}
+		late[i].Aliases = memberAliases(res.Path, g.paths[0], paths)
+	}
+	return late
+}
+
+// groupKey returns the key grouping files with content sum and metadata meta.
+func groupKey(sum string, meta *binary.FileMetadata) string {
+	if meta == nil {
+		return sum
+	}
+	key := fmt.Sprintf("%s %o %d:%d", sum, meta.Mode, meta.UID, meta.GID)
+	if meta.Capabilities != nil {
+		key += fmt.Sprintf(" %+v", *meta.Capabilities)
+	}
+	return key
+}
+
+// finish returns the results of analyzing group with the paths that joined it so far as aliases. Paths joining it later
+// are reported by add.
+func (d *deduper) finish(group *fileGroup, results []analyzer.FileResult) []analyzer.FileResult {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	group.reported = make([]analyzer.FileResult, len(results))
+	for i, res := range results {
+		group.reported[i] = analyzer.FileResult{Path: res.Path, Slice: res.Slice, Skipped: res.Skipped}
+	}
+	return withAliases(results, group)
+}
+
+// withAliases sets the Aliases of results analyzed from the first path of group to the other paths of the group.
+// Results for archive members get the member path under every alias of the archive.
+func withAliases(results []analyzer.FileResult, group *fileGroup) []analyzer.FileResult {
+	if len(group.paths) < 2 {
+		return results
+	}
+	for i := range results {
+		results[i].Aliases = append(results[i].Aliases, memberAliases(results[i].Path, group.paths[0], group.paths[1:])...)
+	}
+	return results
+}
+
+// memberAliases returns the path under each of aliases of the result path, which was analyzed from primary.
+func memberAliases(path, primary string, aliases []string) []string {
+	member := strings.TrimPrefix(path, primary)
+	paths := make([]string, len(aliases))
+	for i, alias := range aliases {
+		paths[i] = alias + member
+	}
+	return paths
+}
+
+func hashPath(p string) (string, error) {
+	f, err := os.Open(p) // #nosec G304 -- user-provided paths are the tool's input
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return hashFile(f)
+}
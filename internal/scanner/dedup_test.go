@@ -0,0 +1,131 @@
+package scanner
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"go.kacmar.sk/crack/internal/analyzer"
+)
+
+func TestDeduper(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	libA := write("liba.so", "same")
+	copyA := write("liba-copy.so", "same")
+	libB := write("libb.so", "other")
+	link := filepath.Join(dir, "liba.so.1")
+	if err := os.Symlink("liba.so", link); err != nil {
+		t.Fatal(err)
+	}
+	hardlink := filepath.Join(dir, "libb.so.1")
+	if err := os.Link(libB, hardlink); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing.so")
+
+	d := newDeduper()
+	var groups []*fileGroup
+	add := func(p string) []analyzer.FileResult {
+		group, late := d.add(p)
+		if group != nil {
+			groups = append(groups, group)
+		}
+		return late
+	}
+	for _, p := range []string{libA, libB, link, missing} {
+		if late := add(p); late != nil {
+			t.Errorf("add(%s) = %+v, want no follow-up before the results are streamed", p, late)
+		}
+	}
+
+	// Results flow as soon as they are analyzed, with the paths grouped so far.
+	if res := d.finish(groups[0], []analyzer.FileResult{{Path: libA}}); len(res) != 1 || !slices.Equal(res[0].Aliases, []string{link}) {
+		t.Errorf("finish() = %+v, want %s with aliases so far", res, libA)
+	}
+	late := add(copyA)
+	if len(late) != 1 || late[0].Path != libA || !late[0].LateAliases || !slices.Equal(late[0].Aliases, []string{copyA}) {
+		t.Errorf("add() after finish() = %+v, want a follow-up listing %s", late, copyA)
+	}
+	if late := add(hardlink); late != nil {
+		t.Errorf("add(%s) = %+v, want no follow-up before the results are streamed", hardlink, late)
+	}
+	if res := d.finish(groups[1], []analyzer.FileResult{{Path: libB}}); len(res) != 1 || !slices.Equal(res[0].Aliases, []string{hardlink}) {
+		t.Errorf("finish() = %+v, want %s with aliases", res, libB)
+	}
+
+	var got [][]string
+	for _, g := range groups {
+		got = append(got, g.paths)
+	}
+	want := [][]string{{libA, link, copyA}, {libB, hardlink}, {missing}}
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("groups = %v, want %v", got, want)
+	}
+	if groups[0].sha256 != hashBytes([]byte("same")) {
+		t.Errorf("sha256 = %q, want the content hash", groups[0].sha256)
+	}
+}
+
+func TestWithAliases(t *testing.T) {
+	group := &fileGroup{paths: []string{"/a/app.tar", "/b/app.tar"}}
+	results := withAliases([]analyzer.FileResult{{Path: "/a/app.tar!/bin/app"}, {Path: "/a/app.tar"}}, group)
+
+	want := [][]string{{"/b/app.tar!/bin/app"}, {"/b/app.tar"}}
+	for i, res := range results {
+		if !slices.Equal(res.Aliases, want[i]) {
+			t.Errorf("result %q aliases = %v, want %v", res.Path, res.Aliases, want[i])
+		}
+	}
+}
+
+func TestScanPaths(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"a": "same", "b": "same", "c": "other"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	paths := []string{filepath.Join(dir, "a"), filepath.Join(dir, "b"), filepath.Join(dir, "c")}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	newScanner := func() *Scanner {
+		return NewScanner(analyzer.NewDispatcher(analyzer.DispatcherOptions{Logger: logger}), Options{Logger: logger, Workers: 2})
+	}
+
+	t.Run("deduplicates", func(t *testing.T) {
+		s := newScanner()
+		got := make(map[string][]string)
+		for res := range s.ScanPaths(context.Background(), paths, false) {
+			got[res.Path] = append(got[res.Path], res.Aliases...)
+		}
+		// Both a and b may be analyzed first, depending on which worker hashes its file first.
+		if len(got) != 2 || got[paths[2]] != nil {
+			t.Fatalf("results = %v, want a or b with an alias, and c", got)
+		}
+		if !slices.Equal(got[paths[0]], paths[1:2]) && !slices.Equal(got[paths[1]], paths[0:1]) {
+			t.Errorf("results = %v, want a or b with the other as alias", got)
+		}
+		if progress := s.Progress(); progress != (Progress{Found: 3, Analyzed: 3}) {
+			t.Errorf("Progress() = %+v, want 3 found and analyzed", progress)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		for res := range newScanner().ScanPaths(ctx, []string{dir}, true) {
+			t.Errorf("unexpected result %q after cancellation", res.Path)
+		}
+	})
+}
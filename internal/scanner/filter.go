@@ -0,0 +1,188 @@
+package scanner
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"go.kacmar.sk/crack/internal/analyzer"
+	"go.kacmar.sk/crack/internal/archive"
+)
+
+// IgnoreFileName is the name of the files listing paths to leave out of directory walks, in a subset of the .gitignore syntax.
+const IgnoreFileName = ".crackignore"
+
+// FilterStats counts the paths left out of directory walks and archives at each filtering stage.
+// A directory pruned by a pattern counts once, whatever it contains.
+type FilterStats struct {
+	// Excluded counts paths not matching --include or matching --exclude.
+	Excluded int64
+	// Ignored counts paths matched by a .crackignore file.
+	Ignored int64
+	// NotBinary counts files whose leading bytes match neither a binary format nor, when enabled, an archive format,
+	// and files that aren't regular, such as FIFOs, sockets and devices. Archive members are counted here too.
+	NotBinary int64
+}
+
+// Total returns the number of paths filtered at any stage.
+func (s FilterStats) Total() int64 {
+	return s.Excluded + s.Ignored + s.NotBinary
+}
+
+// filterCounters accumulates FilterStats while walks are in progress.
+type filterCounters struct {
+	excluded, ignored, notBinary atomic.Int64
+}
+
+func (c *filterCounters) stats() FilterStats {
+	return FilterStats{Excluded: c.excluded.Load(), Ignored: c.ignored.Load(), NotBinary: c.notBinary.Load()}
+}
+
+// matchGlob reports whether the slash-separated name matches pattern.
+// Patterns follow path.Match, extended with "**" segments matching any number of path segments.
+// A pattern without a slash is matched against the last element of name only.
+func matchGlob(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+	return matchSegments(strings.Split(strings.TrimPrefix(pattern, "/"), "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// ignoreRule is a pattern read from an ignore file, applying to paths below the directory holding the file.
+type ignoreRule struct {
+	dir     string
+	pattern string
+	negate  bool
+	dirOnly bool
+}
+
+// readIgnoreFile reads the rules of the ignore file in dir, if there is one.
+// Lines are glob patterns; blank lines and lines starting with "#" are skipped, a leading "!" re-includes a path
+// ignored by an earlier pattern, and a trailing "/" restricts the pattern to directories.
+func readIgnoreFile(dir string) ([]ignoreRule, error) {
+	f, err := os.Open(filepath.Join(dir, IgnoreFileName)) // #nosec G304 -- ignore files live in user-provided directories
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var rules []ignoreRule
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{dir: dir}
+		line, rule.negate = strings.CutPrefix(line, "!")
+		line, rule.dirOnly = strings.CutSuffix(line, "/")
+		rule.pattern = line
+		rules = append(rules, rule)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Join(dir, IgnoreFileName), err)
+	}
+	return rules, nil
+}
+
+// isIgnored reports whether p is ignored by rules. Later rules take precedence, so rules from deeper directories override outer ones.
+func isIgnored(rules []ignoreRule, p string, isDir bool) bool {
+	ignored := false
+	for _, rule := range rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		rel, err := filepath.Rel(rule.dir, p)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+			continue
+		}
+		if matchGlob(rule.pattern, filepath.ToSlash(rel)) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// isExcluded reports whether the --include and --exclude patterns leave out p, relative to the walked root.
+// Includes only select files, so that directories are still descended into.
+func (s *Scanner) isExcluded(root, p string, isDir bool) bool {
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		rel = p
+	}
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range s.exclude {
+		if matchGlob(pattern, rel) {
+			return true
+		}
+	}
+	if isDir || len(s.include) == 0 {
+		return false
+	}
+	for _, pattern := range s.include {
+		if matchGlob(pattern, rel) {
+			return false
+		}
+	}
+	return true
+}
+
+// isCandidate reports whether the file at p, whose type bits are typ, might be a binary or, when archives are descended into, an archive.
+// Only the leading bytes are read, so data files are dropped before any parsing. Symlinks are resolved, and files that aren't regular
+// once resolved are dropped without being opened, as opening a FIFO or a device could block or have side effects. Files that can't
+// be read are kept, so that the error is reported when they are scanned.
+func (s *Scanner) isCandidate(p string, typ fs.FileMode) bool {
+	if typ&fs.ModeSymlink != 0 {
+		info, err := os.Stat(p)
+		if err != nil {
+			return true
+		}
+		typ = info.Mode().Type()
+	}
+	if !typ.IsRegular() {
+		return false
+	}
+
+	f, err := os.Open(p) // #nosec G304 -- user-provided paths are the tool's input
+	if err != nil {
+		return true
+	}
+	defer f.Close()
+
+	magic := make([]byte, analyzer.MagicSize)
+	n, _ := f.ReadAt(magic, 0)
+	if s.dispatcher.Recognizes(magic[:n]) {
+		return true
+	}
+	return s.archiveDepth > 0 && archive.Detect(f) != nil
+}
@@ -0,0 +1,90 @@
+package scanner
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"go.kacmar.sk/crack/internal/analyzer"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.so", "usr/lib/libc.so", true},
+		{"*.so", "usr/lib/libc.so.6", false},
+		{"*.so*", "usr/lib/libc.so.6", true},
+		{"usr/lib/*", "usr/lib/libc.so", true},
+		{"usr/lib/*", "usr/lib/x86_64/libc.so", false},
+		{"usr/**/libc.so", "usr/lib/x86_64/libc.so", true},
+		{"usr/**/libc.so", "usr/libc.so", true},
+		{"/share/**", "share/doc/README", true},
+		{"share/**", "usr/share/doc", false},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCollectFilesFilters(t *testing.T) {
+	root := t.TempDir()
+	elf := "\x7fELF\x02\x01\x01"
+	files := map[string]string{
+		"bin/app":               elf,
+		"bin/app.debug":         elf,
+		"lib/libfoo.so":         elf,
+		"lib/keep/libbar.so":    elf,
+		"build/out/tool":        elf,
+		"share/doc.txt":         "text",
+		"share/empty":           "",
+		IgnoreFileName:          "# build trees\nbuild/\n*.debug\n",
+		"lib/" + IgnoreFileName: "*.so\n!keep/*.so\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	dispatcher := analyzer.NewDispatcher(analyzer.DispatcherOptions{
+		Analyzers: []analyzer.FormatAnalyzer{analyzer.NewELFAnalyzer(analyzer.ELFAnalyzerOptions{Logger: logger})},
+		Logger:    logger,
+	})
+	s := NewScanner(dispatcher, Options{
+		Logger:  logger,
+		Workers: 1,
+		Exclude: []string{"share/**"},
+	})
+	var got []string
+	err := s.collectFiles(context.Background(), root, true, func(p string) error {
+		got = append(got, p)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("collectFiles() error = %v", err)
+	}
+
+	want := []string{filepath.Join(root, "bin/app"), filepath.Join(root, "lib/keep/libbar.so")}
+	if !slices.Equal(got, want) {
+		t.Errorf("collectFiles() = %v, want %v", got, want)
+	}
+	// share/ is pruned by --exclude; build/, app.debug and libfoo.so are ignored; both ignore files aren't binaries.
+	wantStats := FilterStats{Excluded: 1, Ignored: 3, NotBinary: 2}
+	if stats := s.FilterStats(); stats != wantStats {
+		t.Errorf("FilterStats() = %+v, want %+v", stats, wantStats)
+	}
+}
@@ -0,0 +1,68 @@
+//go:build unix
+
+package scanner
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"syscall"
+	"testing"
+	"time"
+
+	"go.kacmar.sk/crack/internal/analyzer"
+)
+
+func TestCollectFilesSkipsFIFOs(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "app"), []byte("\x7fELF\x02\x01\x01"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Mkfifo(filepath.Join(root, "pipe"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("pipe", filepath.Join(root, "pipe-link")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("app", filepath.Join(root, "app-link")); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	dispatcher := analyzer.NewDispatcher(analyzer.DispatcherOptions{
+		Analyzers: []analyzer.FormatAnalyzer{analyzer.NewELFAnalyzer(analyzer.ELFAnalyzerOptions{Logger: logger})},
+		Logger:    logger,
+	})
+
+	for _, recursive := range []bool{false, true} {
+		s := NewScanner(dispatcher, Options{Logger: logger, Workers: 1})
+		var got []string
+		done := make(chan error, 1)
+		go func() {
+			// Opening a FIFO without a writer blocks, so a regression hangs here rather than failing.
+			done <- s.collectFiles(context.Background(), root, recursive, func(p string) error {
+				got = append(got, p)
+				return nil
+			})
+		}()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("collectFiles(recursive=%v) error = %v", recursive, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("collectFiles(recursive=%v) blocked on a FIFO", recursive)
+		}
+
+		want := []string{filepath.Join(root, "app"), filepath.Join(root, "app-link")}
+		if !slices.Equal(got, want) {
+			t.Errorf("collectFiles(recursive=%v) = %v, want %v", recursive, got, want)
+		}
+		if stats := s.FilterStats(); stats != (FilterStats{NotBinary: 2}) {
+			t.Errorf("FilterStats(recursive=%v) = %+v, want the FIFO and its symlink as not binary", recursive, stats)
+		}
+	}
+}
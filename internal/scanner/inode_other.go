@@ -0,0 +1,15 @@
+//go:build !unix
+
+package scanner
+
+import "io/fs"
+
+// fileID identifies a file by device and inode number.
+type fileID struct {
+	dev, ino uint64
+}
+
+// inode reports no identity, as inode numbers aren't exposed here; files are grouped by content alone.
+func inode(fs.FileInfo) (fileID, bool) {
+	return fileID{}, false
+}
@@ -0,0 +1,22 @@
+//go:build unix
+
+package scanner
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// fileID identifies a file by device and inode number.
+type fileID struct {
+	dev, ino uint64
+}
+
+// inode returns the identity of the file described by info.
+func inode(info fs.FileInfo) (fileID, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileID{}, false
+	}
+	return fileID{dev: uint64(st.Dev), ino: uint64(st.Ino)}, true // #nosec G115 -- device numbers are unsigned, Dev is signed on some platforms
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package scanner
+
+import (
+	"io/fs"
+
+	"go.kacmar.sk/crack/binary"
+)
+
+// fileMetadata returns nil, as files have no Unix ownership and modes on this platform.
+func fileMetadata(string, fs.FileInfo) (*binary.FileMetadata, error) {
+	return nil, nil
+}
@@ -0,0 +1,27 @@
+//go:build unix
+
+package scanner
+
+import (
+	"io/fs"
+	"syscall"
+
+	"go.kacmar.sk/crack/binary"
+)
+
+// fileMetadata returns the metadata of the file at path described by info. The metadata is returned along with an error
+// when only the capabilities couldn't be read.
+func fileMetadata(path string, info fs.FileInfo) (*binary.FileMetadata, error) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil, nil
+	}
+	meta := &binary.FileMetadata{
+		Mode: info.Mode() & (fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky),
+		UID:  st.Uid,
+		GID:  st.Gid,
+	}
+	caps, err := readCapabilities(path)
+	meta.Capabilities = caps
+	return meta, err
+}
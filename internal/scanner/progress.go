@@ -0,0 +1,46 @@
+package scanner
+
+import (
+	"sync/atomic"
+
+	"go.kacmar.sk/crack/internal/analyzer"
+)
+
+// Progress counts the files of a scan in progress.
+type Progress struct {
+	// Found counts the files discovered so far, after filtering.
+	Found int64
+	// Analyzed counts the files whose scan is complete, including those found to duplicate another file.
+	Analyzed int64
+	// Failed counts the analyzed files that couldn't be read or parsed, in whole or in part.
+	Failed int64
+	// Cached counts the analyzed files whose results were taken from the result cache.
+	Cached int64
+}
+
+// progressCounters accumulates Progress while the scan is running.
+type progressCounters struct {
+	found, analyzed, failed, cached atomic.Int64
+}
+
+// Progress returns the counts of the scan so far. It may be called while the scan is running.
+func (s *Scanner) Progress() Progress {
+	return Progress{
+		Found:    s.progress.found.Load(),
+		Analyzed: s.progress.analyzed.Load(),
+		Failed:   s.progress.failed.Load(),
+		Cached:   s.progress.cached.Load(),
+	}
+}
+
+// countResults records the scan of a file that produced fileResults and returns them.
+func (s *Scanner) countResults(fileResults []analyzer.FileResult) []analyzer.FileResult {
+	s.progress.analyzed.Add(1)
+	for _, res := range fileResults {
+		if res.Error != nil {
+			s.progress.failed.Add(1)
+			break
+		}
+	}
+	return fileResults
+}
@@ -1,6 +1,8 @@
 package scanner
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
@@ -10,70 +12,213 @@ import (
 	"io/fs"
 	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
 	"go.kacmar.sk/crack/binary"
 	"go.kacmar.sk/crack/internal/analyzer"
+	"go.kacmar.sk/crack/internal/archive"
+	"go.kacmar.sk/crack/internal/proc"
+	"go.kacmar.sk/crack/internal/resultcache"
 )
 
 type Scanner struct {
-	dispatcher *analyzer.Dispatcher
-	logger     *slog.Logger
-	workers    int
+	dispatcher   *analyzer.Dispatcher
+	logger       *slog.Logger
+	workers      int
+	archiveDepth int
+	include      []string
+	exclude      []string
+	cache        *resultcache.Cache
+	maxFileSize  int64
+	fileTimeout  time.Duration
+	filtered     filterCounters
+	progress     progressCounters
 }
 
+// pathQueueSize is how many discovered files may wait for a worker before the directory walk pauses.
+const pathQueueSize = 256
+
 type Options struct {
 	Logger  *slog.Logger
 	Workers int
+	// ArchiveDepth is how many levels of nested archives and packages are descended into, e.g. 2 for a .deb inside a tarball.
+	// Zero disables archive traversal, so archives are reported as unsupported files.
+	ArchiveDepth int
+	// Include and Exclude are glob patterns selecting the files of directory walks, see matchGlob.
+	// Patterns with a slash match the path relative to the walked directory, others the file name.
+	// When Include is set, only files matching one of its patterns are scanned. Excluded directories are not descended into.
+	Include []string
+	Exclude []string
+	// Cache, when set, holds the results of earlier scans. Files found in it aren't analyzed again, and the results
+	// of files that are get stored. Only files scanned by path are cached, as their hash is known before analysis.
+	Cache *resultcache.Cache
+	// MaxFileSize, when positive, is the size in bytes above which binaries, archive members, and streams are reported
+	// with binary.ErrLimitExceeded instead of being analyzed. Oversized files matching no binary format are skipped as usual,
+	// and archives read from disk are descended into whatever their size.
+	MaxFileSize int64
+	// FileTimeout, when positive, limits how long the analysis of a binary may take. A binary exceeding it is reported
+	// with binary.ErrLimitExceeded while its analysis is abandoned in the background.
+	FileTimeout time.Duration
 }
 
 func NewScanner(dispatcher *analyzer.Dispatcher, opts Options) *Scanner {
 	return &Scanner{
-		dispatcher: dispatcher,
-		logger:     opts.Logger.With(slog.String("component", "scanner")),
-		workers:    opts.Workers,
+		dispatcher:   dispatcher,
+		logger:       opts.Logger.With(slog.String("component", "scanner")),
+		workers:      opts.Workers,
+		archiveDepth: opts.ArchiveDepth,
+		include:      opts.Include,
+		exclude:      opts.Exclude,
+		cache:        opts.Cache,
+		maxFileSize:  opts.MaxFileSize,
+		fileTimeout:  opts.FileTimeout,
 	}
 }
 
+// FilterStats returns the number of paths left out of directory walks and archives so far.
+// Once the results channel of ScanPaths is closed, it covers the whole scan.
+func (s *Scanner) FilterStats() FilterStats {
+	return s.filtered.stats()
+}
+
+// ScanPaths analyzes the files named by paths and, for directories, the files they contain that pass the filters.
+// Files are analyzed as the directories are walked, with discovery running at most pathQueueSize files ahead of the workers.
+// Files with identical content are analyzed once. Their results are streamed with the paths found so far as aliases, and
+// paths found later are reported in follow-up records with LateAliases set.
 func (s *Scanner) ScanPaths(ctx context.Context, paths []string, recursive bool) <-chan analyzer.FileResult {
-	var filesToScan []string
+	results := make(chan analyzer.FileResult)
+	files := make(chan string, pathQueueSize)
 
-	for _, path := range paths {
-		files, err := s.collectFiles(path, recursive)
-		if err != nil {
-			s.logger.Warn("failed to collect files", slog.String("path", path), slog.Any("error", err))
-			continue
+	go func() {
+		defer close(files)
+		found := func(p string) error {
+			select {
+			case files <- p:
+				s.progress.found.Add(1)
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		for _, path := range paths {
+			if err := s.collectFiles(ctx, path, recursive, found); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				s.logger.Warn("failed to collect files", slog.String("path", path), slog.Any("error", err))
+			}
+		}
+
+		stats := s.FilterStats()
+		s.logger.Info("collected files to scan", slog.Int64("count", s.progress.found.Load()),
+			slog.Int64("excluded", stats.Excluded), slog.Int64("ignored", stats.Ignored), slog.Int64("not_binary", stats.NotBinary))
+	}()
+
+	go func() {
+		send := func(fileResults []analyzer.FileResult) error {
+			for _, res := range fileResults {
+				select {
+				case results <- res:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		}
+
+		d := newDeduper()
+		g, ctx := errgroup.WithContext(ctx)
+		g.SetLimit(s.workers)
+
+		for p := range files {
+			g.Go(func() error {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				group, late := d.add(p)
+				if group == nil {
+					s.progress.analyzed.Add(1)
+					return send(late)
+				}
+				return send(d.finish(group, s.countResults(s.scanCached(ctx, p, group.sha256, group.meta))))
+			})
+		}
+
+		s.logger.Debug("walk complete", slog.Int64("files", s.progress.found.Load()))
+		_ = g.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// ScanProcesses analyzes the executables and shared objects mapped by the processes pids, each file once however many processes map it.
+// With all set, pids is ignored and every running process is scanned, skipping those that can't be inspected, such as kernel threads
+// and other users' processes; otherwise a process that can't be inspected is reported as an error.
+func (s *Scanner) ScanProcesses(ctx context.Context, pids []int, all bool) <-chan analyzer.FileResult {
+	var failed []analyzer.FileResult
+	if all {
+		var err error
+		if pids, err = proc.List(); err != nil {
+			s.logger.Warn("failed to list processes", slog.Any("error", err))
+			failed = append(failed, analyzer.FileResult{Path: "/proc", Error: err})
 		}
-		filesToScan = append(filesToScan, files...)
 	}
 
-	s.logger.Debug("collected files to scan", slog.Int("count", len(filesToScan)))
+	set := proc.NewSet()
+	for _, pid := range pids {
+		if err := set.Add(pid); err != nil {
+			if all {
+				s.logger.Debug("skipping process", slog.Int("pid", pid), slog.Any("error", err))
+				continue
+			}
+			s.logger.Warn("failed to inspect process", slog.Int("pid", pid), slog.Any("error", err))
+			failed = append(failed, analyzer.FileResult{Path: fmt.Sprintf("/proc/%d", pid), Error: err})
+		}
+	}
+	images := set.Images()
+
+	s.logger.Debug("collected mapped files to scan", slog.Int("processes", len(pids)), slog.Int("count", len(images)))
 
-	return s.scanFiles(ctx, filesToScan)
+	return s.scan(ctx, len(images), failed, func(ctx context.Context, i int) []analyzer.FileResult {
+		return s.scanImage(ctx, images[i])
+	})
 }
 
-func (s *Scanner) scanFiles(ctx context.Context, files []string) <-chan analyzer.FileResult {
+// scan runs scanOne for inputs 0 to n-1 on the worker pool and streams their results after the already known results.
+func (s *Scanner) scan(ctx context.Context, n int, known []analyzer.FileResult, scanOne func(ctx context.Context, i int) []analyzer.FileResult) <-chan analyzer.FileResult {
 	results := make(chan analyzer.FileResult)
 
-	if len(files) == 0 {
+	if n == 0 && len(known) == 0 {
 		close(results)
 		return results
 	}
 
-	s.logger.Debug("starting parallel scan", slog.Int("workers", s.workers), slog.Int("files", len(files)))
+	s.logger.Debug("starting parallel scan", slog.Int("workers", s.workers), slog.Int("files", n))
+	s.progress.found.Add(int64(n))
 
 	go func() {
+		for _, res := range known {
+			select {
+			case results <- res:
+			case <-ctx.Done():
+			}
+		}
+
 		g, ctx := errgroup.WithContext(ctx)
 		g.SetLimit(s.workers)
 
-		for _, path := range files {
+		for i := range n {
 			g.Go(func() error {
 				if ctx.Err() != nil {
 					return ctx.Err()
 				}
-				fileResults := s.scanFile(ctx, path)
+				fileResults := s.countResults(scanOne(ctx, i))
 				for _, res := range fileResults {
 					select {
 					case results <- res:
@@ -92,8 +237,9 @@ func (s *Scanner) scanFiles(ctx context.Context, files []string) <-chan analyzer
 	return results
 }
 
-// scanFile returns a slice of FileResult to support future fat/universal binaries.
-func (s *Scanner) scanFile(ctx context.Context, path string) []analyzer.FileResult {
+// scanFile returns a slice of FileResult to support fat/universal binaries and archives holding many binaries.
+// sum is the SHA256 of the file when already known, or "" to compute it for recognized binaries.
+func (s *Scanner) scanFile(ctx context.Context, path, sum string) []analyzer.FileResult {
 	s.logger.Debug("scanning file", slog.String("path", path))
 
 	f, err := os.Open(path) // #nosec G304 -- user-provided paths are the tool's input
@@ -103,74 +249,433 @@ func (s *Scanner) scanFile(ctx context.Context, path string) []analyzer.FileResu
 	}
 	defer f.Close()
 
-	results, err := s.dispatcher.Analyze(ctx, f)
+	info, err := f.Stat()
+	if err != nil {
+		s.logger.Warn("failed to stat file", slog.String("path", path), slog.Any("error", err))
+		return []analyzer.FileResult{{Path: path, Error: err}}
+	}
+
+	base := analyzer.FileResult{Path: path}
+	base.Profile.File = s.metadata(path, info)
+	if s.archiveDepth > 0 {
+		if format := archive.Detect(f); format != nil {
+			return s.scanArchive(ctx, base, format, f, info.Size(), 1)
+		}
+	}
+
+	return s.analyze(ctx, base, f, info.Size(), func() (string, error) {
+		if sum != "" {
+			return sum, nil
+		}
+		return hashFile(f)
+	})
+}
+
+// scanCached returns the cached results for the file at path with the SHA256 sum and metadata meta, or scans it and caches the results.
+func (s *Scanner) scanCached(ctx context.Context, path, sum string, meta *binary.FileMetadata) []analyzer.FileResult {
+	if s.cache == nil || sum == "" {
+		return s.scanFile(ctx, path, sum)
+	}
+	key := cacheKey(sum, meta)
+	if results, ok := s.cache.Get(key, path); ok {
+		s.logger.Debug("using cached results", slog.String("path", path), slog.String("sha256", sum))
+		s.progress.cached.Add(1)
+		// The metadata of the file is its own, while that of archive members comes with the content.
+		for i := range results {
+			if results[i].Path == path && !results[i].Skipped {
+				results[i].Profile.File = meta
+			}
+		}
+		return results
+	}
+	results := s.scanFile(ctx, path, sum)
+	if ctx.Err() == nil {
+		if err := s.cache.Put(key, path, results); err != nil {
+			s.logger.Warn("failed to cache results", slog.String("path", path), slog.Any("error", err))
+		}
+	}
+	return results
+}
+
+// cacheKey returns the key the results of a file are cached under. Findings depend on the content of a file and on whether
+// it is privileged, so privileged files are cached apart from unprivileged files with the same content.
+func cacheKey(sum string, meta *binary.FileMetadata) string {
+	if !meta.Privileged() {
+		return sum
+	}
+	return hashBytes([]byte(sum + " privileged"))
+}
+
+// metadata returns the metadata of the file at path described by info, logging capabilities that couldn't be read.
+func (s *Scanner) metadata(path string, info fs.FileInfo) *binary.FileMetadata {
+	meta, err := fileMetadata(path, info)
+	if err != nil {
+		s.logger.Warn("failed to read file capabilities", slog.String("path", path), slog.Any("error", err))
+	}
+	return meta
+}
+
+// scanImage analyzes a file mapped by running processes. Archives aren't descended into, as processes only map binaries.
+func (s *Scanner) scanImage(ctx context.Context, image *proc.Image) []analyzer.FileResult {
+	s.logger.Debug("scanning mapped file", slog.String("path", image.Path), slog.Int("processes", len(image.Processes)))
+
+	base := analyzer.FileResult{Path: image.Path, Processes: image.Processes, DiskState: image.State}
+	f, err := image.Open()
+	if err != nil {
+		s.logger.Warn("failed to open mapped file", slog.String("path", image.Path), slog.Any("error", err))
+		if image.State != proc.DiskUnchanged {
+			err = fmt.Errorf("file was %s on disk and its mapping can't be read: %w", image.State, err)
+		}
+		base.Error = err
+		return []analyzer.FileResult{base}
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		s.logger.Warn("failed to stat mapped file", slog.String("path", image.Path), slog.Any("error", err))
+		base.Error = err
+		return []analyzer.FileResult{base}
+	}
+	base.Profile.File = s.metadata(f.Name(), info)
+
+	return s.analyze(ctx, base, f, info.Size(), func() (string, error) { return hashFile(f) })
+}
+
+// scanArchive analyzes every regular file inside the archive described by parent, descending into nested archives up to the configured depth.
+// Members whose leading bytes match neither a binary format nor a nested archive are counted as not binary and dropped, like files
+// of a directory walk. The rest are buffered because the dispatcher needs random access: in memory, or in a temporary file
+// once they grow beyond streamMemoryLimit. Members are reported as path!/member and inherit the provenance of parent unless the
+// archive records its own.
+func (s *Scanner) scanArchive(ctx context.Context, parent analyzer.FileResult, format *archive.Format, r io.ReaderAt, size int64, depth int) []analyzer.FileResult {
+	s.logger.Debug("scanning archive", slog.String("path", parent.Path), slog.String("format", format.Name), slog.Int("depth", depth))
+
+	var fileResults []analyzer.FileResult
+	var symlinks []archive.Entry
+	err := format.Walk(r, size, func(e archive.Entry) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if e.Symlink {
+			// Symlinks are resolved once the walk is over, as their targets may come later in the archive.
+			symlinks = append(symlinks, e)
+			return nil
+		}
+		if e.Link != "" {
+			path := archive.MemberPath(parent.Path, e.Name)
+			if !addAlias(fileResults, archive.MemberPath(parent.Path, e.Link), path) {
+				s.logger.Debug("skipping link", slog.String("path", path), slog.String("target", e.Link))
+			}
+			return nil
+		}
+
+		base := parent
+		base.Path = archive.MemberPath(parent.Path, e.Name)
+		if e.Package != nil {
+			base.Package = e.Package
+		}
+		if e.Layer != "" {
+			base.Layer = e.Layer
+		}
+		if e.ABI != "" {
+			base.ABI = e.ABI
+		}
+		// Members run with their own privileges, not those of the archive.
+		base.Profile.File = e.Metadata
+
+		// Members are recognized from their leading bytes and checked against the size limit before they are buffered,
+		// so that data files and oversized binaries aren't read whole.
+		content := bufio.NewReaderSize(e.Content, memberPeekSize)
+		header, err := content.Peek(memberPeekSize)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+			return fmt.Errorf("failed to read member %s: %w", e.Name, err)
+		}
+		if !s.dispatcher.Recognizes(header[:min(len(header), analyzer.MagicSize)]) &&
+			(depth >= s.archiveDepth || archive.Detect(bytes.NewReader(header)) == nil) {
+			s.logger.Debug("skipping non-binary archive member", slog.String("path", base.Path))
+			s.filtered.notBinary.Add(1)
+			return nil
+		}
+		if err := s.checkSize(e.Size); err != nil {
+			s.logger.Warn("skipping oversized archive member", slog.String("path", base.Path), slog.Int64("size", e.Size))
+			base.Error = err
+			fileResults = append(fileResults, base)
+			return nil
+		}
+
+		h := sha256.New()
+		member, memberSize, cleanup, err := bufferStream(io.TeeReader(content, h), streamMemoryLimit)
+		if err != nil {
+			return fmt.Errorf("failed to read member %s: %w", e.Name, err)
+		}
+		defer cleanup()
+
+		if depth < s.archiveDepth {
+			if nested := archive.Detect(member); nested != nil {
+				fileResults = append(fileResults, s.scanArchive(ctx, base, nested, member, memberSize, depth+1)...)
+				return nil
+			}
+		}
+		fileResults = append(fileResults, s.analyze(ctx, base, member, memberSize, func() (string, error) {
+			return hex.EncodeToString(h.Sum(nil)), nil
+		})...)
+		return nil
+	})
+	if err != nil {
+		s.logger.Warn("failed to read archive", slog.String("path", parent.Path), slog.Any("error", err))
+		failed := parent
+		failed.Error = err
+		fileResults = append(fileResults, failed)
+	}
+
+	targets := make(map[string]string, len(symlinks))
+	for _, e := range symlinks {
+		if target, ok := symlinkTarget(e); ok {
+			targets[e.Name] = target
+		}
+	}
+	for _, e := range symlinks {
+		alias := archive.MemberPath(parent.Path, e.Name)
+		target, ok := targets[e.Name]
+		// Symlinks to symlinks are followed as far as the kernel would.
+		for i := 0; ok && i < maxSymlinkHops; i++ {
+			next, chained := targets[target]
+			if !chained {
+				break
+			}
+			target = next
+		}
+		if !ok || !addAlias(fileResults, archive.MemberPath(parent.Path, target), alias) {
+			s.logger.Debug("skipping link", slog.String("path", alias), slog.String("target", e.Link))
+		}
+	}
+	return fileResults
+}
+
+// memberPeekSize is how much of an archive member is read to recognize it as a binary or a nested archive.
+// Compressed archives are recognized by decompressing their first header, which needs more than the header itself.
+const memberPeekSize = 64 << 10
+
+// maxSymlinkHops is the number of symlinks followed when resolving a symlink to another, matching the limit of Linux.
+const maxSymlinkHops = 40
+
+// symlinkTarget resolves the target of the archive symlink e relative to its directory, and reports whether it stays inside the archive.
+// Absolute targets are left alone, as they point into whatever filesystem the archive ends up unpacked on.
+func symlinkTarget(e archive.Entry) (string, bool) {
+	if path.IsAbs(e.Link) {
+		return "", false
+	}
+	target := path.Join(path.Dir(e.Name), e.Link)
+	if target == ".." || strings.HasPrefix(target, "../") {
+		return "", false
+	}
+	return target, true
+}
+
+// addAlias records alias as another path of the archive member target, whose results are among results, and reports whether
+// there were any. Results for members of an archive nested in target get the member path under alias.
+func addAlias(results []analyzer.FileResult, target, alias string) bool {
+	found := false
+	for i := range results {
+		member, ok := strings.CutPrefix(results[i].Path, target)
+		if !ok || member != "" && !strings.HasPrefix(member, archive.Separator) {
+			continue
+		}
+		results[i].Aliases = append(results[i].Aliases, alias+member)
+		found = true
+	}
+	return found
+}
+
+// analyze runs the dispatcher on r and completes a copy of base for every analysis result.
+// base carries the reporting path and provenance of the file. hash is only invoked for recognized binaries.
+func (s *Scanner) analyze(ctx context.Context, base analyzer.FileResult, r io.ReaderAt, size int64, hash func() (string, error)) []analyzer.FileResult {
+	if err := s.checkSize(size); err != nil {
+		magic := make([]byte, analyzer.MagicSize)
+		if _, readErr := r.ReadAt(magic, 0); readErr != nil || !s.dispatcher.Recognizes(magic) {
+			s.logger.Debug("skipping unsupported format", slog.String("path", base.Path))
+			base.Skipped = true
+			return []analyzer.FileResult{base}
+		}
+		s.logger.Warn("skipping oversized file", slog.String("path", base.Path), slog.Int64("size", size))
+		base.Error = err
+		return []analyzer.FileResult{base}
+	}
+
+	results, err := s.dispatch(ctx, r, base.Profile.File)
 	if err != nil {
 		if errors.Is(err, analyzer.ErrUnrecognizedFormat) {
-			s.logger.Debug("skipping unsupported format", slog.String("path", path))
-			return []analyzer.FileResult{{Path: path, Skipped: true}}
+			s.logger.Debug("skipping unsupported format", slog.String("path", base.Path))
+			base.Skipped = true
+			return []analyzer.FileResult{base}
 		}
-		s.logger.Warn("failed to analyze file", slog.String("path", path), slog.Any("error", err))
-		return []analyzer.FileResult{{Path: path, Error: err}}
+		s.logger.Warn("failed to analyze file", slog.String("path", base.Path), slog.Any("error", err))
+		base.Error = err
+		return []analyzer.FileResult{base}
 	}
 
-	hash, err := hashFile(f)
+	sum, err := hash()
 	if err != nil {
-		s.logger.Warn("failed to compute SHA256", slog.String("path", path), slog.Any("error", err))
+		s.logger.Warn("failed to compute SHA256", slog.String("path", base.Path), slog.Any("error", err))
 	}
 
 	// Assemble FileResults from AnalysisResults
 	fileResults := make([]analyzer.FileResult, len(results))
 	for i, r := range results {
-		fileResults[i] = analyzer.FileResult{
-			Path:     path,
-			Format:   r.Format,
-			Identity: binary.Identity{BuildID: r.Identity.BuildID, SHA256: hash},
-			Profile:  r.Profile,
-			Findings: r.Findings,
+		res := base
+		if r.Member != "" {
+			res.Path = archive.MemberPath(base.Path, r.Member)
 		}
+		res.Slice = r.Slice
+		res.Format = r.Format
+		res.Identity = binary.Identity{BuildID: r.Identity.BuildID, SHA256: sum}
+		if r.Identity.SHA256 != "" {
+			res.Identity.SHA256 = r.Identity.SHA256
+		}
+		res.Profile = r.Profile
+		res.Findings = r.Findings
+		fileResults[i] = res
 	}
 	return fileResults
 }
 
-func (s *Scanner) collectFiles(path string, recursive bool) ([]string, error) {
-	info, err := os.Stat(path)
+// checkSize returns binary.ErrLimitExceeded when size is over the maximum file size.
+func (s *Scanner) checkSize(size int64) error {
+	if s.maxFileSize > 0 && size > s.maxFileSize {
+		return fmt.Errorf("%w: file is %d bytes, over the maximum of %d", binary.ErrLimitExceeded, size, s.maxFileSize)
+	}
+	return nil
+}
+
+// dispatch runs the dispatcher on r within the per-file timeout. On timeout, the analysis is left to finish in the background:
+// its reads of r fail once its context is done, as do its debuginfod requests, and the memory it reserved stays taken
+// from the budget until it returns.
+func (s *Scanner) dispatch(ctx context.Context, r io.ReaderAt, file *binary.FileMetadata) ([]analyzer.AnalysisResult, error) {
+	if s.fileTimeout <= 0 {
+		return s.dispatcher.Analyze(ctx, ctxReaderAt{ctx, r}, file)
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.fileTimeout)
+	defer cancel()
+	r = ctxReaderAt{ctx, r}
+
+	type outcome struct {
+		results []analyzer.AnalysisResult
+		err     error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		results, err := s.dispatcher.Analyze(ctx, r, file)
+		done <- outcome{results, err}
+	}()
+	select {
+	case o := <-done:
+		return o.results, o.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%w: analysis took longer than %s", binary.ErrLimitExceeded, s.fileTimeout)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// ctxReaderAt fails reads once ctx is done. Archive members and buffered streams are read from memory, where nothing else
+// would stop an analysis that ran out of time.
+type ctxReaderAt struct {
+	ctx context.Context
+	r   io.ReaderAt
+}
+
+func (r ctxReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.ReadAt(p, off)
+}
+
+// collectFiles passes found the files to scan for an input path: the path itself when it names a file,
+// otherwise the files of the directory, or of its whole tree when recursive, that pass the filters.
+// Explicitly named files are never filtered. The walk stops when found returns an error or ctx is canceled.
+func (s *Scanner) collectFiles(ctx context.Context, root string, recursive bool, found func(string) error) error {
+	info, err := os.Stat(root)
 	if err != nil {
-		return nil, fmt.Errorf("failed to stat path: %w", err)
+		return fmt.Errorf("failed to stat path: %w", err)
 	}
 
 	if !info.IsDir() {
-		return []string{path}, nil
+		return found(root)
 	}
 
-	var files []string
+	var rules []ignoreRule
+	// enterDir loads the ignore file of a directory being descended into.
+	enterDir := func(dir string) {
+		dirRules, err := readIgnoreFile(dir)
+		if err != nil {
+			s.logger.Warn("failed to read ignore file", slog.String("dir", dir), slog.Any("error", err))
+		}
+		rules = append(rules, dirRules...)
+	}
+	// keep applies the filters to an entry below root, whose type bits are typ, pruning directories and counting what is left out.
+	keep := func(p string, typ fs.FileMode) bool {
+		isDir := typ.IsDir()
+		switch {
+		case s.isExcluded(root, p, isDir):
+			s.filtered.excluded.Add(1)
+			return false
+		case isIgnored(rules, p, isDir):
+			s.filtered.ignored.Add(1)
+			return false
+		case !isDir && !s.isCandidate(p, typ):
+			s.filtered.notBinary.Add(1)
+			return false
+		}
+		return true
+	}
 
+	enterDir(root)
 	if recursive {
-		err := filepath.WalkDir(path, func(p string, d fs.DirEntry, walkErr error) error {
+		err := filepath.WalkDir(root, func(p string, d fs.DirEntry, walkErr error) error {
 			if walkErr != nil {
 				return walkErr
 			}
-			if !d.IsDir() {
-				files = append(files, p)
+			if err := ctx.Err(); err != nil {
+				return err
 			}
-			return nil
+			if p == root {
+				return nil
+			}
+			if !keep(p, d.Type()) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() {
+				enterDir(p)
+				return nil
+			}
+			return found(p)
 		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to walk directory: %w", err)
+			return fmt.Errorf("failed to walk directory: %w", err)
 		}
 	} else {
-		entries, err := os.ReadDir(path)
+		entries, err := os.ReadDir(root)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read directory: %w", err)
+			return fmt.Errorf("failed to read directory: %w", err)
 		}
 
 		for _, entry := range entries {
-			if !entry.IsDir() {
-				files = append(files, filepath.Join(path, entry.Name()))
+			p := filepath.Join(root, entry.Name())
+			if !entry.IsDir() && keep(p, entry.Type()) {
+				if err := found(p); err != nil {
+					return err
+				}
 			}
 		}
 	}
 
-	return files, nil
+	return nil
 }
 
 func hashFile(f *os.File) (string, error) {
@@ -183,3 +688,8 @@ func hashFile(f *os.File) (string, error) {
 	}
 	return hex.EncodeToString(h.Sum(nil)), nil
 }
+
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
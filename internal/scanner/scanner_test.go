@@ -0,0 +1,198 @@
+package scanner
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/internal/analyzer"
+)
+
+func TestMaxFileSize(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"small": "\x7fELF\x02\x01\x01",
+		"huge":  "\x7fELF\x02\x01\x01" + strings.Repeat("\x00", 100),
+		"notes": strings.Repeat("notes", 100),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := NewScanner(analyzer.NewDispatcher(analyzer.DispatcherOptions{
+		Analyzers: []analyzer.FormatAnalyzer{analyzer.NewELFAnalyzer(analyzer.ELFAnalyzerOptions{Logger: logger})},
+		Logger:    logger,
+	}), Options{Logger: logger, Workers: 1, MaxFileSize: 64})
+
+	tests := []struct {
+		name        string
+		wantLimit   bool
+		wantSkipped bool
+	}{
+		// Too short to parse as ELF, but analyzed rather than rejected by size.
+		{name: "small", wantSkipped: true},
+		{name: "huge", wantLimit: true},
+		{name: "notes", wantSkipped: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := s.scanFile(context.Background(), filepath.Join(dir, tt.name), "")
+			if len(results) != 1 {
+				t.Fatalf("scanFile() returned %d results, want 1", len(results))
+			}
+			res := results[0]
+			if got := errors.Is(res.Error, analyzer.ErrLimitExceeded); got != tt.wantLimit {
+				t.Errorf("error = %v, want limit exceeded %v", res.Error, tt.wantLimit)
+			}
+			if res.Skipped != tt.wantSkipped {
+				t.Errorf("skipped = %v, want %v", res.Skipped, tt.wantSkipped)
+			}
+		})
+	}
+
+	t.Run("stream", func(t *testing.T) {
+		for name, wantLimit := range map[string]bool{"huge": true, "notes": false} {
+			var got []analyzer.FileResult
+			for res := range s.ScanReader(context.Background(), strings.NewReader(files[name]), "stdin") {
+				got = append(got, res)
+			}
+			if len(got) != 1 || errors.Is(got[0].Error, analyzer.ErrLimitExceeded) != wantLimit || got[0].Skipped == wantLimit {
+				t.Errorf("ScanReader(%s) = %+v, want limit exceeded %v", name, got, wantLimit)
+			}
+		}
+	})
+
+	// Members are filtered by their leading bytes before the size limit applies, so oversized data files aren't errors.
+	t.Run("archive", func(t *testing.T) {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for _, name := range []string{"huge", "notes", "small"} {
+			if err := tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o755, Size: int64(len(files[name]))}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tw.Write([]byte(files[name])); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := tw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(t.TempDir(), "app.tar")
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		s := NewScanner(analyzer.NewDispatcher(analyzer.DispatcherOptions{
+			Analyzers: []analyzer.FormatAnalyzer{analyzer.NewELFAnalyzer(analyzer.ELFAnalyzerOptions{Logger: logger})},
+			Logger:    logger,
+		}), Options{Logger: logger, Workers: 1, MaxFileSize: 64, ArchiveDepth: 1})
+		got := s.scanFile(context.Background(), path, "")
+		if len(got) != 2 || got[0].Path != path+"!/huge" || !errors.Is(got[0].Error, analyzer.ErrLimitExceeded) ||
+			got[1].Path != path+"!/small" || !got[1].Skipped {
+			t.Errorf("scanFile() = %+v, want a limit exceeded error for huge and small skipped", got)
+		}
+		if stats := s.FilterStats(); stats != (FilterStats{NotBinary: 1}) {
+			t.Errorf("FilterStats() = %+v, want notes as not binary", stats)
+		}
+	})
+}
+
+func TestScanArchiveLinks(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, hdr := range []tar.Header{
+		{Name: "usr/bin/early", Typeflag: tar.TypeSymlink, Linkname: "../../bin/tool"},
+		{Name: "bin/tool", Typeflag: tar.TypeReg, Size: 7},
+		{Name: "bin/tool-hard", Typeflag: tar.TypeLink, Linkname: "bin/tool"},
+		{Name: "bin/tool-alias", Typeflag: tar.TypeSymlink, Linkname: "tool"},
+		{Name: "bin/tool-chain", Typeflag: tar.TypeSymlink, Linkname: "./tool-alias"},
+		{Name: "bin/orphan", Typeflag: tar.TypeLink, Linkname: "bin/missing"},
+		{Name: "bin/dangling", Typeflag: tar.TypeSymlink, Linkname: "missing"},
+		{Name: "bin/absolute", Typeflag: tar.TypeSymlink, Linkname: "/bin/tool"},
+		{Name: "bin/escape", Typeflag: tar.TypeSymlink, Linkname: "../../bin/tool"},
+	} {
+		if err := tw.WriteHeader(&hdr); err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Size > 0 {
+			if _, err := tw.Write([]byte("\x7fELF\x02\x01\x01")); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := NewScanner(analyzer.NewDispatcher(analyzer.DispatcherOptions{
+		Analyzers: []analyzer.FormatAnalyzer{analyzer.NewELFAnalyzer(analyzer.ELFAnalyzerOptions{Logger: logger})},
+		Logger:    logger,
+	}), Options{Logger: logger, Workers: 1, ArchiveDepth: 1})
+
+	var got []analyzer.FileResult
+	for res := range s.ScanReader(context.Background(), &buf, "app.tar") {
+		got = append(got, res)
+	}
+	want := []string{"app.tar!/bin/tool-hard", "app.tar!/usr/bin/early", "app.tar!/bin/tool-alias", "app.tar!/bin/tool-chain"}
+	if len(got) != 1 || got[0].Path != "app.tar!/bin/tool" || !slices.Equal(got[0].Aliases, want) {
+		t.Errorf("ScanReader() = %+v, want bin/tool with aliases %v", got, want)
+	}
+}
+
+// stubbornAnalyzer ignores its context and reads until a read fails, then reports the error on done.
+type stubbornAnalyzer struct {
+	done chan error
+}
+
+func (a stubbornAnalyzer) Format() binary.Format { return binary.FormatELF }
+
+func (a stubbornAnalyzer) Recognizes([]byte) bool { return true }
+
+func (a stubbornAnalyzer) Analyze(_ context.Context, r io.ReaderAt, _ *binary.FileMetadata) ([]analyzer.AnalysisResult, error) {
+	buf := make([]byte, 1)
+	for {
+		if _, err := r.ReadAt(buf, 0); err != nil {
+			a.done <- err
+			return nil, err
+		}
+	}
+}
+
+func TestFileTimeout(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	slow := stubbornAnalyzer{done: make(chan error, 1)}
+	s := NewScanner(analyzer.NewDispatcher(analyzer.DispatcherOptions{Analyzers: []analyzer.FormatAnalyzer{slow}, Logger: logger}),
+		Options{Logger: logger, Workers: 1, FileTimeout: 10 * time.Millisecond})
+
+	// A stream is buffered in memory, which no file close would interrupt.
+	var got []analyzer.FileResult
+	for res := range s.ScanReader(context.Background(), strings.NewReader("\x7fELF"), "stdin") {
+		got = append(got, res)
+	}
+	if len(got) != 1 || !errors.Is(got[0].Error, analyzer.ErrLimitExceeded) {
+		t.Fatalf("ScanReader() = %+v, want a limit exceeded error", got)
+	}
+
+	select {
+	case err := <-slow.done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("abandoned analysis read error = %v, want the deadline", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("abandoned analysis still reading")
+	}
+}
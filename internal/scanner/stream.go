@@ -0,0 +1,93 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"go.kacmar.sk/crack/internal/analyzer"
+	"go.kacmar.sk/crack/internal/archive"
+)
+
+// streamMemoryLimit is how much of a stream ScanReader buffers in memory before spilling it to a temporary file.
+const streamMemoryLimit = 64 << 20
+
+// ScanReader analyzes the file read from r, such as a binary piped to stdin, and reports it under name.
+// The stream is buffered because analysis needs random access: in memory, or in a temporary file once it grows
+// beyond streamMemoryLimit. Archives are descended into as for files on disk.
+func (s *Scanner) ScanReader(ctx context.Context, r io.Reader, name string) <-chan analyzer.FileResult {
+	return s.scan(ctx, 1, nil, func(ctx context.Context, _ int) []analyzer.FileResult {
+		return s.scanStream(ctx, r, name)
+	})
+}
+
+func (s *Scanner) scanStream(ctx context.Context, r io.Reader, name string) []analyzer.FileResult {
+	s.logger.Debug("scanning stream", slog.String("name", name))
+
+	base := analyzer.FileResult{Path: name}
+	if s.maxFileSize > 0 {
+		// Reading one byte past the maximum tells an oversized stream without buffering all of it.
+		r = io.LimitReader(r, s.maxFileSize+1)
+	}
+	h := sha256.New()
+	content, size, cleanup, err := bufferStream(io.TeeReader(r, h), streamMemoryLimit)
+	if err != nil {
+		s.logger.Warn("failed to read stream", slog.String("name", name), slog.Any("error", err))
+		base.Error = fmt.Errorf("failed to read stream: %w", err)
+		return []analyzer.FileResult{base}
+	}
+	defer cleanup()
+	sum := hex.EncodeToString(h.Sum(nil))
+	s.logger.Debug("buffered stream", slog.String("name", name), slog.Int64("size", size), slog.String("sha256", sum))
+
+	if s.archiveDepth > 0 {
+		if format := archive.Detect(content); format != nil {
+			// Unlike archives on disk, a stream is buffered whole, so the maximum file size applies to it even when it is an archive.
+			if err := s.checkSize(size); err != nil {
+				s.logger.Warn("skipping oversized stream", slog.String("name", name), slog.Int64("size", size))
+				base.Error = err
+				return []analyzer.FileResult{base}
+			}
+			return s.scanArchive(ctx, base, format, content, size, 1)
+		}
+	}
+	return s.analyze(ctx, base, content, size, func() (string, error) { return sum, nil })
+}
+
+// bufferStream reads r to the end, keeping up to limit bytes in memory and spilling larger streams to a temporary file,
+// which cleanup removes.
+func bufferStream(r io.Reader, limit int64) (content io.ReaderAt, size int64, cleanup func(), err error) {
+	var buf bytes.Buffer
+	n, err := io.CopyN(&buf, r, limit+1)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, 0, nil, err
+	}
+	if n <= limit {
+		return bytes.NewReader(buf.Bytes()), n, func() {}, nil
+	}
+
+	f, err := os.CreateTemp("", "crack-stream-*")
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	cleanup = func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}
+	if _, err := buf.WriteTo(f); err != nil {
+		cleanup()
+		return nil, 0, nil, err
+	}
+	rest, err := io.Copy(f, r)
+	if err != nil {
+		cleanup()
+		return nil, 0, nil, err
+	}
+	return f, n + rest, cleanup, nil
+}
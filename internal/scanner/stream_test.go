@@ -0,0 +1,61 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"go.kacmar.sk/crack/internal/analyzer"
+)
+
+func TestBufferStream(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		limit    int64
+		wantFile bool
+	}{
+		{name: "in memory", data: "0123456789", limit: 10},
+		{name: "spilled", data: strings.Repeat("0123456789", 10), limit: 10, wantFile: true},
+		{name: "empty", limit: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, size, cleanup, err := bufferStream(strings.NewReader(tt.data), tt.limit)
+			if err != nil {
+				t.Fatalf("bufferStream() error = %v", err)
+			}
+			f, isFile := content.(*os.File)
+			if isFile != tt.wantFile {
+				t.Errorf("buffered in a file = %v, want %v", isFile, tt.wantFile)
+			}
+			got, err := io.ReadAll(io.NewSectionReader(content, 0, size))
+			if err != nil || string(got) != tt.data {
+				t.Errorf("content = %q, %v, want %q", got, err, tt.data)
+			}
+			cleanup()
+			if isFile {
+				if _, err := os.Stat(f.Name()); !os.IsNotExist(err) {
+					t.Errorf("temporary file %s not removed", f.Name())
+				}
+			}
+		})
+	}
+}
+
+func TestScanReader(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := NewScanner(analyzer.NewDispatcher(analyzer.DispatcherOptions{Logger: logger}), Options{Logger: logger, Workers: 1})
+
+	var got []analyzer.FileResult
+	for res := range s.ScanReader(context.Background(), bytes.NewReader([]byte("not a binary")), "artifact.bin") {
+		got = append(got, res)
+	}
+	if len(got) != 1 || got[0].Path != "artifact.bin" || !got[0].Skipped {
+		t.Errorf("ScanReader() = %+v, want artifact.bin skipped", got)
+	}
+}
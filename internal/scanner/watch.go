@@ -0,0 +1,321 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"go.kacmar.sk/crack/internal/analyzer"
+)
+
+// watchQuietPeriod is how long a file must go unwritten before it is analyzed,
+// so that a file written and closed several times in a row, as some linkers do, is analyzed once.
+const watchQuietPeriod = 250 * time.Millisecond
+
+// watchEvent is a file written, a directory created, or either removed in a watched directory.
+type watchEvent struct {
+	path string
+	dir  bool
+	// removed reports that the path was deleted or moved out of the directory.
+	removed bool
+	// overflow reports that events were lost.
+	overflow bool
+}
+
+// fileType returns the type bits of the path of the event. A file that can't be looked up is treated as regular, so that the error
+// is reported when it is scanned.
+func (ev watchEvent) fileType() fs.FileMode {
+	if ev.dir {
+		return fs.ModeDir
+	}
+	info, err := os.Lstat(ev.path)
+	if err != nil {
+		return 0
+	}
+	return info.Mode().Type()
+}
+
+// watchedDir holds what the filters need to know about a watched directory.
+type watchedDir struct {
+	// root is the watched input path the directory was found under, which --include and --exclude patterns are relative to.
+	root string
+	// rules are the ignore rules of the directory and its parents up to root.
+	rules []ignoreRule
+	// files, when set, are the only files of the directory watched, for inputs naming files.
+	files map[string]bool
+}
+
+// watchTree tracks the directories of a watch and applies the directory walk filters to their events.
+type watchTree struct {
+	s         *Scanner
+	w         *fileWatcher
+	recursive bool
+	dirs      map[string]*watchedDir
+}
+
+// Watch analyzes files as they are written in the directories of paths, or in their whole trees when recursive,
+// until ctx is canceled. A file is analyzed once it is closed after writing or moved into a watched directory, and only
+// when it passes the filters of a directory walk. Paths naming a file watch that file only. Files already present aren't
+// analyzed; use ScanPaths for them. Watching is only supported on Linux.
+func (s *Scanner) Watch(ctx context.Context, paths []string, recursive bool) (<-chan analyzer.FileResult, error) {
+	w, err := newFileWatcher()
+	if err != nil {
+		return nil, err
+	}
+	tree := &watchTree{s: s, w: w, recursive: recursive, dirs: make(map[string]*watchedDir)}
+	for _, p := range paths {
+		if err := tree.addRoot(p); err != nil {
+			_ = w.close()
+			return nil, err
+		}
+	}
+	s.logger.Info("watching directories", slog.Int("count", len(tree.dirs)))
+
+	results := make(chan analyzer.FileResult)
+	go func() {
+		defer close(results)
+		tree.run(ctx, results)
+	}()
+	return results, nil
+}
+
+func (t *watchTree) addRoot(root string) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return fmt.Errorf("failed to stat path: %w", err)
+	}
+	if !info.IsDir() {
+		dir := filepath.Dir(root)
+		watched := t.dirs[dir]
+		if watched == nil {
+			if err := t.w.add(dir); err != nil {
+				return err
+			}
+			watched = &watchedDir{root: dir, files: make(map[string]bool)}
+			t.dirs[dir] = watched
+		}
+		if watched.files != nil {
+			watched.files[root] = true
+		}
+		return nil
+	}
+	_, err = t.addDir(root, &watchedDir{root: root})
+	return err
+}
+
+// addDir watches dir, and its subdirectories when recursive, with the ignore rules of parent, and returns the files
+// already in them that pass the filters. They may have been written before the watch was in place.
+func (t *watchTree) addDir(dir string, parent *watchedDir) ([]string, error) {
+	rules, err := readIgnoreFile(dir)
+	if err != nil {
+		t.s.logger.Warn("failed to read ignore file", slog.String("dir", dir), slog.Any("error", err))
+	}
+	watched := &watchedDir{root: parent.root, rules: append(parent.rules[:len(parent.rules):len(parent.rules)], rules...)}
+	if err := t.w.add(dir); err != nil {
+		return nil, err
+	}
+	t.dirs[dir] = watched
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		p := filepath.Join(dir, e.Name())
+		if !t.keep(watched, p, e.Type()) {
+			continue
+		}
+		if !e.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		if t.recursive {
+			found, err := t.addDir(p, watched)
+			if err != nil {
+				return files, err
+			}
+			files = append(files, found...)
+		}
+	}
+	return files, nil
+}
+
+// keep applies the filters of a directory walk to an entry of a watched directory, whose type bits are typ.
+func (t *watchTree) keep(dir *watchedDir, p string, typ fs.FileMode) bool {
+	if dir.files != nil {
+		return dir.files[p]
+	}
+	isDir := typ.IsDir()
+	return !t.s.isExcluded(dir.root, p, isDir) && !isIgnored(dir.rules, p, isDir) && (isDir || t.s.isCandidate(p, typ))
+}
+
+// run analyzes the files reported by the watcher until ctx is canceled or the watcher fails.
+// Events are read on their own goroutine and files are analyzed on another, so that a slow analysis doesn't leave the
+// events unread until the kernel drops them.
+func (t *watchTree) run(ctx context.Context, results chan<- analyzer.FileResult) {
+	events := make(chan []watchEvent)
+	go func() {
+		defer close(events)
+		for {
+			batch, err := t.w.read()
+			if err != nil {
+				if !errors.Is(err, os.ErrClosed) {
+					t.s.logger.Error("failed to read file events", slog.Any("error", err))
+				}
+				return
+			}
+			select {
+			case events <- batch:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	defer func() {
+		_ = t.w.close()
+		// Drain the reader, which stops once the watcher is closed.
+		for range events {
+		}
+	}()
+
+	work := make(chan []string)
+	analyzed := make(chan struct{})
+	go func() {
+		defer close(analyzed)
+		for files := range work {
+			if err := t.analyze(ctx, files, results); err != nil {
+				return
+			}
+		}
+	}()
+	defer func() {
+		close(work)
+		<-analyzed
+	}()
+
+	// pending maps written files to when they were last written.
+	pending := make(map[string]time.Time)
+	// due holds the files that went unwritten for the quiet period and wait for the analysis worker.
+	due := make(map[string]bool)
+	timer := time.NewTimer(watchQuietPeriod)
+	timer.Stop()
+	for {
+		// Sending is only enabled when there are files due.
+		var send chan<- []string
+		var files []string
+		if len(due) > 0 {
+			send, files = work, slices.Collect(maps.Keys(due))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-analyzed:
+			return
+		case send <- files:
+			clear(due)
+		case batch, ok := <-events:
+			if !ok {
+				return
+			}
+			now := time.Now()
+			for _, ev := range batch {
+				if ev.removed {
+					t.remove(ev)
+					forgetPath(pending, ev.path)
+					forgetPath(due, ev.path)
+					continue
+				}
+				for _, p := range t.handle(ev) {
+					pending[p] = now
+					delete(due, p)
+				}
+			}
+			if len(pending) > 0 {
+				timer.Reset(watchQuietPeriod)
+			}
+		case <-timer.C:
+			for p, written := range pending {
+				if time.Since(written) >= watchQuietPeriod {
+					due[p] = true
+					delete(pending, p)
+				}
+			}
+			if len(pending) > 0 {
+				timer.Reset(watchQuietPeriod)
+			}
+		}
+	}
+}
+
+// remove stops watching a removed directory and the directories below it.
+func (t *watchTree) remove(ev watchEvent) {
+	if !ev.dir {
+		return
+	}
+	forgetPath(t.dirs, ev.path)
+	t.w.remove(ev.path)
+}
+
+// forgetPath deletes p and the paths below it from m.
+func forgetPath[V any](m map[string]V, p string) {
+	prefix := p + string(filepath.Separator)
+	for k := range m {
+		if k == p || strings.HasPrefix(k, prefix) {
+			delete(m, k)
+		}
+	}
+}
+
+// handle returns the files to analyze for an event, watching directories created in the tree.
+func (t *watchTree) handle(ev watchEvent) []string {
+	if ev.overflow {
+		t.s.logger.Warn("file events were lost, some written files won't be analyzed")
+		return nil
+	}
+	dir := t.dirs[filepath.Dir(ev.path)]
+	if dir == nil || !t.keep(dir, ev.path, ev.fileType()) {
+		return nil
+	}
+	if !ev.dir {
+		return []string{ev.path}
+	}
+	if !t.recursive {
+		return nil
+	}
+	files, err := t.addDir(ev.path, dir)
+	if err != nil {
+		t.s.logger.Warn("failed to watch directory", slog.String("dir", ev.path), slog.Any("error", err))
+	}
+	return files
+}
+
+// analyze scans files on the worker pool and sends their results.
+func (t *watchTree) analyze(ctx context.Context, files []string, results chan<- analyzer.FileResult) error {
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(t.s.workers)
+	for _, p := range files {
+		g.Go(func() error {
+			t.s.logger.Debug("analyzing written file", slog.String("path", p))
+			for _, res := range t.s.scanFile(ctx, p, "") {
+				select {
+				case results <- res:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		})
+	}
+	return g.Wait()
+}
@@ -0,0 +1,112 @@
+//go:build linux
+
+package scanner
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// watchMask selects files closed after writing, files and directories created or moved into a watched directory, and
+// files and directories deleted or moved out of it.
+const watchMask = syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_TO | syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MOVED_FROM |
+	syscall.IN_ONLYDIR
+
+// fileWatcher reports writes in watched directories through inotify.
+type fileWatcher struct {
+	f    *os.File
+	fd   int
+	mu   sync.Mutex
+	dirs map[int32]string
+	buf  [64 * 1024]byte
+}
+
+func newFileWatcher() (*fileWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("inotify: %w", err)
+	}
+	// A non-blocking descriptor lets the runtime poller wait for events, so that close interrupts a pending read.
+	return &fileWatcher{f: os.NewFile(uintptr(fd), "inotify"), fd: fd, dirs: make(map[int32]string)}, nil
+}
+
+// add watches the entries of dir, not those of its subdirectories.
+func (w *fileWatcher) add(dir string) error {
+	wd, err := syscall.InotifyAddWatch(w.fd, dir, watchMask)
+	if err != nil {
+		return fmt.Errorf("inotify: watch %s: %w", dir, err)
+	}
+	w.mu.Lock()
+	w.dirs[int32(wd)] = dir // #nosec G115 -- watch descriptors are small positive ints
+	w.mu.Unlock()
+	return nil
+}
+
+// remove stops watching dir and the directories below it. A directory moved elsewhere would otherwise keep being
+// reported under its old path.
+func (w *fileWatcher) remove(dir string) {
+	prefix := dir + string(filepath.Separator)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for wd, d := range w.dirs {
+		if d == dir || strings.HasPrefix(d, prefix) {
+			// The watch of a deleted directory is already gone.
+			_, _ = syscall.InotifyRmWatch(w.fd, uint32(wd)) // #nosec G115 -- watch descriptors are small positive ints
+			delete(w.dirs, wd)
+		}
+	}
+}
+
+// read blocks until events are available and returns them. It fails with os.ErrClosed once the watcher is closed.
+func (w *fileWatcher) read() ([]watchEvent, error) {
+	n, err := w.f.Read(w.buf[:])
+	if err != nil {
+		return nil, err
+	}
+
+	var events []watchEvent
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for data := w.buf[:n]; len(data) >= syscall.SizeofInotifyEvent; {
+		wd := int32(binary.NativeEndian.Uint32(data[0:])) // #nosec G115 -- the kernel's signed watch descriptor
+		mask := binary.NativeEndian.Uint32(data[4:])
+		nameLen := int(binary.NativeEndian.Uint32(data[12:]))
+		if len(data) < syscall.SizeofInotifyEvent+nameLen {
+			return events, errors.New("inotify: truncated event")
+		}
+		name := string(data[syscall.SizeofInotifyEvent : syscall.SizeofInotifyEvent+nameLen])
+		data = data[syscall.SizeofInotifyEvent+nameLen:]
+
+		switch {
+		case mask&syscall.IN_Q_OVERFLOW != 0:
+			events = append(events, watchEvent{overflow: true})
+		case mask&syscall.IN_IGNORED != 0:
+			// The directory was removed or unmounted.
+			delete(w.dirs, wd)
+		default:
+			dir, ok := w.dirs[wd]
+			if !ok {
+				continue
+			}
+			// Names are padded with NULs to align the next event.
+			p := filepath.Join(dir, strings.TrimRight(name, "\x00"))
+			isDir := mask&syscall.IN_ISDIR != 0
+			if !isDir && mask&syscall.IN_CREATE != 0 {
+				// Created files are reported once closed after writing.
+				continue
+			}
+			events = append(events, watchEvent{path: p, dir: isDir, removed: mask&(syscall.IN_DELETE|syscall.IN_MOVED_FROM) != 0})
+		}
+	}
+	return events, nil
+}
+
+func (w *fileWatcher) close() error {
+	return w.f.Close()
+}
@@ -0,0 +1,76 @@
+//go:build linux
+
+package scanner
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.kacmar.sk/crack/internal/analyzer"
+)
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	dispatcher := analyzer.NewDispatcher(analyzer.DispatcherOptions{
+		Analyzers: []analyzer.FormatAnalyzer{analyzer.NewELFAnalyzer(analyzer.ELFAnalyzerOptions{Logger: logger})},
+		Logger:    logger,
+	})
+	s := NewScanner(dispatcher, Options{Logger: logger, Workers: 2})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	results, err := s.Watch(ctx, []string{dir}, true)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	// A directory created after the watch started is watched too, and data files are filtered out.
+	sub := filepath.Join(dir, "sub")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	app := filepath.Join(sub, "app")
+	if err := os.WriteFile(app, []byte("\x7fELF truncated"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case res := <-results:
+		if res.Path != app {
+			t.Errorf("result path = %q, want %q", res.Path, app)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no result for the written file")
+	}
+
+	// A file moved away within the quiet period is forgotten, and only analyzed under its new path.
+	tmp, renamed := filepath.Join(sub, "app.tmp"), filepath.Join(sub, "app2")
+	if err := os.WriteFile(tmp, []byte("\x7fELF truncated"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, renamed); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case res := <-results:
+		if res.Path != renamed {
+			t.Errorf("result path = %q, want %q", res.Path, renamed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no result for the renamed file")
+	}
+
+	cancel()
+	for res := range results {
+		t.Errorf("unexpected result %q", res.Path)
+	}
+}
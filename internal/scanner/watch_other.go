@@ -0,0 +1,20 @@
+//go:build !linux
+
+package scanner
+
+import "errors"
+
+// fileWatcher reports writes in watched directories. Watching is only implemented on Linux.
+type fileWatcher struct{}
+
+func newFileWatcher() (*fileWatcher, error) {
+	return nil, errors.New("watching directories is only supported on Linux")
+}
+
+func (w *fileWatcher) add(string) error { return nil }
+
+func (w *fileWatcher) remove(string) {}
+
+func (w *fileWatcher) read() ([]watchEvent, error) { return nil, nil }
+
+func (w *fileWatcher) close() error { return nil }
@@ -0,0 +1,27 @@
+//go:build linux
+
+package scanner
+
+import (
+	"errors"
+	"syscall"
+
+	"go.kacmar.sk/crack/binary"
+)
+
+// capabilityXattr is the extended attribute holding file capabilities.
+const capabilityXattr = "security.capability"
+
+// readCapabilities returns the file capabilities of the file at path, or nil when it has none.
+func readCapabilities(path string) (*binary.Capabilities, error) {
+	// The largest vfs_cap_data, revision 3, is 24 bytes.
+	var buf [64]byte
+	n, err := syscall.Getxattr(path, capabilityXattr, buf[:])
+	if errors.Is(err, syscall.ENODATA) || errors.Is(err, syscall.ENOTSUP) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return binary.ParseCapabilities(buf[:n])
+}
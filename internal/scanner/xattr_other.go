@@ -0,0 +1,10 @@
+//go:build !linux
+
+package scanner
+
+import "go.kacmar.sk/crack/binary"
+
+// readCapabilities returns nil, as file capabilities are only read on Linux.
+func readCapabilities(string) (*binary.Capabilities, error) {
+	return nil, nil
+}
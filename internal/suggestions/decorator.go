@@ -34,7 +34,12 @@ func Decorate(findings []rule.Finding, profile binary.Profile) []DecoratedFindin
 			continue
 		}
 
-		result[i].Suggestion = buildSuggestion(profile, r.Applicability())
+		// Rules without compiler requirements aren't fixed by a build flag, so there is nothing to suggest.
+		applicability := r.Applicability()
+		if len(applicability.Compilers) == 0 {
+			continue
+		}
+		result[i].Suggestion = buildSuggestion(profile, applicability)
 	}
 
 	return result
@@ -92,6 +97,15 @@ func buildCompilerSuggestion(profile binary.Profile, applicability rule.Applicab
 	flag := req.Flag
 	compilerName := profile.Toolchain.Compiler.String()
 
+	if flag == "" {
+		// Requirements without a flag are met by the toolchain alone.
+		if !profile.Toolchain.Version.IsAtLeast(req.MinVersion) {
+			return fmt.Sprintf("Requires %s %s+ (you have %s %s), update.",
+				compilerName, req.MinVersion.String(), compilerName, profile.Toolchain.Version.String())
+		}
+		return ""
+	}
+
 	if !profile.Toolchain.Version.IsAtLeast(req.MinVersion) {
 		return fmt.Sprintf("Requires %s %s+ (you have %s %s), update and use \"%s\".",
 			compilerName, req.MinVersion.String(), compilerName, profile.Toolchain.Version.String(), flag)
@@ -117,6 +117,17 @@ func TestBuildSuggestion(t *testing.T) {
 			},
 			wantContain: []string{"Should be enabled by default"},
 		},
+		{
+			name:    "requirement without flag below minimum version",
+			profile: binary.Profile{Toolchain: toolchain.Toolchain{Compiler: toolchain.Go, Version: toolchain.Version{Major: 1, Minor: 22}}},
+			applicability: rule.Applicability{
+				Platform: binary.PlatformAll,
+				Compilers: map[toolchain.Compiler]rule.CompilerRequirement{
+					toolchain.Go: {MinVersion: toolchain.Version{Major: 1, Minor: 26}},
+				},
+			},
+			wantExact: "Requires go 1.26+ (you have go 1.22), update.",
+		},
 		{
 			name:          "empty requirements",
 			profile:       binary.Profile{Toolchain: toolchain.Toolchain{Compiler: toolchain.GCC, Version: toolchain.Version{Major: 12, Minor: 0}}},
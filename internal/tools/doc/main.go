@@ -30,6 +30,8 @@ type ruleData struct {
 	StructName  string
 	Description string
 	Platform    string
+	Kinds       string
+	Privileged  bool
 	Compilers   []compilerData
 }
 
@@ -97,6 +99,8 @@ func toRuleData(r rule.Rule) ruleData {
 		StructName:  structName,
 		Description: r.Description(),
 		Platform:    formatPlatform(applicability.Platform),
+		Kinds:       applicability.EffectiveKinds().String(),
+		Privileged:  applicability.Privileged,
 		Compilers:   compilerList,
 	}
 }
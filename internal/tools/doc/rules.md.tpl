@@ -15,6 +15,10 @@
 
 {{$r.Platform}}
 
+### File Kinds
+
+{{$r.Kinds}}{{if $r.Privileged}}, setuid, setgid, or capability-bearing only{{end}}
+
 ### Toolchain
 
 {{if $r.Compilers -}}
@@ -11,8 +11,10 @@ type ApplicabilityResult int
 const (
 	Applicable ApplicabilityResult = iota
 	NotApplicableArchitecture
+	NotApplicableKind
 	NotApplicableCompiler
 	NotApplicableLibC
+	NotApplicablePrivilege
 )
 
 // String returns a human-readable skip message for non-applicable results.
@@ -20,10 +22,14 @@ func (r ApplicabilityResult) String() string {
 	switch r {
 	case NotApplicableArchitecture:
 		return "architecture not applicable"
+	case NotApplicableKind:
+		return "file kind not applicable"
 	case NotApplicableCompiler:
 		return "compiler not applicable"
 	case NotApplicableLibC:
 		return "libc not applicable"
+	case NotApplicablePrivilege:
+		return "privilege not applicable"
 	default:
 		return ""
 	}
@@ -34,10 +40,17 @@ func (r ApplicabilityResult) Reason(profile binary.Profile) string {
 	switch r {
 	case NotApplicableArchitecture:
 		return "rule not applicable to " + profile.Architecture.String() + " architecture"
+	case NotApplicableKind:
+		if profile.Kind == binary.KindRelocatable {
+			return "rule checks a property set at link time, not applicable to relocatable objects"
+		}
+		return "rule not applicable to " + profile.Kind.String() + " files"
 	case NotApplicableCompiler:
 		return "rule not applicable to " + profile.Toolchain.Compiler.String() + " binaries"
 	case NotApplicableLibC:
 		return "rule not applicable to " + profile.LibC.String() + " binaries"
+	case NotApplicablePrivilege:
+		return "rule only applies to setuid, setgid, or capability-bearing binaries"
 	default:
 		return ""
 	}
@@ -46,11 +59,17 @@ func (r ApplicabilityResult) Reason(profile binary.Profile) string {
 // CheckApplicability determines whether a rule applies to the binary.
 // When detection of an optional axis yields the Unknown sentinel (compiler, libc), the axis is skipped in the filter and the rule runs as best-effort.
 // Architecture has no such bypass because ELF machine detection cannot fail in practice.
+// An unknown kind is likewise bypassed, while an empty Kinds in the applicability means linked binaries only.
+// Rules for privileged binaries don't apply to binaries without file metadata, whose privileges can't be told.
 func CheckApplicability(app Applicability, profile binary.Profile) ApplicabilityResult {
 	if !profile.Architecture.Matches(app.Platform.Architecture) {
 		return NotApplicableArchitecture
 	}
 
+	if profile.Kind != binary.KindUnknown && !profile.Kind.Matches(app.EffectiveKinds()) {
+		return NotApplicableKind
+	}
+
 	if profile.Toolchain.Compiler != toolchain.Unknown {
 		hasCompiler := false
 		for comp := range app.Compilers {
@@ -68,5 +87,9 @@ func CheckApplicability(app Applicability, profile binary.Profile) Applicability
 		return NotApplicableLibC
 	}
 
+	if app.Privileged && !profile.File.Privileged() {
+		return NotApplicablePrivilege
+	}
+
 	return Applicable
 }
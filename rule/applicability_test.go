@@ -1,6 +1,7 @@
 package rule
 
 import (
+	"io/fs"
 	"testing"
 
 	"go.kacmar.sk/crack/binary"
@@ -140,6 +141,32 @@ func TestCheckApplicability(t *testing.T) {
 			},
 			want: NotApplicableLibC,
 		},
+		{
+			name: "privileged rule applies to setuid binary",
+			app:  Applicability{Platform: binary.PlatformAll, LibC: binary.LibCAll, Privileged: true},
+			profile: binary.Profile{
+				Architecture: binary.ArchAMD64,
+				File:         &binary.FileMetadata{Mode: fs.ModeSetuid | 0o755},
+			},
+			want: Applicable,
+		},
+		{
+			name: "privileged rule skips unprivileged binary",
+			app:  Applicability{Platform: binary.PlatformAll, LibC: binary.LibCAll, Privileged: true},
+			profile: binary.Profile{
+				Architecture: binary.ArchAMD64,
+				File:         &binary.FileMetadata{Mode: 0o755},
+			},
+			want: NotApplicablePrivilege,
+		},
+		{
+			name: "privileged rule skips binary without file metadata",
+			app:  Applicability{Platform: binary.PlatformAll, LibC: binary.LibCAll, Privileged: true},
+			profile: binary.Profile{
+				Architecture: binary.ArchAMD64,
+			},
+			want: NotApplicablePrivilege,
+		},
 		{
 			name: "unknown libc bypasses filter (best-effort)",
 			app: Applicability{
@@ -152,6 +179,42 @@ func TestCheckApplicability(t *testing.T) {
 			},
 			want: Applicable,
 		},
+		{
+			name: "default kinds skip relocatable objects",
+			app:  Applicability{Platform: binary.PlatformAll},
+			profile: binary.Profile{
+				Architecture: binary.ArchAMD64,
+				Kind:         binary.KindRelocatable,
+			},
+			want: NotApplicableKind,
+		},
+		{
+			name: "default kinds cover shared libraries",
+			app:  Applicability{Platform: binary.PlatformAll},
+			profile: binary.Profile{
+				Architecture: binary.ArchAMD64,
+				Kind:         binary.KindSharedLibrary,
+			},
+			want: Applicable,
+		},
+		{
+			name: "relocatable objects opted in",
+			app:  Applicability{Platform: binary.PlatformAll, Kinds: binary.KindAll},
+			profile: binary.Profile{
+				Architecture: binary.ArchAMD64,
+				Kind:         binary.KindRelocatable,
+			},
+			want: Applicable,
+		},
+		{
+			name: "unknown kind bypasses filter (best-effort)",
+			app:  Applicability{Platform: binary.PlatformAll, Kinds: binary.KindRelocatable},
+			profile: binary.Profile{
+				Architecture: binary.ArchAMD64,
+				Kind:         binary.KindUnknown,
+			},
+			want: Applicable,
+		},
 		{
 			name: "architecture failure shadows compiler failure",
 			app: Applicability{
@@ -197,6 +260,7 @@ func TestApplicabilityResultString(t *testing.T) {
 	}{
 		{Applicable, ""},
 		{NotApplicableArchitecture, "architecture not applicable"},
+		{NotApplicableKind, "file kind not applicable"},
 		{NotApplicableCompiler, "compiler not applicable"},
 		{NotApplicableLibC, "libc not applicable"},
 	}
@@ -210,6 +274,7 @@ func TestApplicabilityResultString(t *testing.T) {
 func TestApplicabilityResultReason(t *testing.T) {
 	profile := binary.Profile{
 		Architecture: binary.ArchAMD64,
+		Kind:         binary.KindRelocatable,
 		Toolchain:    toolchain.Toolchain{Compiler: toolchain.Clang},
 		LibC:         binary.LibCMusl,
 	}
@@ -219,6 +284,7 @@ func TestApplicabilityResultReason(t *testing.T) {
 	}{
 		{Applicable, ""},
 		{NotApplicableArchitecture, "rule not applicable to amd64 architecture"},
+		{NotApplicableKind, "rule checks a property set at link time, not applicable to relocatable objects"},
 		{NotApplicableCompiler, "rule not applicable to clang binaries"},
 		{NotApplicableLibC, "rule not applicable to musl binaries"},
 	}
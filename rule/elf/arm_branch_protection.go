@@ -27,6 +27,7 @@ func (r ARMBranchProtectionRule) Description() string {
 func (r ARMBranchProtectionRule) Applicability() rule.Applicability {
 	return rule.Applicability{
 		Platform: binary.PlatformARM64v85,
+		Kinds:    binary.KindAll,
 		Compilers: map[toolchain.Compiler]rule.CompilerRequirement{
 			toolchain.GCC:   {MinVersion: toolchain.Version{Major: 10, Minor: 1}, Flag: "-mbranch-protection=standard"},
 			toolchain.Clang: {MinVersion: toolchain.Version{Major: 12, Minor: 0}, Flag: "-mbranch-protection=standard"},
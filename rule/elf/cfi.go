@@ -45,6 +45,7 @@ func (r CFIRule) Applicability() rule.Applicability {
 	return rule.Applicability{
 		// LLVM does not support user-space CFI on riscv64.
 		Platform: binary.Platform{Architecture: binary.ArchAllX86 | binary.ArchAllARM},
+		Kinds:    binary.KindAll,
 		Compilers: map[toolchain.Compiler]rule.CompilerRequirement{
 			toolchain.Clang: {MinVersion: toolchain.Version{Major: 6, Minor: 0}, Flag: "-fsanitize=cfi -flto -fvisibility=hidden"},
 		},
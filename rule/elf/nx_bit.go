@@ -30,6 +30,9 @@ func (r NXBitRule) Applicability() rule.Applicability {
 		Compilers: map[toolchain.Compiler]rule.CompilerRequirement{
 			toolchain.GCC:   {MinVersion: toolchain.Version{Major: 3, Minor: 4}, DefaultVersion: toolchain.Version{Major: 3, Minor: 4}, Flag: "-z noexecstack"},
 			toolchain.Clang: {MinVersion: toolchain.Version{Major: 1, Minor: 0}, DefaultVersion: toolchain.Version{Major: 1, Minor: 0}, Flag: "-z noexecstack"},
+			// cgo binaries are linked by the C linker, which makes the stack executable when any C object asks for it.
+			toolchain.Go:    {MinVersion: toolchain.Version{Major: 1, Minor: 0}, DefaultVersion: toolchain.Version{Major: 1, Minor: 0}, Flag: "-ldflags=-extldflags=-Wl,-z,noexecstack"},
+			toolchain.Rustc: {MinVersion: toolchain.Version{Major: 1, Minor: 0}, DefaultVersion: toolchain.Version{Major: 1, Minor: 0}, Flag: "-C link-arg=-Wl,-z,noexecstack"},
 		},
 		LibC: binary.LibCAll,
 	}
@@ -0,0 +1,34 @@
+package elf
+
+import (
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+)
+
+// PrivilegedFullRELRORuleID is the rule ID for full RELRO in privileged binaries.
+const PrivilegedFullRELRORuleID = "privileged-full-relro"
+
+// PrivilegedFullRELRORule checks for full RELRO protection in privileged binaries.
+// It runs the full-relro check, reported separately so that lazy binding in a privileged binary stands out.
+//
+// References:
+//   - https://sourceware.org/binutils/docs/ld/Options.html
+type PrivilegedFullRELRORule struct{}
+
+func (r PrivilegedFullRELRORule) ID() string   { return PrivilegedFullRELRORuleID }
+func (r PrivilegedFullRELRORule) Name() string { return "Full RELRO in Privileged Binaries" }
+func (r PrivilegedFullRELRORule) Description() string {
+	return "Checks that setuid, setgid, and capability-bearing executables are linked with full RELRO. Lazy binding leaves the Global Offset Table writable for the lifetime of a privileged process, so a single memory corruption bug can redirect its function calls."
+}
+
+func (r PrivilegedFullRELRORule) Applicability() rule.Applicability {
+	app := FullRELRORule{}.Applicability()
+	app.Kinds = binary.KindExecutable
+	app.Privileged = true
+	return app
+}
+
+func (r PrivilegedFullRELRORule) Execute(bin elf.Binary) rule.Result {
+	return FullRELRORule{}.Execute(bin)
+}
@@ -0,0 +1,61 @@
+package elf
+
+import (
+	"fmt"
+	"slices"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+)
+
+// PrivilegedNoDLOpenRuleID is the rule ID for no runtime library loading in privileged binaries.
+const PrivilegedNoDLOpenRuleID = "privileged-no-dlopen"
+
+// dlopenFunctions are the functions that load a shared library at runtime.
+var dlopenFunctions = []string{"dlopen", "dlmopen"}
+
+// PrivilegedNoDLOpenRule checks that privileged binaries don't load shared libraries at runtime.
+//
+// References:
+//   - https://man7.org/linux/man-pages/man3/dlopen.3.html
+type PrivilegedNoDLOpenRule struct{}
+
+func (r PrivilegedNoDLOpenRule) ID() string   { return PrivilegedNoDLOpenRuleID }
+func (r PrivilegedNoDLOpenRule) Name() string { return "No dlopen in Privileged Binaries" }
+func (r PrivilegedNoDLOpenRule) Description() string {
+	return "Checks that setuid, setgid, and capability-bearing executables don't reference dlopen(3) or dlmopen(3). Libraries loaded at runtime are chosen by paths and environment that are easy to get wrong, and any code they run executes with the privileges of the binary."
+}
+
+func (r PrivilegedNoDLOpenRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform:   binary.Platform{Architecture: binary.ArchAllX86 | binary.ArchAllARM | binary.ArchRISCV},
+		Kinds:      binary.KindExecutable,
+		LibC:       binary.LibCAll,
+		Privileged: true,
+	}
+}
+
+func (r PrivilegedNoDLOpenRule) Execute(bin elf.Binary) rule.Result {
+	symbols, err := bin.Symbols()
+	if err != nil {
+		return rule.Skip("symbols unavailable", err)
+	}
+	dynSymbols, err := bin.DynSymbols()
+	if err != nil {
+		return rule.Skip("dynamic symbols unavailable", err)
+	}
+	for _, sym := range slices.Concat(symbols, dynSymbols) {
+		if slices.Contains(dlopenFunctions, sym.Name) {
+			return rule.Result{
+				Status:  rule.StatusFailed,
+				Message: fmt.Sprintf("Privileged binary references %s", sym.Name),
+			}
+		}
+	}
+
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "No dlopen references",
+	}
+}
@@ -0,0 +1,58 @@
+package elf
+
+import (
+	stdelf "debug/elf"
+	"fmt"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+)
+
+// PrivilegedNoRUNPATHRuleID is the rule ID for no RPATH or RUNPATH in privileged binaries.
+const PrivilegedNoRUNPATHRuleID = "privileged-no-runpath"
+
+// PrivilegedNoRUNPATHRule checks that privileged binaries set no library search path.
+//
+// References:
+//   - https://man7.org/linux/man-pages/man8/ld.so.8.html
+type PrivilegedNoRUNPATHRule struct{}
+
+func (r PrivilegedNoRUNPATHRule) ID() string   { return PrivilegedNoRUNPATHRuleID }
+func (r PrivilegedNoRUNPATHRule) Name() string { return "No RPATH or RUNPATH in Privileged Binaries" }
+func (r PrivilegedNoRUNPATHRule) Description() string {
+	return "Checks that setuid, setgid, and capability-bearing executables set neither RPATH nor RUNPATH. Any search path, even an absolute or $ORIGIN-relative one, makes the libraries loaded into a privileged process depend on directories outside the system library path, where a hard link to the binary or a writable directory lets an attacker supply their own."
+}
+
+func (r PrivilegedNoRUNPATHRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform:   binary.Platform{Architecture: binary.ArchAllX86 | binary.ArchAllARM | binary.ArchRISCV},
+		Kinds:      binary.KindExecutable,
+		LibC:       binary.LibCAll,
+		Privileged: true,
+	}
+}
+
+func (r PrivilegedNoRUNPATHRule) Execute(bin elf.Binary) rule.Result {
+	for _, tag := range []stdelf.DynTag{stdelf.DT_RPATH, stdelf.DT_RUNPATH} {
+		path, err := elf.DynString(bin, tag)
+		if err != nil {
+			return rule.Skip("failed to read dynamic section", err)
+		}
+		if path != "" {
+			name := "RPATH"
+			if tag == stdelf.DT_RUNPATH {
+				name = "RUNPATH"
+			}
+			return rule.Result{
+				Status:  rule.StatusFailed,
+				Message: fmt.Sprintf("Privileged binary sets %s: %s", name, path),
+			}
+		}
+	}
+
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "No RPATH or RUNPATH set",
+	}
+}
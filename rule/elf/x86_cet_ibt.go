@@ -25,6 +25,7 @@ func (r X86CETIBTRule) Description() string {
 func (r X86CETIBTRule) Applicability() rule.Applicability {
 	return rule.Applicability{
 		Platform: binary.PlatformAllX86,
+		Kinds:    binary.KindAll,
 		Compilers: map[toolchain.Compiler]rule.CompilerRequirement{
 			toolchain.GCC:   {MinVersion: toolchain.Version{Major: 8, Minor: 1}, Flag: "-fcf-protection=full"},
 			toolchain.Clang: {MinVersion: toolchain.Version{Major: 10, Minor: 0}, Flag: "-fcf-protection=full"},
@@ -0,0 +1,26 @@
+package golang
+
+import (
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+)
+
+// buildSettings returns the build information of a Go binary that records its build settings.
+// When it doesn't, ok is false and skip is the result to report.
+func buildSettings(bin elf.Binary) (info *binary.GoBuildInfo, skip rule.Result, ok bool) {
+	info = elf.DetectGoBuildInfo(bin)
+	switch {
+	case info == nil:
+		return nil, rule.Result{
+			Status:  rule.StatusSkipped,
+			Message: "No Go build information",
+		}, false
+	case info.BuildMode == "":
+		return nil, rule.Result{
+			Status:  rule.StatusSkipped,
+			Message: "Build settings not recorded, built before Go 1.18",
+		}, false
+	}
+	return info, rule.Result{}, true
+}
@@ -0,0 +1,124 @@
+package golang
+
+import (
+	"errors"
+	"strings"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+	"go.kacmar.sk/crack/toolchain"
+)
+
+// CgoHardeningRuleID is the rule ID for hardened cgo C flags.
+const CgoHardeningRuleID = "go-cgo-hardening"
+
+// CgoHardeningRule checks if the C code of a cgo binary was compiled with hardening options.
+//
+// References:
+//   - https://pkg.go.dev/cmd/cgo
+//   - https://gcc.gnu.org/onlinedocs/gcc/Instrumentation-Options.html#index-fstack-protector
+//   - https://www.gnu.org/software/libc/manual/html_node/Source-Fortification.html
+type CgoHardeningRule struct{}
+
+func (r CgoHardeningRule) ID() string   { return CgoHardeningRuleID }
+func (r CgoHardeningRule) Name() string { return "Go cgo Hardening Flags" }
+func (r CgoHardeningRule) Description() string {
+	return "Checks if the CGO_CFLAGS a cgo binary was built with enable the stack protector and _FORTIFY_SOURCE. The C code linked into a Go binary gets none of Go's memory safety, and cgo compiles it with just \"-O2 -g\" unless CGO_CFLAGS says otherwise. Hardening a C compiler enables by default isn't recorded, and -trimpath builds don't record CGO_CFLAGS at all."
+}
+
+func (r CgoHardeningRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.PlatformAll,
+		Compilers: map[toolchain.Compiler]rule.CompilerRequirement{
+			toolchain.Go: {MinVersion: toolchain.Version{Major: 1, Minor: 18}, Flag: "CGO_CFLAGS='-O2 -fstack-protector-strong -D_FORTIFY_SOURCE=2'"},
+		},
+		LibC: binary.LibCAll,
+	}
+}
+
+func (r CgoHardeningRule) Execute(bin elf.Binary) rule.Result {
+	info, skip, ok := buildSettings(bin)
+	if !ok {
+		return skip
+	}
+	cgo, err := usesCgo(bin)
+	if err != nil {
+		return rule.Skip("failed to detect cgo", err)
+	}
+	if !info.CGOEnabled || !cgo {
+		return rule.Result{
+			Status:  rule.StatusSkipped,
+			Message: "No cgo code linked",
+		}
+	}
+	return checkCFlags(info)
+}
+
+// checkCFlags checks the CGO_CFLAGS recorded in the build information of a binary that links cgo code.
+func checkCFlags(info *binary.GoBuildInfo) rule.Result {
+	if info.TrimPath && info.CGOCFlags == "" {
+		return rule.Result{
+			Status:  rule.StatusSkipped,
+			Message: "CGO_CFLAGS not recorded in -trimpath builds",
+		}
+	}
+	var missing []string
+	stackProtector, fortify := cflagsHardening(info.CGOCFlags)
+	if !stackProtector {
+		missing = append(missing, "-fstack-protector-strong")
+	}
+	if !fortify {
+		missing = append(missing, "-D_FORTIFY_SOURCE=2")
+	}
+	if len(missing) > 0 {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "CGO_CFLAGS missing " + strings.Join(missing, ", "),
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "CGO_CFLAGS enable stack protector and _FORTIFY_SOURCE",
+	}
+}
+
+// cflagsHardening reports whether the C compiler flags enable the strong or full stack protector and _FORTIFY_SOURCE level 2 or 3.
+// Later flags override earlier ones, as they do for the compiler.
+func cflagsHardening(cflags string) (stackProtector, fortify bool) {
+	for _, flag := range strings.Fields(cflags) {
+		switch flag {
+		case "-fstack-protector-strong", "-fstack-protector-all":
+			stackProtector = true
+		case "-fstack-protector", "-fno-stack-protector":
+			stackProtector = false
+		case "-D_FORTIFY_SOURCE=2", "-D_FORTIFY_SOURCE=3":
+			fortify = true
+		case "-U_FORTIFY_SOURCE", "-D_FORTIFY_SOURCE=0", "-D_FORTIFY_SOURCE=1", "-D_FORTIFY_SOURCE":
+			fortify = false
+		}
+	}
+	return stackProtector, fortify
+}
+
+// usesCgo reports whether C code is linked into the binary. Dynamically linked Go binaries only load shared libraries
+// through cgo, and statically linked ones carry the x_cgo_init function of runtime/cgo.
+func usesCgo(bin elf.Binary) (bool, error) {
+	libs, err := elf.ImportedLibraries(bin)
+	if err != nil && !errors.Is(err, elf.ErrSectionMissing) {
+		return false, err
+	}
+	if len(libs) > 0 {
+		return true, nil
+	}
+	symbols, err := bin.Symbols()
+	if err != nil {
+		return false, err
+	}
+	for _, sym := range symbols {
+		if sym.Name == "x_cgo_init" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,61 @@
+package golang
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/rule"
+)
+
+func TestCFlagsHardening(t *testing.T) {
+	tests := []struct {
+		name               string
+		cflags             string
+		wantStackProtector bool
+		wantFortify        bool
+	}{
+		{name: "unset", cflags: ""},
+		{name: "cgo default", cflags: "-O2 -g"},
+		{name: "hardened", cflags: "-O2 -fstack-protector-strong -D_FORTIFY_SOURCE=2", wantStackProtector: true, wantFortify: true},
+		{name: "full protector and fortify 3", cflags: "-fstack-protector-all -D_FORTIFY_SOURCE=3", wantStackProtector: true, wantFortify: true},
+		{name: "weak protector and fortify 1", cflags: "-fstack-protector -D_FORTIFY_SOURCE=1"},
+		{name: "protector disabled later", cflags: "-fstack-protector-strong -D_FORTIFY_SOURCE=2 -fno-stack-protector", wantFortify: true},
+		{name: "protector enabled later", cflags: "-fno-stack-protector -fstack-protector-strong", wantStackProtector: true},
+		{name: "protector weakened later", cflags: "-fstack-protector-all -fstack-protector"},
+		{name: "fortify undefined later", cflags: "-D_FORTIFY_SOURCE=2 -fstack-protector-strong -U_FORTIFY_SOURCE", wantStackProtector: true},
+		{name: "fortify redefined later", cflags: "-U_FORTIFY_SOURCE -D_FORTIFY_SOURCE=3", wantFortify: true},
+		{name: "fortify lowered later", cflags: "-D_FORTIFY_SOURCE=3 -D_FORTIFY_SOURCE=0"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			stackProtector, fortify := cflagsHardening(tc.cflags)
+			if stackProtector != tc.wantStackProtector || fortify != tc.wantFortify {
+				t.Errorf("cflagsHardening(%q) = (%v, %v), want (%v, %v)", tc.cflags, stackProtector, fortify, tc.wantStackProtector, tc.wantFortify)
+			}
+		})
+	}
+}
+
+func TestCheckCFlags(t *testing.T) {
+	const hardened = "-O2 -fstack-protector-strong -D_FORTIFY_SOURCE=2"
+	tests := []struct {
+		name string
+		info binary.GoBuildInfo
+		want rule.Status
+	}{
+		{name: "hardened", info: binary.GoBuildInfo{CGOCFlags: hardened}, want: rule.StatusPassed},
+		{name: "unset", info: binary.GoBuildInfo{}, want: rule.StatusFailed},
+		{name: "hardening undone", info: binary.GoBuildInfo{CGOCFlags: hardened + " -fno-stack-protector"}, want: rule.StatusFailed},
+		{name: "trimpath", info: binary.GoBuildInfo{TrimPath: true}, want: rule.StatusSkipped},
+		{name: "trimpath with recorded flags", info: binary.GoBuildInfo{TrimPath: true, CGOCFlags: hardened}, want: rule.StatusPassed},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := checkCFlags(&tc.info); got.Status != tc.want {
+				t.Errorf("checkCFlags() = %+v, want status %v", got, tc.want)
+			}
+		})
+	}
+}
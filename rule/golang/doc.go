@@ -0,0 +1,2 @@
+// Package golang provides built-in security hardening rules for Go binaries, based on the build information the Go linker embeds.
+package golang
@@ -0,0 +1,50 @@
+package golang
+
+import (
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+	"go.kacmar.sk/crack/toolchain"
+)
+
+// NoRaceRuleID is the rule ID for Go binaries built without the race detector.
+const NoRaceRuleID = "go-no-race"
+
+// NoRaceRule checks that a Go binary wasn't built with the race detector.
+//
+// References:
+//   - https://go.dev/doc/articles/race_detector
+type NoRaceRule struct{}
+
+func (r NoRaceRule) ID() string   { return NoRaceRuleID }
+func (r NoRaceRule) Name() string { return "Go Race Detector Disabled" }
+func (r NoRaceRule) Description() string {
+	return "Checks that a Go binary wasn't built with -race. The race detector is meant for testing: it links the ThreadSanitizer runtime, which maps large shadow memory regions at fixed addresses, may re-execute the process with ASLR disabled, and slows it down several times over, so it has no place in release builds."
+}
+
+func (r NoRaceRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.PlatformAll,
+		Compilers: map[toolchain.Compiler]rule.CompilerRequirement{
+			toolchain.Go: {MinVersion: toolchain.Version{Major: 1, Minor: 18}},
+		},
+		LibC: binary.LibCAll,
+	}
+}
+
+func (r NoRaceRule) Execute(bin elf.Binary) rule.Result {
+	info, skip, ok := buildSettings(bin)
+	if !ok {
+		return skip
+	}
+	if info.Race {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "Race detector enabled",
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "Race detector not enabled",
+	}
+}
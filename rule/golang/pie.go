@@ -0,0 +1,51 @@
+package golang
+
+import (
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+	"go.kacmar.sk/crack/toolchain"
+)
+
+// PIERuleID is the rule ID for the Go PIE build mode.
+const PIERuleID = "go-pie"
+
+// PIERule checks if a Go executable was built with -buildmode=pie.
+//
+// References:
+//   - https://pkg.go.dev/cmd/go#hdr-Build_modes
+type PIERule struct{}
+
+func (r PIERule) ID() string   { return PIERuleID }
+func (r PIERule) Name() string { return "Go Position Independent Executable" }
+func (r PIERule) Description() string {
+	return "Checks if a Go executable was built with -buildmode=pie, so that ASLR loads its code at a random address. The Go toolchain defaults to non-PIE executables on Linux, whose fixed addresses give an attacker who found a memory corruption bug, for example in cgo code, known locations to jump to."
+}
+
+func (r PIERule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.PlatformAll,
+		Kinds:    binary.KindExecutable,
+		Compilers: map[toolchain.Compiler]rule.CompilerRequirement{
+			toolchain.Go: {MinVersion: toolchain.Version{Major: 1, Minor: 15}, Flag: "-buildmode=pie"},
+		},
+		LibC: binary.LibCAll,
+	}
+}
+
+func (r PIERule) Execute(bin elf.Binary) rule.Result {
+	info, skip, ok := buildSettings(bin)
+	if !ok {
+		return skip
+	}
+	if info.BuildMode != "pie" {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "Built with -buildmode=" + info.BuildMode,
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "Built with -buildmode=pie",
+	}
+}
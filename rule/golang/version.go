@@ -0,0 +1,71 @@
+package golang
+
+import (
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+	"go.kacmar.sk/crack/toolchain"
+)
+
+// VersionRuleID is the rule ID for the minimum Go version.
+const VersionRuleID = "go-version"
+
+// minimumVersion is the oldest Go release still receiving security fixes when this rule set was last updated.
+// The Go team supports the two most recent major releases.
+var minimumVersion = toolchain.Version{Major: 1, Minor: 26}
+
+// VersionRule checks if a Go binary was built by a supported Go release.
+//
+// References:
+//   - https://go.dev/doc/devel/release#policy
+//   - https://go.dev/doc/security/policy
+type VersionRule struct{}
+
+func (r VersionRule) ID() string   { return VersionRuleID }
+func (r VersionRule) Name() string { return "Go Version" }
+func (r VersionRule) Description() string {
+	return "Checks if a Go binary was built by a Go release that still receives security fixes. The standard library and runtime are linked statically into every Go binary, so vulnerabilities fixed in crypto/tls, net/http or the runtime stay in a binary until it is rebuilt with a patched toolchain."
+}
+
+func (r VersionRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.PlatformAll,
+		Compilers: map[toolchain.Compiler]rule.CompilerRequirement{
+			toolchain.Go: {MinVersion: minimumVersion},
+		},
+		LibC: binary.LibCAll,
+	}
+}
+
+func (r VersionRule) Execute(bin elf.Binary) rule.Result {
+	info := elf.DetectGoBuildInfo(bin)
+	if info == nil {
+		return rule.Result{
+			Status:  rule.StatusSkipped,
+			Message: "No Go build information",
+		}
+	}
+	if info.GoVersion == "" {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "Built before Go 1.18, minimum " + minimumVersion.String(),
+		}
+	}
+	version, ok := toolchain.ParseGoVersion(info.GoVersion)
+	if !ok {
+		return rule.Result{
+			Status:  rule.StatusSkipped,
+			Message: "Unrecognized Go version " + info.GoVersion,
+		}
+	}
+	if !version.IsAtLeast(minimumVersion) {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "Built with Go " + version.String() + ", minimum " + minimumVersion.String(),
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "Built with Go " + version.String(),
+	}
+}
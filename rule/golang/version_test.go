@@ -0,0 +1,90 @@
+package golang
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"testing"
+
+	crackelf "go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+)
+
+// buildGoBinary returns a little-endian 64-bit ELF file whose only section is a .go.buildinfo holding buildinfo.
+func buildGoBinary(t *testing.T, buildinfo []byte) *crackelf.File {
+	t.Helper()
+
+	const (
+		headerSize        = 64
+		sectionHeaderSize = 64
+	)
+	names := []byte("\x00.go.buildinfo\x00.shstrtab\x00")
+	buildinfoOff := uint64(headerSize)
+	namesOff := buildinfoOff + uint64(len(buildinfo))
+	sectionHeadersOff := namesOff + uint64(len(names))
+
+	header := elf.Header64{
+		Ident:     [16]byte{0x7f, 'E', 'L', 'F', byte(elf.ELFCLASS64), byte(elf.ELFDATA2LSB), byte(elf.EV_CURRENT)},
+		Type:      uint16(elf.ET_EXEC),
+		Machine:   uint16(elf.EM_X86_64),
+		Version:   uint32(elf.EV_CURRENT),
+		Shoff:     sectionHeadersOff,
+		Ehsize:    headerSize,
+		Shentsize: sectionHeaderSize,
+		Shnum:     3,
+		Shstrndx:  2,
+	}
+	sections := []elf.Section64{
+		{},
+		{Name: 1, Type: uint32(elf.SHT_PROGBITS), Flags: uint64(elf.SHF_ALLOC | elf.SHF_WRITE), Off: buildinfoOff, Size: uint64(len(buildinfo)), Addralign: 16},
+		{Name: 15, Type: uint32(elf.SHT_STRTAB), Off: namesOff, Size: uint64(len(names)), Addralign: 1},
+	}
+
+	var buf bytes.Buffer
+	for _, v := range []any{&header, buildinfo, names, sections} {
+		if err := binary.Write(&buf, binary.LittleEndian, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := crackelf.Open(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	return f
+}
+
+// goBuildInfoHeader returns the 32-byte .go.buildinfo header with the given flags. Go 1.18 and later set flag 0x2 and follow
+// the header with the version and module information, older releases point at them from the rest of the header.
+func goBuildInfoHeader(flags byte) []byte {
+	header := make([]byte, 32)
+	copy(header, "\xff Go buildinf:")
+	header[14] = 8
+	header[15] = flags
+	return header
+}
+
+// varintString encodes s the way Go 1.18 and later store strings in .go.buildinfo.
+func varintString(s string) []byte {
+	return append(binary.AppendUvarint(nil, uint64(len(s))), s...)
+}
+
+func TestVersionRule(t *testing.T) {
+	tests := []struct {
+		name      string
+		buildinfo []byte
+		want      rule.Status
+	}{
+		{name: "before Go 1.18", buildinfo: goBuildInfoHeader(0), want: rule.StatusFailed},
+		{name: "outdated", buildinfo: append(goBuildInfoHeader(0x2), append(varintString("go1.21.13"), varintString("")...)...), want: rule.StatusFailed},
+		{name: "supported", buildinfo: append(goBuildInfoHeader(0x2), append(varintString("go1.26.2"), varintString("")...)...), want: rule.StatusPassed},
+		{name: "development build", buildinfo: append(goBuildInfoHeader(0x2), append(varintString("devel +abc123"), varintString("")...)...), want: rule.StatusSkipped},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := (VersionRule{}).Execute(buildGoBinary(t, tc.buildinfo)); got.Status != tc.want {
+				t.Errorf("Execute() = %+v, want status %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,2 @@
+// Package kernel provides built-in security hardening rules for Linux kernel images and modules.
+package kernel
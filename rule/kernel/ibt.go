@@ -0,0 +1,43 @@
+package kernel
+
+import (
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+)
+
+// IBTRuleID is the rule ID for kernel indirect branch tracking.
+const IBTRuleID = "kernel-ibt"
+
+// IBTRule checks if a kernel image was built with Intel CET Indirect Branch Tracking.
+//
+// References:
+//   - https://gcc.gnu.org/onlinedocs/gcc/x86-Options.html#index-fcf-protection
+type IBTRule struct{}
+
+func (r IBTRule) ID() string   { return IBTRuleID }
+func (r IBTRule) Name() string { return "Kernel Indirect Branch Tracking" }
+func (r IBTRule) Description() string {
+	return "Checks if the kernel was built with CONFIG_X86_KERNEL_IBT, which starts the targets of indirect branches with ENDBR64 so the CPU faults when a branch lands elsewhere. Objtool records the ENDBR64 instructions of functions that are never called indirectly in the .ibt_endbr_seal section, from where the kernel overwrites them at boot."
+}
+
+func (r IBTRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.PlatformAllX86,
+		Kinds:    binary.KindKernelImage,
+		LibC:     binary.LibCNone,
+	}
+}
+
+func (r IBTRule) Execute(bin elf.Binary) rule.Result {
+	if !hasSection(bin, ".ibt_endbr_seal") {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "IBT not enabled",
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "IBT enabled",
+	}
+}
@@ -0,0 +1,53 @@
+package kernel
+
+import (
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+)
+
+// KCFIRuleID is the rule ID for kernel control flow integrity.
+const KCFIRuleID = "kernel-kcfi"
+
+// KCFIRule checks if a kernel image was built with kCFI.
+//
+// References:
+//   - https://clang.llvm.org/docs/ControlFlowIntegrity.html#fsanitize-kcfi
+type KCFIRule struct{}
+
+func (r KCFIRule) ID() string   { return KCFIRuleID }
+func (r KCFIRule) Name() string { return "Kernel Control Flow Integrity" }
+func (r KCFIRule) Description() string {
+	return "Checks if the kernel was built with CONFIG_CFI_CLANG, Clang's kCFI, which checks the type hash stored before the target of every indirect call and traps on a mismatch. The kernel exports type hashes for assembly code as __kcfi_typeid_ symbols, and on x86 lists the trapping checks in the .kcfi_traps section."
+}
+
+func (r KCFIRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.Platform{Architecture: binary.ArchAMD64 | binary.ArchARM64 | binary.ArchRISCV},
+		Kinds:    binary.KindKernelImage,
+		LibC:     binary.LibCNone,
+	}
+}
+
+func (r KCFIRule) Execute(bin elf.Binary) rule.Result {
+	if hasSection(bin, ".kcfi_traps") {
+		return rule.Result{
+			Status:  rule.StatusPassed,
+			Message: "kCFI enabled",
+		}
+	}
+	symbols, skip, ok := imageSymbols(bin)
+	if !ok {
+		return skip
+	}
+	if !hasSymbolPrefix(symbols, "__kcfi_typeid_") {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "kCFI not enabled",
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "kCFI enabled",
+	}
+}
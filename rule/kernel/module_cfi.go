@@ -0,0 +1,73 @@
+package kernel
+
+import (
+	"bytes"
+	"errors"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+)
+
+// ModuleCFIRuleID is the rule ID for kernel module control flow integrity.
+const ModuleCFIRuleID = "kmod-cfi"
+
+// The ENDBR64 and ENDBR32 instructions that IBT requires at the target of an indirect branch.
+var (
+	endbr64 = []byte{0xf3, 0x0f, 0x1e, 0xfa}
+	endbr32 = []byte{0xf3, 0x0f, 0x1e, 0xfb}
+)
+
+// ModuleCFIRule checks if a kernel module was built with kCFI or IBT landing pads.
+//
+// References:
+//   - https://gcc.gnu.org/onlinedocs/gcc/x86-Options.html#index-fcf-protection
+//   - https://clang.llvm.org/docs/ControlFlowIntegrity.html#fsanitize-kcfi
+type ModuleCFIRule struct{}
+
+func (r ModuleCFIRule) ID() string   { return ModuleCFIRuleID }
+func (r ModuleCFIRule) Name() string { return "Kernel Module Control Flow Integrity" }
+func (r ModuleCFIRule) Description() string {
+	return "Checks if a kernel module was built with forward-edge control flow integrity. CONFIG_CFI_CLANG precedes every address-taken function with a __cfi_ preamble holding its type hash, which indirect calls check. CONFIG_X86_KERNEL_IBT starts those functions with ENDBR64, and the CPU faults when an indirect branch lands elsewhere. The module's init_module entry point, which the kernel calls indirectly, is checked for either."
+}
+
+func (r ModuleCFIRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.PlatformAllX86,
+		Kinds:    binary.KindKernelModule,
+	}
+}
+
+func (r ModuleCFIRule) Execute(bin elf.Binary) rule.Result {
+	symbols, err := bin.Symbols()
+	if err != nil {
+		return rule.Skip("symbols unavailable", err)
+	}
+	if referencesSymbol(symbols, "__cfi_init_module") {
+		return rule.Result{
+			Status:  rule.StatusPassed,
+			Message: "kCFI enabled",
+		}
+	}
+
+	entry, err := elf.SymbolData(bin, "init_module", len(endbr64))
+	if errors.Is(err, elf.ErrSectionMissing) {
+		return rule.Result{
+			Status:  rule.StatusSkipped,
+			Message: "Module has no init_module entry point",
+		}
+	}
+	if err != nil {
+		return rule.Skip("failed to read init_module", err)
+	}
+	if bytes.Equal(entry, endbr64) || bytes.Equal(entry, endbr32) {
+		return rule.Result{
+			Status:  rule.StatusPassed,
+			Message: "IBT enabled",
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusFailed,
+		Message: "Neither kCFI nor IBT enabled",
+	}
+}
@@ -0,0 +1,52 @@
+package kernel
+
+import (
+	stdelf "debug/elf"
+	"strings"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+)
+
+// ModuleNoWXRuleID is the rule ID for kernel modules without writable and executable sections.
+const ModuleNoWXRuleID = "kmod-no-wx"
+
+// ModuleNoWXRule checks that no section of a kernel module is both writable and executable.
+//
+// References:
+//   - https://docs.kernel.org/security/self-protection.html
+type ModuleNoWXRule struct{}
+
+func (r ModuleNoWXRule) ID() string   { return ModuleNoWXRuleID }
+func (r ModuleNoWXRule) Name() string { return "Kernel Module Writable and Executable Sections" }
+func (r ModuleNoWXRule) Description() string {
+	return "Checks that no section of a kernel module is both writable and executable. With CONFIG_STRICT_MODULE_RWX the kernel maps module code read-only and data non-executable, and refuses to load a module with a section that needs both, since code that stays writable can be patched by any kernel write primitive."
+}
+
+func (r ModuleNoWXRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.PlatformAll,
+		Kinds:    binary.KindKernelModule,
+	}
+}
+
+func (r ModuleNoWXRule) Execute(bin elf.Binary) rule.Result {
+	const wx = stdelf.SHF_ALLOC | stdelf.SHF_WRITE | stdelf.SHF_EXECINSTR
+	var names []string
+	for _, sec := range bin.Sections() {
+		if sec.Flags&wx == wx {
+			names = append(names, sec.Name)
+		}
+	}
+	if len(names) > 0 {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "Writable and executable sections: " + strings.Join(names, ", "),
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "No writable and executable sections",
+	}
+}
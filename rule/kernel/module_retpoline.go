@@ -0,0 +1,53 @@
+package kernel
+
+import (
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+)
+
+// ModuleRetpolineRuleID is the rule ID for kernel module retpoline and return thunks.
+const ModuleRetpolineRuleID = "kmod-retpoline"
+
+// ModuleRetpolineRule checks if a kernel module was built with retpolines and return thunks.
+//
+// References:
+//   - https://docs.kernel.org/admin-guide/hw-vuln/spectre.html
+type ModuleRetpolineRule struct{}
+
+func (r ModuleRetpolineRule) ID() string   { return ModuleRetpolineRuleID }
+func (r ModuleRetpolineRule) Name() string { return "Kernel Module Retpoline" }
+func (r ModuleRetpolineRule) Description() string {
+	return "Checks if a kernel module was built with retpolines and return thunks, the Spectre v2 and Retbleed mitigations of CONFIG_MITIGATION_RETPOLINE and CONFIG_MITIGATION_RETHUNK. The kernel records \"retpoline=Y\" in .modinfo when the module's indirect branches go through retpolines, and warns when loading a module without it into a kernel that relies on them. Return thunks show as references to __x86_return_thunk."
+}
+
+func (r ModuleRetpolineRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.PlatformAllX86,
+		Kinds:    binary.KindKernelModule,
+	}
+}
+
+func (r ModuleRetpolineRule) Execute(bin elf.Binary) rule.Result {
+	if info := elf.DetectModuleInfo(bin); info == nil || !info.Retpoline {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "Retpoline not enabled",
+		}
+	}
+
+	symbols, err := bin.Symbols()
+	if err != nil {
+		return rule.Skip("symbols unavailable", err)
+	}
+	if !referencesSymbol(symbols, "__x86_return_thunk") {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "Retpoline enabled, return thunks not enabled",
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "Retpoline and return thunks enabled",
+	}
+}
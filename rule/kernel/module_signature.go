@@ -0,0 +1,46 @@
+package kernel
+
+import (
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+)
+
+// ModuleSignatureRuleID is the rule ID for kernel module signatures.
+const ModuleSignatureRuleID = "kmod-signature"
+
+// ModuleSignatureRule checks if a kernel module has a signature appended.
+//
+// References:
+//   - https://docs.kernel.org/admin-guide/module-signing.html
+type ModuleSignatureRule struct{}
+
+func (r ModuleSignatureRule) ID() string   { return ModuleSignatureRuleID }
+func (r ModuleSignatureRule) Name() string { return "Kernel Module Signature" }
+func (r ModuleSignatureRule) Description() string {
+	return "Checks if a signature is appended to the kernel module, ending with the \"~Module signature appended~\" marker. Kernels built with CONFIG_MODULE_SIG_FORCE, or booted with module.sig_enforce=1 or under Secure Boot lockdown, refuse to load unsigned modules, and others taint themselves when loading one. The signature itself isn't verified."
+}
+
+func (r ModuleSignatureRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.PlatformAll,
+		Kinds:    binary.KindKernelModule,
+	}
+}
+
+func (r ModuleSignatureRule) Execute(bin elf.Binary) rule.Result {
+	signed, err := elf.HasModuleSignature(bin)
+	if err != nil {
+		return rule.Skip("failed to read module signature", err)
+	}
+	if !signed {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "Module signature not appended",
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "Module signature appended",
+	}
+}
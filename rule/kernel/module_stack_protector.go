@@ -0,0 +1,47 @@
+package kernel
+
+import (
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+)
+
+// ModuleStackProtectorRuleID is the rule ID for kernel module stack protector.
+const ModuleStackProtectorRuleID = "kmod-stack-protector"
+
+// ModuleStackProtectorRule checks if a kernel module was built with the stack protector.
+//
+// References:
+//   - https://docs.kernel.org/security/self-protection.html#stack-buffer-overflow
+//   - https://gcc.gnu.org/onlinedocs/gcc/Instrumentation-Options.html#index-fstack-protector
+type ModuleStackProtectorRule struct{}
+
+func (r ModuleStackProtectorRule) ID() string   { return ModuleStackProtectorRuleID }
+func (r ModuleStackProtectorRule) Name() string { return "Kernel Module Stack Protector" }
+func (r ModuleStackProtectorRule) Description() string {
+	return "Checks if a kernel module was built with the stack protector of CONFIG_STACKPROTECTOR, which places a canary between local buffers and the return address and panics when an overflow overwrites it. Protected functions reference __stack_chk_guard, or on x86, where the canary is read from per-CPU data, only __stack_chk_fail. A module without functions that need a canary fails too."
+}
+
+func (r ModuleStackProtectorRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.PlatformAll,
+		Kinds:    binary.KindKernelModule,
+	}
+}
+
+func (r ModuleStackProtectorRule) Execute(bin elf.Binary) rule.Result {
+	symbols, err := bin.Symbols()
+	if err != nil {
+		return rule.Skip("symbols unavailable", err)
+	}
+	if !referencesSymbol(symbols, "__stack_chk_guard", "__stack_chk_fail") {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "Stack protector not enabled",
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "Stack protector enabled",
+	}
+}
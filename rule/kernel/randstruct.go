@@ -0,0 +1,63 @@
+package kernel
+
+import (
+	"bytes"
+	"errors"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+)
+
+// RandstructRuleID is the rule ID for kernel structure layout randomization.
+const RandstructRuleID = "kernel-randstruct"
+
+// randstructMarker starts the part of the vermagic string naming the hash of the structure layout seed.
+var randstructMarker = []byte(" RANDSTRUCT_")
+
+// RandstructRule checks if a kernel image was built with structure layout randomization.
+//
+// References:
+//   - https://docs.kernel.org/security/self-protection.html#randomization
+type RandstructRule struct{}
+
+func (r RandstructRule) ID() string   { return RandstructRuleID }
+func (r RandstructRule) Name() string { return "Kernel Structure Layout Randomization" }
+func (r RandstructRule) Description() string {
+	return "Checks if the kernel was built with CONFIG_RANDSTRUCT, which shuffles the fields of sensitive structures with a per-build seed so an exploit can't rely on their offsets. The layout leaves no symbols behind, so the rule looks for the seed hash the kernel adds to its vermagic string in .rodata to keep modules of another layout from loading. Kernels built without module support carry no vermagic string and fail the rule."
+}
+
+func (r RandstructRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.PlatformAll,
+		Kinds:    binary.KindKernelImage,
+		LibC:     binary.LibCNone,
+	}
+}
+
+func (r RandstructRule) Execute(bin elf.Binary) rule.Result {
+	sec, err := elf.FindSection(bin, ".rodata")
+	if errors.Is(err, elf.ErrSectionMissing) {
+		return rule.Result{
+			Status:  rule.StatusSkipped,
+			Message: "Kernel image has no .rodata section",
+		}
+	}
+	if err != nil {
+		return rule.Skip("failed to read .rodata", err)
+	}
+	data, err := sec.Data()
+	if err != nil {
+		return rule.Skip("failed to read .rodata", err)
+	}
+	if !bytes.Contains(data, randstructMarker) {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "Structure layout randomization not enabled",
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "Structure layout randomization enabled",
+	}
+}
@@ -0,0 +1,53 @@
+package kernel
+
+import (
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+)
+
+// RetpolineRuleID is the rule ID for kernel retpoline and return thunks.
+const RetpolineRuleID = "kernel-retpoline"
+
+// RetpolineRule checks if a kernel image was built with retpolines and return thunks.
+//
+// References:
+//   - https://docs.kernel.org/admin-guide/hw-vuln/spectre.html
+type RetpolineRule struct{}
+
+func (r RetpolineRule) ID() string   { return RetpolineRuleID }
+func (r RetpolineRule) Name() string { return "Kernel Retpoline" }
+func (r RetpolineRule) Description() string {
+	return "Checks if the kernel was built with retpolines and return thunks, the Spectre v2 and Retbleed mitigations of CONFIG_MITIGATION_RETPOLINE and CONFIG_MITIGATION_RETHUNK. Retpolines show as the __x86_indirect_thunk_ functions indirect branches are compiled to, and return thunks as the .return_sites section, where objtool lists the returns the kernel patches at boot."
+}
+
+func (r RetpolineRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.PlatformAllX86,
+		Kinds:    binary.KindKernelImage,
+		LibC:     binary.LibCNone,
+	}
+}
+
+func (r RetpolineRule) Execute(bin elf.Binary) rule.Result {
+	symbols, skip, ok := imageSymbols(bin)
+	if !ok {
+		return skip
+	}
+	if !hasSymbolPrefix(symbols, "__x86_indirect_thunk_") {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "Retpoline not enabled",
+		}
+	}
+	if !hasSection(bin, ".return_sites") {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "Retpoline enabled, return thunks not enabled",
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "Retpoline and return thunks enabled",
+	}
+}
@@ -0,0 +1,55 @@
+package kernel
+
+import (
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+)
+
+// ROAfterInitRuleID is the rule ID for kernel read-only-after-init data.
+const ROAfterInitRuleID = "kernel-ro-after-init"
+
+// ROAfterInitRule checks if a kernel image write-protects its read-only-after-init data.
+//
+// References:
+//   - https://docs.kernel.org/security/self-protection.html#function-pointers-and-sensitive-variables-must-not-be-writable
+type ROAfterInitRule struct{}
+
+func (r ROAfterInitRule) ID() string   { return ROAfterInitRuleID }
+func (r ROAfterInitRule) Name() string { return "Kernel Read-Only After Init" }
+func (r ROAfterInitRule) Description() string {
+	return "Checks if the kernel write-protects the variables marked __ro_after_init, such as function pointer tables set up during boot. The linker gathers them between the __start_ro_after_init and __end_ro_after_init symbols, and with CONFIG_STRICT_KERNEL_RWX mark_rodata_ro maps the range read-only once init finishes. The sections holding them are writable in the file, so their flags don't tell."
+}
+
+func (r ROAfterInitRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.PlatformAll,
+		Kinds:    binary.KindKernelImage,
+		LibC:     binary.LibCNone,
+	}
+}
+
+func (r ROAfterInitRule) Execute(bin elf.Binary) rule.Result {
+	symbols, skip, ok := imageSymbols(bin)
+	if !ok {
+		return skip
+	}
+	start, hasStart := findSymbol(symbols, "__start_ro_after_init")
+	end, hasEnd := findSymbol(symbols, "__end_ro_after_init")
+	if !hasStart || !hasEnd || end.Value <= start.Value {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "No read-only-after-init data",
+		}
+	}
+	if !referencesSymbol(symbols, "mark_rodata_ro") {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "Read-only-after-init data not write-protected, CONFIG_STRICT_KERNEL_RWX not set",
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "Read-only-after-init data write-protected",
+	}
+}
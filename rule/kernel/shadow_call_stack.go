@@ -0,0 +1,48 @@
+package kernel
+
+import (
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+)
+
+// ShadowCallStackRuleID is the rule ID for kernel shadow call stack.
+const ShadowCallStackRuleID = "kernel-shadow-call-stack"
+
+// ShadowCallStackRule checks if a kernel image was built with the shadow call stack.
+//
+// References:
+//   - https://clang.llvm.org/docs/ShadowCallStack.html
+//   - https://gcc.gnu.org/onlinedocs/gcc/Instrumentation-Options.html#index-fsanitize_003dshadow-call-stack
+type ShadowCallStackRule struct{}
+
+func (r ShadowCallStackRule) ID() string   { return ShadowCallStackRuleID }
+func (r ShadowCallStackRule) Name() string { return "Kernel Shadow Call Stack" }
+func (r ShadowCallStackRule) Description() string {
+	return "Checks if the kernel was built with CONFIG_SHADOW_CALL_STACK, which keeps return addresses on a separate stack that stack buffer overflows can't reach. The kernel then allocates a shadow stack for every task with scs_alloc."
+}
+
+func (r ShadowCallStackRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.Platform{Architecture: binary.ArchARM64 | binary.ArchRISCV},
+		Kinds:    binary.KindKernelImage,
+		LibC:     binary.LibCNone,
+	}
+}
+
+func (r ShadowCallStackRule) Execute(bin elf.Binary) rule.Result {
+	symbols, skip, ok := imageSymbols(bin)
+	if !ok {
+		return skip
+	}
+	if !referencesSymbol(symbols, "scs_alloc") {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "Shadow call stack not enabled",
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "Shadow call stack enabled",
+	}
+}
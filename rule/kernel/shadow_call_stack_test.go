@@ -0,0 +1,30 @@
+package kernel
+
+import (
+	"debug/elf"
+	"testing"
+
+	"go.kacmar.sk/crack/rule"
+)
+
+func TestShadowCallStackRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		machine elf.Machine
+		symbols []string
+		want    rule.Status
+	}{
+		{name: "arm64 enabled", machine: elf.EM_AARCH64, symbols: []string{"start_kernel", "scs_alloc"}, want: rule.StatusPassed},
+		{name: "riscv enabled", machine: elf.EM_RISCV, symbols: []string{"start_kernel", "scs_alloc"}, want: rule.StatusPassed},
+		{name: "arm64 disabled", machine: elf.EM_AARCH64, symbols: []string{"start_kernel"}, want: rule.StatusFailed},
+		{name: "arm64 stripped", machine: elf.EM_AARCH64, want: rule.StatusSkipped},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ShadowCallStackRule{}.Execute(buildImage(t, tt.machine, tt.symbols...))
+			if got.Status != tt.want {
+				t.Errorf("Execute() = %+v, want status %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package kernel
+
+import (
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+)
+
+// StackProtectorRuleID is the rule ID for kernel stack protector.
+const StackProtectorRuleID = "kernel-stack-protector"
+
+// StackProtectorRule checks if a kernel image was built with the stack protector.
+//
+// References:
+//   - https://docs.kernel.org/security/self-protection.html#stack-buffer-overflow
+//   - https://gcc.gnu.org/onlinedocs/gcc/Instrumentation-Options.html#index-fstack-protector
+type StackProtectorRule struct{}
+
+func (r StackProtectorRule) ID() string   { return StackProtectorRuleID }
+func (r StackProtectorRule) Name() string { return "Kernel Stack Protector" }
+func (r StackProtectorRule) Description() string {
+	return "Checks if the kernel was built with CONFIG_STACKPROTECTOR, which places a canary between local buffers and the return address and panics when an overflow overwrites it. The kernel defines the __stack_chk_fail handler only when the option is set."
+}
+
+func (r StackProtectorRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.PlatformAll,
+		Kinds:    binary.KindKernelImage,
+		LibC:     binary.LibCNone,
+	}
+}
+
+func (r StackProtectorRule) Execute(bin elf.Binary) rule.Result {
+	symbols, skip, ok := imageSymbols(bin)
+	if !ok {
+		return skip
+	}
+	if !referencesSymbol(symbols, "__stack_chk_fail") {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "Stack protector not enabled",
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "Stack protector enabled",
+	}
+}
@@ -0,0 +1,55 @@
+package kernel
+
+import (
+	stdelf "debug/elf"
+	"slices"
+	"strings"
+
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+)
+
+// referencesSymbol reports whether any of names appears in symbols, whether defined or referenced.
+func referencesSymbol(symbols []stdelf.Symbol, names ...string) bool {
+	return slices.ContainsFunc(symbols, func(sym stdelf.Symbol) bool {
+		return slices.Contains(names, sym.Name)
+	})
+}
+
+// hasSymbolPrefix reports whether any symbol name starts with prefix.
+func hasSymbolPrefix(symbols []stdelf.Symbol, prefix string) bool {
+	return slices.ContainsFunc(symbols, func(sym stdelf.Symbol) bool {
+		return strings.HasPrefix(sym.Name, prefix)
+	})
+}
+
+// findSymbol returns the symbol of the given name.
+func findSymbol(symbols []stdelf.Symbol, name string) (stdelf.Symbol, bool) {
+	i := slices.IndexFunc(symbols, func(sym stdelf.Symbol) bool { return sym.Name == name })
+	if i < 0 {
+		return stdelf.Symbol{}, false
+	}
+	return symbols[i], true
+}
+
+// hasSection reports whether the binary has the named section.
+func hasSection(bin elf.Binary, name string) bool {
+	_, err := elf.FindSection(bin, name)
+	return err == nil
+}
+
+// imageSymbols returns the symbol table of a kernel image. When it can't be read, ok is false and skip is the result to report.
+// Kernel features leave no trace in the dynamic symbols, so a stripped image can't be checked.
+func imageSymbols(bin elf.Binary) (symbols []stdelf.Symbol, skip rule.Result, ok bool) {
+	symbols, err := bin.Symbols()
+	if err != nil {
+		return nil, rule.Skip("symbols unavailable", err), false
+	}
+	if len(symbols) == 0 {
+		return nil, rule.Result{
+			Status:  rule.StatusSkipped,
+			Message: "Static symbols (.symtab) unavailable, kernel image stripped",
+		}, false
+	}
+	return symbols, rule.Result{}, true
+}
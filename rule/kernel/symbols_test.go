@@ -0,0 +1,96 @@
+package kernel
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"testing"
+
+	crackelf "go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+)
+
+// buildImage returns a little-endian 64-bit executable for machine whose .symtab defines symbols in .text.
+// With no symbols, the file has no .symtab, like a stripped kernel image.
+func buildImage(t *testing.T, machine elf.Machine, symbols ...string) *crackelf.File {
+	t.Helper()
+
+	const (
+		headerSize        = 64
+		sectionHeaderSize = 64
+		symbolSize        = 24
+	)
+	shstrtab := []byte("\x00.text\x00.shstrtab\x00.symtab\x00.strtab\x00")
+	strtab := []byte{0}
+	syms := []elf.Sym64{{}}
+	for _, name := range symbols {
+		syms = append(syms, elf.Sym64{
+			Name:  uint32(len(strtab)),
+			Info:  elf.ST_INFO(elf.STB_GLOBAL, elf.STT_FUNC),
+			Shndx: 1,
+		})
+		strtab = append(append(strtab, name...), 0)
+	}
+
+	shstrtabOff := uint64(headerSize)
+	sections := []elf.Section64{
+		{},
+		{Name: 1, Type: uint32(elf.SHT_PROGBITS), Flags: uint64(elf.SHF_ALLOC | elf.SHF_EXECINSTR), Off: headerSize, Addralign: 16},
+		{Name: 7, Type: uint32(elf.SHT_STRTAB), Off: shstrtabOff, Size: uint64(len(shstrtab)), Addralign: 1},
+	}
+	contents := []any{shstrtab}
+	off := shstrtabOff + uint64(len(shstrtab))
+	if len(symbols) > 0 {
+		sections = append(sections,
+			elf.Section64{Name: 17, Type: uint32(elf.SHT_SYMTAB), Off: off, Size: uint64(len(syms) * symbolSize), Link: 4, Info: 1, Addralign: 8, Entsize: symbolSize},
+			elf.Section64{Name: 25, Type: uint32(elf.SHT_STRTAB), Off: off + uint64(len(syms)*symbolSize), Size: uint64(len(strtab)), Addralign: 1},
+		)
+		contents = append(contents, syms, strtab)
+		off += uint64(len(syms)*symbolSize + len(strtab))
+	}
+
+	header := elf.Header64{
+		Ident:     [16]byte{0x7f, 'E', 'L', 'F', byte(elf.ELFCLASS64), byte(elf.ELFDATA2LSB), byte(elf.EV_CURRENT)},
+		Type:      uint16(elf.ET_EXEC),
+		Machine:   uint16(machine),
+		Version:   uint32(elf.EV_CURRENT),
+		Shoff:     off,
+		Ehsize:    headerSize,
+		Shentsize: sectionHeaderSize,
+		Shnum:     uint16(len(sections)),
+		Shstrndx:  2,
+	}
+
+	var buf bytes.Buffer
+	for _, v := range append(append([]any{&header}, contents...), sections) {
+		if err := binary.Write(&buf, binary.LittleEndian, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := crackelf.Open(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	return f
+}
+
+func TestImageSymbolsStripped(t *testing.T) {
+	tests := []struct {
+		name string
+		rule rule.ELFRule
+	}{
+		{name: "kcfi", rule: KCFIRule{}},
+		{name: "retpoline", rule: RetpolineRule{}},
+		{name: "ro after init", rule: ROAfterInitRule{}},
+		{name: "shadow call stack", rule: ShadowCallStackRule{}},
+		{name: "stack protector", rule: StackProtectorRule{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.rule.Execute(buildImage(t, elf.EM_X86_64))
+			if got.Status != rule.StatusSkipped {
+				t.Errorf("Execute() = %+v, want skipped for a stripped image", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package macho
+
+import (
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/macho"
+	"go.kacmar.sk/crack/rule"
+)
+
+// CodeSignatureRuleID is the rule ID for code signature presence.
+const CodeSignatureRuleID = "macho-code-signature"
+
+// CodeSignatureRule checks if the binary carries an embedded code signature.
+//
+// References:
+//   - https://developer.apple.com/documentation/technotes/tn3126-inside-code-signing-hashes
+//   - https://developer.apple.com/documentation/security/code-signing-services
+type CodeSignatureRule struct{}
+
+func (r CodeSignatureRule) ID() string   { return CodeSignatureRuleID }
+func (r CodeSignatureRule) Name() string { return "Code Signature" }
+func (r CodeSignatureRule) Description() string {
+	return "Checks if the binary has an embedded code signature. The kernel verifies the signed page hashes as pages are loaded, killing a process whose code was modified on disk, and macOS on Apple silicon refuses to run unsigned arm64 code. Ad-hoc signatures, which the linker applies by default, pass but don't identify the signer."
+}
+
+func (r CodeSignatureRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.Platform{Architecture: binary.ArchAllX86 | binary.ArchAllARM},
+	}
+}
+
+func (r CodeSignatureRule) Execute(bin macho.Binary) rule.Result {
+	sig, err := bin.CodeSignature()
+	if err != nil {
+		return rule.Skip("failed to read code signature", err)
+	}
+	switch {
+	case sig == nil:
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "No code signature",
+		}
+	case sig.Flags&macho.CodeSignatureAdhoc != 0:
+		return rule.Result{
+			Status:  rule.StatusPassed,
+			Message: "Ad-hoc code signature present",
+		}
+	default:
+		return rule.Result{
+			Status:  rule.StatusPassed,
+			Message: "Code signature present",
+		}
+	}
+}
@@ -0,0 +1,2 @@
+// Package macho provides built-in Mach-O security hardening rules for macOS and iOS binaries.
+package macho
@@ -0,0 +1,53 @@
+package macho
+
+import (
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/macho"
+	"go.kacmar.sk/crack/rule"
+)
+
+// HardenedRuntimeRuleID is the rule ID for the hardened runtime.
+const HardenedRuntimeRuleID = "macho-hardened-runtime"
+
+// HardenedRuntimeRule checks if the executable is signed with the hardened runtime enabled.
+//
+// References:
+//   - https://developer.apple.com/documentation/security/hardened-runtime
+type HardenedRuntimeRule struct{}
+
+func (r HardenedRuntimeRule) ID() string   { return HardenedRuntimeRuleID }
+func (r HardenedRuntimeRule) Name() string { return "Hardened Runtime" }
+func (r HardenedRuntimeRule) Description() string {
+	return "Checks if the executable's code signature has the runtime flag set, as codesign --options runtime does. The hardened runtime blocks unsigned executable memory, DYLD_* environment variables, debugger attachment and loading libraries signed by other teams, unless the executable claims entitlements to allow them. Notarization requires it."
+}
+
+func (r HardenedRuntimeRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.Platform{Architecture: binary.ArchAllX86 | binary.ArchAllARM},
+		Kinds:    binary.KindExecutable,
+	}
+}
+
+func (r HardenedRuntimeRule) Execute(bin macho.Binary) rule.Result {
+	sig, err := bin.CodeSignature()
+	if err != nil {
+		return rule.Skip("failed to read code signature", err)
+	}
+	switch {
+	case sig == nil:
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "No code signature, hardened runtime not enabled",
+		}
+	case sig.Flags&macho.CodeSignatureRuntime == 0:
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "Hardened runtime not enabled",
+		}
+	default:
+		return rule.Result{
+			Status:  rule.StatusPassed,
+			Message: "Hardened runtime enabled",
+		}
+	}
+}
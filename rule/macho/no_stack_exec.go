@@ -0,0 +1,45 @@
+package macho
+
+import (
+	stdmacho "debug/macho"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/macho"
+	"go.kacmar.sk/crack/rule"
+)
+
+// NoStackExecRuleID is the rule ID for MH_ALLOW_STACK_EXECUTION.
+const NoStackExecRuleID = "macho-no-stack-exec"
+
+// NoStackExecRule checks that an executable doesn't request an executable stack.
+//
+// References:
+//   - https://github.com/apple-oss-distributions/xnu/blob/main/EXTERNAL_HEADERS/mach-o/loader.h
+//   - https://keith.github.io/xcode-man-pages/ld.1.html#allow_stack_execute
+type NoStackExecRule struct{}
+
+func (r NoStackExecRule) ID() string   { return NoStackExecRuleID }
+func (r NoStackExecRule) Name() string { return "Non-Executable Stack (MH_ALLOW_STACK_EXECUTION)" }
+func (r NoStackExecRule) Description() string {
+	return "Checks that the executable doesn't have the MH_ALLOW_STACK_EXECUTION flag set, which the linker sets for -allow_stack_execute. The kernel then maps the stacks of every thread of the process executable, letting an attacker who overflows a stack buffer run injected code directly."
+}
+
+func (r NoStackExecRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.Platform{Architecture: binary.ArchAllX86 | binary.ArchAllARM},
+		Kinds:    binary.KindExecutable,
+	}
+}
+
+func (r NoStackExecRule) Execute(bin macho.Binary) rule.Result {
+	if bin.Flags()&stdmacho.FlagAllowStackExecution != 0 {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "MH_ALLOW_STACK_EXECUTION set, stack is executable",
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "MH_ALLOW_STACK_EXECUTION not set",
+	}
+}
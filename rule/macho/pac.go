@@ -0,0 +1,49 @@
+package macho
+
+import (
+	stdmacho "debug/macho"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/macho"
+	"go.kacmar.sk/crack/rule"
+	"go.kacmar.sk/crack/toolchain"
+)
+
+// PACRuleID is the rule ID for arm64e pointer authentication.
+const PACRuleID = "macho-pac"
+
+// PACRule checks if an arm64 binary is built for the arm64e ABI, which signs code pointers with pointer authentication.
+//
+// References:
+//   - https://developer.apple.com/documentation/security/preparing-your-app-to-work-with-pointer-authentication
+//   - https://clang.llvm.org/docs/PointerAuthentication.html
+type PACRule struct{}
+
+func (r PACRule) ID() string   { return PACRuleID }
+func (r PACRule) Name() string { return "ARM Pointer Authentication (arm64e)" }
+func (r PACRule) Description() string {
+	return "Checks if the arm64 binary is built for the arm64e architecture. The arm64e ABI signs return addresses, function pointers and vtable pointers with ARMv8.3 pointer authentication codes (PAC), so an overwritten pointer fails authentication instead of redirecting control flow."
+}
+
+func (r PACRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.Platform{Architecture: binary.ArchARM64},
+		Kinds:    binary.KindAll,
+		Compilers: map[toolchain.Compiler]rule.CompilerRequirement{
+			toolchain.Clang: {MinVersion: toolchain.Version{Major: 11, Minor: 0}, Flag: "-arch arm64e"},
+		},
+	}
+}
+
+func (r PACRule) Execute(bin macho.Binary) rule.Result {
+	if bin.CPU() != stdmacho.CpuArm64 || bin.SubCPU() != macho.CPUSubtypeARM64E {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "Built for arm64, pointer authentication not enabled",
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "Built for arm64e",
+	}
+}
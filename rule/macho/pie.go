@@ -0,0 +1,49 @@
+package macho
+
+import (
+	stdmacho "debug/macho"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/macho"
+	"go.kacmar.sk/crack/rule"
+	"go.kacmar.sk/crack/toolchain"
+)
+
+// PIERuleID is the rule ID for MH_PIE.
+const PIERuleID = "macho-pie"
+
+// PIERule checks if an executable can be loaded at a random address.
+//
+// References:
+//   - https://github.com/apple-oss-distributions/xnu/blob/main/EXTERNAL_HEADERS/mach-o/loader.h
+//   - https://developer.apple.com/library/archive/qa/qa1788/_index.html
+type PIERule struct{}
+
+func (r PIERule) ID() string   { return PIERuleID }
+func (r PIERule) Name() string { return "Position Independent Executable (MH_PIE)" }
+func (r PIERule) Description() string {
+	return "Checks if the executable has the MH_PIE flag set, letting the kernel load it at a random address. Without it, the main executable stays at a fixed address even though ASLR randomizes the libraries, giving return-oriented programming (ROP) attacks a known set of gadgets."
+}
+
+func (r PIERule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.Platform{Architecture: binary.ArchAllX86 | binary.ArchAllARM},
+		Kinds:    binary.KindExecutable,
+		Compilers: map[toolchain.Compiler]rule.CompilerRequirement{
+			toolchain.Clang: {MinVersion: toolchain.Version{Major: 3, Minor: 0}, Flag: "-Wl,-pie"},
+		},
+	}
+}
+
+func (r PIERule) Execute(bin macho.Binary) rule.Result {
+	if bin.Flags()&stdmacho.FlagPIE == 0 {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "MH_PIE not set",
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "MH_PIE set",
+	}
+}
@@ -0,0 +1,46 @@
+package macho
+
+import (
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/macho"
+	"go.kacmar.sk/crack/rule"
+	"go.kacmar.sk/crack/toolchain"
+)
+
+// RestrictRuleID is the rule ID for the __RESTRICT segment.
+const RestrictRuleID = "macho-restrict"
+
+// RestrictRule checks if an executable opts out of dyld environment variables with a __RESTRICT segment.
+//
+// References:
+//   - https://github.com/apple-oss-distributions/dyld/blob/main/dyld/DyldProcessConfig.cpp
+type RestrictRule struct{}
+
+func (r RestrictRule) ID() string   { return RestrictRuleID }
+func (r RestrictRule) Name() string { return "Restricted Segment (__RESTRICT)" }
+func (r RestrictRule) Description() string {
+	return "Checks if the executable has a __restrict section in a __RESTRICT segment, which makes dyld ignore DYLD_* environment variables such as DYLD_INSERT_LIBRARIES. Without it, or the hardened runtime, anyone able to set the environment of the process can inject a library into it."
+}
+
+func (r RestrictRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.Platform{Architecture: binary.ArchAllX86 | binary.ArchAllARM},
+		Kinds:    binary.KindExecutable,
+		Compilers: map[toolchain.Compiler]rule.CompilerRequirement{
+			toolchain.Clang: {MinVersion: toolchain.Version{Major: 3, Minor: 0}, Flag: "-Wl,-sectcreate,__RESTRICT,__restrict,/dev/null"},
+		},
+	}
+}
+
+func (r RestrictRule) Execute(bin macho.Binary) rule.Result {
+	if _, err := macho.FindSection(bin, "__RESTRICT", "__restrict"); err != nil {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "No __RESTRICT,__restrict section, dyld environment variables honored",
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "__RESTRICT,__restrict section present",
+	}
+}
@@ -0,0 +1,65 @@
+package macho
+
+import (
+	"slices"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/macho"
+	"go.kacmar.sk/crack/rule"
+	"go.kacmar.sk/crack/toolchain"
+)
+
+// StackCanaryRuleID is the rule ID for stack canaries.
+const StackCanaryRuleID = "macho-stack-canary"
+
+// stackProtectorSymbols are referenced by code built with -fstack-protector. Mach-O symbol names carry a leading underscore.
+var stackProtectorSymbols = []string{"___stack_chk_fail", "___stack_chk_guard"}
+
+// StackCanaryRule checks for stack buffer overflow protection.
+//
+// References:
+//   - https://clang.llvm.org/docs/ClangCommandLineReference.html#cmdoption-clang-fstack-protector
+type StackCanaryRule struct{}
+
+func (r StackCanaryRule) ID() string   { return StackCanaryRuleID }
+func (r StackCanaryRule) Name() string { return "Stack Canary" }
+func (r StackCanaryRule) Description() string {
+	return "Checks if the binary references ___stack_chk_fail or ___stack_chk_guard, which code built with -fstack-protector uses to verify a canary placed between local buffers and the return address. A stack buffer overflow overwriting the return address also overwrites the canary, which is detected before the function returns."
+}
+
+func (r StackCanaryRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.Platform{Architecture: binary.ArchAllX86 | binary.ArchAllARM},
+		Kinds:    binary.KindAll,
+		Compilers: map[toolchain.Compiler]rule.CompilerRequirement{
+			toolchain.Clang: {MinVersion: toolchain.Version{Major: 3, Minor: 5}, Flag: "-fstack-protector-strong"},
+		},
+	}
+}
+
+func (r StackCanaryRule) Execute(bin macho.Binary) rule.Result {
+	imports, err := bin.ImportedSymbols()
+	if err != nil {
+		return rule.Skip("failed to read imports", err)
+	}
+	for _, name := range imports {
+		if slices.Contains(stackProtectorSymbols, name) {
+			return rule.Result{
+				Status:  rule.StatusPassed,
+				Message: "Stack canary found",
+			}
+		}
+	}
+	for _, sym := range bin.Symbols() {
+		if slices.Contains(stackProtectorSymbols, sym.Name) {
+			return rule.Result{
+				Status:  rule.StatusPassed,
+				Message: "Stack canary found",
+			}
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusFailed,
+		Message: "No stack canary, ___stack_chk_fail not referenced",
+	}
+}
@@ -0,0 +1,49 @@
+package pe
+
+import (
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/pe"
+	"go.kacmar.sk/crack/rule"
+	"go.kacmar.sk/crack/toolchain"
+)
+
+// CETCompatRuleID is the rule ID for CET shadow stack compatibility.
+const CETCompatRuleID = "pe-cet-compat"
+
+// CETCompatRule checks if the image is marked compatible with CET shadow stacks.
+//
+// References:
+//   - https://learn.microsoft.com/en-us/cpp/build/reference/cetcompat
+type CETCompatRule struct{}
+
+func (r CETCompatRule) ID() string   { return CETCompatRuleID }
+func (r CETCompatRule) Name() string { return "CET Shadow Stack Compatibility" }
+func (r CETCompatRule) Description() string {
+	return "Checks if the image is marked CET_COMPAT in its extended DLL characteristics. Windows only enforces hardware-enforced stack protection, which keeps a shadow copy of return addresses on Intel CET and AMD shadow stack capable processors, for processes whose images declare themselves compatible."
+}
+
+func (r CETCompatRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.PlatformAllX86,
+		Compilers: map[toolchain.Compiler]rule.CompilerRequirement{
+			toolchain.Clang: {MinVersion: toolchain.Version{Major: 11, Minor: 0}, Flag: "/link /CETCOMPAT"},
+		},
+	}
+}
+
+func (r CETCompatRule) Execute(bin pe.Binary) rule.Result {
+	flags, err := bin.ExDllCharacteristics()
+	if err != nil {
+		return rule.Skip("failed to read debug directory", err)
+	}
+	if flags&pe.DllCharacteristicsExCETCompat == 0 {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "CET_COMPAT not set",
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "CET_COMPAT set",
+	}
+}
@@ -0,0 +1,59 @@
+package pe
+
+import (
+	stdpe "debug/pe"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/pe"
+	"go.kacmar.sk/crack/rule"
+	"go.kacmar.sk/crack/toolchain"
+)
+
+// CFGRuleID is the rule ID for Control Flow Guard.
+const CFGRuleID = "pe-cfg"
+
+// CFGRule checks for Control Flow Guard instrumentation.
+//
+// References:
+//   - https://learn.microsoft.com/en-us/windows/win32/secbp/control-flow-guard
+//   - https://clang.llvm.org/docs/ClangCommandLineReference.html#cmdoption-clang-mguard
+type CFGRule struct{}
+
+func (r CFGRule) ID() string   { return CFGRuleID }
+func (r CFGRule) Name() string { return "Control Flow Guard" }
+func (r CFGRule) Description() string {
+	return "Checks if the image is built with Control Flow Guard (CFG). CFG validates the target of every indirect call against a table of valid functions, preventing attackers from redirecting calls through corrupted function pointers or vtables."
+}
+
+func (r CFGRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.Platform{Architecture: binary.ArchAllX86 | binary.ArchAllARM},
+		Compilers: map[toolchain.Compiler]rule.CompilerRequirement{
+			toolchain.Clang: {MinVersion: toolchain.Version{Major: 10, Minor: 0}, Flag: "/guard:cf"},
+		},
+	}
+}
+
+func (r CFGRule) Execute(bin pe.Binary) rule.Result {
+	if bin.DllCharacteristics()&stdpe.IMAGE_DLLCHARACTERISTICS_GUARD_CF == 0 {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "CFG not enabled",
+		}
+	}
+
+	lc, err := bin.LoadConfig()
+	if err != nil {
+		return rule.Skip("failed to read load configuration", err)
+	}
+	if lc == nil || lc.GuardFlags&pe.GuardCFInstrumented == 0 {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "CFG not enabled, GUARD_CF set without instrumented code",
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "CFG enabled",
+	}
+}
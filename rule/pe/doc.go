@@ -0,0 +1,2 @@
+// Package pe provides built-in Windows PE security hardening rules.
+package pe
@@ -0,0 +1,49 @@
+package pe
+
+import (
+	stdpe "debug/pe"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/pe"
+	"go.kacmar.sk/crack/rule"
+	"go.kacmar.sk/crack/toolchain"
+)
+
+// DynamicBaseRuleID is the rule ID for DYNAMICBASE.
+const DynamicBaseRuleID = "pe-dynamic-base"
+
+// DynamicBaseRule checks if the image can be relocated at load time.
+//
+// References:
+//   - https://learn.microsoft.com/en-us/cpp/build/reference/dynamicbase-use-address-space-layout-randomization
+//   - https://sourceware.org/binutils/docs/ld/Options.html#index-_002d_002ddynamicbase
+type DynamicBaseRule struct{}
+
+func (r DynamicBaseRule) ID() string   { return DynamicBaseRuleID }
+func (r DynamicBaseRule) Name() string { return "ASLR (DYNAMICBASE)" }
+func (r DynamicBaseRule) Description() string {
+	return "Checks if the image has the DYNAMICBASE flag set, allowing Windows to load it at a random base address. Without it, ASLR leaves the image at its preferred address, where its code is available at a known location for return-oriented programming (ROP) attacks."
+}
+
+func (r DynamicBaseRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.Platform{Architecture: binary.ArchAllX86 | binary.ArchAllARM},
+		Compilers: map[toolchain.Compiler]rule.CompilerRequirement{
+			toolchain.GCC:   {MinVersion: toolchain.Version{Major: 4, Minor: 5}, DefaultVersion: toolchain.Version{Major: 11, Minor: 1}, Flag: "-Wl,--dynamicbase"},
+			toolchain.Clang: {MinVersion: toolchain.Version{Major: 5, Minor: 0}, DefaultVersion: toolchain.Version{Major: 5, Minor: 0}, Flag: "/link /DYNAMICBASE"},
+		},
+	}
+}
+
+func (r DynamicBaseRule) Execute(bin pe.Binary) rule.Result {
+	if bin.DllCharacteristics()&stdpe.IMAGE_DLLCHARACTERISTICS_DYNAMIC_BASE == 0 {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "DYNAMICBASE not set",
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "DYNAMICBASE set",
+	}
+}
@@ -0,0 +1,49 @@
+package pe
+
+import (
+	stdpe "debug/pe"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/pe"
+	"go.kacmar.sk/crack/rule"
+	"go.kacmar.sk/crack/toolchain"
+)
+
+// ForceIntegrityRuleID is the rule ID for FORCE_INTEGRITY.
+const ForceIntegrityRuleID = "pe-force-integrity"
+
+// ForceIntegrityRule checks if the image requires a valid signature to load.
+//
+// References:
+//   - https://learn.microsoft.com/en-us/cpp/build/reference/integritycheck-require-signature-check
+//   - https://sourceware.org/binutils/docs/ld/Options.html#index-_002d_002dforceinteg
+type ForceIntegrityRule struct{}
+
+func (r ForceIntegrityRule) ID() string   { return ForceIntegrityRuleID }
+func (r ForceIntegrityRule) Name() string { return "Signature Check (FORCE_INTEGRITY)" }
+func (r ForceIntegrityRule) Description() string {
+	return "Checks if the image has the FORCE_INTEGRITY flag set, making Windows refuse to load it unless its digital signature is valid. This prevents a tampered copy of the image from running, but requires the image to be signed."
+}
+
+func (r ForceIntegrityRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.Platform{Architecture: binary.ArchAllX86 | binary.ArchAllARM},
+		Compilers: map[toolchain.Compiler]rule.CompilerRequirement{
+			toolchain.GCC:   {MinVersion: toolchain.Version{Major: 4, Minor: 5}, Flag: "-Wl,--forceinteg"},
+			toolchain.Clang: {MinVersion: toolchain.Version{Major: 5, Minor: 0}, Flag: "/link /INTEGRITYCHECK"},
+		},
+	}
+}
+
+func (r ForceIntegrityRule) Execute(bin pe.Binary) rule.Result {
+	if bin.DllCharacteristics()&stdpe.IMAGE_DLLCHARACTERISTICS_FORCE_INTEGRITY == 0 {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "FORCE_INTEGRITY not set",
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "FORCE_INTEGRITY set",
+	}
+}
@@ -0,0 +1,80 @@
+package pe
+
+import (
+	"slices"
+	"strings"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/pe"
+	"go.kacmar.sk/crack/rule"
+	"go.kacmar.sk/crack/toolchain"
+)
+
+// GSRuleID is the rule ID for /GS stack cookies.
+const GSRuleID = "pe-gs"
+
+// stackProtectorSymbols are referenced by MinGW images built with -fstack-protector, which use libssp rather than /GS cookies.
+var stackProtectorSymbols = []string{"__stack_chk_fail", "__stack_chk_guard"}
+
+// GSRule checks for stack buffer overrun detection.
+//
+// References:
+//   - https://learn.microsoft.com/en-us/cpp/build/reference/gs-buffer-security-check
+//   - https://gcc.gnu.org/onlinedocs/gcc/Instrumentation-Options.html#index-fstack-protector
+type GSRule struct{}
+
+func (r GSRule) ID() string   { return GSRuleID }
+func (r GSRule) Name() string { return "Stack Cookies (/GS)" }
+func (r GSRule) Description() string {
+	return "Checks if the image is built with stack buffer overrun detection: /GS security cookies (__security_cookie) registered in the load configuration, or -fstack-protector in MinGW builds. A cookie placed between local buffers and the return address is verified on function return, terminating the process when an overflow has overwritten it."
+}
+
+func (r GSRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.Platform{Architecture: binary.ArchAllX86 | binary.ArchAllARM},
+		Compilers: map[toolchain.Compiler]rule.CompilerRequirement{
+			toolchain.GCC:   {MinVersion: toolchain.Version{Major: 4, Minor: 9}, Flag: "-fstack-protector-strong"},
+			toolchain.Clang: {MinVersion: toolchain.Version{Major: 5, Minor: 0}, DefaultVersion: toolchain.Version{Major: 5, Minor: 0}, Flag: "/GS"},
+		},
+	}
+}
+
+func (r GSRule) Execute(bin pe.Binary) rule.Result {
+	lc, err := bin.LoadConfig()
+	if err != nil {
+		return rule.Skip("failed to read load configuration", err)
+	}
+	if lc != nil && lc.SecurityCookie != 0 {
+		return rule.Result{
+			Status:  rule.StatusPassed,
+			Message: "/GS security cookie registered",
+		}
+	}
+
+	imports, err := bin.ImportedSymbols()
+	if err != nil {
+		return rule.Skip("failed to read imports", err)
+	}
+	for _, imp := range imports {
+		name, _, _ := strings.Cut(imp, ":")
+		if slices.Contains(stackProtectorSymbols, name) {
+			return rule.Result{
+				Status:  rule.StatusPassed,
+				Message: "Stack protector enabled",
+			}
+		}
+	}
+	for _, sym := range bin.Symbols() {
+		if slices.Contains(stackProtectorSymbols, sym.Name) {
+			return rule.Result{
+				Status:  rule.StatusPassed,
+				Message: "Stack protector enabled",
+			}
+		}
+	}
+
+	return rule.Result{
+		Status:  rule.StatusFailed,
+		Message: "No stack cookies, /GS security cookie not registered",
+	}
+}
@@ -0,0 +1,55 @@
+package pe
+
+import (
+	stdpe "debug/pe"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/pe"
+	"go.kacmar.sk/crack/rule"
+	"go.kacmar.sk/crack/toolchain"
+)
+
+// HighEntropyVARuleID is the rule ID for HIGH_ENTROPY_VA.
+const HighEntropyVARuleID = "pe-high-entropy-va"
+
+// HighEntropyVARule checks if a 64-bit image supports high-entropy ASLR.
+//
+// References:
+//   - https://learn.microsoft.com/en-us/cpp/build/reference/highentropyva-support-64-bit-aslr
+//   - https://sourceware.org/binutils/docs/ld/Options.html#index-_002d_002dhigh_002dentropy_002dva
+type HighEntropyVARule struct{}
+
+func (r HighEntropyVARule) ID() string   { return HighEntropyVARuleID }
+func (r HighEntropyVARule) Name() string { return "High Entropy ASLR" }
+func (r HighEntropyVARule) Description() string {
+	return "Checks if a 64-bit image has the HIGH_ENTROPY_VA flag set, allowing Windows to pick its base address from the full 64-bit address space. Without it, ASLR randomizes the image within the low 4 GiB, leaving few enough possible addresses to brute-force."
+}
+
+func (r HighEntropyVARule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.Platform{Architecture: binary.ArchAMD64 | binary.ArchARM64},
+		Compilers: map[toolchain.Compiler]rule.CompilerRequirement{
+			toolchain.GCC:   {MinVersion: toolchain.Version{Major: 4, Minor: 5}, DefaultVersion: toolchain.Version{Major: 11, Minor: 1}, Flag: "-Wl,--high-entropy-va"},
+			toolchain.Clang: {MinVersion: toolchain.Version{Major: 5, Minor: 0}, DefaultVersion: toolchain.Version{Major: 5, Minor: 0}, Flag: "/link /HIGHENTROPYVA"},
+		},
+	}
+}
+
+func (r HighEntropyVARule) Execute(bin pe.Binary) rule.Result {
+	if bin.DllCharacteristics()&stdpe.IMAGE_DLLCHARACTERISTICS_HIGH_ENTROPY_VA == 0 {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "HIGH_ENTROPY_VA not set",
+		}
+	}
+	if bin.DllCharacteristics()&stdpe.IMAGE_DLLCHARACTERISTICS_DYNAMIC_BASE == 0 {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "HIGH_ENTROPY_VA set but ineffective without DYNAMICBASE",
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "HIGH_ENTROPY_VA set",
+	}
+}
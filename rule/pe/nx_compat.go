@@ -0,0 +1,49 @@
+package pe
+
+import (
+	stdpe "debug/pe"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/pe"
+	"go.kacmar.sk/crack/rule"
+	"go.kacmar.sk/crack/toolchain"
+)
+
+// NXCompatRuleID is the rule ID for NX_COMPAT.
+const NXCompatRuleID = "pe-nx-compat"
+
+// NXCompatRule checks if the image is compatible with Data Execution Prevention.
+//
+// References:
+//   - https://learn.microsoft.com/en-us/cpp/build/reference/nxcompat-compatible-with-data-execution-prevention
+//   - https://sourceware.org/binutils/docs/ld/Options.html#index-_002d_002dnxcompat
+type NXCompatRule struct{}
+
+func (r NXCompatRule) ID() string   { return NXCompatRuleID }
+func (r NXCompatRule) Name() string { return "Data Execution Prevention (NX_COMPAT)" }
+func (r NXCompatRule) Description() string {
+	return "Checks if the image has the NX_COMPAT flag set, declaring it compatible with Data Execution Prevention (DEP). Without it, Windows may run the process with an executable stack and heap, letting attackers run code injected into data memory."
+}
+
+func (r NXCompatRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.Platform{Architecture: binary.ArchAllX86 | binary.ArchAllARM},
+		Compilers: map[toolchain.Compiler]rule.CompilerRequirement{
+			toolchain.GCC:   {MinVersion: toolchain.Version{Major: 4, Minor: 5}, DefaultVersion: toolchain.Version{Major: 11, Minor: 1}, Flag: "-Wl,--nxcompat"},
+			toolchain.Clang: {MinVersion: toolchain.Version{Major: 5, Minor: 0}, DefaultVersion: toolchain.Version{Major: 5, Minor: 0}, Flag: "/link /NXCOMPAT"},
+		},
+	}
+}
+
+func (r NXCompatRule) Execute(bin pe.Binary) rule.Result {
+	if bin.DllCharacteristics()&stdpe.IMAGE_DLLCHARACTERISTICS_NX_COMPAT == 0 {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "NX_COMPAT not set",
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "NX_COMPAT set",
+	}
+}
@@ -0,0 +1,58 @@
+package pe
+
+import (
+	stdpe "debug/pe"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/pe"
+	"go.kacmar.sk/crack/rule"
+	"go.kacmar.sk/crack/toolchain"
+)
+
+// SafeSEHRuleID is the rule ID for SafeSEH.
+const SafeSEHRuleID = "pe-safeseh"
+
+// SafeSEHRule checks that a 32-bit x86 image registers its exception handlers.
+//
+// References:
+//   - https://learn.microsoft.com/en-us/cpp/build/reference/safeseh-image-has-safe-exception-handlers
+type SafeSEHRule struct{}
+
+func (r SafeSEHRule) ID() string   { return SafeSEHRuleID }
+func (r SafeSEHRule) Name() string { return "Safe Exception Handlers (SafeSEH)" }
+func (r SafeSEHRule) Description() string {
+	return "Checks if a 32-bit x86 image lists its exception handlers in a SafeSEH table, or declares that it has none. Windows then only dispatches exceptions to registered handlers, defeating exploits that overwrite an exception registration record on the stack. 64-bit images keep their handlers in tables by design."
+}
+
+func (r SafeSEHRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.Platform{Architecture: binary.ArchX86},
+		Compilers: map[toolchain.Compiler]rule.CompilerRequirement{
+			toolchain.Clang: {MinVersion: toolchain.Version{Major: 5, Minor: 0}, Flag: "/link /SAFESEH"},
+		},
+	}
+}
+
+func (r SafeSEHRule) Execute(bin pe.Binary) rule.Result {
+	if bin.DllCharacteristics()&stdpe.IMAGE_DLLCHARACTERISTICS_NO_SEH != 0 {
+		return rule.Result{
+			Status:  rule.StatusPassed,
+			Message: "No exception handlers, NO_SEH set",
+		}
+	}
+
+	lc, err := bin.LoadConfig()
+	if err != nil {
+		return rule.Skip("failed to read load configuration", err)
+	}
+	if lc == nil || lc.SEHandlerTable == 0 {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "SafeSEH not enabled, no safe exception handler table",
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "SafeSEH enabled",
+	}
+}
@@ -3,6 +3,11 @@ package registry
 import (
 	"go.kacmar.sk/crack/rule"
 	"go.kacmar.sk/crack/rule/elf"
+	"go.kacmar.sk/crack/rule/golang"
+	"go.kacmar.sk/crack/rule/kernel"
+	"go.kacmar.sk/crack/rule/macho"
+	"go.kacmar.sk/crack/rule/pe"
+	"go.kacmar.sk/crack/rule/rust"
 )
 
 var registry = []rule.Rule{
@@ -20,6 +25,9 @@ var registry = []rule.Rule{
 	elf.NoInsecureRPATHRule{},
 	elf.NoInsecureRUNPATHRule{},
 	elf.PIERule{},
+	elf.PrivilegedFullRELRORule{},
+	elf.PrivilegedNoDLOpenRule{},
+	elf.PrivilegedNoRUNPATHRule{},
 	elf.RELRORule{},
 	elf.SafeStackRule{},
 	elf.SeparateCodeRule{},
@@ -29,6 +37,40 @@ var registry = []rule.Rule{
 	elf.X86CETIBTRule{},
 	elf.X86CETShadowStackRule{},
 	elf.X86RetpolineRule{},
+	golang.CgoHardeningRule{},
+	golang.NoRaceRule{},
+	golang.PIERule{},
+	golang.VersionRule{},
+	kernel.IBTRule{},
+	kernel.KCFIRule{},
+	kernel.ModuleCFIRule{},
+	kernel.ModuleNoWXRule{},
+	kernel.ModuleRetpolineRule{},
+	kernel.ModuleSignatureRule{},
+	kernel.ModuleStackProtectorRule{},
+	kernel.RandstructRule{},
+	kernel.RetpolineRule{},
+	kernel.ROAfterInitRule{},
+	kernel.ShadowCallStackRule{},
+	kernel.StackProtectorRule{},
+	macho.CodeSignatureRule{},
+	macho.HardenedRuntimeRule{},
+	macho.NoStackExecRule{},
+	macho.PACRule{},
+	macho.PIERule{},
+	macho.RestrictRule{},
+	macho.StackCanaryRule{},
+	pe.CETCompatRule{},
+	pe.CFGRule{},
+	pe.DynamicBaseRule{},
+	pe.ForceIntegrityRule{},
+	pe.GSRule{},
+	pe.HighEntropyVARule{},
+	pe.NXCompatRule{},
+	pe.SafeSEHRule{},
+	rust.CHardeningRule{},
+	rust.OverflowChecksRule{},
+	rust.PanicAbortRule{},
 }
 
 // All returns all registered rules.
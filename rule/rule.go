@@ -5,6 +5,8 @@ import (
 
 	"go.kacmar.sk/crack/binary"
 	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/binary/macho"
+	"go.kacmar.sk/crack/binary/pe"
 	"go.kacmar.sk/crack/toolchain"
 )
 
@@ -57,11 +59,25 @@ type CompilerRequirement struct {
 	Flag           string
 }
 
-// Applicability defines which platforms and compilers a rule applies to.
+// Applicability defines which platforms, file kinds and compilers a rule applies to.
 type Applicability struct {
-	Platform  binary.Platform
+	Platform binary.Platform
+	// Kinds lists the kinds of file the rule checks. Zero means executables and shared libraries,
+	// which suits rules that inspect properties set by the linker.
+	Kinds     binary.Kind
 	Compilers map[toolchain.Compiler]CompilerRequirement
 	LibC      binary.LibC
+	// Privileged restricts the rule to binaries that grant privileges when executed: setuid or setgid binaries
+	// and those with file capabilities.
+	Privileged bool
+}
+
+// EffectiveKinds returns the file kinds the rule checks, applying the default for an unset Kinds.
+func (app Applicability) EffectiveKinds() binary.Kind {
+	if app.Kinds == binary.KindUnknown {
+		return binary.KindLinked
+	}
+	return app.Kinds
 }
 
 // Rule is a check that can be executed against a binary.
@@ -77,3 +93,15 @@ type ELFRule interface {
 	Rule
 	Execute(bin elf.Binary) Result
 }
+
+// PERule is a Rule that operates on Windows PE images.
+type PERule interface {
+	Rule
+	Execute(bin pe.Binary) Result
+}
+
+// MachORule is a Rule that operates on Mach-O binaries, including each slice of a universal binary.
+type MachORule interface {
+	Rule
+	Execute(bin macho.Binary) Result
+}
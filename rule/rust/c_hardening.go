@@ -0,0 +1,130 @@
+package rust
+
+import (
+	stdelf "debug/elf"
+	"slices"
+	"strings"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+	"go.kacmar.sk/crack/toolchain"
+)
+
+// CHardeningRuleID is the rule ID for hardened C code in Rust binaries.
+const CHardeningRuleID = "rust-c-hardening"
+
+// cFortifiableFunctions maps the fortifiable libc functions only C code calls to their fortified variants.
+// The standard library calls memcpy, read, realpath and the like itself, so they don't tell C code apart.
+var cFortifiableFunctions = map[string]string{
+	"fgets":     "__fgets_chk",
+	"fread":     "__fread_chk",
+	"gets":      "__gets_chk",
+	"snprintf":  "__snprintf_chk",
+	"sprintf":   "__sprintf_chk",
+	"stpcpy":    "__stpcpy_chk",
+	"stpncpy":   "__stpncpy_chk",
+	"strcat":    "__strcat_chk",
+	"strcpy":    "__strcpy_chk",
+	"strncat":   "__strncat_chk",
+	"strncpy":   "__strncpy_chk",
+	"vsnprintf": "__vsnprintf_chk",
+	"vsprintf":  "__vsprintf_chk",
+	"wcscat":    "__wcscat_chk",
+	"wcscpy":    "__wcscpy_chk",
+	"wcsncpy":   "__wcsncpy_chk",
+}
+
+// CHardeningRule checks if the C code linked into a Rust binary was compiled with hardening options.
+//
+// References:
+//   - https://docs.rs/cc/latest/cc/#external-configuration-via-environment-variables
+//   - https://gcc.gnu.org/onlinedocs/gcc/Instrumentation-Options.html#index-fstack-protector
+//   - https://www.gnu.org/software/libc/manual/html_node/Source-Fortification.html
+type CHardeningRule struct{}
+
+func (r CHardeningRule) ID() string   { return CHardeningRuleID }
+func (r CHardeningRule) Name() string { return "Hardened C Code in Rust Binaries" }
+func (r CHardeningRule) Description() string {
+	return "Checks if the C and C++ code linked into a Rust binary, usually compiled by the cc crate from a build script, was built with the stack protector and, on glibc, _FORTIFY_SOURCE. Such code gets none of Rust's memory safety, and the cc crate only passes the hardening options of CFLAGS. Rust code references neither __stack_chk_fail nor the fortified libc functions, so they're attributed to the C code, which is recognized by its debug information or the libc functions only C code calls. A statically linked libc defines __stack_chk_fail whether the C code references it or not, so the stack protector is only checked in binaries that import it."
+}
+
+func (r CHardeningRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.Platform{Architecture: binary.ArchAllX86 | binary.ArchAllARM | binary.ArchRISCV},
+		Compilers: map[toolchain.Compiler]rule.CompilerRequirement{
+			toolchain.Rustc: {MinVersion: toolchain.Version{Major: 1, Minor: 0}, Flag: "CFLAGS='-O2 -fstack-protector-strong -D_FORTIFY_SOURCE=2'"},
+		},
+		LibC: binary.LibCAll,
+	}
+}
+
+func (r CHardeningRule) Execute(bin elf.Binary) rule.Result {
+	if _, skip, ok := rustInfo(bin); !ok {
+		return skip
+	}
+	symbols, err := bin.Symbols()
+	if err != nil {
+		return rule.Skip("symbols unavailable", err)
+	}
+	dynSymbols, err := bin.DynSymbols()
+	if err != nil {
+		return rule.Skip("dynamic symbols unavailable", err)
+	}
+
+	// Only a reference to __stack_chk_fail tells of protected C code. A statically linked libc brings its definition along
+	// whether the C code was protected or not, as libc itself may be.
+	var canary, canaryDefined bool
+	names := make(map[string]struct{})
+	for _, sym := range slices.Concat(symbols, dynSymbols) {
+		names[sym.Name] = struct{}{}
+		if strings.Contains(sym.Name, "__stack_chk_fail") {
+			if sym.Section == stdelf.SHN_UNDEF {
+				canary = true
+			} else {
+				canaryDefined = true
+			}
+		}
+	}
+	var fortified, unfortified int
+	for plain, chk := range cFortifiableFunctions {
+		if _, ok := names[chk]; ok {
+			fortified++
+		}
+		if _, ok := names[plain]; ok {
+			unfortified++
+		}
+	}
+
+	if found, _ := elf.HasCCompileUnit(bin); !found && !canary && fortified == 0 && unfortified == 0 {
+		return rule.Result{
+			Status:  rule.StatusSkipped,
+			Message: "No C code detected",
+		}
+	}
+
+	var missing []string
+	if !canary && !canaryDefined {
+		missing = append(missing, "stack protector")
+	}
+	// FORTIFY_SOURCE is a glibc feature, and C code calling no fortifiable function gives no evidence either way.
+	if elf.DetectLibC(bin) == binary.LibCGlibc && fortified == 0 && unfortified > 0 {
+		missing = append(missing, "FORTIFY_SOURCE")
+	}
+	if len(missing) > 0 {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "C code built without " + strings.Join(missing, " and "),
+		}
+	}
+	if !canary {
+		return rule.Result{
+			Status:  rule.StatusSkipped,
+			Message: "Can't tell the stack protector of C code from that of the statically linked libc",
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "C code built with hardening options",
+	}
+}
@@ -0,0 +1,3 @@
+// Package rust provides built-in security hardening rules for Rust binaries, based on what rustc and the Rust standard
+// library leave in them.
+package rust
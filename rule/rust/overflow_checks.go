@@ -0,0 +1,69 @@
+package rust
+
+import (
+	"bytes"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+	"go.kacmar.sk/crack/toolchain"
+)
+
+// OverflowChecksRuleID is the rule ID for Rust integer overflow checks.
+const OverflowChecksRuleID = "rust-overflow-checks"
+
+// overflowMessages are the panic messages of the arithmetic overflow checks rustc inserts with -C overflow-checks.
+// Division and remainder overflow are always checked, so their messages don't tell.
+var overflowMessages = [][]byte{
+	[]byte("attempt to add with overflow"),
+	[]byte("attempt to subtract with overflow"),
+	[]byte("attempt to multiply with overflow"),
+	[]byte("attempt to negate with overflow"),
+	[]byte("attempt to shift left with overflow"),
+	[]byte("attempt to shift right with overflow"),
+}
+
+// OverflowChecksRule checks if a Rust binary was built with integer overflow checks.
+//
+// References:
+//   - https://doc.rust-lang.org/rustc/codegen-options/index.html#overflow-checks
+//   - https://doc.rust-lang.org/cargo/reference/profiles.html#overflow-checks
+type OverflowChecksRule struct{}
+
+func (r OverflowChecksRule) ID() string   { return OverflowChecksRuleID }
+func (r OverflowChecksRule) Name() string { return "Rust Integer Overflow Checks" }
+func (r OverflowChecksRule) Description() string {
+	return "Checks if a Rust binary was built with -C overflow-checks. Release builds silently wrap integer arithmetic by default, and a wrapped length or index passed to unsafe code turns into memory corruption; with overflow checks, the overflow panics instead. The checks are recognized by their panic messages in .rodata, so a binary whose code does no checked arithmetic fails the rule."
+}
+
+func (r OverflowChecksRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.PlatformAll,
+		Compilers: map[toolchain.Compiler]rule.CompilerRequirement{
+			toolchain.Rustc: {MinVersion: toolchain.Version{Major: 1, Minor: 0}, Flag: "-C overflow-checks=on"},
+		},
+		LibC: binary.LibCAll,
+	}
+}
+
+func (r OverflowChecksRule) Execute(bin elf.Binary) rule.Result {
+	if _, skip, ok := rustInfo(bin); !ok {
+		return skip
+	}
+	data, skip, ok := readRodata(bin)
+	if !ok {
+		return skip
+	}
+	for _, msg := range overflowMessages {
+		if bytes.Contains(data, msg) {
+			return rule.Result{
+				Status:  rule.StatusPassed,
+				Message: "Integer overflow checks enabled",
+			}
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusFailed,
+		Message: "Integer overflow checks not enabled",
+	}
+}
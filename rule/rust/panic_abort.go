@@ -0,0 +1,81 @@
+package rust
+
+import (
+	"bytes"
+	"strings"
+
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+	"go.kacmar.sk/crack/toolchain"
+)
+
+// PanicAbortRuleID is the rule ID for Rust binaries that abort on panic.
+const PanicAbortRuleID = "rust-panic-abort"
+
+// unwindMarker is the message of the panic_unwind runtime, which only binaries built with panic=unwind link.
+var unwindMarker = []byte("Rust panics must be rethrown")
+
+// PanicAbortRule checks if a Rust binary was built with panic=abort.
+//
+// References:
+//   - https://doc.rust-lang.org/rustc/codegen-options/index.html#panic
+//   - https://doc.rust-lang.org/cargo/reference/profiles.html#panic
+type PanicAbortRule struct{}
+
+func (r PanicAbortRule) ID() string   { return PanicAbortRuleID }
+func (r PanicAbortRule) Name() string { return "Rust Panic Abort" }
+func (r PanicAbortRule) Description() string {
+	return "Checks if a Rust binary was built with -C panic=abort. By default a panic unwinds the stack, running the destructors of every frame on the way up over state the panic found inconsistent, and the unwinder with its landing pads stays reachable to an attacker. Aborting ends the process at the first panic. Programs that catch panics with catch_unwind, for example to isolate the requests of a server, need unwinding and should not run this rule."
+}
+
+func (r PanicAbortRule) Applicability() rule.Applicability {
+	return rule.Applicability{
+		Platform: binary.PlatformAll,
+		Compilers: map[toolchain.Compiler]rule.CompilerRequirement{
+			toolchain.Rustc: {MinVersion: toolchain.Version{Major: 1, Minor: 10}, Flag: "-C panic=abort"},
+		},
+		LibC: binary.LibCAll,
+	}
+}
+
+func (r PanicAbortRule) Execute(bin elf.Binary) rule.Result {
+	info, skip, ok := rustInfo(bin)
+	if !ok {
+		return skip
+	}
+	// no_std binaries bring their own panic handler and link neither panic runtime.
+	if !info.Std.Linked {
+		return rule.Result{
+			Status:  rule.StatusSkipped,
+			Message: "Rust standard library not linked",
+		}
+	}
+	data, skip, ok := readRodata(bin)
+	if !ok {
+		return skip
+	}
+	unwind := bytes.Contains(data, unwindMarker)
+	if !unwind {
+		symbols, err := bin.Symbols()
+		if err != nil {
+			return rule.Skip("symbols unavailable", err)
+		}
+		for _, sym := range symbols {
+			if strings.Contains(sym.Name, "12panic_unwind") {
+				unwind = true
+				break
+			}
+		}
+	}
+	if unwind {
+		return rule.Result{
+			Status:  rule.StatusFailed,
+			Message: "Panics unwind",
+		}
+	}
+	return rule.Result{
+		Status:  rule.StatusPassed,
+		Message: "Panics abort",
+	}
+}
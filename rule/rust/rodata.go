@@ -0,0 +1,27 @@
+package rust
+
+import (
+	"errors"
+
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+)
+
+// readRodata returns the contents of .rodata, which holds the panic messages and source paths of Rust code.
+// When it can't be read, ok is false and skip is the result to report.
+func readRodata(bin elf.Binary) (data []byte, skip rule.Result, ok bool) {
+	sec, err := elf.FindSection(bin, ".rodata")
+	if errors.Is(err, elf.ErrSectionMissing) {
+		return nil, rule.Result{
+			Status:  rule.StatusSkipped,
+			Message: "No .rodata section",
+		}, false
+	}
+	if err == nil {
+		data, err = sec.Data()
+	}
+	if err != nil {
+		return nil, rule.Skip("failed to read .rodata", err), false
+	}
+	return data, rule.Result{}, true
+}
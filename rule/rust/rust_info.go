@@ -0,0 +1,21 @@
+package rust
+
+import (
+	"go.kacmar.sk/crack/binary"
+	"go.kacmar.sk/crack/binary/elf"
+	"go.kacmar.sk/crack/rule"
+)
+
+// rustInfo returns what the binary reveals about the Rust standard library. Binaries whose compiler isn't detected are
+// checked by every rule, so the Rust rules skip those that show no sign of Rust.
+// When it shows none, ok is false and skip is the result to report.
+func rustInfo(bin elf.Binary) (info *binary.RustInfo, skip rule.Result, ok bool) {
+	info = elf.DetectRustInfo(bin)
+	if info == nil {
+		return nil, rule.Result{
+			Status:  rule.StatusSkipped,
+			Message: "Not a Rust binary",
+		}, false
+	}
+	return info, rule.Result{}, true
+}
@@ -21,6 +21,8 @@ type Expectation string
 
 type TestCase struct {
 	Binary string
+	// Slice names the universal binary slice to check, as lipo names it, or is "" to check the first result.
+	Slice  string
 	Expect Expectation
 }
 
@@ -30,9 +32,14 @@ func RunRuleTests(t *testing.T, rule string, cases []TestCase) {
 	_, thisFile, _, _ := runtime.Caller(0)
 	e2eDir := filepath.Dir(thisFile)
 	rootDir := filepath.Join(e2eDir, "..", "..")
-	binariesDir := filepath.Join(e2eDir, "elf", rule, "binaries")
 	crackBin := filepath.Join(rootDir, "crack")
 
+	// Rules are grouped by binary format, e.g. elf/pie and macho/macho-pie.
+	ruleDirs, _ := filepath.Glob(filepath.Join(e2eDir, "*", rule))
+	if len(ruleDirs) == 0 {
+		t.Skipf("rule directory for %q not found", rule)
+	}
+	binariesDir := filepath.Join(ruleDirs[0], "binaries")
 	if _, err := os.Stat(binariesDir); os.IsNotExist(err) {
 		t.Skipf("binaries directory %q not found", binariesDir)
 	}
@@ -44,7 +51,11 @@ func RunRuleTests(t *testing.T, rule string, cases []TestCase) {
 	validateBinaries(t, binariesDir, cases)
 
 	for _, tc := range cases {
-		t.Run(tc.Binary, func(t *testing.T) {
+		name := tc.Binary
+		if tc.Slice != "" {
+			name += "@" + tc.Slice
+		}
+		t.Run(name, func(t *testing.T) {
 			binaryPath := filepath.Join(binariesDir, tc.Binary)
 
 			sarifPath := filepath.Join(t.TempDir(), "result.sarif")
@@ -59,7 +70,7 @@ func RunRuleTests(t *testing.T, rule string, cases []TestCase) {
 			)
 			_ = cmd.Run()
 
-			state := getRuleState(t, sarifPath, rule)
+			state := getRuleState(t, sarifPath, rule, tc.Slice)
 			if state != tc.Expect {
 				t.Errorf("expected %s, got %s", tc.Expect, state)
 			}
@@ -107,7 +118,7 @@ func validateBinaries(t *testing.T, binariesDir string, cases []TestCase) {
 	}
 }
 
-func getRuleState(t *testing.T, sarifPath, rule string) Expectation {
+func getRuleState(t *testing.T, sarifPath, rule, slice string) Expectation {
 	t.Helper()
 
 	data, err := os.ReadFile(sarifPath) // #nosec G304 -- test code with controlled paths
@@ -128,11 +139,14 @@ func getRuleState(t *testing.T, sarifPath, rule string) Expectation {
 	rules := run.Tool.Driver.Rules
 
 	for _, r := range run.Results {
+		if slice != "" && r.Properties["slice"] != slice {
+			continue
+		}
 		if r.RuleIndex >= 0 && r.RuleIndex < len(rules) && rules[r.RuleIndex].ID == rule {
 			return Expectation(r.Kind)
 		}
 	}
 
-	t.Fatalf("no result found for rule %q in SARIF output", rule)
+	t.Fatalf("no result found for rule %q in slice %q of SARIF output", rule, slice)
 	return ""
 }
@@ -0,0 +1,73 @@
+#!/bin/sh
+set -ex
+
+ARCH=$1
+OUT=$PWD/binaries
+C_SRC=$PWD/test/e2e/elf/testdata/main.c
+mkdir -p binaries
+
+. test/e2e/elf/testdata/log-env.sh
+go version
+
+SRC=$(mktemp -d)
+cat > $SRC/main.go << 'GO'
+package main
+
+/*
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+static int greet(char *out, int size, const char *name) {
+	char buf[64];
+	strcpy(buf, name);
+	return snprintf(out, size, "hello %s", buf);
+}
+*/
+import "C"
+
+import (
+	"os"
+	"unsafe"
+)
+
+func main() {
+	name := C.CString(os.Args[0])
+	defer C.free(unsafe.Pointer(name))
+	out := (*C.char)(C.malloc(80))
+	defer C.free(unsafe.Pointer(out))
+	C.greet(out, 80, name)
+	os.Stdout.WriteString(C.GoString(out) + "\n")
+}
+GO
+mkdir $SRC/pure
+cat > $SRC/pure/main.go << 'GO'
+package main
+
+import "os"
+
+func main() {
+	os.Stdout.WriteString("hello\n")
+}
+GO
+cd $SRC
+go mod init example.com/hello
+
+# DWARF is left out to keep the binaries small, it plays no part in the rule.
+build_go() { CGO_CFLAGS="$1" go build -ldflags="-w $4" $2 -o $OUT/${ARCH}-go-$3 ${5:-.}; }
+
+HARDENED="-O2 -fstack-protector-strong -D_FORTIFY_SOURCE=2"
+
+build_go "$HARDENED" "" hardened
+build_go "-O2 -g" "" default-cflags
+build_go "$HARDENED -fno-stack-protector" "" protector-disabled
+build_go "-O2 -fstack-protector-all -D_FORTIFY_SOURCE=3" "" hardened-all
+build_go "$HARDENED" "" hardened-static "-linkmode=external -extldflags=-static"
+build_go "$HARDENED" -trimpath trimpath
+build_go "$HARDENED" "" no-cgo "" ./pure
+
+cd -
+rm -rf $SRC
+gcc -o binaries/${ARCH}-gcc-c $C_SRC
+
+ls -la binaries/
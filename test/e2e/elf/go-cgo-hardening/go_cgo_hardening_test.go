@@ -0,0 +1,20 @@
+package go_cgo_hardening_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestGoCgoHardeningRule(t *testing.T) {
+	e2e.RunRuleTests(t, "go-cgo-hardening", []e2e.TestCase{
+		{Binary: "amd64-go-hardened", Expect: e2e.Pass},
+		{Binary: "amd64-go-hardened-all", Expect: e2e.Pass},
+		{Binary: "amd64-go-hardened-static", Expect: e2e.Pass},
+		{Binary: "amd64-go-default-cflags", Expect: e2e.Fail},
+		{Binary: "amd64-go-protector-disabled", Expect: e2e.Fail},
+		{Binary: "amd64-go-trimpath", Expect: e2e.Skip},
+		{Binary: "amd64-go-no-cgo", Expect: e2e.Skip},
+		{Binary: "amd64-gcc-c", Expect: e2e.Skip},
+	})
+}
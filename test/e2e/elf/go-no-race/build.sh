@@ -0,0 +1,37 @@
+#!/bin/sh
+set -ex
+
+ARCH=$1
+OUT=$PWD/binaries
+C_SRC=$PWD/test/e2e/elf/testdata/main.c
+mkdir -p binaries
+
+. test/e2e/elf/testdata/log-env.sh
+go version
+
+SRC=$(mktemp -d)
+cat > $SRC/main.go << 'GO'
+package main
+
+import "os"
+
+func main() {
+	os.Stdout.WriteString("hello\n")
+}
+GO
+cd $SRC
+go mod init example.com/hello
+
+# DWARF is left out to keep the binaries small, it plays no part in the rule.
+build_go() { go build -ldflags="-w $3" $1 -o $OUT/${ARCH}-go-$2 .; }
+
+build_go "" no-race
+build_go -race race
+build_go -race race-stripped -s
+build_go "-race -trimpath" race-trimpath
+
+cd -
+rm -rf $SRC
+gcc -o binaries/${ARCH}-gcc-c $C_SRC
+
+ls -la binaries/
@@ -0,0 +1,17 @@
+package go_no_race_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestGoNoRaceRule(t *testing.T) {
+	e2e.RunRuleTests(t, "go-no-race", []e2e.TestCase{
+		{Binary: "amd64-go-no-race", Expect: e2e.Pass},
+		{Binary: "amd64-go-race", Expect: e2e.Fail},
+		{Binary: "amd64-go-race-stripped", Expect: e2e.Fail},
+		{Binary: "amd64-go-race-trimpath", Expect: e2e.Fail},
+		{Binary: "amd64-gcc-c", Expect: e2e.Skip},
+	})
+}
@@ -0,0 +1,20 @@
+package go_pie_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestGoPIERule(t *testing.T) {
+	e2e.RunRuleTests(t, "go-pie", []e2e.TestCase{
+		{Binary: "amd64-go-pie", Expect: e2e.Pass},
+		{Binary: "amd64-go-exe", Expect: e2e.Fail},
+		{Binary: "amd64-go-default", Expect: e2e.Fail},
+		{Binary: "amd64-go-pie-trimpath", Expect: e2e.Pass},
+		{Binary: "amd64-go-pie-stripped", Expect: e2e.Pass},
+		{Binary: "amd64-go-pie-static", Expect: e2e.Pass},
+		{Binary: "amd64-go-c-shared", Expect: e2e.Skip},
+		{Binary: "amd64-gcc-c", Expect: e2e.Skip},
+	})
+}
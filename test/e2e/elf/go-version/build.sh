@@ -0,0 +1,36 @@
+#!/bin/sh
+set -ex
+
+ARCH=$1
+OUT=$PWD/binaries
+C_SRC=$PWD/test/e2e/elf/testdata/main.c
+mkdir -p binaries
+
+. test/e2e/elf/testdata/log-env.sh
+go version
+
+SRC=$(mktemp -d)
+cat > $SRC/main.go << 'GO'
+package main
+
+import "os"
+
+func main() {
+	os.Stdout.WriteString("hello\n")
+}
+GO
+cd $SRC
+go mod init example.com/hello
+
+# DWARF is left out to keep the binaries small, it plays no part in the rule.
+build_go() { go build -ldflags="-w $2" $3 -o $OUT/${ARCH}-go-$1 .; }
+
+build_go current
+build_go current-stripped -s
+build_go current-trimpath "" -trimpath
+
+cd -
+rm -rf $SRC
+gcc -o binaries/${ARCH}-gcc-c $C_SRC
+
+ls -la binaries/
@@ -0,0 +1,16 @@
+package go_version_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestGoVersionRule(t *testing.T) {
+	e2e.RunRuleTests(t, "go-version", []e2e.TestCase{
+		{Binary: "amd64-go-current", Expect: e2e.Pass},
+		{Binary: "amd64-go-current-stripped", Expect: e2e.Pass},
+		{Binary: "amd64-go-current-trimpath", Expect: e2e.Pass},
+		{Binary: "amd64-gcc-c", Expect: e2e.Skip},
+	})
+}
@@ -0,0 +1,23 @@
+#!/bin/sh
+set -ex
+
+ARCH=$1
+VMLINUX_SRC=test/e2e/elf/testdata/vmlinux.c
+C_SRC=test/e2e/elf/testdata/main.c
+mkdir -p binaries
+
+. test/e2e/elf/testdata/log-env.sh
+
+# The code model and flags Kbuild compiles x86 kernels with, linked freestanding without the kernel linker script.
+KFLAGS="-O2 -fno-pic -no-pie -mcmodel=kernel -mno-red-zone -ffreestanding -nostdlib -static"
+build_vmlinux() { gcc $KFLAGS $1 -o binaries/${ARCH}-gcc-$2 $VMLINUX_SRC; }
+build_vmlinux_strip() { build_vmlinux "$1" $2 && strip binaries/${ARCH}-gcc-$2; }
+
+build_vmlinux "-fcf-protection=branch -DIBT" ibt
+build_vmlinux_strip "-fcf-protection=branch -DIBT" ibt-stripped
+build_vmlinux "-fcf-protection=branch" endbr-unsealed
+build_vmlinux "-fcf-protection=none" none
+
+gcc -o binaries/${ARCH}-gcc-c $C_SRC
+
+ls -la binaries/
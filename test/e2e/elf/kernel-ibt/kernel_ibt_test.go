@@ -0,0 +1,17 @@
+package kernel_ibt_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestKernelIBTRule(t *testing.T) {
+	e2e.RunRuleTests(t, "kernel-ibt", []e2e.TestCase{
+		{Binary: "amd64-gcc-ibt", Expect: e2e.Pass},
+		{Binary: "amd64-gcc-ibt-stripped", Expect: e2e.Pass},
+		{Binary: "amd64-gcc-endbr-unsealed", Expect: e2e.Fail},
+		{Binary: "amd64-gcc-none", Expect: e2e.Fail},
+		{Binary: "amd64-gcc-c", Expect: e2e.Skip},
+	})
+}
@@ -0,0 +1,18 @@
+package kernel_kcfi_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestKernelKCFIRule(t *testing.T) {
+	e2e.RunRuleTests(t, "kernel-kcfi", []e2e.TestCase{
+		{Binary: "amd64-gcc-kcfi", Expect: e2e.Pass},
+		{Binary: "amd64-gcc-kcfi-stripped", Expect: e2e.Pass},
+		{Binary: "amd64-gcc-kcfi-typeid", Expect: e2e.Pass},
+		{Binary: "amd64-gcc-kcfi-typeid-stripped", Expect: e2e.Skip},
+		{Binary: "amd64-gcc-none", Expect: e2e.Fail},
+		{Binary: "amd64-gcc-c", Expect: e2e.Skip},
+	})
+}
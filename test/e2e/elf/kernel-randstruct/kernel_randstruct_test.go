@@ -0,0 +1,16 @@
+package kernel_randstruct_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestKernelRandstructRule(t *testing.T) {
+	e2e.RunRuleTests(t, "kernel-randstruct", []e2e.TestCase{
+		{Binary: "amd64-gcc-randstruct", Expect: e2e.Pass},
+		{Binary: "amd64-gcc-randstruct-stripped", Expect: e2e.Pass},
+		{Binary: "amd64-gcc-none", Expect: e2e.Fail},
+		{Binary: "amd64-gcc-c", Expect: e2e.Skip},
+	})
+}
@@ -0,0 +1,17 @@
+package kernel_retpoline_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestKernelRetpolineRule(t *testing.T) {
+	e2e.RunRuleTests(t, "kernel-retpoline", []e2e.TestCase{
+		{Binary: "amd64-gcc-retpoline-rethunk", Expect: e2e.Pass},
+		{Binary: "amd64-gcc-retpoline-rethunk-stripped", Expect: e2e.Skip},
+		{Binary: "amd64-gcc-retpoline", Expect: e2e.Fail},
+		{Binary: "amd64-gcc-none", Expect: e2e.Fail},
+		{Binary: "amd64-gcc-c", Expect: e2e.Skip},
+	})
+}
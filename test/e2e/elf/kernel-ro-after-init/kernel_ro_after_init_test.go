@@ -0,0 +1,17 @@
+package kernel_ro_after_init_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestKernelROAfterInitRule(t *testing.T) {
+	e2e.RunRuleTests(t, "kernel-ro-after-init", []e2e.TestCase{
+		{Binary: "amd64-gcc-strict-rwx", Expect: e2e.Pass},
+		{Binary: "amd64-gcc-strict-rwx-stripped", Expect: e2e.Skip},
+		{Binary: "amd64-gcc-ro-after-init", Expect: e2e.Fail},
+		{Binary: "amd64-gcc-none", Expect: e2e.Fail},
+		{Binary: "amd64-gcc-c", Expect: e2e.Skip},
+	})
+}
@@ -0,0 +1,16 @@
+package kernel_stack_protector_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestKernelStackProtectorRule(t *testing.T) {
+	e2e.RunRuleTests(t, "kernel-stack-protector", []e2e.TestCase{
+		{Binary: "amd64-gcc-strong", Expect: e2e.Pass},
+		{Binary: "amd64-gcc-strong-stripped", Expect: e2e.Skip},
+		{Binary: "amd64-gcc-disabled", Expect: e2e.Fail},
+		{Binary: "amd64-gcc-c", Expect: e2e.Skip},
+	})
+}
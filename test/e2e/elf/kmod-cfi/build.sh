@@ -0,0 +1,21 @@
+#!/bin/sh
+set -ex
+
+ARCH=$1
+MOD_SRC=test/e2e/elf/testdata/module.c
+C_SRC=test/e2e/elf/testdata/main.c
+mkdir -p binaries
+
+. test/e2e/elf/testdata/log-env.sh
+
+# The code model and flags Kbuild compiles x86 modules with, minus the kernel headers.
+KFLAGS="-O2 -fno-pic -mcmodel=kernel -mno-red-zone -ffreestanding -fcf-protection=branch -fstack-protector-strong"
+build_ko() { gcc $KFLAGS $1 -c -o binaries/${ARCH}-gcc-$2.ko $MOD_SRC; }
+
+build_ko "" ibt
+build_ko "-fcf-protection=none" no-ibt
+build_ko "-fcf-protection=none -DNO_INIT" no-init
+
+gcc -o binaries/${ARCH}-gcc-c $C_SRC
+
+ls -la binaries/
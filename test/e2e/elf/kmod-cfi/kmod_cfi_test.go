@@ -0,0 +1,16 @@
+package kmod_cfi_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestModuleCFIRule(t *testing.T) {
+	e2e.RunRuleTests(t, "kmod-cfi", []e2e.TestCase{
+		{Binary: "amd64-gcc-ibt.ko", Expect: e2e.Pass},
+		{Binary: "amd64-gcc-no-ibt.ko", Expect: e2e.Fail},
+		{Binary: "amd64-gcc-no-init.ko", Expect: e2e.Skip},
+		{Binary: "amd64-gcc-c", Expect: e2e.Skip},
+	})
+}
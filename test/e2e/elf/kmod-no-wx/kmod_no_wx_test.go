@@ -0,0 +1,15 @@
+package kmod_no_wx_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestModuleNoWXRule(t *testing.T) {
+	e2e.RunRuleTests(t, "kmod-no-wx", []e2e.TestCase{
+		{Binary: "amd64-gcc-rx.ko", Expect: e2e.Pass},
+		{Binary: "amd64-gcc-wx.ko", Expect: e2e.Fail},
+		{Binary: "amd64-gcc-c", Expect: e2e.Skip},
+	})
+}
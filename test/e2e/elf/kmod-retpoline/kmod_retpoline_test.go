@@ -0,0 +1,16 @@
+package kmod_retpoline_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestModuleRetpolineRule(t *testing.T) {
+	e2e.RunRuleTests(t, "kmod-retpoline", []e2e.TestCase{
+		{Binary: "amd64-gcc-retpoline-rethunk.ko", Expect: e2e.Pass},
+		{Binary: "amd64-gcc-retpoline.ko", Expect: e2e.Fail},
+		{Binary: "amd64-gcc-none.ko", Expect: e2e.Fail},
+		{Binary: "amd64-gcc-c", Expect: e2e.Skip},
+	})
+}
@@ -0,0 +1,43 @@
+#!/bin/sh
+set -ex
+
+ARCH=$1
+MOD_SRC=test/e2e/elf/testdata/module.c
+C_SRC=test/e2e/elf/testdata/main.c
+mkdir -p binaries
+
+. test/e2e/elf/testdata/log-env.sh
+openssl version
+
+# The code model and flags Kbuild compiles x86 modules with, minus the kernel headers.
+KFLAGS="-O2 -fno-pic -mcmodel=kernel -mno-red-zone -ffreestanding -fcf-protection=branch -fstack-protector-strong"
+build_ko() { gcc $KFLAGS $1 -c -o binaries/${ARCH}-gcc-$2.ko $MOD_SRC; }
+
+KEY_DIR=$(mktemp -d)
+trap 'rm -rf "$KEY_DIR"' EXIT
+openssl req -x509 -newkey rsa:2048 -nodes -days 1 -subj "/CN=crack e2e module signing key" \
+    -keyout "$KEY_DIR/key.pem" -out "$KEY_DIR/cert.pem"
+
+# What scripts/sign-file does: append a detached PKCS#7 signature, a struct module_signature with id_type
+# PKEY_ID_PKCS7 and the big-endian signature length, and the marker.
+sign_ko() {
+    openssl cms -sign -binary -noattr -nocerts -outform DER -md sha256 \
+        -in "$1" -signer "$KEY_DIR/cert.pem" -inkey "$KEY_DIR/key.pem" -out "$KEY_DIR/sig.der"
+    len=$(wc -c < "$KEY_DIR/sig.der")
+    cat "$KEY_DIR/sig.der" >> "$1"
+    printf '\000\000\002\000\000\000\000\000' >> "$1"
+    printf "\\$(printf %03o $((len >> 24 & 255)))\\$(printf %03o $((len >> 16 & 255)))" >> "$1"
+    printf "\\$(printf %03o $((len >> 8 & 255)))\\$(printf %03o $((len & 255)))" >> "$1"
+    printf '~Module signature appended~\n' >> "$1"
+}
+
+build_ko "" signed
+sign_ko binaries/${ARCH}-gcc-signed.ko
+build_ko "" unsigned
+build_ko "" stripped-signed
+strip --strip-debug binaries/${ARCH}-gcc-stripped-signed.ko
+sign_ko binaries/${ARCH}-gcc-stripped-signed.ko
+
+gcc -o binaries/${ARCH}-gcc-c $C_SRC
+
+ls -la binaries/
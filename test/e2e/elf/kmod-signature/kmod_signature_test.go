@@ -0,0 +1,16 @@
+package kmod_signature_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestModuleSignatureRule(t *testing.T) {
+	e2e.RunRuleTests(t, "kmod-signature", []e2e.TestCase{
+		{Binary: "amd64-gcc-signed.ko", Expect: e2e.Pass},
+		{Binary: "amd64-gcc-stripped-signed.ko", Expect: e2e.Pass},
+		{Binary: "amd64-gcc-unsigned.ko", Expect: e2e.Fail},
+		{Binary: "amd64-gcc-c", Expect: e2e.Skip},
+	})
+}
@@ -0,0 +1,16 @@
+package kmod_stack_protector_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestModuleStackProtectorRule(t *testing.T) {
+	e2e.RunRuleTests(t, "kmod-stack-protector", []e2e.TestCase{
+		{Binary: "amd64-gcc-strong.ko", Expect: e2e.Pass},
+		{Binary: "amd64-gcc-all.ko", Expect: e2e.Pass},
+		{Binary: "amd64-gcc-disabled.ko", Expect: e2e.Fail},
+		{Binary: "amd64-gcc-c", Expect: e2e.Skip},
+	})
+}
@@ -0,0 +1,17 @@
+package privileged_full_relro_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestPrivilegedFullRELRORule(t *testing.T) {
+	e2e.RunRuleTests(t, "privileged-full-relro", []e2e.TestCase{
+		{Binary: "amd64-gcc-setuid-full-relro.tar", Expect: e2e.Pass},
+		{Binary: "amd64-gcc-setuid-partial-relro.tar", Expect: e2e.Fail},
+		{Binary: "amd64-gcc-setgid-no-relro.tar", Expect: e2e.Fail},
+		{Binary: "amd64-gcc-partial-relro.tar", Expect: e2e.Skip},
+		{Binary: "amd64-gcc-c", Expect: e2e.Skip},
+	})
+}
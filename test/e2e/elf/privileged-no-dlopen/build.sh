@@ -0,0 +1,35 @@
+#!/bin/sh
+set -ex
+
+ARCH=$1
+C_SRC=test/e2e/elf/testdata/main.c
+mkdir -p binaries
+
+. test/e2e/elf/testdata/log-env.sh
+
+WORK=$(mktemp -d)
+trap 'rm -rf "$WORK"' EXIT
+
+# Git keeps neither the setuid and setgid bits nor the owner, so each binary is packed into a tarball whose header records them.
+build_c() { gcc $1 -o "$WORK/$2" ${3:-$C_SRC}; }
+pack() { tar --owner=0 --group=0 --numeric-owner --mode=$1 --mtime=@0 -cf binaries/${ARCH}-gcc-$2.tar -C "$WORK" $2; }
+
+cat > "$WORK/dlopen.c" <<'SRC'
+#include <dlfcn.h>
+#include <stdio.h>
+
+int main(int argc, char **argv) {
+    void *handle = dlopen(argc > 1 ? argv[1] : "libm.so.6", RTLD_NOW);
+    printf("%p\n", handle);
+    return handle == NULL;
+}
+SRC
+
+build_c "" setuid && pack 4755 setuid
+build_c "" setuid-dlopen "$WORK/dlopen.c" && pack 4755 setuid-dlopen
+build_c "" setgid-dlopen "$WORK/dlopen.c" && pack 2755 setgid-dlopen
+build_c "" dlopen "$WORK/dlopen.c" && pack 0755 dlopen
+
+gcc -o binaries/${ARCH}-gcc-c $C_SRC
+
+ls -la binaries/
@@ -0,0 +1,17 @@
+package privileged_no_dlopen_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestPrivilegedNoDLOpenRule(t *testing.T) {
+	e2e.RunRuleTests(t, "privileged-no-dlopen", []e2e.TestCase{
+		{Binary: "amd64-gcc-setuid.tar", Expect: e2e.Pass},
+		{Binary: "amd64-gcc-setuid-dlopen.tar", Expect: e2e.Fail},
+		{Binary: "amd64-gcc-setgid-dlopen.tar", Expect: e2e.Fail},
+		{Binary: "amd64-gcc-dlopen.tar", Expect: e2e.Skip},
+		{Binary: "amd64-gcc-c", Expect: e2e.Skip},
+	})
+}
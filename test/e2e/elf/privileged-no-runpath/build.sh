@@ -0,0 +1,24 @@
+#!/bin/sh
+set -ex
+
+ARCH=$1
+C_SRC=test/e2e/elf/testdata/main.c
+mkdir -p binaries
+
+. test/e2e/elf/testdata/log-env.sh
+
+WORK=$(mktemp -d)
+trap 'rm -rf "$WORK"' EXIT
+
+# Git keeps neither the setuid and setgid bits nor the owner, so each binary is packed into a tarball whose header records them.
+build_c() { gcc $1 -o "$WORK/$2" ${3:-$C_SRC}; }
+pack() { tar --owner=0 --group=0 --numeric-owner --mode=$1 --mtime=@0 -cf binaries/${ARCH}-gcc-$2.tar -C "$WORK" $2; }
+
+build_c "" setuid && pack 4755 setuid
+build_c "-Wl,-rpath,/opt/app/lib,--enable-new-dtags" setuid-runpath && pack 4755 setuid-runpath
+build_c "-Wl,-rpath,\$ORIGIN/../lib,--disable-new-dtags" setgid-rpath && pack 2755 setgid-rpath
+build_c "-Wl,-rpath,/opt/app/lib,--enable-new-dtags" runpath && pack 0755 runpath
+
+gcc -o binaries/${ARCH}-gcc-c $C_SRC
+
+ls -la binaries/
@@ -0,0 +1,17 @@
+package privileged_no_runpath_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestPrivilegedNoRUNPATHRule(t *testing.T) {
+	e2e.RunRuleTests(t, "privileged-no-runpath", []e2e.TestCase{
+		{Binary: "amd64-gcc-setuid.tar", Expect: e2e.Pass},
+		{Binary: "amd64-gcc-setuid-runpath.tar", Expect: e2e.Fail},
+		{Binary: "amd64-gcc-setgid-rpath.tar", Expect: e2e.Fail},
+		{Binary: "amd64-gcc-runpath.tar", Expect: e2e.Skip},
+		{Binary: "amd64-gcc-c", Expect: e2e.Skip},
+	})
+}
@@ -0,0 +1,34 @@
+#!/bin/sh
+set -ex
+
+ARCH=$1
+RS_SRC=test/e2e/elf/testdata/main.rs
+FFI_SRC=test/e2e/elf/testdata/ffi.rs
+C_SRC=test/e2e/elf/testdata/ffi.c
+mkdir -p binaries
+
+. test/e2e/elf/testdata/log-env.sh
+rustc --version
+
+# build_cc compiles the C module the way the cc crate does from a build script, appending CFLAGS to its own options,
+# and links it into the Rust binary. The debug information the standard library ships with would only bloat the binaries.
+build_cc() {
+    OUT=$(mktemp -d)
+    gcc -O3 -ffunction-sections -fdata-sections -fPIC $1 -c -o $OUT/ffi.o $C_SRC
+    ar cq $OUT/libffi.a $OUT/ffi.o
+    rustc -C opt-level=3 -C strip=debuginfo $2 -L native=$OUT -l static=ffi -o binaries/${ARCH}-rustc-$3 $FFI_SRC
+    rm -rf $OUT
+}
+
+HARDENED="-fstack-protector-strong -D_FORTIFY_SOURCE=2"
+UNHARDENED="-fno-stack-protector -U_FORTIFY_SOURCE"
+
+build_cc "$HARDENED" "" cc-hardened
+build_cc "$UNHARDENED" "" cc-unhardened
+build_cc "$HARDENED" "" cc-hardened-stripped && strip binaries/${ARCH}-rustc-cc-hardened-stripped
+build_cc "$UNHARDENED" "" cc-unhardened-stripped && strip binaries/${ARCH}-rustc-cc-unhardened-stripped
+build_cc "$HARDENED" "-C target-feature=+crt-static" cc-hardened-static
+
+rustc -C opt-level=3 -C strip=debuginfo -o binaries/${ARCH}-rustc-no-cc $RS_SRC
+
+ls -la binaries/
@@ -0,0 +1,18 @@
+package rust_c_hardening_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestRustCHardeningRule(t *testing.T) {
+	e2e.RunRuleTests(t, "rust-c-hardening", []e2e.TestCase{
+		{Binary: "amd64-rustc-cc-hardened", Expect: e2e.Pass},
+		{Binary: "amd64-rustc-cc-unhardened", Expect: e2e.Fail},
+		{Binary: "amd64-rustc-cc-hardened-stripped", Expect: e2e.Pass},
+		{Binary: "amd64-rustc-cc-unhardened-stripped", Expect: e2e.Fail},
+		{Binary: "amd64-rustc-cc-hardened-static", Expect: e2e.Skip},
+		{Binary: "amd64-rustc-no-cc", Expect: e2e.Skip},
+	})
+}
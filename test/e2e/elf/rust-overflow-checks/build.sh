@@ -0,0 +1,23 @@
+#!/bin/sh
+set -ex
+
+ARCH=$1
+RS_SRC=test/e2e/elf/testdata/main.rs
+C_SRC=test/e2e/elf/testdata/main.c
+mkdir -p binaries
+
+. test/e2e/elf/testdata/log-env.sh
+rustc --version
+
+# The standard library ships with debug information, which would only bloat the binaries.
+build_rs() { rustc -C strip=debuginfo $1 -o binaries/${ARCH}-rustc-$2 $RS_SRC; }
+build_rs_strip() { rustc $1 -o binaries/${ARCH}-rustc-$2 $RS_SRC && strip binaries/${ARCH}-rustc-$2; }
+
+build_rs "-C opt-level=3" release
+build_rs "-C opt-level=3 -C overflow-checks=on" overflow-checks
+build_rs_strip "-C opt-level=3 -C overflow-checks=on" overflow-checks-stripped
+build_rs "-C opt-level=0 -C debug-assertions" debug
+
+gcc -o binaries/${ARCH}-gcc-c $C_SRC
+
+ls -la binaries/
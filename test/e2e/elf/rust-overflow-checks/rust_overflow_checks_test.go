@@ -0,0 +1,17 @@
+package rust_overflow_checks_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestRustOverflowChecksRule(t *testing.T) {
+	e2e.RunRuleTests(t, "rust-overflow-checks", []e2e.TestCase{
+		{Binary: "amd64-rustc-release", Expect: e2e.Fail},
+		{Binary: "amd64-rustc-overflow-checks", Expect: e2e.Pass},
+		{Binary: "amd64-rustc-overflow-checks-stripped", Expect: e2e.Pass},
+		{Binary: "amd64-rustc-debug", Expect: e2e.Pass},
+		{Binary: "amd64-gcc-c", Expect: e2e.Skip},
+	})
+}
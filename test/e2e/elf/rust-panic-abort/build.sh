@@ -0,0 +1,23 @@
+#!/bin/sh
+set -ex
+
+ARCH=$1
+RS_SRC=test/e2e/elf/testdata/main.rs
+C_SRC=test/e2e/elf/testdata/main.c
+mkdir -p binaries
+
+. test/e2e/elf/testdata/log-env.sh
+rustc --version
+
+# The standard library ships with debug information, which would only bloat the binaries.
+build_rs() { rustc -C strip=debuginfo $1 -o binaries/${ARCH}-rustc-$2 $RS_SRC; }
+build_rs_strip() { rustc $1 -o binaries/${ARCH}-rustc-$2 $RS_SRC && strip binaries/${ARCH}-rustc-$2; }
+
+build_rs "-C opt-level=3" unwind
+build_rs_strip "-C opt-level=3" unwind-stripped
+build_rs "-C opt-level=3 -C panic=abort" abort
+build_rs_strip "-C opt-level=3 -C panic=abort" abort-stripped
+
+gcc -o binaries/${ARCH}-gcc-c $C_SRC
+
+ls -la binaries/
@@ -0,0 +1,17 @@
+package rust_panic_abort_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestRustPanicAbortRule(t *testing.T) {
+	e2e.RunRuleTests(t, "rust-panic-abort", []e2e.TestCase{
+		{Binary: "amd64-rustc-unwind", Expect: e2e.Fail},
+		{Binary: "amd64-rustc-unwind-stripped", Expect: e2e.Fail},
+		{Binary: "amd64-rustc-abort", Expect: e2e.Pass},
+		{Binary: "amd64-rustc-abort-stripped", Expect: e2e.Pass},
+		{Binary: "amd64-gcc-c", Expect: e2e.Skip},
+	})
+}
@@ -0,0 +1,8 @@
+#include <stdio.h>
+#include <string.h>
+
+int greet(char *out, const char *name, int size) {
+    char buf[64];
+    strcpy(buf, name);
+    return snprintf(out, size, "hello %s", buf);
+}
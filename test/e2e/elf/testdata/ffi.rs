@@ -0,0 +1,14 @@
+use std::ffi::CString;
+
+extern "C" {
+    fn greet(out: *mut u8, name: *const u8, size: i32) -> i32;
+}
+
+fn main() {
+    let name = CString::new(std::env::args().next().unwrap_or_default()).unwrap();
+    let mut out = [0u8; 80];
+    unsafe {
+        greet(out.as_mut_ptr(), name.as_ptr() as *const u8, out.len() as i32);
+    }
+    println!("{}", String::from_utf8_lossy(&out));
+}
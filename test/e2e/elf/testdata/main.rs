@@ -0,0 +1,11 @@
+use std::collections::HashMap;
+
+fn main() {
+    let args: Vec<String> = std::env::args().collect();
+    let mut lengths = HashMap::new();
+    for arg in &args {
+        lengths.insert(arg.clone(), arg.len());
+    }
+    let count = args.len() as u32;
+    println!("{:?} {}", lengths, count * 3 + 1);
+}
@@ -0,0 +1,33 @@
+/*
+ * A stand-in for a Linux kernel module: an object file with the .modinfo section modpost generates and the entry points
+ * module_init and module_exit define, compiled without the kernel headers.
+ */
+
+#ifdef RETPOLINE
+#define RETPOLINE_INFO "retpoline=Y\0"
+#else
+#define RETPOLINE_INFO ""
+#endif
+
+static const char modinfo[] __attribute__((section(".modinfo"), used, aligned(1))) =
+    "license=GPL\0" RETPOLINE_INFO "name=hello\0vermagic=6.8.0 SMP preempt mod_unload modversions ";
+
+#ifdef WX
+__asm__(".pushsection .wxdata, \"awx\"\n.byte 0\n.popsection");
+#endif
+
+extern int _printk(const char *fmt, ...);
+extern void fill(char *buf, unsigned long size);
+
+void (*hook)(char *buf, unsigned long size) = fill;
+
+#ifndef NO_INIT
+int init_module(void) {
+    char buf[64];
+    hook(buf, sizeof(buf));
+    return _printk("%s\n", buf);
+}
+#endif
+
+void cleanup_module(void) {
+}
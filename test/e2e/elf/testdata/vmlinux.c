@@ -0,0 +1,76 @@
+/*
+ * A stand-in for vmlinux: a freestanding executable with the .init.text and __ex_table sections of a kernel image, and
+ * the sections and symbols the kernel's linker script, objtool and Clang leave behind for the hardening options the
+ * macros below select. Linked with -nostdlib -static, it never runs.
+ */
+
+#ifdef RANDSTRUCT
+#define RANDSTRUCT_INFO " RANDSTRUCT_0f1e2d3c4b5a69788796a5b4c3d2e1f0"
+#else
+#define RANDSTRUCT_INFO ""
+#endif
+
+const char vermagic[] = "6.8.0 SMP preempt mod_unload modversions" RANDSTRUCT_INFO " ";
+
+__asm__(".pushsection __ex_table, \"a\"\n.long 0, 0, 0\n.popsection");
+
+#ifdef IBT
+/* The ENDBR64 instructions objtool found in functions that are never called indirectly. */
+__asm__(".pushsection .ibt_endbr_seal, \"a\"\n.long 0\n.popsection");
+#endif
+
+#ifdef KCFI_TRAPS
+/* The addresses of the trapping kCFI checks, x86 only. */
+__asm__(".pushsection .kcfi_traps, \"a\"\n.long 0\n.popsection");
+#endif
+
+#ifdef KCFI_TYPEID
+/* The type hash of an assembly function called indirectly, as Clang defines it. */
+__asm__(".globl __kcfi_typeid_fill\n.set __kcfi_typeid_fill, 0x2f6b4e1a");
+#endif
+
+#ifdef RETPOLINE
+__asm__(".pushsection .text\n"
+        ".globl __x86_indirect_thunk_rax\n__x86_indirect_thunk_rax: jmp *%rax\n"
+        ".popsection");
+#endif
+
+#ifdef RETHUNK
+__asm__(".pushsection .text\n"
+        ".globl __x86_return_thunk\n__x86_return_thunk: ret\n"
+        ".popsection\n"
+        ".pushsection .return_sites, \"a\"\n.long 0\n.popsection");
+#endif
+
+#ifdef RO_AFTER_INIT
+__asm__(".pushsection .data..ro_after_init, \"aw\"\n"
+        ".globl __start_ro_after_init\n__start_ro_after_init: .quad 0\n"
+        ".globl __end_ro_after_init\n__end_ro_after_init:\n"
+        ".popsection");
+#endif
+
+#ifdef STRICT_KERNEL_RWX
+void mark_rodata_ro(void) {
+}
+#endif
+
+#if defined(__SSP__) || defined(__SSP_STRONG__) || defined(__SSP_ALL__)
+void __stack_chk_fail(void) {
+    for (;;)
+        ;
+}
+#endif
+
+__attribute__((noinline)) void fill(char *buf, unsigned long size) {
+    for (unsigned long i = 0; i < size; i++)
+        buf[i] = vermagic[i % sizeof(vermagic)];
+}
+
+void (*volatile hook)(char *buf, unsigned long size) = fill;
+
+__attribute__((section(".init.text"))) void _start(void) {
+    char buf[64];
+    hook(buf, sizeof(buf));
+    for (;;)
+        ;
+}
@@ -0,0 +1,22 @@
+package macho_code_signature_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestCodeSignatureRule(t *testing.T) {
+	e2e.RunRuleTests(t, "macho-code-signature", []e2e.TestCase{
+		{Binary: "x86_64-unsigned", Expect: e2e.Fail},
+		{Binary: "x86_64-adhoc-runtime", Expect: e2e.Pass},
+		{Binary: "x86_64-developer-id-runtime", Expect: e2e.Pass},
+		{Binary: "arm64-unsigned", Expect: e2e.Fail},
+		{Binary: "arm64e-linker-signed", Expect: e2e.Pass},
+		{Binary: "arm64e-dylib-linker-signed", Expect: e2e.Pass},
+		{Binary: "universal-signed", Slice: "x86_64", Expect: e2e.Pass},
+		{Binary: "universal-signed", Slice: "arm64e", Expect: e2e.Pass},
+		{Binary: "universal-unsigned-x86_64", Slice: "x86_64", Expect: e2e.Fail},
+		{Binary: "universal-unsigned-x86_64", Slice: "arm64e", Expect: e2e.Pass},
+	})
+}
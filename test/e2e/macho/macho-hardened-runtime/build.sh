@@ -0,0 +1,18 @@
+#!/bin/sh
+set -ex
+
+mkdir -p binaries
+
+. test/e2e/macho/testdata/macho.sh
+llvm-lipo --version
+
+macho x86_64 binaries/x86_64-unsigned
+macho x86_64 binaries/x86_64-adhoc-runtime adhoc-runtime
+macho x86_64 binaries/x86_64-developer-id-runtime developer-id-runtime
+macho arm64e binaries/arm64e-linker-signed linker-signed
+macho arm64e binaries/arm64e-adhoc-runtime adhoc-runtime
+macho arm64e binaries/arm64e-dylib-adhoc-runtime dylib adhoc-runtime
+universal binaries/universal-runtime binaries/x86_64-developer-id-runtime binaries/arm64e-adhoc-runtime
+universal binaries/universal-no-runtime binaries/x86_64-unsigned binaries/arm64e-linker-signed
+
+ls -la binaries/
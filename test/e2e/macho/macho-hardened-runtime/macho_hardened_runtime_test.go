@@ -0,0 +1,22 @@
+package macho_hardened_runtime_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestHardenedRuntimeRule(t *testing.T) {
+	e2e.RunRuleTests(t, "macho-hardened-runtime", []e2e.TestCase{
+		{Binary: "x86_64-unsigned", Expect: e2e.Fail},
+		{Binary: "x86_64-adhoc-runtime", Expect: e2e.Pass},
+		{Binary: "x86_64-developer-id-runtime", Expect: e2e.Pass},
+		{Binary: "arm64e-linker-signed", Expect: e2e.Fail},
+		{Binary: "arm64e-adhoc-runtime", Expect: e2e.Pass},
+		{Binary: "arm64e-dylib-adhoc-runtime", Expect: e2e.Skip},
+		{Binary: "universal-runtime", Slice: "x86_64", Expect: e2e.Pass},
+		{Binary: "universal-runtime", Slice: "arm64e", Expect: e2e.Pass},
+		{Binary: "universal-no-runtime", Slice: "x86_64", Expect: e2e.Fail},
+		{Binary: "universal-no-runtime", Slice: "arm64e", Expect: e2e.Fail},
+	})
+}
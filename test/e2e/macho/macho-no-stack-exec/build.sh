@@ -0,0 +1,17 @@
+#!/bin/sh
+set -ex
+
+mkdir -p binaries
+
+. test/e2e/macho/testdata/macho.sh
+llvm-lipo --version
+
+macho x86_64 binaries/x86_64-nx
+macho x86_64 binaries/x86_64-stack-exec stack-exec
+macho arm64e binaries/arm64e-nx linker-signed
+macho arm64e binaries/arm64e-stack-exec stack-exec linker-signed
+macho arm64e binaries/arm64e-dylib-stack-exec dylib stack-exec linker-signed
+universal binaries/universal-nx binaries/x86_64-nx binaries/arm64e-nx
+universal binaries/universal-stack-exec-x86_64 binaries/x86_64-stack-exec binaries/arm64e-nx
+
+ls -la binaries/
@@ -0,0 +1,21 @@
+package macho_no_stack_exec_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestNoStackExecRule(t *testing.T) {
+	e2e.RunRuleTests(t, "macho-no-stack-exec", []e2e.TestCase{
+		{Binary: "x86_64-nx", Expect: e2e.Pass},
+		{Binary: "x86_64-stack-exec", Expect: e2e.Fail},
+		{Binary: "arm64e-nx", Expect: e2e.Pass},
+		{Binary: "arm64e-stack-exec", Expect: e2e.Fail},
+		{Binary: "arm64e-dylib-stack-exec", Expect: e2e.Skip},
+		{Binary: "universal-nx", Slice: "x86_64", Expect: e2e.Pass},
+		{Binary: "universal-nx", Slice: "arm64e", Expect: e2e.Pass},
+		{Binary: "universal-stack-exec-x86_64", Slice: "x86_64", Expect: e2e.Fail},
+		{Binary: "universal-stack-exec-x86_64", Slice: "arm64e", Expect: e2e.Pass},
+	})
+}
@@ -0,0 +1,17 @@
+#!/bin/sh
+set -ex
+
+mkdir -p binaries
+
+. test/e2e/macho/testdata/macho.sh
+llvm-lipo --version
+
+macho arm64e binaries/arm64e linker-signed
+macho arm64 binaries/arm64 linker-signed
+macho arm64e binaries/arm64e-dylib dylib linker-signed
+macho x86_64 binaries/x86_64
+universal binaries/universal-x86_64-arm64e binaries/x86_64 binaries/arm64e
+universal binaries/universal-x86_64-arm64 binaries/x86_64 binaries/arm64
+universal binaries/universal-arm64-arm64e binaries/arm64 binaries/arm64e
+
+ls -la binaries/
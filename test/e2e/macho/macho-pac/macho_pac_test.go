@@ -0,0 +1,22 @@
+package macho_pac_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestPACRule(t *testing.T) {
+	e2e.RunRuleTests(t, "macho-pac", []e2e.TestCase{
+		{Binary: "arm64e", Expect: e2e.Pass},
+		{Binary: "arm64", Expect: e2e.Fail},
+		{Binary: "arm64e-dylib", Expect: e2e.Pass},
+		{Binary: "x86_64", Expect: e2e.Skip},
+		{Binary: "universal-x86_64-arm64e", Slice: "x86_64", Expect: e2e.Skip},
+		{Binary: "universal-x86_64-arm64e", Slice: "arm64e", Expect: e2e.Pass},
+		{Binary: "universal-x86_64-arm64", Slice: "x86_64", Expect: e2e.Skip},
+		{Binary: "universal-x86_64-arm64", Slice: "arm64", Expect: e2e.Fail},
+		{Binary: "universal-arm64-arm64e", Slice: "arm64", Expect: e2e.Fail},
+		{Binary: "universal-arm64-arm64e", Slice: "arm64e", Expect: e2e.Pass},
+	})
+}
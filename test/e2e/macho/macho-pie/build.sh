@@ -0,0 +1,16 @@
+#!/bin/sh
+set -ex
+
+mkdir -p binaries
+
+. test/e2e/macho/testdata/macho.sh
+llvm-lipo --version
+
+macho x86_64 binaries/x86_64-pie
+macho x86_64 binaries/x86_64-no-pie no-pie
+macho arm64e binaries/arm64e-pie linker-signed
+macho arm64e binaries/arm64e-dylib dylib linker-signed
+universal binaries/universal-pie binaries/x86_64-pie binaries/arm64e-pie
+universal binaries/universal-no-pie-x86_64 binaries/x86_64-no-pie binaries/arm64e-pie
+
+ls -la binaries/
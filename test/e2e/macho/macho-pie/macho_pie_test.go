@@ -0,0 +1,20 @@
+package macho_pie_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestPIERule(t *testing.T) {
+	e2e.RunRuleTests(t, "macho-pie", []e2e.TestCase{
+		{Binary: "x86_64-pie", Expect: e2e.Pass},
+		{Binary: "x86_64-no-pie", Expect: e2e.Fail},
+		{Binary: "arm64e-pie", Expect: e2e.Pass},
+		{Binary: "arm64e-dylib", Expect: e2e.Skip},
+		{Binary: "universal-pie", Slice: "x86_64", Expect: e2e.Pass},
+		{Binary: "universal-pie", Slice: "arm64e", Expect: e2e.Pass},
+		{Binary: "universal-no-pie-x86_64", Slice: "x86_64", Expect: e2e.Fail},
+		{Binary: "universal-no-pie-x86_64", Slice: "arm64e", Expect: e2e.Pass},
+	})
+}
@@ -0,0 +1,17 @@
+#!/bin/sh
+set -ex
+
+mkdir -p binaries
+
+. test/e2e/macho/testdata/macho.sh
+llvm-lipo --version
+
+macho x86_64 binaries/x86_64-restrict restrict
+macho x86_64 binaries/x86_64-no-restrict
+macho arm64e binaries/arm64e-restrict restrict adhoc-runtime
+macho arm64e binaries/arm64e-no-restrict adhoc-runtime
+macho arm64e binaries/arm64e-dylib-restrict dylib restrict linker-signed
+universal binaries/universal-restrict binaries/x86_64-restrict binaries/arm64e-restrict
+universal binaries/universal-no-restrict-x86_64 binaries/x86_64-no-restrict binaries/arm64e-restrict
+
+ls -la binaries/
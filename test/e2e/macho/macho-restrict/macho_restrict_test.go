@@ -0,0 +1,21 @@
+package macho_restrict_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestRestrictRule(t *testing.T) {
+	e2e.RunRuleTests(t, "macho-restrict", []e2e.TestCase{
+		{Binary: "x86_64-restrict", Expect: e2e.Pass},
+		{Binary: "x86_64-no-restrict", Expect: e2e.Fail},
+		{Binary: "arm64e-restrict", Expect: e2e.Pass},
+		{Binary: "arm64e-no-restrict", Expect: e2e.Fail},
+		{Binary: "arm64e-dylib-restrict", Expect: e2e.Skip},
+		{Binary: "universal-restrict", Slice: "x86_64", Expect: e2e.Pass},
+		{Binary: "universal-restrict", Slice: "arm64e", Expect: e2e.Pass},
+		{Binary: "universal-no-restrict-x86_64", Slice: "x86_64", Expect: e2e.Fail},
+		{Binary: "universal-no-restrict-x86_64", Slice: "arm64e", Expect: e2e.Pass},
+	})
+}
@@ -0,0 +1,17 @@
+#!/bin/sh
+set -ex
+
+mkdir -p binaries
+
+. test/e2e/macho/testdata/macho.sh
+llvm-lipo --version
+
+macho x86_64 binaries/x86_64-canary canary
+macho x86_64 binaries/x86_64-no-canary
+macho arm64e binaries/arm64e-canary canary linker-signed
+macho arm64e binaries/arm64e-no-canary linker-signed
+macho arm64e binaries/arm64e-dylib-canary dylib canary linker-signed
+universal binaries/universal-canary binaries/x86_64-canary binaries/arm64e-canary
+universal binaries/universal-no-canary-arm64e binaries/x86_64-canary binaries/arm64e-no-canary
+
+ls -la binaries/
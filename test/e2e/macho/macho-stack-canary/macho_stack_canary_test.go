@@ -0,0 +1,21 @@
+package macho_stack_canary_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestStackCanaryRule(t *testing.T) {
+	e2e.RunRuleTests(t, "macho-stack-canary", []e2e.TestCase{
+		{Binary: "x86_64-canary", Expect: e2e.Pass},
+		{Binary: "x86_64-no-canary", Expect: e2e.Fail},
+		{Binary: "arm64e-canary", Expect: e2e.Pass},
+		{Binary: "arm64e-no-canary", Expect: e2e.Fail},
+		{Binary: "arm64e-dylib-canary", Expect: e2e.Pass},
+		{Binary: "universal-canary", Slice: "x86_64", Expect: e2e.Pass},
+		{Binary: "universal-canary", Slice: "arm64e", Expect: e2e.Pass},
+		{Binary: "universal-no-canary-arm64e", Slice: "x86_64", Expect: e2e.Pass},
+		{Binary: "universal-no-canary-arm64e", Slice: "arm64e", Expect: e2e.Fail},
+	})
+}
@@ -0,0 +1,209 @@
+# Builds stand-ins for the Mach-O binaries clang and ld64 produce, for toolchain images without an Apple SDK.
+# Each stand-in has the load commands, symbols and code signature the macho-* rules look at, described in YAML for
+# yaml2obj from LLVM, and universal binaries are merged with llvm-lipo.
+#
+# macho <arch> <output> [option...] writes a thin executable for x86_64, arm64 or arm64e. Options:
+#   dylib       a dynamic library instead of an executable
+#   no-pie      without MH_PIE, as -Wl,-no_pie
+#   stack-exec  with MH_ALLOW_STACK_EXECUTION, as -Wl,-allow_stack_execute
+#   canary      importing ___stack_chk_fail and ___stack_chk_guard, as -fstack-protector-strong
+#   restrict    with a __RESTRICT,__restrict section, as -Wl,-sectcreate,__RESTRICT,__restrict,/dev/null
+#   linker-signed, adhoc-runtime, developer-id-runtime
+#               with the code signature ld64 applies on arm64, codesign -s - -o runtime, or
+#               codesign -s "Developer ID Application: ..." -o runtime
+# universal <output> <input>... merges thin binaries.
+
+MACHO_DIR=$(mktemp -d)
+trap 'rm -rf "$MACHO_DIR"' EXIT
+
+# be32 prints n as a big-endian 32-bit integer, the byte order of code signature blobs.
+be32() {
+    printf "\\$(printf %03o $(($1 >> 24 & 255)))\\$(printf %03o $(($1 >> 16 & 255)))"
+    printf "\\$(printf %03o $(($1 >> 8 & 255)))\\$(printf %03o $(($1 & 255)))"
+}
+
+# codesign_blob <flags> <identifier> prints an embedded signature superblob holding a code directory with no hash slots.
+codesign_blob() {
+    ident_len=$((${#2} + 1))
+    cd_len=$((52 + ident_len))
+    be32 $((0xfade0cc0)); be32 $((20 + cd_len)); be32 1
+    be32 0; be32 20
+    # CodeDirectory version 0x20100: hashOffset, identOffset, nSpecialSlots, nCodeSlots, codeLimit, then
+    # hashSize 32, hashType SHA-256, platform 0, pageSize 2^12, spare2 and scatterOffset.
+    be32 $((0xfade0c02)); be32 $cd_len; be32 $((0x20100)); be32 $1
+    be32 $cd_len; be32 52; be32 0; be32 0; be32 0
+    printf '\040\002\000\014'; be32 0; be32 0
+    printf '%s\000' "$2"
+}
+
+macho() {
+    arch=$1 out=$2
+    shift 2
+    dylib= pie=1 stackexec= canary= restrict= sigflags=
+    for opt in "$@"; do
+        case $opt in
+            dylib) dylib=1 ;;
+            no-pie) pie= ;;
+            stack-exec) stackexec=1 ;;
+            canary) canary=1 ;;
+            restrict) restrict=1 ;;
+            linker-signed) sigflags=$((0x20002)) ;;
+            adhoc-runtime) sigflags=$((0x10002)) ;;
+            developer-id-runtime) sigflags=$((0x10000)) ;;
+            *) echo "unknown option $opt" >&2; return 1 ;;
+        esac
+    done
+
+    case $arch in
+        x86_64) cputype=0x01000007 cpusubtype=0x00000003 code=554889E531C05DC3 ;;
+        arm64) cputype=0x0100000C cpusubtype=0x00000000 code=00008052C0035FD6 ;;
+        # CPU_SUBTYPE_PTRAUTH_ABI with ABI version 0, and pacibsp before retab.
+        arm64e) cputype=0x0100000C cpusubtype=0x80000002 code=7F2303D500008052FF0F5FD6 ;;
+        *) echo "unknown arch $arch" >&2; return 1 ;;
+    esac
+
+    # MH_NOUNDEFS | MH_DYLDLINK | MH_TWOLEVEL, plus MH_PIE and MH_ALLOW_STACK_EXECUTION.
+    flags=$((0x85))
+    [ -n "$dylib" ] && flags=$((0x100085))
+    [ -z "$dylib" ] && [ -n "$pie" ] && flags=$((flags | 0x200000))
+    [ -n "$stackexec" ] && flags=$((flags | 0x20000))
+
+    if [ -n "$dylib" ]; then
+        filetype=6 entry=_fixture
+    else
+        filetype=2 entry=_main
+    fi
+
+    # The string table starts with " \0", then the defined symbol and the imports.
+    strings="' ', $entry"
+    nsyms=1 strsize=$((2 + ${#entry} + 1))
+    if [ -n "$canary" ]; then
+        strings="$strings, ___stack_chk_fail, ___stack_chk_guard"
+        nsyms=3 strsize=$((strsize + 18 + 19))
+    fi
+    strsize=$(((strsize + 7) / 8 * 8))
+    symoff=16384 stroff=$((16384 + nsyms * 16))
+    sigoff=$(((stroff + strsize + 15) / 16 * 16))
+    sigsize=0
+    if [ -n "$sigflags" ]; then
+        codesign_blob $sigflags "$(basename "$out")" > "$MACHO_DIR/sig"
+        sigsize=$(wc -c < "$MACHO_DIR/sig")
+    fi
+    linkedit=$((sigoff + sigsize - 16384))
+    codesize=$((${#code} / 2))
+    textoff=$((16384 - codesize))
+
+    ncmds=0 sizeofcmds=0
+    cmd() { ncmds=$((ncmds + 1)); sizeofcmds=$((sizeofcmds + $1)); }
+
+    y="$MACHO_DIR/macho.yaml"
+    {
+        echo "--- !mach-o"
+        echo "LoadCommands:"
+        if [ -z "$dylib" ]; then
+            cmd 72
+            cat <<EOF
+  - { cmd: LC_SEGMENT_64, cmdsize: 72, segname: __PAGEZERO, vmaddr: 0, vmsize: 0x100000000, fileoff: 0, filesize: 0, maxprot: 0, initprot: 0, nsects: 0, flags: 0 }
+EOF
+            base=0x100000000
+        else
+            base=0
+        fi
+        cmd 152
+        cat <<EOF
+  - cmd: LC_SEGMENT_64
+    cmdsize: 152
+    segname: __TEXT
+    vmaddr: $base
+    vmsize: 16384
+    fileoff: 0
+    filesize: 16384
+    maxprot: 5
+    initprot: 5
+    nsects: 1
+    flags: 0
+    Sections:
+      - { sectname: __text, segname: __TEXT, addr: $((base + textoff)), size: $codesize, offset: $textoff, align: 2, reloff: 0, nreloc: 0, flags: 0x80000400, reserved1: 0, reserved2: 0, reserved3: 0, content: $code }
+EOF
+        next=$((base + 16384))
+        if [ -n "$restrict" ]; then
+            cmd 152
+            cat <<EOF
+  - cmd: LC_SEGMENT_64
+    cmdsize: 152
+    segname: __RESTRICT
+    vmaddr: $next
+    vmsize: 16384
+    fileoff: 16384
+    filesize: 0
+    maxprot: 3
+    initprot: 3
+    nsects: 1
+    flags: 0
+    Sections:
+      - { sectname: __restrict, segname: __RESTRICT, addr: $next, size: 0, offset: 0, align: 0, reloff: 0, nreloc: 0, flags: 0, reserved1: 0, reserved2: 0, reserved3: 0 }
+EOF
+            next=$((next + 16384))
+        fi
+        cmd 72
+        cat <<EOF
+  - { cmd: LC_SEGMENT_64, cmdsize: 72, segname: __LINKEDIT, vmaddr: $next, vmsize: 16384, fileoff: 16384, filesize: $linkedit, maxprot: 1, initprot: 1, nsects: 0, flags: 0 }
+EOF
+        if [ -n "$dylib" ]; then
+            cmd 56
+            cat <<EOF
+  - { cmd: LC_ID_DYLIB, cmdsize: 56, dylib: { name: 24, timestamp: 1, current_version: 0x10000, compatibility_version: 0x10000 }, Content: '@rpath/libfixture.dylib' }
+EOF
+        else
+            cmd 32
+            cmd 24
+            cat <<EOF
+  - { cmd: LC_LOAD_DYLINKER, cmdsize: 32, name: 12, Content: /usr/lib/dyld }
+  - { cmd: LC_MAIN, cmdsize: 24, entryoff: $textoff, stacksize: 0 }
+EOF
+        fi
+        cmd 56
+        cmd 24
+        cmd 80
+        cat <<EOF
+  - { cmd: LC_LOAD_DYLIB, cmdsize: 56, dylib: { name: 24, timestamp: 2, current_version: 0x5276403, compatibility_version: 0x10000 }, Content: /usr/lib/libSystem.B.dylib }
+  - { cmd: LC_SYMTAB, cmdsize: 24, symoff: $symoff, nsyms: $nsyms, stroff: $stroff, strsize: $strsize }
+  - { cmd: LC_DYSYMTAB, cmdsize: 80, ilocalsym: 0, nlocalsym: 0, iextdefsym: 0, nextdefsym: 1, iundefsym: 1, nundefsym: $((nsyms - 1)), tocoff: 0, ntoc: 0, modtaboff: 0, nmodtab: 0, extrefsymoff: 0, nextrefsyms: 0, indirectsymoff: 0, nindirectsyms: 0, extreloff: 0, nextrel: 0, locreloff: 0, nlocrel: 0 }
+EOF
+        if [ -n "$sigflags" ]; then
+            cmd 16
+            cat <<EOF
+  - { cmd: LC_CODE_SIGNATURE, cmdsize: 16, dataoff: $sigoff, datasize: $sigsize }
+EOF
+        fi
+        cat <<EOF
+LinkEditData:
+  NameList:
+    - { n_strx: 2, n_type: 0x0F, n_sect: 1, n_desc: 0, n_value: $((base + textoff)) }
+EOF
+        if [ -n "$canary" ]; then
+            cat <<EOF
+    - { n_strx: $((3 + ${#entry})), n_type: 0x01, n_sect: 0, n_desc: 256, n_value: 0 }
+    - { n_strx: $((3 + ${#entry} + 18)), n_type: 0x01, n_sect: 0, n_desc: 256, n_value: 0 }
+EOF
+        fi
+        echo "  StringTable: [ $strings ]"
+        echo "..."
+    } > "$y.body"
+    {
+        echo "--- !mach-o"
+        echo "FileHeader: { magic: 0xFEEDFACF, cputype: $cputype, cpusubtype: $cpusubtype, filetype: $filetype, ncmds: $ncmds, sizeofcmds: $sizeofcmds, flags: $(printf 0x%08X $flags), reserved: 0 }"
+        sed 1d "$y.body"
+    } > "$y"
+
+    yaml2obj "$y" -o "$out"
+    if [ -n "$sigflags" ]; then
+        dd if="$MACHO_DIR/sig" of="$out" bs=1 seek=$sigoff conv=notrunc 2>/dev/null
+    fi
+}
+
+universal() {
+    out=$1
+    shift
+    llvm-lipo -create "$@" -output "$out"
+}
@@ -0,0 +1,16 @@
+#!/bin/sh
+set -ex
+
+mkdir -p binaries
+
+. test/e2e/pe/testdata/pe.sh
+llvm-readobj --version
+
+pe amd64 binaries/amd64-clang-cl-cetcompat dynamicbase high-entropy-va nxcompat cetcompat
+pe amd64 binaries/amd64-clang-cl-no-cetcompat dynamicbase high-entropy-va nxcompat
+pe x86 binaries/x86-clang-cl-cetcompat dynamicbase nxcompat cetcompat
+pe amd64 binaries/amd64-clang-cl-dll-cetcompat dll dynamicbase high-entropy-va nxcompat cetcompat
+pe arm64 binaries/arm64-clang-cl dynamicbase high-entropy-va nxcompat
+pe amd64 binaries/amd64-mingw mingw=13.2.0 dynamicbase high-entropy-va nxcompat
+
+ls -la binaries/
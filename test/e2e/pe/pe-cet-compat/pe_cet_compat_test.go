@@ -0,0 +1,18 @@
+package pe_cet_compat_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestCETCompatRule(t *testing.T) {
+	e2e.RunRuleTests(t, "pe-cet-compat", []e2e.TestCase{
+		{Binary: "amd64-clang-cl-cetcompat", Expect: e2e.Pass},
+		{Binary: "amd64-clang-cl-no-cetcompat", Expect: e2e.Fail},
+		{Binary: "x86-clang-cl-cetcompat", Expect: e2e.Pass},
+		{Binary: "amd64-clang-cl-dll-cetcompat", Expect: e2e.Pass},
+		{Binary: "arm64-clang-cl", Expect: e2e.Skip},
+		{Binary: "amd64-mingw", Expect: e2e.Skip},
+	})
+}
@@ -0,0 +1,16 @@
+#!/bin/sh
+set -ex
+
+mkdir -p binaries
+
+. test/e2e/pe/testdata/pe.sh
+llvm-readobj --version
+
+pe amd64 binaries/amd64-clang-cl-guard-cf dynamicbase high-entropy-va nxcompat guard-cf cfg
+pe amd64 binaries/amd64-clang-cl-no-guard-cf dynamicbase high-entropy-va nxcompat
+pe amd64 binaries/amd64-clang-cl-guard-cf-uninstrumented dynamicbase high-entropy-va nxcompat guard-cf
+pe x86 binaries/x86-clang-cl-guard-cf dynamicbase nxcompat guard-cf cfg
+pe arm64 binaries/arm64-clang-cl-dll-guard-cf dll dynamicbase high-entropy-va nxcompat guard-cf cfg
+pe amd64 binaries/amd64-mingw mingw=13.2.0 dynamicbase high-entropy-va nxcompat
+
+ls -la binaries/
@@ -0,0 +1,18 @@
+package pe_cfg_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestCFGRule(t *testing.T) {
+	e2e.RunRuleTests(t, "pe-cfg", []e2e.TestCase{
+		{Binary: "amd64-clang-cl-guard-cf", Expect: e2e.Pass},
+		{Binary: "amd64-clang-cl-no-guard-cf", Expect: e2e.Fail},
+		{Binary: "amd64-clang-cl-guard-cf-uninstrumented", Expect: e2e.Fail},
+		{Binary: "x86-clang-cl-guard-cf", Expect: e2e.Pass},
+		{Binary: "arm64-clang-cl-dll-guard-cf", Expect: e2e.Pass},
+		{Binary: "amd64-mingw", Expect: e2e.Skip},
+	})
+}
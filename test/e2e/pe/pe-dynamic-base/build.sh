@@ -0,0 +1,17 @@
+#!/bin/sh
+set -ex
+
+mkdir -p binaries
+
+. test/e2e/pe/testdata/pe.sh
+llvm-readobj --version
+
+pe amd64 binaries/amd64-mingw-dynamicbase mingw=13.2.0 dynamicbase high-entropy-va nxcompat
+pe amd64 binaries/amd64-mingw-no-dynamicbase mingw=13.2.0 nxcompat
+pe amd64 binaries/amd64-mingw-4.4 mingw=4.4.7
+pe amd64 binaries/amd64-clang-cl-dynamicbase dynamicbase high-entropy-va nxcompat
+pe amd64 binaries/amd64-clang-cl-no-dynamicbase nxcompat
+pe x86 binaries/x86-clang-cl-dynamicbase dynamicbase nxcompat
+pe arm64 binaries/arm64-clang-cl-dll-dynamicbase dll dynamicbase high-entropy-va nxcompat
+
+ls -la binaries/
@@ -0,0 +1,19 @@
+package pe_dynamic_base_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestDynamicBaseRule(t *testing.T) {
+	e2e.RunRuleTests(t, "pe-dynamic-base", []e2e.TestCase{
+		{Binary: "amd64-mingw-dynamicbase", Expect: e2e.Pass},
+		{Binary: "amd64-mingw-no-dynamicbase", Expect: e2e.Fail},
+		{Binary: "amd64-mingw-4.4", Expect: e2e.Fail},
+		{Binary: "amd64-clang-cl-dynamicbase", Expect: e2e.Pass},
+		{Binary: "amd64-clang-cl-no-dynamicbase", Expect: e2e.Fail},
+		{Binary: "x86-clang-cl-dynamicbase", Expect: e2e.Pass},
+		{Binary: "arm64-clang-cl-dll-dynamicbase", Expect: e2e.Pass},
+	})
+}
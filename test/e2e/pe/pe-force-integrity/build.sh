@@ -0,0 +1,16 @@
+#!/bin/sh
+set -ex
+
+mkdir -p binaries
+
+. test/e2e/pe/testdata/pe.sh
+llvm-readobj --version
+
+pe amd64 binaries/amd64-mingw-forceinteg mingw=13.2.0 dynamicbase high-entropy-va nxcompat force-integrity
+pe amd64 binaries/amd64-mingw-no-forceinteg mingw=13.2.0 dynamicbase high-entropy-va nxcompat
+pe amd64 binaries/amd64-mingw-4.4-forceinteg mingw=4.4.7 force-integrity
+pe amd64 binaries/amd64-clang-cl-integritycheck dynamicbase high-entropy-va nxcompat force-integrity
+pe amd64 binaries/amd64-clang-cl-no-integritycheck dynamicbase high-entropy-va nxcompat
+pe arm64 binaries/arm64-clang-cl-dll-integritycheck dll dynamicbase high-entropy-va nxcompat force-integrity
+
+ls -la binaries/
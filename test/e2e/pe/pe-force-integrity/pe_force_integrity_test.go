@@ -0,0 +1,18 @@
+package pe_force_integrity_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestForceIntegrityRule(t *testing.T) {
+	e2e.RunRuleTests(t, "pe-force-integrity", []e2e.TestCase{
+		{Binary: "amd64-mingw-forceinteg", Expect: e2e.Pass},
+		{Binary: "amd64-mingw-no-forceinteg", Expect: e2e.Fail},
+		{Binary: "amd64-mingw-4.4-forceinteg", Expect: e2e.Pass},
+		{Binary: "amd64-clang-cl-integritycheck", Expect: e2e.Pass},
+		{Binary: "amd64-clang-cl-no-integritycheck", Expect: e2e.Fail},
+		{Binary: "arm64-clang-cl-dll-integritycheck", Expect: e2e.Pass},
+	})
+}
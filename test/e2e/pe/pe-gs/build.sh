@@ -0,0 +1,18 @@
+#!/bin/sh
+set -ex
+
+mkdir -p binaries
+
+. test/e2e/pe/testdata/pe.sh
+llvm-readobj --version
+
+pe amd64 binaries/amd64-clang-cl-gs dynamicbase high-entropy-va nxcompat gs
+pe amd64 binaries/amd64-clang-cl-no-gs dynamicbase high-entropy-va nxcompat
+pe x86 binaries/x86-clang-cl-gs dynamicbase nxcompat gs
+pe arm64 binaries/arm64-clang-cl-dll-gs dll dynamicbase high-entropy-va nxcompat gs
+pe amd64 binaries/amd64-mingw-stack-protector mingw=13.2.0 dynamicbase high-entropy-va nxcompat gs
+pe amd64 binaries/amd64-mingw-no-stack-protector mingw=13.2.0 dynamicbase high-entropy-va nxcompat
+pe x86 binaries/x86-mingw-stack-protector mingw=13.2.0 dynamicbase nxcompat gs
+pe amd64 binaries/amd64-mingw-4.8-stack-protector mingw=4.8.5 gs
+
+ls -la binaries/
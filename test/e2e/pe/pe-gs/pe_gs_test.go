@@ -0,0 +1,20 @@
+package pe_gs_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestGSRule(t *testing.T) {
+	e2e.RunRuleTests(t, "pe-gs", []e2e.TestCase{
+		{Binary: "amd64-clang-cl-gs", Expect: e2e.Pass},
+		{Binary: "amd64-clang-cl-no-gs", Expect: e2e.Fail},
+		{Binary: "x86-clang-cl-gs", Expect: e2e.Pass},
+		{Binary: "arm64-clang-cl-dll-gs", Expect: e2e.Pass},
+		{Binary: "amd64-mingw-stack-protector", Expect: e2e.Pass},
+		{Binary: "amd64-mingw-no-stack-protector", Expect: e2e.Fail},
+		{Binary: "x86-mingw-stack-protector", Expect: e2e.Pass},
+		{Binary: "amd64-mingw-4.8-stack-protector", Expect: e2e.Pass},
+	})
+}
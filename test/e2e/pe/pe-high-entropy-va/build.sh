@@ -0,0 +1,17 @@
+#!/bin/sh
+set -ex
+
+mkdir -p binaries
+
+. test/e2e/pe/testdata/pe.sh
+llvm-readobj --version
+
+pe amd64 binaries/amd64-mingw-high-entropy-va mingw=13.2.0 dynamicbase high-entropy-va nxcompat
+pe amd64 binaries/amd64-mingw-no-high-entropy-va mingw=13.2.0 dynamicbase nxcompat
+pe amd64 binaries/amd64-mingw-no-dynamicbase mingw=13.2.0 high-entropy-va nxcompat
+pe amd64 binaries/amd64-clang-cl-high-entropy-va dynamicbase high-entropy-va nxcompat
+pe amd64 binaries/amd64-clang-cl-no-high-entropy-va dynamicbase nxcompat
+pe arm64 binaries/arm64-clang-cl-high-entropy-va dynamicbase high-entropy-va nxcompat
+pe x86 binaries/x86-clang-cl dynamicbase nxcompat
+
+ls -la binaries/
@@ -0,0 +1,19 @@
+package pe_high_entropy_va_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestHighEntropyVARule(t *testing.T) {
+	e2e.RunRuleTests(t, "pe-high-entropy-va", []e2e.TestCase{
+		{Binary: "amd64-mingw-high-entropy-va", Expect: e2e.Pass},
+		{Binary: "amd64-mingw-no-high-entropy-va", Expect: e2e.Fail},
+		{Binary: "amd64-mingw-no-dynamicbase", Expect: e2e.Fail},
+		{Binary: "amd64-clang-cl-high-entropy-va", Expect: e2e.Pass},
+		{Binary: "amd64-clang-cl-no-high-entropy-va", Expect: e2e.Fail},
+		{Binary: "arm64-clang-cl-high-entropy-va", Expect: e2e.Pass},
+		{Binary: "x86-clang-cl", Expect: e2e.Skip},
+	})
+}
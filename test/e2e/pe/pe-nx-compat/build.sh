@@ -0,0 +1,17 @@
+#!/bin/sh
+set -ex
+
+mkdir -p binaries
+
+. test/e2e/pe/testdata/pe.sh
+llvm-readobj --version
+
+pe amd64 binaries/amd64-mingw-nxcompat mingw=13.2.0 dynamicbase high-entropy-va nxcompat
+pe amd64 binaries/amd64-mingw-no-nxcompat mingw=13.2.0 dynamicbase high-entropy-va
+pe amd64 binaries/amd64-mingw-4.4 mingw=4.4.7
+pe amd64 binaries/amd64-clang-cl-nxcompat dynamicbase high-entropy-va nxcompat
+pe amd64 binaries/amd64-clang-cl-no-nxcompat dynamicbase high-entropy-va
+pe x86 binaries/x86-clang-cl-nxcompat dynamicbase nxcompat
+pe x86 binaries/x86-mingw-no-nxcompat mingw=13.2.0 dynamicbase
+
+ls -la binaries/
@@ -0,0 +1,19 @@
+package pe_nx_compat_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestNXCompatRule(t *testing.T) {
+	e2e.RunRuleTests(t, "pe-nx-compat", []e2e.TestCase{
+		{Binary: "amd64-mingw-nxcompat", Expect: e2e.Pass},
+		{Binary: "amd64-mingw-no-nxcompat", Expect: e2e.Fail},
+		{Binary: "amd64-mingw-4.4", Expect: e2e.Fail},
+		{Binary: "amd64-clang-cl-nxcompat", Expect: e2e.Pass},
+		{Binary: "amd64-clang-cl-no-nxcompat", Expect: e2e.Fail},
+		{Binary: "x86-clang-cl-nxcompat", Expect: e2e.Pass},
+		{Binary: "x86-mingw-no-nxcompat", Expect: e2e.Fail},
+	})
+}
@@ -0,0 +1,16 @@
+#!/bin/sh
+set -ex
+
+mkdir -p binaries
+
+. test/e2e/pe/testdata/pe.sh
+llvm-readobj --version
+
+pe x86 binaries/x86-clang-cl-safeseh dynamicbase nxcompat safeseh
+pe x86 binaries/x86-clang-cl-no-seh dynamicbase nxcompat no-seh
+pe x86 binaries/x86-clang-cl-no-safeseh dynamicbase nxcompat
+pe x86 binaries/x86-clang-cl-dll-safeseh dll dynamicbase nxcompat safeseh
+pe amd64 binaries/amd64-clang-cl dynamicbase high-entropy-va nxcompat
+pe x86 binaries/x86-mingw mingw=13.2.0 dynamicbase nxcompat
+
+ls -la binaries/
@@ -0,0 +1,18 @@
+package pe_safeseh_test
+
+import (
+	"testing"
+
+	"go.kacmar.sk/crack/test/e2e"
+)
+
+func TestSafeSEHRule(t *testing.T) {
+	e2e.RunRuleTests(t, "pe-safeseh", []e2e.TestCase{
+		{Binary: "x86-clang-cl-safeseh", Expect: e2e.Pass},
+		{Binary: "x86-clang-cl-no-seh", Expect: e2e.Pass},
+		{Binary: "x86-clang-cl-no-safeseh", Expect: e2e.Fail},
+		{Binary: "x86-clang-cl-dll-safeseh", Expect: e2e.Pass},
+		{Binary: "amd64-clang-cl", Expect: e2e.Skip},
+		{Binary: "x86-mingw", Expect: e2e.Skip},
+	})
+}
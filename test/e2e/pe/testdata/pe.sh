@@ -0,0 +1,168 @@
+# Builds stand-ins for the PE images MinGW-w64 GCC and clang-cl with lld-link produce, for toolchain images without
+# a Windows cross toolchain. Each stand-in has the headers, load configuration, debug directory and symbols the pe-*
+# rules look at, described in YAML for yaml2obj from LLVM.
+#
+# pe <machine> <output> [option...] writes an executable for x86, amd64 or arm64. Options:
+#   mingw=<version>  identify as MinGW-w64 GCC <version>, which keeps COFF symbols and records "GCC: (GNU) <version>"
+#                    in .rdata; without it the image is linked by lld-link, which records no compiler
+#   dll              a DLL instead of an executable
+#   dynamicbase, high-entropy-va, nxcompat, force-integrity, guard-cf, no-seh
+#                    set the DLL characteristic, as /DYNAMICBASE, /HIGHENTROPYVA, /NXCOMPAT, /INTEGRITYCHECK,
+#                    /guard:cf and /SAFESEH:NO on images without handlers
+#   gs               with stack cookies: the load configuration registers __security_cookie for clang-cl /GS, and
+#                    __stack_chk_fail is linked in for MinGW -fstack-protector-strong
+#   cfg              with GuardFlags marking code instrumented by /guard:cf
+#   safeseh          with a safe exception handler table, as /SAFESEH on x86
+#   cetcompat        with the extended DLL characteristics debug entry marking CET compatibility, as /CETCOMPAT
+
+PE_DIR=$(mktemp -d)
+trap 'rm -rf "$PE_DIR"' EXIT
+
+# le <size> <value> prints value as a little-endian integer of size bytes.
+le() {
+    i=0
+    while [ $i -lt $1 ]; do
+        printf "\\$(printf %03o $(($2 >> (i * 8) & 255)))"
+        i=$((i + 1))
+    done
+}
+
+# put <file> <offset> <size> <value> writes value as a little-endian integer at offset in file.
+put() { le $3 $4 | dd of="$1" bs=1 seek=$2 conv=notrunc 2>/dev/null; }
+
+hex() { od -An -tx1 -v "$1" | tr -d ' \n'; }
+
+pe() {
+    machine=$1 out=$2
+    shift 2
+    gcc= dll= chars= gs= cfg= safeseh= cetcompat=
+    for opt in "$@"; do
+        case $opt in
+            mingw=*) gcc=${opt#mingw=} ;;
+            dll) dll=1 ;;
+            dynamicbase) chars="$chars IMAGE_DLL_CHARACTERISTICS_DYNAMIC_BASE" ;;
+            high-entropy-va) chars="$chars IMAGE_DLL_CHARACTERISTICS_HIGH_ENTROPY_VA" ;;
+            nxcompat) chars="$chars IMAGE_DLL_CHARACTERISTICS_NX_COMPAT" ;;
+            force-integrity) chars="$chars IMAGE_DLL_CHARACTERISTICS_FORCE_INTEGRITY" ;;
+            guard-cf) chars="$chars IMAGE_DLL_CHARACTERISTICS_GUARD_CF" ;;
+            no-seh) chars="$chars IMAGE_DLL_CHARACTERISTICS_NO_SEH" ;;
+            gs) gs=1 ;;
+            cfg) cfg=1 ;;
+            safeseh) safeseh=1 ;;
+            cetcompat) cetcompat=1 ;;
+            *) echo "unknown option $opt" >&2; return 1 ;;
+        esac
+    done
+
+    # The load configuration directory sizes and field offsets of IMAGE_LOAD_CONFIG_DIRECTORY32 and 64.
+    case $machine in
+        x86) name=IMAGE_FILE_MACHINE_I386 base=$((0x400000)) ptr=4 lcsize=$((0xc0)) cookie=$((0x3c)) seh=$((0x40)) guard=$((0x58)) code=31C0C3 ;;
+        amd64) name=IMAGE_FILE_MACHINE_AMD64 base=$((0x140000000)) ptr=8 lcsize=$((0x140)) cookie=$((0x58)) seh=$((0x60)) guard=$((0x90)) code=31C0C3 ;;
+        arm64) name=IMAGE_FILE_MACHINE_ARM64 base=$((0x140000000)) ptr=8 lcsize=$((0x140)) cookie=$((0x58)) seh=$((0x60)) guard=$((0x90)) code=00008052C0035FD6 ;;
+        *) echo "unknown machine $machine" >&2; return 1 ;;
+    esac
+    characteristics=IMAGE_FILE_EXECUTABLE_IMAGE
+    [ $ptr = 4 ] && characteristics="$characteristics, IMAGE_FILE_32BIT_MACHINE"
+    [ $ptr = 8 ] && characteristics="$characteristics, IMAGE_FILE_LARGE_ADDRESS_AWARE"
+    [ -n "$dll" ] && characteristics="$characteristics, IMAGE_FILE_DLL"
+
+    # .rdata at RVA 0x2000 holds the load configuration at 0x000, the debug directory at 0x200, the extended DLL
+    # characteristics at 0x220, the safe exception handler table at 0x240 and the compiler identification at 0x260.
+    # .data at RVA 0x3000 holds __security_cookie.
+    rdata="$PE_DIR/rdata"
+    dd if=/dev/zero of="$rdata" bs=1 count=$((0x300)) 2>/dev/null
+    loadconfig=
+    if [ -z "$gcc" ] || [ -n "$cfg" ] || [ -n "$safeseh" ]; then
+        loadconfig=1
+        put "$rdata" 0 4 $lcsize
+        [ -n "$gs" ] && put "$rdata" $cookie $ptr $((base + 0x3000))
+        if [ -n "$safeseh" ]; then
+            put "$rdata" $seh $ptr $((base + 0x2240))
+            put "$rdata" $((seh + ptr)) $ptr 1
+            put "$rdata" $((0x240)) 4 $((0x1000))
+        fi
+        [ -n "$cfg" ] && put "$rdata" $guard 4 $((0x100))
+    fi
+    if [ -n "$cetcompat" ]; then
+        put "$rdata" $((0x200 + 12)) 4 20
+        put "$rdata" $((0x200 + 16)) 4 4
+        put "$rdata" $((0x200 + 20)) 4 $((0x2220))
+        put "$rdata" $((0x220)) 4 1
+    fi
+    if [ -n "$gcc" ]; then
+        printf 'GCC: (GNU) %s\000' "$gcc" | dd of="$rdata" bs=1 seek=$((0x260)) conv=notrunc 2>/dev/null
+    fi
+
+    subsystem=IMAGE_SUBSYSTEM_WINDOWS_CUI
+    {
+        echo "--- !COFF"
+        echo "OptionalHeader:"
+        echo "  AddressOfEntryPoint: 4096"
+        echo "  ImageBase: $base"
+        cat <<EOF
+  SectionAlignment: 4096
+  FileAlignment: 512
+  MajorOperatingSystemVersion: 6
+  MinorOperatingSystemVersion: 0
+  MajorImageVersion: 0
+  MinorImageVersion: 0
+  MajorSubsystemVersion: 6
+  MinorSubsystemVersion: 0
+  Subsystem: $subsystem
+  DLLCharacteristics: [ $(echo $chars | sed 's/ /, /g') ]
+  SizeOfStackReserve: 1048576
+  SizeOfStackCommit: 4096
+  SizeOfHeapReserve: 1048576
+  SizeOfHeapCommit: 4096
+EOF
+        if [ -n "$loadconfig" ]; then
+            echo "  LoadConfigTable: { RelativeVirtualAddress: 8192, Size: $lcsize }"
+        fi
+        if [ -n "$cetcompat" ]; then
+            echo "  Debug: { RelativeVirtualAddress: 8704, Size: 28 }"
+        fi
+        cat <<EOF
+header:
+  Machine: $name
+  Characteristics: [ $characteristics ]
+sections:
+  - Name: .text
+    Characteristics: [ IMAGE_SCN_CNT_CODE, IMAGE_SCN_MEM_EXECUTE, IMAGE_SCN_MEM_READ ]
+    VirtualAddress: 4096
+    VirtualSize: $((${#code} / 2))
+    SectionData: $code
+  - Name: .rdata
+    Characteristics: [ IMAGE_SCN_CNT_INITIALIZED_DATA, IMAGE_SCN_MEM_READ ]
+    VirtualAddress: 8192
+    VirtualSize: $((0x300))
+    SectionData: $(hex "$rdata")
+  - Name: .data
+    Characteristics: [ IMAGE_SCN_CNT_INITIALIZED_DATA, IMAGE_SCN_MEM_READ, IMAGE_SCN_MEM_WRITE ]
+    VirtualAddress: 12288
+    VirtualSize: 8
+    SectionData: 32A2DF2D992B0000
+EOF
+        if [ -n "$gcc" ]; then
+            echo "symbols:"
+            symbol main 1 0
+            [ -n "$gs" ] && symbol __stack_chk_fail 1 0
+            symbol __security_cookie 3 0
+        else
+            echo "symbols: []"
+        fi
+    } > "$PE_DIR/pe.yaml"
+
+    yaml2obj "$PE_DIR/pe.yaml" -o "$out"
+}
+
+# symbol <name> <section> <value> prints an external function symbol for the COFF symbol table MinGW keeps.
+symbol() {
+    cat <<EOF
+  - Name: $1
+    Value: $3
+    SectionNumber: $2
+    SimpleType: IMAGE_SYM_TYPE_NULL
+    ComplexType: IMAGE_SYM_DTYPE_FUNCTION
+    StorageClass: IMAGE_SYM_CLASS_EXTERNAL
+EOF
+}
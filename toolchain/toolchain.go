@@ -93,6 +93,20 @@ type StringDetector interface {
 	Detect(s string) (Compiler, Version)
 }
 
+// ParseGoVersion extracts a semantic version from a Go toolchain version string.
+// Accepts forms like "go1.21.3" or "go1.21" and ignores pre-release suffixes.
+func ParseGoVersion(s string) (Version, bool) {
+	s = strings.TrimPrefix(s, "go")
+	if i := strings.IndexAny(s, "-+ "); i >= 0 {
+		s = s[:i]
+	}
+	v, err := ParseVersion(s)
+	if err != nil {
+		return Version{}, false
+	}
+	return v, true
+}
+
 // DefaultStringDetector recognizes gcc, clang, and rustc from the strings their toolchains embed.
 type DefaultStringDetector struct{}
 
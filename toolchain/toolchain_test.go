@@ -82,6 +82,34 @@ func TestVersionIsAtLeast(t *testing.T) {
 	}
 }
 
+func TestParseGoVersion(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   Version
+		wantOK bool
+	}{
+		{"go1.21.3", Version{Major: 1, Minor: 21, Patch: 3}, true},
+		{"go1.21", Version{Major: 1, Minor: 21}, true},
+		{"go1.22-rc1", Version{Major: 1, Minor: 22}, true},
+		{"go1.22+something", Version{Major: 1, Minor: 22}, true},
+		{"go1.22 someinfo", Version{Major: 1, Minor: 22}, true},
+		{"devel go1.22", Version{}, false},
+		{"go1", Version{}, false},
+		{"", Version{}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			got, ok := ParseGoVersion(tc.in)
+			if ok != tc.wantOK {
+				t.Fatalf("ParseGoVersion(%q) ok = %v, want %v", tc.in, ok, tc.wantOK)
+			}
+			if got != tc.want {
+				t.Errorf("ParseGoVersion(%q) = %v, want %v", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
 func TestDefaultStringDetector(t *testing.T) {
 	tests := []struct {
 		name     string